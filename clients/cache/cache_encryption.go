@@ -0,0 +1,98 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// encryptedCachePrefix mark the cache files written with a CacheEncryptionKey, the files without it are
+// plaintext ones written before the key was set, and still readable.
+const encryptedCachePrefix = "nacos-aes-gcm:"
+
+var cacheEncryptionAeads sync.Map
+
+// SetCacheEncryptionKey encrypt the config snapshots in cacheDir with AES-GCM, the key must be 16, 24 or
+// 32 bytes, an empty key turns off the encryption.
+func SetCacheEncryptionKey(cacheDir string, key string) error {
+	if len(key) == 0 {
+		cacheEncryptionAeads.Delete(cacheDir)
+		return nil
+	}
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return errors.Wrap(err, "invalid cache encryption key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	cacheEncryptionAeads.Store(cacheDir, aead)
+	return nil
+}
+
+func getCacheAead(cacheDir string) (cipher.AEAD, bool) {
+	value, ok := cacheEncryptionAeads.Load(cacheDir)
+	if !ok {
+		return nil, false
+	}
+	return value.(cipher.AEAD), true
+}
+
+func encryptCacheContent(cacheDir string, content string) (string, error) {
+	aead, ok := getCacheAead(cacheDir)
+	if !ok || len(content) == 0 {
+		return content, nil
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(content), nil)
+	return encryptedCachePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptCacheContent(cacheDir string, content string) (string, error) {
+	if !strings.HasPrefix(content, encryptedCachePrefix) {
+		return content, nil
+	}
+	aead, ok := getCacheAead(cacheDir)
+	if !ok {
+		return "", errors.New("cache file is encrypted, but the cache encryption key is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(content, encryptedCachePrefix))
+	if err != nil {
+		return "", errors.Wrap(err, "decode encrypted cache file failed")
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted cache file is too short")
+	}
+	nonce, cipherText := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plainText, err := aead.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypt cache file failed")
+	}
+	return string(plainText), nil
+}
@@ -92,6 +92,22 @@ func (m ConcurrentMap) Upsert(key string, value interface{}, cb UpsertCb) (res i
 	return res
 }
 
+// UpdateCb is callback executed in a map.Update() call, while Lock is held
+// it returns the new value of the existing element
+type UpdateCb func(valueInMap interface{}) interface{}
+
+// Update updates the existing element using UpdateCb, it returns false if the key is absent
+func (m ConcurrentMap) Update(key string, cb UpdateCb) bool {
+	shard := m.GetShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if ok {
+		shard.items[key] = cb(v)
+	}
+	return ok
+}
+
 // Sets the given value under the specified key if no value was associated with it.
 func (m ConcurrentMap) SetIfAbsent(key string, value interface{}) bool {
 	// Get map shard.
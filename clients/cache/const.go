@@ -5,7 +5,9 @@ type ConfigCachedFileType string
 const (
 	ConfigContent          ConfigCachedFileType = "Config Content"
 	ConfigEncryptedDataKey ConfigCachedFileType = "Config Encrypted Data Key"
+	ListenerCheckpoint     ConfigCachedFileType = "Listener Checkpoint"
 
-	ENCRYPTED_DATA_KEY_FILE_NAME = "encrypted-data-key"
-	FAILOVER_FILE_SUFFIX         = "_failover"
+	ENCRYPTED_DATA_KEY_FILE_NAME  = "encrypted-data-key"
+	LISTENER_CHECKPOINT_FILE_NAME = "listener-checkpoint"
+	FAILOVER_FILE_SUFFIX          = "_failover"
 )
@@ -77,6 +77,9 @@ func ReadServicesFromFile(cacheDir string) map[string]model.Service {
 	}
 	serviceMap := map[string]model.Service{}
 	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
 		fileName := GetFileName(f.Name(), cacheDir)
 		b, err := os.ReadFile(fileName)
 		if err != nil {
@@ -105,6 +108,10 @@ func WriteConfigToFile(cacheKey string, cacheDir string, content string) error {
 		logger.Error(errMsg)
 		return errors.New(errMsg)
 	}
+	if content, err = encryptCacheContent(cacheDir, content); err != nil {
+		logger.Error(err)
+		return err
+	}
 	err = writeConfigToFile(GetFileName(cacheKey, cacheDir), content, ConfigContent)
 	if err != nil {
 		logger.Error(err)
@@ -120,6 +127,10 @@ func WriteEncryptedDataKeyToFile(cacheKey string, cacheDir string, content strin
 		logger.Error(errMsg)
 		return errors.New(errMsg)
 	}
+	if content, err = encryptCacheContent(cacheDir, content); err != nil {
+		logger.Error(err)
+		return err
+	}
 	err = writeConfigToFile(GetConfigEncryptedDataKeyFileName(cacheKey, cacheDir), content, ConfigEncryptedDataKey)
 	if err != nil {
 		logger.Error(err)
@@ -156,11 +167,15 @@ func ReadEncryptedDataKeyFromFile(cacheKey string, cacheDir string) (string, err
 			return "", nil
 		}
 	}
-	return content, nil
+	return decryptCacheContent(cacheDir, content)
 }
 
 func ReadConfigFromFile(cacheKey string, cacheDir string) (string, error) {
-	return readConfigFromFile(GetFileName(cacheKey, cacheDir), ConfigEncryptedDataKey)
+	content, err := readConfigFromFile(GetFileName(cacheKey, cacheDir), ConfigEncryptedDataKey)
+	if err != nil {
+		return "", err
+	}
+	return decryptCacheContent(cacheDir, content)
 }
 
 func readConfigFromFile(fileName string, fileType ConfigCachedFileType) (string, error) {
@@ -176,6 +191,31 @@ func readConfigFromFile(fileName string, fileType ConfigCachedFileType) (string,
 	return string(b), nil
 }
 
+func GetListenerCheckpointFileName(cacheKey, cacheDir string) string {
+	return cacheDir + string(os.PathSeparator) + LISTENER_CHECKPOINT_FILE_NAME + string(os.PathSeparator) + cacheKey
+}
+
+// WriteListenerCheckpoint persist the md5 of the content the listeners of the config have received, an empty md5
+// deletes the checkpoint.
+func WriteListenerCheckpoint(cacheKey string, cacheDir string, md5 string) error {
+	err := file.MkdirIfNecessary(cacheDir + string(os.PathSeparator) + LISTENER_CHECKPOINT_FILE_NAME)
+	if err != nil {
+		errMsg := fmt.Sprintf("make dir failed, dir path %s, err: %v.", cacheDir, err)
+		logger.Error(errMsg)
+		return errors.New(errMsg)
+	}
+	return writeConfigToFile(GetListenerCheckpointFileName(cacheKey, cacheDir), md5, ListenerCheckpoint)
+}
+
+// ReadListenerCheckpoint return the md5 written by WriteListenerCheckpoint, or empty if there is no checkpoint.
+func ReadListenerCheckpoint(cacheKey string, cacheDir string) string {
+	md5, err := readConfigFromFile(GetListenerCheckpointFileName(cacheKey, cacheDir), ListenerCheckpoint)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(md5)
+}
+
 // GetFailover , get failover content
 func GetFailover(key, dir string) string {
 	filePath := GetConfigFailOverContentFileName(key, dir)
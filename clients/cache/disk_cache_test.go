@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/jun3372/nacos-sdk-go/util"
@@ -126,3 +127,32 @@ func TestGetFailover(t *testing.T) {
 func writeFileContent(filepath, content string) error {
 	return os.WriteFile(filepath, []byte(content), 0666)
 }
+
+func TestWriteAndGetEncryptedConfigToFile(t *testing.T) {
+	encryptedDir := t.TempDir()
+	cacheKey := util.GetConfigCacheKey("config_encrypted", group, ns)
+	configContent := "password=123456"
+
+	assert.NotNil(t, SetCacheEncryptionKey(encryptedDir, "short"))
+	assert.Nil(t, SetCacheEncryptionKey(encryptedDir, "1234567890123456"))
+
+	err := WriteConfigToFile(cacheKey, encryptedDir, configContent)
+	assert.Nil(t, err)
+	raw, err := os.ReadFile(GetFileName(cacheKey, encryptedDir))
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(raw), configContent))
+
+	fromFile, err := ReadConfigFromFile(cacheKey, encryptedDir)
+	assert.Nil(t, err)
+	assert.Equal(t, configContent, fromFile)
+
+	err = WriteEncryptedDataKeyToFile(cacheKey, encryptedDir, "data key")
+	assert.Nil(t, err)
+	dataKey, err := ReadEncryptedDataKeyFromFile(cacheKey, encryptedDir)
+	assert.Nil(t, err)
+	assert.Equal(t, "data key", dataKey)
+
+	assert.Nil(t, SetCacheEncryptionKey(encryptedDir, ""))
+	_, err = ReadConfigFromFile(cacheKey, encryptedDir)
+	assert.NotNil(t, err)
+}
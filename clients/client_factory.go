@@ -17,6 +17,8 @@
 package clients
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
@@ -98,7 +100,7 @@ func setConfig(param vo.NacosClientParam) (iClient nacos_client.INacosClient, er
 
 	if len(param.ServerConfigs) == 0 {
 		clientConfig, _ := client.GetClientConfig()
-		if len(clientConfig.Endpoint) <= 0 {
+		if len(clientConfig.Endpoint) <= 0 && clientConfig.ServerListProvider == nil {
 			err = errors.New("server configs not found in properties")
 			return nil, err
 		}
@@ -120,7 +122,14 @@ func setConfig(param vo.NacosClientParam) (iClient nacos_client.INacosClient, er
 
 	if _, _err := client.GetHttpAgent(); _err != nil {
 		if clientCfg, err := client.GetClientConfig(); err == nil {
-			_ = client.SetHttpAgent(&http_agent.HttpAgent{TlsConfig: clientCfg.TLSCfg})
+			_ = client.SetHttpAgent(&http_agent.HttpAgent{
+				TlsConfig:           clientCfg.TLSCfg,
+				ProxyURL:            clientCfg.ProxyURL,
+				MaxIdleConnsPerHost: clientCfg.HttpMaxIdlePerHost,
+				TLSHandshakeTimeout: time.Duration(clientCfg.HttpTLSHandshakeMs) * time.Millisecond,
+				KeepAlive:           time.Duration(clientCfg.HttpKeepAliveMs) * time.Millisecond,
+				IdleConnTimeout:     time.Duration(clientCfg.HttpIdleTimeoutMs) * time.Millisecond,
+			})
 		}
 	}
 	iClient = client
@@ -0,0 +1,110 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+)
+
+// callbackExecutor run the config callbacks in a bounded pool of workers. The callbacks of the same key are always
+// handled by the same worker, so they are executed one by one in the order of submission, and a panicking callback
+// only breaks itself. The submit never blocks, the callbacks are spilled over when the queue of the worker is full.
+type callbackExecutor struct {
+	ctx     context.Context
+	workers []*callbackWorker
+}
+
+// callbackWorker runs the tasks of its queue then of the spill, once the spill is not empty the new tasks are
+// appended to it to keep the order.
+type callbackWorker struct {
+	queue chan func()
+	wake  chan struct{}
+	mutex sync.Mutex
+	spill []func()
+}
+
+func newCallbackExecutor(ctx context.Context, workerNum, queueSize int) *callbackExecutor {
+	executor := &callbackExecutor{ctx: ctx, workers: make([]*callbackWorker, workerNum)}
+	for i := range executor.workers {
+		worker := &callbackWorker{queue: make(chan func(), queueSize), wake: make(chan struct{}, 1)}
+		executor.workers[i] = worker
+		go executor.work(worker)
+	}
+	return executor
+}
+
+func (e *callbackExecutor) work(worker *callbackWorker) {
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case task := <-worker.queue:
+			e.run(task)
+		case <-worker.wake:
+		}
+		if len(worker.queue) == 0 {
+			for _, task := range worker.takeSpill() {
+				e.run(task)
+			}
+		}
+	}
+}
+
+func (e *callbackExecutor) run(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("config callback panic:%v, stack:%s", r, debug.Stack())
+		}
+	}()
+	task()
+}
+
+// submit put the task into the queue of the key, the task is spilled over without blocking the caller when the
+// queue is full.
+func (e *callbackExecutor) submit(key string, task func()) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	worker := e.workers[h.Sum32()%uint32(len(e.workers))]
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
+	if len(worker.spill) == 0 {
+		select {
+		case worker.queue <- task:
+			return
+		default:
+		}
+		logger.Warnf("config callback queue is full, spill the callbacks over, key:%s", key)
+	}
+	worker.spill = append(worker.spill, task)
+	select {
+	case worker.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *callbackWorker) takeSpill() []func() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	spill := w.spill
+	w.spill = nil
+	return spill
+}
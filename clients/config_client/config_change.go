@@ -0,0 +1,148 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"strings"
+
+	"github.com/jun3372/nacos-sdk-go/common/format"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// maxDiffCells bound the memory of the line diff, larger contents are diffed as fully replaced.
+const maxDiffCells = 4 * 1024 * 1024
+
+func buildConfigChangeEvent(namespace, group, dataId, contentType, oldContent, newContent string) vo.ConfigChangeEvent {
+	event := vo.ConfigChangeEvent{
+		Namespace:  namespace,
+		Group:      group,
+		DataId:     dataId,
+		OldContent: oldContent,
+		NewContent: newContent,
+		Diff:       lineDiff(oldContent, newContent),
+	}
+	contentFormat := format.DetectFormat(contentType, dataId, newContent)
+	if contentFormat == format.TEXT && newContent == "" {
+		contentFormat = format.DetectFormat(contentType, dataId, oldContent)
+	}
+	oldKeys, oldErr := flattenContent(contentFormat, oldContent)
+	newKeys, newErr := flattenContent(contentFormat, newContent)
+	if oldErr == nil && newErr == nil {
+		event.ChangedKeys = changedKeys(oldKeys, newKeys)
+	}
+	return event
+}
+
+func flattenContent(contentFormat, content string) (map[string]string, error) {
+	if strings.TrimSpace(content) == "" {
+		return map[string]string{}, nil
+	}
+	return format.Flatten(contentFormat, content)
+}
+
+func changedKeys(oldKeys, newKeys map[string]string) map[string]vo.ConfigChangeItem {
+	changes := make(map[string]vo.ConfigChangeItem)
+	for key, oldValue := range oldKeys {
+		newValue, ok := newKeys[key]
+		if !ok {
+			changes[key] = vo.ConfigChangeItem{Key: key, OldValue: oldValue, Type: vo.ConfigDeleted}
+		} else if newValue != oldValue {
+			changes[key] = vo.ConfigChangeItem{Key: key, OldValue: oldValue, NewValue: newValue, Type: vo.ConfigModified}
+		}
+	}
+	for key, newValue := range newKeys {
+		if _, ok := oldKeys[key]; !ok {
+			changes[key] = vo.ConfigChangeItem{Key: key, NewValue: newValue, Type: vo.ConfigAdded}
+		}
+	}
+	return changes
+}
+
+// filterChangedKeys return the changes of the keys and their children.
+func filterChangedKeys(changes map[string]vo.ConfigChangeItem, keys []string) map[string]vo.ConfigChangeItem {
+	filtered := make(map[string]vo.ConfigChangeItem)
+	for changedKey, change := range changes {
+		for _, key := range keys {
+			if changedKey == key || (strings.HasPrefix(changedKey, key) &&
+				(changedKey[len(key)] == '.' || changedKey[len(key)] == '[')) {
+				filtered[changedKey] = change
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// lineDiff compute the line-level diff of two contents by the longest common subsequence.
+func lineDiff(oldContent, newContent string) []vo.DiffLine {
+	oldLines, newLines := splitLines(oldContent), splitLines(newContent)
+	n, m := len(oldLines), len(newLines)
+	diff := make([]vo.DiffLine, 0, n+m)
+	if (n+1)*(m+1) > maxDiffCells {
+		for _, line := range oldLines {
+			diff = append(diff, vo.DiffLine{Op: "-", Text: line})
+		}
+		for _, line := range newLines {
+			diff = append(diff, vo.DiffLine{Op: "+", Text: line})
+		}
+		return diff
+	}
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, vo.DiffLine{Op: " ", Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, vo.DiffLine{Op: "-", Text: oldLines[i]})
+			i++
+		default:
+			diff = append(diff, vo.DiffLine{Op: "+", Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, vo.DiffLine{Op: "-", Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, vo.DiffLine{Op: "+", Text: newLines[j]})
+	}
+	return diff
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+}
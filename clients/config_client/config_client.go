@@ -19,7 +19,10 @@ package config_client
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -29,9 +32,11 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	nacos_inner_encryption "github.com/jun3372/nacos-sdk-go/common/encryption"
 	"github.com/jun3372/nacos-sdk-go/common/filter"
+	"github.com/jun3372/nacos-sdk-go/common/format"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
 	"github.com/jun3372/nacos-sdk-go/common/monitor"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
 	"github.com/jun3372/nacos-sdk-go/inner/uuid"
@@ -44,6 +49,7 @@ import (
 const (
 	perTaskConfigSize = 3000
 	executorErrDelay  = 5 * time.Second
+	batchConcurrency  = 16
 )
 
 type ConfigClient struct {
@@ -59,8 +65,28 @@ type ConfigClient struct {
 	cacheMap                 cache.ConcurrentMap
 	uid                      string
 	listenExecute            chan struct{}
+	fuzzyMutex               sync.Mutex
+	fuzzyWatchers            map[string]*fuzzyWatcher
+	md5RetryMutex            sync.Mutex
+	md5RetryTasks            map[string]int
+	callbackExecutor         *callbackExecutor
+	localOverride            *localOverride
+	syncMutex                sync.Mutex
+	syncedKeys               map[string]struct{}
+	syncChanged              chan struct{}
+	snapshotRefreshing       sync.Map
+	listenQueue              *rpc.OrderedExecutor // orders the listen and the cancel of each cache key
 }
 
+// casFailMessage is the prefix of the message returned by the server when the cas publish fails.
+const casFailMessage = "cas publish fail"
+
+// ErrCasConflict means the config has been changed since the expected md5 was read.
+var ErrCasConflict = errors.New("[client.PublishConfigCas] cas publish conflict, the config has been changed")
+
+// ErrReadOnlyClient means the client is created with ClientConfig.ReadOnly, the configs can't be changed by it.
+var ErrReadOnlyClient = errors.New("[client] the config client is read only")
+
 type cacheData struct {
 	isInitializing    bool
 	dataId            string
@@ -75,16 +101,124 @@ type cacheData struct {
 	taskId            int
 	configClient      *ConfigClient
 	isSyncWithServer  bool
+	notifyTime        time.Time
+}
+
+type configListener struct {
+	id         uint64 // 0 for the listeners added by ListenConfig, they are only removed with the whole cache
+	listener   vo.Listener
+	listenerV2 func(event vo.ConfigChangeEvent)
 }
 
+// listenerSeq is the id of the last listener which can be removed alone, e.g. the listener of a ConfigRef.
+var listenerSeq uint64
+
+// cacheDataListener fan out the changes of one listen entry to all the listeners of the config.
 type cacheDataListener struct {
-	listener vo.Listener
-	lastMd5  string
+	mutex       sync.RWMutex
+	listeners   []configListener
+	lastMd5     string
+	lastContent string
+}
+
+func (l *cacheDataListener) addListener(listener configListener) {
+	if listener.listener == nil && listener.listenerV2 == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.listeners = append(l.listeners, listener)
+}
+
+// removeListener remove the listener of the id and return the number of the remaining listeners.
+func (l *cacheDataListener) removeListener(id uint64) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i, listener := range l.listeners {
+		if listener.id == id {
+			l.listeners = append(l.listeners[:i:i], l.listeners[i+1:]...)
+			break
+		}
+	}
+	return len(l.listeners)
+}
+
+func (l *cacheDataListener) getListeners() []configListener {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return append([]configListener(nil), l.listeners...)
+}
+
+func (l *cacheDataListener) getLastMd5() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.lastMd5
+}
+
+func (l *cacheDataListener) setLastMd5(md5 string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.lastMd5 = md5
+}
+
+// lastNotified return the md5 and the decrypted content the listeners have been notified of.
+func (l *cacheDataListener) lastNotified() (string, string) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.lastMd5, l.lastContent
+}
+
+// setLastNotified record the md5 and the decrypted content notified to the listeners, the previous content
+// is returned.
+func (l *cacheDataListener) setLastNotified(md5, content string) string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	lastContent := l.lastContent
+	l.lastMd5, l.lastContent = md5, content
+	return lastContent
+}
+
+// notifyListener send the content the other listeners have received to a new listener.
+func (cacheData *cacheData) notifyListener(l configListener, content string) {
+	if len(content) <= 0 {
+		return
+	}
+	cacheKey := util.GetConfigCacheKey(cacheData.dataId, cacheData.group, cacheData.tenant)
+	tenant, group, dataId, contentType := cacheData.tenant, cacheData.group, cacheData.dataId, cacheData.contentType
+	if listener := l.listener; listener != nil {
+		cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+			listener(tenant, group, dataId, content)
+		})
+	}
+	if listenerV2 := l.listenerV2; listenerV2 != nil {
+		cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+			listenerV2(buildConfigChangeEvent(tenant, group, dataId, contentType, "", content))
+		})
+	}
+}
+
+// writeBack stores the data read outside the listenQueue back into the cache, it's skipped if the config was
+// canceled or listened again meanwhile, so a canceled config isn't listened on the server again.
+func (client *ConfigClient) writeBack(data cacheData) bool {
+	written := false
+	client.cacheMap.Update(util.GetConfigCacheKey(data.dataId, data.group, data.tenant), func(v interface{}) interface{} {
+		if current, ok := v.(cacheData); ok && current.cacheDataListener == data.cacheDataListener {
+			written = true
+			return data
+		}
+		return v
+	})
+	return written
 }
 
 func (cacheData *cacheData) executeListener() {
-	cacheData.cacheDataListener.lastMd5 = cacheData.md5
-	cacheData.configClient.cacheMap.Set(util.GetConfigCacheKey(cacheData.dataId, cacheData.group, cacheData.tenant), *cacheData)
+	notifyTime := cacheData.notifyTime
+	cacheData.notifyTime = time.Time{}
+	if !cacheData.configClient.writeBack(*cacheData) {
+		logger.Infof("[config_rpc_client] skip the listeners of the canceled config, dataId=%s, group=%s, tenant=%s",
+			cacheData.dataId, cacheData.group, cacheData.tenant)
+		return
+	}
 
 	param := &vo.ConfigParam{
 		DataId:           cacheData.dataId,
@@ -95,10 +229,46 @@ func (cacheData *cacheData) executeListener() {
 	if err := cacheData.configClient.configFilterChainManager.DoFilters(param); err != nil {
 		logger.Errorf("do filters failed ,dataId=%s,group=%s,tenant=%s,err:%+v ", cacheData.dataId,
 			cacheData.group, cacheData.tenant, err)
+		cacheData.cacheDataListener.setLastMd5(cacheData.md5)
 		return
 	}
 	decryptedContent := param.Content
-	go cacheData.cacheDataListener.listener(cacheData.tenant, cacheData.group, cacheData.dataId, decryptedContent)
+	lastContent := cacheData.cacheDataListener.setLastNotified(cacheData.md5, decryptedContent)
+	cacheKey := util.GetConfigCacheKey(cacheData.dataId, cacheData.group, cacheData.tenant)
+	tenant, group, dataId, contentType := cacheData.tenant, cacheData.group, cacheData.dataId, cacheData.contentType
+	var (
+		event     vo.ConfigChangeEvent
+		eventOnce sync.Once
+	)
+	for _, l := range cacheData.cacheDataListener.getListeners() {
+		if listener := l.listener; listener != nil {
+			cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+				listener(tenant, group, dataId, decryptedContent)
+			})
+		}
+		if listenerV2 := l.listenerV2; listenerV2 != nil {
+			// the event is built once and shared by all the listeners of the change
+			cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+				eventOnce.Do(func() {
+					event = buildConfigChangeEvent(tenant, group, dataId, contentType, lastContent, decryptedContent)
+				})
+				listenerV2(event)
+			})
+		}
+	}
+	if md5, cacheDir := cacheData.md5, cacheData.configClient.configCacheDir; len(md5) > 0 {
+		// the callbacks of a config run in order, so the checkpoint is written after all the listeners return
+		cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+			if err := cache.WriteListenerCheckpoint(cacheKey, cacheDir, md5); err != nil {
+				logger.Warnf("write listener checkpoint failed, dataId=%s, group=%s, err:%v", dataId, group, err)
+			}
+		})
+	}
+	if !notifyTime.IsZero() {
+		cacheData.configClient.callbackExecutor.submit(cacheKey, func() {
+			monitor.GetConfigMetrics().ObserveNotify(dataId, group, time.Since(notifyTime))
+		})
+	}
 }
 
 func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
@@ -123,6 +293,11 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 	}
 	clientConfig.CacheDir = clientConfig.CacheDir + string(os.PathSeparator) + "config"
 	config.configCacheDir = clientConfig.CacheDir
+	if len(clientConfig.CacheEncryptionKey) > 0 {
+		if err = cache.SetCacheEncryptionKey(config.configCacheDir, clientConfig.CacheEncryptionKey); err != nil {
+			return nil, err
+		}
+	}
 
 	if config.configProxy, err = NewConfigProxy(config.ctx, serverConfig, clientConfig, httpAgent); err != nil {
 		return nil, err
@@ -138,6 +313,11 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 		if err != nil {
 			logger.Error(err)
 		}
+	} else if err = config.registerEncryptionPlugins(clientConfig); err != nil {
+		return nil, err
+	}
+	if err = config.registerConfigFilters(); err != nil {
+		return nil, err
 	}
 
 	uid, err := uuid.NewV4()
@@ -148,84 +328,94 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 	config.uid = uid.String()
 	config.cacheMap = cache.NewConcurrentMap()
 	config.listenExecute = make(chan struct{})
+	config.fuzzyWatchers = make(map[string]*fuzzyWatcher)
+	config.md5RetryTasks = make(map[string]int)
+	config.syncedKeys = make(map[string]struct{})
+	config.listenQueue = rpc.NewOrderedExecutor()
+	callbackQueueSize := clientConfig.CallbackQueueSize
+	if callbackQueueSize <= 0 {
+		callbackQueueSize = constant.DEFAULT_CALLBACK_QUEUE_SIZE
+	}
+	callbackWorkerNum := clientConfig.CallbackWorkerNum
+	if callbackWorkerNum <= 0 {
+		callbackWorkerNum = constant.DEFAULT_CALLBACK_WORKER_NUM
+	}
+	config.callbackExecutor = newCallbackExecutor(config.ctx, callbackWorkerNum, callbackQueueSize)
+	if len(clientConfig.LocalConfigDir) > 0 {
+		config.localOverride = newLocalOverride(clientConfig.LocalConfigDir)
+		if err = config.localOverride.start(config.ctx.Done(), config.onLocalOverrideChange); err != nil {
+			logger.Warnf("[local-override] watch %s failed, the changes of local files will not be notified, err:%v",
+				clientConfig.LocalConfigDir, err)
+		}
+	}
 	config.startInternal()
 	return config, err
 }
 
+// registerEncryptionPlugins use the plugins of the default plugin manager, and the cipher-aes plugin
+// if AesEncryptionKey is set, to encrypt and decrypt the cipher- configs.
+func (client *ConfigClient) registerEncryptionPlugins(clientConfig constant.ClientConfig) error {
+	pluginManager := nacos_inner_encryption.GetDefaultEncryptionPluginManager().Clone()
+	if len(clientConfig.AesEncryptionKey) > 0 {
+		aesPlugin, err := nacos_inner_encryption.NewAesPlugin(clientConfig.AesEncryptionKey)
+		if err != nil {
+			return err
+		}
+		if err = pluginManager.Join(aesPlugin); err != nil {
+			return err
+		}
+	}
+	if pluginManager.Size() == 0 {
+		return nil
+	}
+	encryptionFilter := filter.NewDefaultConfigEncryptionFilter(nacos_inner_encryption.NewPluginManagerHandler(pluginManager))
+	return filter.RegisterConfigFilterToChain(client.configFilterChainManager, encryptionFilter)
+}
+
 func initLogger(clientConfig constant.ClientConfig) error {
 	return logger.InitLogger(logger.BuildLoggerConfig(clientConfig))
 }
 
 func (client *ConfigClient) GetConfig(param vo.ConfigParam) (content string, err error) {
-	content, encryptedDataKey, err := client.getConfigInner(param)
+	result, err := client.GetConfigWithResult(param)
 	if err != nil {
 		return "", err
 	}
+	return result.Content, nil
+}
+
+// GetConfigWithResult get the config from the sources in the order of ClientConfig.ConfigSourcePriority, the
+// result tells which source served the content.
+func (client *ConfigClient) GetConfigWithResult(param vo.ConfigParam) (*model.GetConfigResult, error) {
+	result, encryptedDataKey, err := client.getConfigFromSources(param)
+	if err != nil {
+		return nil, err
+	}
 	deepCopyParam := param.DeepCopy()
 	deepCopyParam.EncryptedDataKey = encryptedDataKey
-	deepCopyParam.Content = content
+	deepCopyParam.Content = result.Content
 	deepCopyParam.UsageType = vo.ResponseType
 	if err = client.configFilterChainManager.DoFilters(deepCopyParam); err != nil {
-		return "", err
+		return nil, err
 	}
-	content = deepCopyParam.Content
-	return content, nil
+	result.Content = deepCopyParam.Content
+	return result, nil
 }
 
-func (client *ConfigClient) getConfigInner(param vo.ConfigParam) (content, encryptedDataKey string, err error) {
-	if len(param.DataId) <= 0 {
-		err = errors.New("[client.GetConfig] param.dataId can not be empty")
-		return "", "", err
-	}
-	if len(param.Group) <= 0 {
-		param.Group = constant.DEFAULT_GROUP
-	}
-
-	clientConfig, _ := client.GetClientConfig()
-	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
-	content = cache.GetFailover(cacheKey, client.configCacheDir)
-	if len(content) > 0 {
-		logger.Warnf("%s %s %s is using failover content!", clientConfig.NamespaceId, param.Group, param.DataId)
-		encryptedDataKey = cache.GetFailoverEncryptedDataKey(cacheKey, client.configCacheDir)
-		return content, encryptedDataKey, nil
-	}
-	response, err := client.configProxy.queryConfig(param.DataId, param.Group, clientConfig.NamespaceId,
-		clientConfig.TimeoutMs, false, client)
+// GetConfigAs get the config and decode it into v, the format is param.Type if set, otherwise
+// detected from the dataId extension or the content.
+func (client *ConfigClient) GetConfigAs(param vo.ConfigParam, v interface{}) error {
+	content, err := client.GetConfig(param)
 	if err != nil {
-		logger.Errorf("get config from server error:%v, dataId=%s, group=%s, namespaceId=%s", err,
-			param.DataId, param.Group, clientConfig.NamespaceId)
-
-		if clientConfig.DisableUseSnapShot {
-			return "", "", errors.Errorf("get config from remote nacos server fail, and is not allowed to read local file, err:%v", err)
-		}
-
-		cacheContent, cacheErr := cache.ReadConfigFromFile(cacheKey, client.configCacheDir)
-		if cacheErr != nil {
-			return "", "", errors.Errorf("read config from both server and cache fail, err=%v，dataId=%s, group=%s, namespaceId=%s",
-				cacheErr, param.DataId, param.Group, clientConfig.NamespaceId)
-		}
-
-		if !strings.HasPrefix(param.DataId, nacos_inner_encryption.CipherPrefix) {
-			return cacheContent, "", nil
-		}
-		encryptedDataKey, cacheErr = cache.ReadEncryptedDataKeyFromFile(cacheKey, client.configCacheDir)
-		if cacheErr != nil {
-			return "", "", errors.Errorf("read encryptedDataKey from server and cache fail, err=%v，dataId=%s, group=%s, namespaceId=%s",
-				cacheErr, param.DataId, param.Group, clientConfig.NamespaceId)
-		}
-
-		logger.Warnf("read config from cache success, dataId=%s, group=%s, namespaceId=%s", param.DataId, param.Group, clientConfig.NamespaceId)
-		return cacheContent, encryptedDataKey, nil
-	}
-	if response != nil && response.Response != nil && !response.IsSuccess() {
-		return response.Content, response.EncryptedDataKey, errors.New(response.GetMessage())
+		return err
 	}
-	encryptedDataKey = response.EncryptedDataKey
-	content = response.Content
-	return content, encryptedDataKey, nil
+	return format.Decode(format.DetectFormat(param.Type, param.DataId, content), []byte(content), v)
 }
 
 func (client *ConfigClient) PublishConfig(param vo.ConfigParam) (published bool, err error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.PublishConfig] param.dataId can not be empty")
 		return
@@ -239,31 +429,159 @@ func (client *ConfigClient) PublishConfig(param vo.ConfigParam) (published bool,
 		param.Group = constant.DEFAULT_GROUP
 	}
 
+	clientConfig, _ := client.GetClientConfig()
+	if len(param.Type) <= 0 && clientConfig.InferConfigType {
+		param.Type = format.InferType(param.DataId, param.Content)
+	}
+	if err = format.Validate(param.Type, param.Content); err != nil {
+		return false, errors.WithMessage(err, "[client.PublishConfig] content doesn't match the type")
+	}
+
 	param.UsageType = vo.RequestType
 	if err = client.configFilterChainManager.DoFilters(&param); err != nil {
 		return false, err
 	}
 
-	clientConfig, _ := client.GetClientConfig()
-	request := rpc_request.NewConfigPublishRequest(param.Group, param.DataId, clientConfig.NamespaceId, param.Content, param.CasMd5)
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	request := rpc_request.NewConfigPublishRequest(param.Group, param.DataId, namespaceId, param.Content, param.CasMd5)
 	request.AdditionMap["tag"] = param.Tag
 	request.AdditionMap["appName"] = param.AppName
 	request.AdditionMap["betaIps"] = param.BetaIps
 	request.AdditionMap["type"] = param.Type
 	request.AdditionMap["src_user"] = param.SrcUser
 	request.AdditionMap["encryptedDataKey"] = param.EncryptedDataKey
+	request.AdditionMap["config_tags"] = param.ConfigTags
+	request.AdditionMap["desc"] = param.Desc
+	request.AdditionMap["use"] = param.Use
+	request.AdditionMap["effect"] = param.Effect
+	request.AdditionMap["schema"] = param.Schema
 	rpcClient := client.configProxy.getRpcClient(client)
-	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
+	defer func(start time.Time) {
+		monitor.GetConfigMetrics().ObservePublish(param.DataId, param.Group, time.Since(start), err)
+	}(time.Now())
+	response, err := client.publishWithRetry(rpcClient, request)
 	if err != nil {
 		return false, err
 	}
 	if response != nil {
+		if len(param.CasMd5) > 0 && isCasConflict(response) {
+			return false, ErrCasConflict
+		}
 		return client.buildResponse(response)
 	}
 	return false, err
 }
 
+// PublishConfigCas publish the config only when the md5 of the content on the server is expectedMd5, it returns
+// ErrCasConflict when the config has been changed by others, then the caller should get the config again and retry.
+func (client *ConfigClient) PublishConfigCas(param vo.ConfigParam, expectedMd5 string) (bool, error) {
+	if len(expectedMd5) <= 0 {
+		return false, errors.New("[client.PublishConfigCas] expectedMd5 can not be empty")
+	}
+	param.CasMd5 = expectedMd5
+	return client.PublishConfig(param)
+}
+
+func (client *ConfigClient) isReadOnly() bool {
+	clientConfig, _ := client.GetClientConfig()
+	return clientConfig.ReadOnly
+}
+
+func isCasConflict(response rpc_response.IResponse) bool {
+	return !response.IsSuccess() && strings.Contains(strings.ToLower(response.GetMessage()), casFailMessage)
+}
+
+// BatchPublishConfig publish the configs concurrently over the shared rpc client, the results are in
+// the same order as params.
+func (client *ConfigClient) BatchPublishConfig(params []vo.ConfigParam) []model.BatchConfigResult {
+	return client.executeBatch(params, func(param vo.ConfigParam, result *model.BatchConfigResult) {
+		result.Success, result.Err = client.PublishConfig(param)
+	})
+}
+
+// BatchGetConfig get the configs concurrently over the shared rpc client, the results are in the
+// same order as params.
+func (client *ConfigClient) BatchGetConfig(params []vo.ConfigParam) []model.BatchConfigResult {
+	return client.executeBatch(params, func(param vo.ConfigParam, result *model.BatchConfigResult) {
+		result.Content, result.Err = client.GetConfig(param)
+		result.Success = result.Err == nil
+	})
+}
+
+func (client *ConfigClient) executeBatch(params []vo.ConfigParam, execute func(vo.ConfigParam, *model.BatchConfigResult)) []model.BatchConfigResult {
+	results := make([]model.BatchConfigResult, len(params))
+	semaphore := util.NewSemaphore(batchConcurrency)
+	wg := sync.WaitGroup{}
+	for i := range params {
+		results[i].DataId = params[i].DataId
+		results[i].Group = params[i].Group
+		if len(results[i].Group) <= 0 {
+			results[i].Group = constant.DEFAULT_GROUP
+		}
+		semaphore.Acquire()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer semaphore.Release()
+			execute(params[i], &results[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+func (client *ConfigClient) PublishConfigBeta(param vo.ConfigParam) (published bool, err error) {
+	if len(param.BetaIps) <= 0 {
+		err = errors.New("[client.PublishConfigBeta] param.betaIps can not be empty")
+		return
+	}
+	return client.PublishConfig(param)
+}
+
+func (client *ConfigClient) GetConfigBeta(param vo.ConfigParam) (*model.ConfigBetaItem, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.GetConfigBeta] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	betaItem, err := client.configProxy.queryConfigBetaProxy(param.DataId, param.Group, namespaceId)
+	if err != nil || betaItem == nil {
+		return betaItem, err
+	}
+	deepCopyParam := param.DeepCopy()
+	deepCopyParam.EncryptedDataKey = betaItem.EncryptedDataKey
+	deepCopyParam.Content = betaItem.Content
+	deepCopyParam.UsageType = vo.ResponseType
+	if err = client.configFilterChainManager.DoFilters(deepCopyParam); err != nil {
+		return nil, err
+	}
+	betaItem.Content = deepCopyParam.Content
+	return betaItem, nil
+}
+
+func (client *ConfigClient) StopConfigBeta(param vo.ConfigParam) (stopped bool, err error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
+	if len(param.DataId) <= 0 {
+		err = errors.New("[client.StopConfigBeta] param.dataId can not be empty")
+		return
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	return client.configProxy.stopConfigBetaProxy(param.DataId, param.Group, namespaceId)
+}
+
 func (client *ConfigClient) DeleteConfig(param vo.ConfigParam) (deleted bool, err error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.DeleteConfig] param.dataId can not be empty")
 	}
@@ -274,7 +592,8 @@ func (client *ConfigClient) DeleteConfig(param vo.ConfigParam) (deleted bool, er
 		return false, err
 	}
 	clientConfig, _ := client.GetClientConfig()
-	request := rpc_request.NewConfigRemoveRequest(param.Group, param.DataId, clientConfig.NamespaceId)
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	request := rpc_request.NewConfigRemoveRequest(param.Group, param.DataId, namespaceId)
 	rpcClient := client.configProxy.getRpcClient(client)
 	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
 	if err != nil {
@@ -286,6 +605,40 @@ func (client *ConfigClient) DeleteConfig(param vo.ConfigParam) (deleted bool, er
 	return false, err
 }
 
+// DeleteConfigWithScope delete the whole config, only its beta version, or only one tag of it.
+func (client *ConfigClient) DeleteConfigWithScope(param vo.DeleteConfigParam) (bool, error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
+	if len(param.DataId) <= 0 {
+		return false, errors.New("[client.DeleteConfigWithScope] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	switch param.Scope {
+	case "", vo.DeleteScopeAll:
+		return client.DeleteConfig(vo.ConfigParam{DataId: param.DataId, Group: param.Group, NamespaceId: param.NamespaceId})
+	case vo.DeleteScopeBeta:
+		return client.configProxy.stopConfigBetaProxy(param.DataId, param.Group, namespaceId)
+	case vo.DeleteScopeTag:
+		if len(param.Tag) <= 0 {
+			return false, errors.New("[client.DeleteConfigWithScope] param.tag can not be empty when scope is TAG")
+		}
+		request := rpc_request.NewConfigRemoveRequest(param.Group, param.DataId, namespaceId)
+		request.Tag = param.Tag
+		response, err := client.configProxy.requestProxy(client.configProxy.getRpcClient(client), request, constant.DEFAULT_TIMEOUT_MILLS)
+		if err != nil {
+			return false, err
+		}
+		return client.buildResponse(response)
+	default:
+		return false, errors.Errorf("[client.DeleteConfigWithScope] unknown scope:%s", param.Scope)
+	}
+}
+
 // Cancel Listen Config
 func (client *ConfigClient) CancelListenConfig(param vo.ConfigParam) (err error) {
 	clientConfig, err := client.GetClientConfig()
@@ -293,12 +646,116 @@ func (client *ConfigClient) CancelListenConfig(param vo.ConfigParam) (err error)
 		logger.Errorf("[checkConfigInfo.GetClientConfig] failed,err:%+v", err)
 		return
 	}
-	client.cacheMap.Remove(util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId))
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, namespaceId)
+	<-client.listenQueue.Submit(cacheKey, func() {
+		client.removeCache(cacheKey)
+	})
 	logger.Infof("Cancel listen config DataId:%s Group:%s", param.DataId, param.Group)
 	return err
 }
 
+// cancelListener remove the listener of the id added by listenConfig, the config is only cancelled when it
+// has no listener left.
+func (client *ConfigClient) cancelListener(param vo.ConfigParam, id uint64) error {
+	clientConfig, err := client.GetClientConfig()
+	if err != nil {
+		return err
+	}
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, resolveNamespaceId(clientConfig, param.NamespaceId))
+	<-client.listenQueue.Submit(cacheKey, func() {
+		value, ok := client.cacheMap.Get(cacheKey)
+		if !ok || value.(cacheData).cacheDataListener.removeListener(id) > 0 {
+			return
+		}
+		client.removeCache(cacheKey)
+		logger.Infof("Cancel listen config DataId:%s Group:%s", param.DataId, param.Group)
+	})
+	return nil
+}
+
+// removeCache stop listening the config, it must be called in the listenQueue of the cache key.
+func (client *ConfigClient) removeCache(cacheKey string) {
+	value, ok := client.cacheMap.Pop(cacheKey)
+	client.unmarkSynced(cacheKey)
+	_ = cache.WriteListenerCheckpoint(cacheKey, client.configCacheDir, "")
+	if ok {
+		client.removeListenOnServer(cacheKey, value.(cacheData))
+	}
+}
+
+// removeListenOnServer queue a remove-listen request of the config after the listen requests sent before, it's
+// skipped if the config is listened again when it runs.
+func (client *ConfigClient) removeListenOnServer(cacheKey string, data cacheData) {
+	client.listenQueue.Submit(cacheKey, func() {
+		if client.ctx.Err() != nil || client.cacheMap.Has(cacheKey) {
+			return
+		}
+		request := rpc_request.NewConfigBatchListenRequest(1)
+		request.Listen = false
+		request.ConfigListenContexts = append(request.ConfigListenContexts,
+			model.ConfigListenContext{Group: data.group, Md5: data.md5, DataId: data.dataId, Tenant: data.tenant})
+		rpcClient := client.configProxy.createRpcClient(client.ctx, fmt.Sprintf("%d", data.taskId), client)
+		if _, err := client.configProxy.requestProxy(rpcClient, request, 3000); err != nil {
+			logger.Warnf("remove listen config failed, dataId:%s, group:%s, err:%v", data.dataId, data.group, err)
+		}
+	})
+}
+
+// ServerHealth return the connectivity of the config rpc client to each nacos server, it can be used as a
+// readiness probe of the application.
+func (client *ConfigClient) ServerHealth() model.ServerHealth {
+	return client.configProxy.getRpcClient(client).ServerHealth()
+}
+
+// ActiveProtocol return grpc, or http when the server is 1.x.
+func (client *ConfigClient) ActiveProtocol() string {
+	return client.configProxy.activeProtocol()
+}
+
+// WatchConnection add a callback of the events of the config rpc connections, including the ones created later
+func (client *ConfigClient) WatchConnection(param *vo.WatchConnectionParam) error {
+	if param.Callback == nil {
+		return errors.New("callback cannot be nil")
+	}
+	client.configProxy.watchConnection(&param.Callback)
+	return nil
+}
+
+// UnwatchConnection remove the callback added by WatchConnection
+func (client *ConfigClient) UnwatchConnection(param *vo.WatchConnectionParam) error {
+	client.configProxy.unwatchConnection(&param.Callback)
+	return nil
+}
+
+// ListListeners return the listened configs and the number of listeners registered on each of them.
+func (client *ConfigClient) ListListeners() []model.ConfigListenerInfo {
+	items := client.cacheMap.Items()
+	infos := make([]model.ConfigListenerInfo, 0, len(items))
+	for _, v := range items {
+		data := v.(cacheData)
+		infos = append(infos, model.ConfigListenerInfo{
+			DataId:           data.dataId,
+			Group:            data.group,
+			Tenant:           data.tenant,
+			Md5:              data.md5,
+			ListenerCount:    len(data.cacheDataListener.getListeners()),
+			IsSyncWithServer: data.isSyncWithServer,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return util.GetConfigCacheKey(infos[i].DataId, infos[i].Group, infos[i].Tenant) <
+			util.GetConfigCacheKey(infos[j].DataId, infos[j].Group, infos[j].Tenant)
+	})
+	return infos
+}
+
 func (client *ConfigClient) ListenConfig(param vo.ConfigParam) (err error) {
+	return client.listenConfig(param, 0)
+}
+
+// listenConfig add the listener with the id, a listener with a none zero id can be removed alone by cancelListener.
+func (client *ConfigClient) listenConfig(param vo.ConfigParam, id uint64) (err error) {
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.ListenConfig] DataId can not be empty")
 		return err
@@ -312,12 +769,28 @@ func (client *ConfigClient) ListenConfig(param vo.ConfigParam) (err error) {
 		err = errors.New("[checkConfigInfo.GetClientConfig] failed")
 		return err
 	}
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
 
-	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
+	key := util.GetConfigCacheKey(param.DataId, param.Group, namespaceId)
+	<-client.listenQueue.Submit(key, func() {
+		client.addCacheListener(key, namespaceId, param, id)
+	})
+	return
+}
+
+// addCacheListener add the listener to the cache of the config, the cache is created from the local snapshot
+// if the config isn't listened. A listener with an id added to a notified cache receives the current content at
+// once, so a watch doesn't miss the change made after it reads the config.
+func (client *ConfigClient) addCacheListener(key, namespaceId string, param vo.ConfigParam, id uint64) {
 	var cData cacheData
+	newListener := configListener{id: id, listener: param.OnChange, listenerV2: param.OnChangeV2}
 	if v, ok := client.cacheMap.Get(key); ok {
 		cData = v.(cacheData)
 		cData.isInitializing = true
+		cData.cacheDataListener.addListener(newListener)
+		if lastMd5, lastContent := cData.cacheDataListener.lastNotified(); id > 0 && len(cData.md5) > 0 && cData.md5 == lastMd5 {
+			cData.notifyListener(newListener, lastContent)
+		}
 	} else {
 		var (
 			content  string
@@ -330,17 +803,24 @@ func (client *ConfigClient) ListenConfig(param vo.ConfigParam) (err error) {
 		encryptedDataKey, _ := cache.ReadEncryptedDataKeyFromFile(key, client.configCacheDir)
 		if len(content) > 0 {
 			md5Str = util.Md5(content)
+			monitor.GetConfigMetrics().IncCacheHit(param.DataId, param.Group)
 		}
-		listener := &cacheDataListener{
-			listener: param.OnChange,
-			lastMd5:  md5Str,
+		// the listeners are notified on the first sync when the server content differs from the checkpoint
+		lastMd5 := cache.ReadListenerCheckpoint(key, client.configCacheDir)
+		if len(lastMd5) <= 0 {
+			lastMd5 = md5Str
+		}
+		listener := &cacheDataListener{lastMd5: lastMd5}
+		listener.addListener(newListener)
+		if !strings.HasPrefix(param.DataId, nacos_inner_encryption.CipherPrefix) {
+			listener.lastContent = content
 		}
 
 		cData = cacheData{
 			isInitializing:    true,
 			dataId:            param.DataId,
 			group:             param.Group,
-			tenant:            clientConfig.NamespaceId,
+			tenant:            namespaceId,
 			content:           content,
 			md5:               md5Str,
 			cacheDataListener: listener,
@@ -350,15 +830,254 @@ func (client *ConfigClient) ListenConfig(param vo.ConfigParam) (err error) {
 		}
 	}
 	client.cacheMap.Set(key, cData)
-	return
+	if len(cData.content) > 0 {
+		client.markSynced(key)
+	}
+}
+
+// ListenConfigAs listen the config and decode every change into the value returned by newValue.
+func (client *ConfigClient) ListenConfigAs(param vo.ConfigParam, newValue func() interface{}, onChange vo.TypedListener) (err error) {
+	if newValue == nil || onChange == nil {
+		return errors.New("[client.ListenConfigAs] newValue and onChange can not be nil")
+	}
+	declared := param.Type
+	param.OnChange = func(namespace, group, dataId, data string) {
+		value := newValue()
+		decodeErr := format.Decode(format.DetectFormat(declared, dataId, data), []byte(data), value)
+		onChange(namespace, group, dataId, value, decodeErr)
+	}
+	return client.ListenConfig(param)
+}
+
+// ListenKeys listen the keys of a properties, yaml, json or toml config, onChange is called only when the listened
+// keys change. A key also matches its children, e.g. "db" matches "db.url" and "db.hosts[0]".
+func (client *ConfigClient) ListenKeys(param vo.ConfigParam, keys []string, onChange vo.KeysListener) (err error) {
+	if len(keys) <= 0 {
+		return errors.New("[client.ListenKeys] keys can not be empty")
+	}
+	if onChange == nil {
+		return errors.New("[client.ListenKeys] onChange can not be nil")
+	}
+	param.OnChange = nil
+	param.OnChangeV2 = func(event vo.ConfigChangeEvent) {
+		if changes := filterChangedKeys(event.ChangedKeys, keys); len(changes) > 0 {
+			onChange(event.Namespace, event.Group, event.DataId, changes)
+		}
+	}
+	return client.ListenConfig(param)
 }
 
 func (client *ConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
 	return client.searchConfigInner(param)
 }
 
+func (client *ConfigClient) GetConfigHistory(param vo.ConfigHistoryParam) (*model.ConfigHistoryPage, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.GetConfigHistory] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	if param.PageNo <= 0 {
+		param.PageNo = 1
+	}
+	if param.PageSize <= 0 {
+		param.PageSize = 10
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	return client.configProxy.configHistoryProxy(param, namespaceId)
+}
+
+func (client *ConfigClient) GetConfigHistoryDetail(param vo.ConfigHistoryDetailParam) (*model.ConfigHistoryItem, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.GetConfigHistoryDetail] param.dataId can not be empty")
+	}
+	if param.Nid <= 0 {
+		return nil, errors.New("[client.GetConfigHistoryDetail] param.nid must be greater than 0")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	return client.configProxy.configHistoryDetailProxy(param, namespaceId)
+}
+
+// RollbackConfig restore the config to the content of a history version. Rolling back an insert
+// operation deletes the config, the same as the console does.
+func (client *ConfigClient) RollbackConfig(param vo.ConfigHistoryDetailParam) (bool, error) {
+	historyItem, err := client.GetConfigHistoryDetail(param)
+	if err != nil {
+		return false, err
+	}
+	if historyItem.OpType == "I" {
+		return client.DeleteConfig(vo.ConfigParam{DataId: historyItem.DataId, Group: historyItem.Group, NamespaceId: param.NamespaceId})
+	}
+	return client.PublishConfig(vo.ConfigParam{
+		NamespaceId:      param.NamespaceId,
+		DataId:           historyItem.DataId,
+		Group:            historyItem.Group,
+		Content:          historyItem.Content,
+		AppName:          historyItem.AppName,
+		EncryptedDataKey: historyItem.EncryptedDataKey,
+	})
+}
+
+// GetConfigAdvanceInfo get the config with its metadata, such as tags, desc and type.
+func (client *ConfigClient) GetConfigAdvanceInfo(param vo.ConfigParam) (*model.ConfigAdvanceInfo, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.GetConfigAdvanceInfo] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	return client.configProxy.queryConfigAdvanceInfoProxy(param.DataId, param.Group, namespaceId)
+}
+
+// ExportConfig export the configs of the client namespace as a zip archive in the console format,
+// group, dataId and appName are optional filters.
+func (client *ConfigClient) ExportConfig(param vo.ExportConfigParam) ([]byte, error) {
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	return client.configProxy.exportConfigProxy(param, namespaceId)
+}
+
+// ImportConfig import a zip archive exported by ExportConfig or the console into the namespace of the param,
+// default is the client namespace.
+func (client *ConfigClient) ImportConfig(param vo.ImportConfigParam) (*model.ConfigImportResult, error) {
+	if client.isReadOnly() {
+		return nil, ErrReadOnlyClient
+	}
+	zipReader, policy := param.ZipReader, param.Policy
+	if zipReader == nil {
+		return nil, errors.New("[client.ImportConfig] zipReader can not be nil")
+	}
+	if len(policy) <= 0 {
+		policy = vo.ImportAbort
+	}
+	if policy != vo.ImportAbort && policy != vo.ImportSkip && policy != vo.ImportOverwrite {
+		return nil, errors.Errorf("[client.ImportConfig] unknown policy:%s", policy)
+	}
+	zipData, err := io.ReadAll(zipReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "[client.ImportConfig] read zip failed")
+	}
+	if len(zipData) <= 0 {
+		return nil, errors.New("[client.ImportConfig] zip content can not be empty")
+	}
+	clientConfig, _ := client.GetClientConfig()
+	return client.configProxy.importConfigProxy(zipData, policy, resolveNamespaceId(clientConfig, param.NamespaceId))
+}
+
+// GetAllNamespaces return all the namespaces of the server, including the public one.
+func (client *ConfigClient) GetAllNamespaces() ([]model.Namespace, error) {
+	return client.configProxy.getNamespacesProxy()
+}
+
+// CreateNamespace create a namespace, the namespaceId is generated by the server when param.NamespaceId is empty.
+func (client *ConfigClient) CreateNamespace(param vo.NamespaceParam) (bool, error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
+	if len(param.NamespaceName) <= 0 {
+		return false, errors.New("[client.CreateNamespace] param.namespaceName can not be empty")
+	}
+	return client.configProxy.modifyNamespaceProxy(http.MethodPost, map[string]string{
+		"customNamespaceId": param.NamespaceId,
+		"namespaceName":     param.NamespaceName,
+		"namespaceDesc":     param.NamespaceDesc,
+	})
+}
+
+// UpdateNamespace change the show name and description of the namespace.
+func (client *ConfigClient) UpdateNamespace(param vo.NamespaceParam) (bool, error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
+	if len(param.NamespaceId) <= 0 {
+		return false, errors.New("[client.UpdateNamespace] param.namespaceId can not be empty")
+	}
+	if len(param.NamespaceName) <= 0 {
+		return false, errors.New("[client.UpdateNamespace] param.namespaceName can not be empty")
+	}
+	return client.configProxy.modifyNamespaceProxy(http.MethodPut, map[string]string{
+		"namespace":         param.NamespaceId,
+		"namespaceShowName": param.NamespaceName,
+		"namespaceDesc":     param.NamespaceDesc,
+	})
+}
+
+// DeleteNamespace delete the namespace, the configs in it are not deleted by the server.
+func (client *ConfigClient) DeleteNamespace(namespaceId string) (bool, error) {
+	if client.isReadOnly() {
+		return false, ErrReadOnlyClient
+	}
+	if len(namespaceId) <= 0 {
+		return false, errors.New("[client.DeleteNamespace] namespaceId can not be empty")
+	}
+	return client.configProxy.modifyNamespaceProxy(http.MethodDelete, map[string]string{"namespaceId": namespaceId})
+}
+
+// CloneConfig copy the items in srcNamespace to dstNamespace, the existing configs in dstNamespace are
+// handled by policy, the same as ImportConfig.
+func (client *ConfigClient) CloneConfig(srcNamespace, dstNamespace string, items []vo.CloneConfigItem, policy vo.ImportPolicy) (*model.ConfigImportResult, error) {
+	if client.isReadOnly() {
+		return nil, ErrReadOnlyClient
+	}
+	if len(items) <= 0 {
+		return nil, errors.New("[client.CloneConfig] items can not be empty")
+	}
+	if srcNamespace == dstNamespace {
+		return nil, errors.New("[client.CloneConfig] srcNamespace and dstNamespace can not be the same")
+	}
+	if len(policy) <= 0 {
+		policy = vo.ImportAbort
+	}
+	if policy != vo.ImportAbort && policy != vo.ImportSkip && policy != vo.ImportOverwrite {
+		return nil, errors.Errorf("[client.CloneConfig] unknown policy:%s", policy)
+	}
+	beans := make([]cloneConfigBean, 0, len(items))
+	for _, item := range items {
+		if len(item.DataId) <= 0 {
+			return nil, errors.New("[client.CloneConfig] item.dataId can not be empty")
+		}
+		if len(item.Group) <= 0 {
+			item.Group = constant.DEFAULT_GROUP
+		}
+		info, err := client.configProxy.queryConfigAdvanceInfoProxy(item.DataId, item.Group, srcNamespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[client.CloneConfig] query the source config failed, dataId=%s, group=%s",
+				item.DataId, item.Group)
+		}
+		bean := cloneConfigBean{CfgId: info.Id, DataId: item.TargetDataId, Group: item.TargetGroup}
+		if len(bean.DataId) <= 0 {
+			bean.DataId = item.DataId
+		}
+		if len(bean.Group) <= 0 {
+			bean.Group = item.Group
+		}
+		beans = append(beans, bean)
+	}
+	return client.configProxy.cloneConfigProxy(beans, policy, dstNamespace)
+}
+
+// resolveNamespaceId use the namespaceId of the call if it is set, otherwise the namespaceId of the client.
+func resolveNamespaceId(clientConfig constant.ClientConfig, namespaceId string) string {
+	if len(namespaceId) > 0 {
+		return namespaceId
+	}
+	return clientConfig.NamespaceId
+}
+
 func (client *ConfigClient) CloseClient() {
-	client.configProxy.getRpcClient(client).Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), constant.DEFAULT_TIMEOUT_MILLS*time.Millisecond)
+	defer cancel()
+	if err := client.configProxy.closeRpcClients(ctx); err != nil {
+		logger.Warnf("close config grpc clients failed:%+v", err)
+	}
 	client.cancel()
 }
 
@@ -373,7 +1092,8 @@ func (client *ConfigClient) searchConfigInner(param vo.SearchConfigParam) (*mode
 		param.PageSize = 10
 	}
 	clientConfig, _ := client.GetClientConfig()
-	configItems, err := client.configProxy.searchConfigProxy(param, clientConfig.NamespaceId, clientConfig.AccessKey, clientConfig.SecretKey)
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	configItems, err := client.configProxy.searchConfigProxy(param, namespaceId, client)
 	if err != nil {
 		logger.Errorf("search config from server error:%+v ", err)
 		if _, ok := err.(*nacos_error.NacosError); ok {
@@ -439,6 +1159,15 @@ func (client *ConfigClient) executeConfigListen() {
 		if !ok {
 			continue
 		}
+		// the listen requests aren't queued with the cancels of the configs by listenQueue, since a request carries
+		// the configs of a task. A config canceled while the request is in flight may be listened again by it on
+		// the server, so its remove-listen request is queued again after the response, and the states of the
+		// canceled configs are not written back.
+		for _, data := range caches {
+			if changeKey := util.GetConfigCacheKey(data.dataId, data.group, data.tenant); !client.cacheMap.Has(changeKey) {
+				client.removeListenOnServer(changeKey, data)
+			}
+		}
 
 		if len(response.ChangedConfigs) > 0 {
 			hasChangedKeys = true
@@ -447,27 +1176,36 @@ func (client *ConfigClient) executeConfigListen() {
 		for _, v := range response.ChangedConfigs {
 			changeKey := util.GetConfigCacheKey(v.DataId, v.Group, v.Tenant)
 			changeKeys[changeKey] = struct{}{}
+			if client.isMd5Retrying(changeKey) {
+				continue
+			}
 			if value, ok := client.cacheMap.Get(changeKey); ok {
 				cData := value.(cacheData)
+				cData.notifyTime = time.Now()
 				client.refreshContentAndCheck(cData, !cData.isInitializing)
 			}
 		}
 
-		for _, v := range client.cacheMap.Items() {
-			data := v.(cacheData)
-			changeKey := util.GetConfigCacheKey(data.dataId, data.group, data.tenant)
-			if _, ok := changeKeys[changeKey]; !ok {
-				data.isSyncWithServer = true
-				client.cacheMap.Set(changeKey, data)
-				continue
+		for _, changeKey := range client.cacheMap.Keys() {
+			_, changed := changeKeys[changeKey]
+			updated := client.cacheMap.Update(changeKey, func(value interface{}) interface{} {
+				data := value.(cacheData)
+				if changed {
+					data.isInitializing = true
+				} else {
+					data.isSyncWithServer = true
+				}
+				return data
+			})
+			if updated && !changed {
+				client.markSynced(changeKey)
 			}
-			data.isInitializing = true
-			client.cacheMap.Set(changeKey, data)
 		}
 
 	}
 	if needAllSync {
 		client.lastAllSyncTime = time.Now()
+		client.syncFuzzyWatchers()
 	}
 
 	if hasChangedKeys {
@@ -486,6 +1224,11 @@ func buildConfigBatchListenRequest(caches []cacheData) *rpc_request.ConfigBatchL
 }
 
 func (client *ConfigClient) refreshContentAndCheck(cacheData cacheData, notify bool) {
+	cacheKey := util.GetConfigCacheKey(cacheData.dataId, cacheData.group, cacheData.tenant)
+	if _, ok := client.readLocalOverride(cacheData.tenant, cacheData.group, cacheData.dataId); ok {
+		client.markSynced(cacheKey)
+		return
+	}
 	configQueryResponse, err := client.configProxy.queryConfig(cacheData.dataId, cacheData.group, cacheData.tenant,
 		constant.DEFAULT_TIMEOUT_MILLS, notify, client)
 	if err != nil {
@@ -498,6 +1241,14 @@ func (client *ConfigClient) refreshContentAndCheck(cacheData cacheData, notify b
 			cacheData.dataId, cacheData.group)
 		return
 	}
+	if client.isStaleContent(cacheData, configQueryResponse) {
+		monitor.GetConfigMetrics().IncMd5Mismatch(cacheData.dataId, cacheData.group)
+		if client.scheduleMd5Retry(cacheData, notify) {
+			return
+		}
+	}
+	client.finishMd5Retry(cacheKey)
+	defer client.markSynced(cacheKey)
 	cacheData.content = configQueryResponse.Content
 	cacheData.contentType = configQueryResponse.ContentType
 	cacheData.encryptedDataKey = configQueryResponse.EncryptedDataKey
@@ -507,7 +1258,7 @@ func (client *ConfigClient) refreshContentAndCheck(cacheData cacheData, notify b
 			util.TruncateContent(cacheData.content), cacheData.contentType)
 	}
 	cacheData.md5 = util.Md5(cacheData.content)
-	if cacheData.md5 != cacheData.cacheDataListener.lastMd5 {
+	if cacheData.md5 != cacheData.cacheDataListener.getLastMd5() {
 		cacheDataPtr := &cacheData
 		cacheDataPtr.executeListener()
 	}
@@ -523,7 +1274,7 @@ func (client *ConfigClient) buildListenTask(needAllSync bool) map[int][]cacheDat
 		}
 
 		if data.isSyncWithServer {
-			if data.md5 != data.cacheDataListener.lastMd5 {
+			if data.md5 != data.cacheDataListener.getLastMd5() {
 				data.executeListener()
 			}
 			if !needAllSync {
@@ -17,6 +17,10 @@
 package config_client
 
 import (
+	"context"
+
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/vo"
 )
@@ -30,6 +34,19 @@ type IConfigClient interface {
 	// tenant ==>nacos.namespace optional
 	GetConfig(param vo.ConfigParam) (string, error)
 
+	// GetConfigWithResult use to get config with the source which served it, see ClientConfig.ConfigSourcePriority
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	GetConfigWithResult(param vo.ConfigParam) (*model.GetConfigResult, error)
+
+	// GetConfigAs use to get config from nacos server and decode it into v
+	// dataId  require
+	// group   require
+	// type    optional, yaml/json/properties/toml or a registered format, detected if not set
+	// tenant ==>nacos.namespace optional
+	GetConfigAs(param vo.ConfigParam, v interface{}) error
+
 	// PublishConfig use to publish config to nacos server
 	// dataId  require
 	// group   require
@@ -37,16 +54,66 @@ type IConfigClient interface {
 	// tenant ==>nacos.namespace optional
 	PublishConfig(param vo.ConfigParam) (bool, error)
 
+	// PublishConfigCas use to publish config only when the md5 of the config on server equals expectedMd5,
+	// ErrCasConflict is returned when the config has been changed by others
+	// dataId  require
+	// group   require
+	// content require
+	// expectedMd5 require
+	// tenant ==>nacos.namespace optional
+	PublishConfigCas(param vo.ConfigParam, expectedMd5 string) (bool, error)
+
+	// BatchPublishConfig use to publish configs concurrently, and return the result of each config in order
+	// dataId  require
+	// group   require
+	// content require
+	// tenant ==>nacos.namespace optional
+	BatchPublishConfig(params []vo.ConfigParam) []model.BatchConfigResult
+
+	// BatchGetConfig use to get configs concurrently, and return the result of each config in order
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	BatchGetConfig(params []vo.ConfigParam) []model.BatchConfigResult
+
+	// PublishConfigBeta use to publish a beta config, only the clients in betaIps will get it
+	// dataId  require
+	// group   require
+	// content require
+	// betaIps require, split by ','
+	// tenant ==>nacos.namespace optional
+	PublishConfigBeta(param vo.ConfigParam) (bool, error)
+
+	// GetConfigBeta use to get the beta config, return nil if there is no beta config
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	GetConfigBeta(param vo.ConfigParam) (*model.ConfigBetaItem, error)
+
+	// StopConfigBeta use to stop the beta config, all clients will get the formal config
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	StopConfigBeta(param vo.ConfigParam) (bool, error)
+
 	// DeleteConfig use to delete config
 	// dataId  require
 	// group   require
 	// tenant ==>nacos.namespace optional
 	DeleteConfig(param vo.ConfigParam) (bool, error)
 
+	// DeleteConfigWithScope use to delete the whole config, only the beta version, or only a tag of it
+	// dataId  require
+	// group   require
+	// tag     require when scope is TAG
+	// scope   option, ALL, BETA or TAG, default is ALL
+	// tenant ==>nacos.namespace optional
+	DeleteConfigWithScope(param vo.DeleteConfigParam) (bool, error)
+
 	// ListenConfig use to listen config change,it will callback OnChange() when config change
 	// dataId  require
 	// group   require
-	// onchange require
+	// onchange require, or onchangeV2 to receive the old content, new content and the diff
 	// tenant ==>nacos.namespace optional
 	ListenConfig(params vo.ConfigParam) (err error)
 
@@ -56,6 +123,77 @@ type IConfigClient interface {
 	// tenant ==>nacos.namespace optional
 	CancelListenConfig(params vo.ConfigParam) (err error)
 
+	// WaitForInitialSync blocks until all listened configs have received their first value or ctx is done
+	WaitForInitialSync(ctx context.Context) error
+
+	// ListListeners use to list the listened configs and the listener count of each one
+	ListListeners() []model.ConfigListenerInfo
+
+	// ServerHealth use to get the connection state and the last error of each nacos server
+	ServerHealth() model.ServerHealth
+
+	// ActiveProtocol use to get the protocol of the config requests, grpc, or http of the 1.x servers, it may
+	// switch to http in the auto compat mode when the server doesn't serve grpc
+	ActiveProtocol() string
+
+	// WatchConnection use to be notified when the grpc connections are connected, disconnected, reconnected to
+	// the same server or switched to another one, the connections of the listen tasks created later included
+	// Callback require,it must not block
+	WatchConnection(param *vo.WatchConnectionParam) error
+
+	// UnwatchConnection use to remove the callback of WatchConnection
+	UnwatchConnection(param *vo.WatchConnectionParam) error
+
+	// Watch use to get config and keep it up to date, the returned ConfigRef holds the latest content
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	Watch(param vo.ConfigParam) (*ConfigRef, error)
+
+	// WatchGroup use to get several configs and keep their combined value up to date, the combiner is re-run
+	// after the changes of the members are debounced by ClientConfig.WatchDebounceMs
+	WatchGroup(params []vo.ConfigParam, combiner vo.ConfigCombiner) (*ConfigGroupRef, error)
+
+	// AddRequestHook use to register a hook called around every grpc request, e.g. to record tracing spans
+	AddRequestHook(hook ConfigRequestHook) error
+
+	// AddInterceptor use to install an interceptor called around every grpc request, it can change the request,
+	// the response and the error, e.g. for audit logging or chaos injection
+	AddInterceptor(interceptor rpc.Interceptor) error
+
+	// RegisterServerRequestHandler use to handle the server push requests of a custom type, request returns a new
+	// request of the type to decode the push into, the response of the handler is sent back to the server, the
+	// built-in types handled by the client are rejected
+	RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error
+
+	// ListenConfigAs use to listen config change, and callback onChange with the content decoded into newValue()
+	// dataId  require
+	// group   require
+	// type    optional, yaml/json/properties/toml or a registered format, detected if not set
+	// tenant ==>nacos.namespace optional
+	ListenConfigAs(params vo.ConfigParam, newValue func() interface{}, onChange vo.TypedListener) (err error)
+
+	// ListenKeys use to listen the changes of some keys of a properties, yaml, json or toml config
+	// dataId   require
+	// group    require
+	// keys     require, a key also matches its children
+	// onChange require, called only when the listened keys change
+	ListenKeys(param vo.ConfigParam, keys []string, onChange vo.KeysListener) (err error)
+
+	// ListenConfigWithPrefix use to listen all configs whose dataId matches a prefix or wildcard pattern,
+	// it will callback OnChange() when a matched config is added, changed or deleted
+	// dataId  require, pattern like "app-" or "app-*.yaml"
+	// group   require
+	// onchange require
+	// tenant ==>nacos.namespace optional
+	ListenConfigWithPrefix(params vo.ConfigParam) (err error)
+
+	// CancelListenConfigWithPrefix use to cancel listen created by ListenConfigWithPrefix
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	CancelListenConfigWithPrefix(params vo.ConfigParam) (err error)
+
 	// SearchConfig use to search nacos config
 	// search  require search=accurate--精确搜索  search=blur--模糊搜索
 	// group   option
@@ -65,6 +203,68 @@ type IConfigClient interface {
 	// pageSize option,default is 10
 	SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error)
 
+	// GetConfigAdvanceInfo use to get config with its metadata, such as config tags, desc, type and schema
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	GetConfigAdvanceInfo(param vo.ConfigParam) (*model.ConfigAdvanceInfo, error)
+
+	// GetConfigHistory use to get the history versions of a config
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	// pageNo  option,default is 1
+	// pageSize option,default is 10
+	GetConfigHistory(param vo.ConfigHistoryParam) (*model.ConfigHistoryPage, error)
+
+	// GetConfigHistoryDetail use to get a history version of a config
+	// dataId  require
+	// group   require
+	// nid     require, the id of the history version
+	// tenant ==>nacos.namespace optional
+	GetConfigHistoryDetail(param vo.ConfigHistoryDetailParam) (*model.ConfigHistoryItem, error)
+
+	// RollbackConfig use to rollback a config to a history version
+	// dataId  require
+	// group   require
+	// nid     require, the id of the history version
+	// tenant ==>nacos.namespace optional
+	RollbackConfig(param vo.ConfigHistoryDetailParam) (bool, error)
+
+	// ExportConfig use to export configs as a zip archive, the same format as the console
+	// group   option
+	// dataId  option
+	// appName option
+	// tenant ==>nacos.namespace optional
+	ExportConfig(param vo.ExportConfigParam) ([]byte, error)
+
+	// ImportConfig use to import a zip archive of configs, the same format as the console
+	// zipReader require
+	// policy    option, ABORT, SKIP or OVERWRITE, default is ABORT
+	// tenant ==>nacos.namespace optional
+	ImportConfig(param vo.ImportConfigParam) (*model.ConfigImportResult, error)
+
+	// CloneConfig use to copy configs from srcNamespace to dstNamespace
+	// items   require, the source configs, optionally renamed in dstNamespace
+	// policy  option, ABORT, SKIP or OVERWRITE, default is ABORT
+	CloneConfig(srcNamespace, dstNamespace string, items []vo.CloneConfigItem, policy vo.ImportPolicy) (*model.ConfigImportResult, error)
+
+	// GetAllNamespaces use to list the namespaces of the server
+	GetAllNamespaces() ([]model.Namespace, error)
+
+	// CreateNamespace use to create a namespace
+	// namespaceName require
+	// namespaceId   optional, generated by the server when it's empty
+	CreateNamespace(param vo.NamespaceParam) (bool, error)
+
+	// UpdateNamespace use to update the name and description of a namespace
+	// namespaceId   require
+	// namespaceName require
+	UpdateNamespace(param vo.NamespaceParam) (bool, error)
+
+	// DeleteNamespace use to delete a namespace
+	DeleteNamespace(namespaceId string) (bool, error)
+
 	// CloseClient Close the GRPC client
 	CloseClient()
 }
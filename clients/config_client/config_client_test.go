@@ -19,7 +19,18 @@ package config_client
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/util"
 
@@ -28,9 +39,12 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
 	"github.com/jun3372/nacos-sdk-go/model"
 
+	"github.com/jun3372/nacos-sdk-go/clients/cache"
 	"github.com/jun3372/nacos-sdk-go/clients/nacos_client"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/format"
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
 	"github.com/jun3372/nacos-sdk-go/vo"
 	"github.com/stretchr/testify/assert"
 )
@@ -99,20 +113,80 @@ func (m *MockConfigProxy) queryConfig(dataId, group, tenant string, timeout uint
 	if IsLimited(cacheKey) {
 		return nil, errors.New("request is limited")
 	}
+	if dataId == "stale-md5" {
+		return &rpc_response.ConfigQueryResponse{Content: "hello world", Md5: util.Md5("other"),
+			Response: &rpc_response.Response{Success: true}}, nil
+	}
 	return &rpc_response.ConfigQueryResponse{Content: "hello world", Response: &rpc_response.Response{Success: true}}, nil
 }
-func (m *MockConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+func (m *MockConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant string, client *ConfigClient) (*model.ConfigPage, error) {
 	return &model.ConfigPage{TotalCount: 1}, nil
 }
 func (m *MockConfigProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if publishRequest, ok := request.(*rpc_request.ConfigPublishRequest); ok && publishRequest.CasMd5 == "stale" {
+		return &rpc_response.ConfigPublishResponse{Response: &rpc_response.Response{ResultCode: 500,
+			Message: "Cas publish fail, server md5 may have changed."}}, nil
+	}
 	return &rpc_response.MockResponse{Response: &rpc_response.Response{Success: true}}, nil
 }
+func (m *MockConfigProxy) queryConfigBetaProxy(dataId, group, tenant string) (*model.ConfigBetaItem, error) {
+	return &model.ConfigBetaItem{DataId: dataId, Group: group, Tenant: tenant, Content: "hello beta", BetaIps: "127.0.0.1"}, nil
+}
+func (m *MockConfigProxy) stopConfigBetaProxy(dataId, group, tenant string) (bool, error) {
+	return true, nil
+}
+func (m *MockConfigProxy) queryConfigAdvanceInfoProxy(dataId, group, tenant string) (*model.ConfigAdvanceInfo, error) {
+	return &model.ConfigAdvanceInfo{DataId: dataId, Group: group, ConfigTags: "a,b", Desc: "desc"}, nil
+}
+func (m *MockConfigProxy) configHistoryProxy(param vo.ConfigHistoryParam, tenant string) (*model.ConfigHistoryPage, error) {
+	return &model.ConfigHistoryPage{TotalCount: 1, PageNumber: param.PageNo,
+		PageItems: []model.ConfigHistoryItem{{Id: "1", DataId: param.DataId, Group: param.Group, OpType: "U"}}}, nil
+}
+func (m *MockConfigProxy) configHistoryDetailProxy(param vo.ConfigHistoryDetailParam, tenant string) (*model.ConfigHistoryItem, error) {
+	return &model.ConfigHistoryItem{Id: "1", DataId: param.DataId, Group: param.Group, Content: "hello history", OpType: "U"}, nil
+}
+func (m *MockConfigProxy) exportConfigProxy(param vo.ExportConfigParam, tenant string) ([]byte, error) {
+	return []byte("PK"), nil
+}
+func (m *MockConfigProxy) importConfigProxy(zipData []byte, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error) {
+	return &model.ConfigImportResult{SuccCount: 1}, nil
+}
+func (m *MockConfigProxy) cloneConfigProxy(beans []cloneConfigBean, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error) {
+	return &model.ConfigImportResult{SuccCount: len(beans)}, nil
+}
+func (m *MockConfigProxy) getNamespacesProxy() ([]model.Namespace, error) {
+	return []model.Namespace{{Namespace: "", NamespaceShowName: "public"}, {Namespace: "dev", NamespaceShowName: "dev"}}, nil
+}
+func (m *MockConfigProxy) modifyNamespaceProxy(method string, params map[string]string) (bool, error) {
+	return true, nil
+}
 func (m *MockConfigProxy) createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
 	return &rpc.RpcClient{}
 }
 func (m *MockConfigProxy) getRpcClient(client *ConfigClient) *rpc.RpcClient {
 	return &rpc.RpcClient{}
 }
+func (m *MockConfigProxy) closeRpcClients(ctx context.Context) error {
+	return nil
+}
+func (m *MockConfigProxy) activeProtocol() string {
+	return constant.GRPC
+}
+
+func (m *MockConfigProxy) addRequestHook(hook ConfigRequestHook) {
+}
+
+func (m *MockConfigProxy) addInterceptor(interceptor rpc.Interceptor) {
+}
+
+func (m *MockConfigProxy) registerServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) {
+}
+
+func (m *MockConfigProxy) watchConnection(callback *func(event model.ConnectionEvent)) {
+}
+
+func (m *MockConfigProxy) unwatchConnection(callback *func(event model.ConnectionEvent)) {
+}
 
 func Test_GetConfig(t *testing.T) {
 	client := createConfigClientTest()
@@ -316,3 +390,1367 @@ func TestCancelListenConfig(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestListenConfigWithPrefix(t *testing.T) {
+	client := createConfigClientTest()
+	param := vo.ConfigParam{
+		DataId: "app-",
+		Group:  localConfigTest.Group,
+		OnChange: func(namespace, group, dataId, data string) {
+		},
+	}
+	err := client.ListenConfigWithPrefix(param)
+	assert.Nil(t, err)
+	assert.NotNil(t, client.ListenConfigWithPrefix(param))
+
+	pattern := generateFuzzyWatchPattern("app-*", localConfigTest.Group, "")
+	handler := &ConfigFuzzyWatchSyncRequestHandler{client: client}
+	syncRequest := rpc_request.NewConfigFuzzyWatchSyncRequest()
+	syncRequest.GroupKeyPattern = pattern
+	syncRequest.Contexts = []model.ConfigFuzzyWatchContext{
+		{GroupKey: "app-a+" + localConfigTest.Group, ChangedType: fuzzyWatchAddConfig},
+		{GroupKey: "other+" + localConfigTest.Group, ChangedType: fuzzyWatchAddConfig},
+	}
+	response := handler.RequestReply(syncRequest, &rpc.RpcClient{})
+	assert.NotNil(t, response)
+	_, ok := client.cacheMap.Get(util.GetConfigCacheKey("app-a", localConfigTest.Group, ""))
+	assert.True(t, ok)
+	_, ok = client.cacheMap.Get(util.GetConfigCacheKey("other", localConfigTest.Group, ""))
+	assert.False(t, ok)
+
+	notifyHandler := &ConfigFuzzyWatchChangeNotifyRequestHandler{client: client}
+	notifyRequest := rpc_request.NewConfigFuzzyWatchChangeNotifyRequest()
+	notifyRequest.GroupKey = "app-a+" + localConfigTest.Group
+	notifyRequest.ChangeType = fuzzyWatchDeleteConfig
+	notifyHandler.RequestReply(notifyRequest, &rpc.RpcClient{})
+	_, ok = client.cacheMap.Get(util.GetConfigCacheKey("app-a", localConfigTest.Group, ""))
+	assert.False(t, ok)
+
+	assert.Nil(t, client.CancelListenConfigWithPrefix(param))
+	assert.Empty(t, client.fuzzyWatchers)
+}
+
+func TestListenConfigWithPrefixNamespaceId(t *testing.T) {
+	client := createConfigClientTest()
+	param := vo.ConfigParam{
+		DataId:      "ns-app-",
+		Group:       localConfigTest.Group,
+		NamespaceId: "dev",
+		OnChange: func(namespace, group, dataId, data string) {
+		},
+	}
+	assert.Nil(t, client.ListenConfigWithPrefix(param))
+
+	handler := &ConfigFuzzyWatchSyncRequestHandler{client: client}
+	syncRequest := rpc_request.NewConfigFuzzyWatchSyncRequest()
+	syncRequest.GroupKeyPattern = generateFuzzyWatchPattern("ns-app-*", localConfigTest.Group, "dev")
+	syncRequest.Contexts = []model.ConfigFuzzyWatchContext{
+		{GroupKey: "ns-app-a+" + localConfigTest.Group + "+dev", ChangedType: fuzzyWatchAddConfig},
+	}
+	assert.NotNil(t, handler.RequestReply(syncRequest, &rpc.RpcClient{}))
+	value, ok := client.cacheMap.Get(util.GetConfigCacheKey("ns-app-a", localConfigTest.Group, "dev"))
+	assert.True(t, ok)
+	assert.Equal(t, "dev", value.(cacheData).tenant)
+	_, ok = client.cacheMap.Get(util.GetConfigCacheKey("ns-app-a", localConfigTest.Group, ""))
+	assert.False(t, ok)
+
+	assert.Nil(t, client.CancelListenConfigWithPrefix(param))
+	assert.Empty(t, client.fuzzyWatchers)
+	_, ok = client.cacheMap.Get(util.GetConfigCacheKey("ns-app-a", localConfigTest.Group, "dev"))
+	assert.False(t, ok)
+}
+
+func TestMatchWildcard(t *testing.T) {
+	assert.True(t, matchWildcard("app-*", "app-a"))
+	assert.True(t, matchWildcard("*.yaml", "a.yaml"))
+	assert.True(t, matchWildcard("a*b*c", "a-b-c"))
+	assert.False(t, matchWildcard("a*b*c", "a-c-b"))
+	assert.False(t, matchWildcard("app", "app-a"))
+}
+
+func TestConfigBeta(t *testing.T) {
+	client := createConfigClientTest()
+	_, err := client.PublishConfigBeta(vo.ConfigParam{
+		DataId:  localConfigTest.DataId,
+		Group:   localConfigTest.Group,
+		Content: "hello beta"})
+	assert.NotNil(t, err)
+
+	success, err := client.PublishConfigBeta(vo.ConfigParam{
+		DataId:  localConfigTest.DataId,
+		Group:   localConfigTest.Group,
+		Content: "hello beta",
+		BetaIps: "127.0.0.1"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+
+	betaItem, err := client.GetConfigBeta(vo.ConfigParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello beta", betaItem.Content)
+	assert.Equal(t, "127.0.0.1", betaItem.BetaIps)
+
+	stopped, err := client.StopConfigBeta(vo.ConfigParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.True(t, stopped)
+}
+
+func TestConfigHistory(t *testing.T) {
+	client := createConfigClientTest()
+	historyPage, err := client.GetConfigHistory(vo.ConfigHistoryParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, historyPage.PageNumber)
+	assert.Equal(t, 1, len(historyPage.PageItems))
+
+	_, err = client.GetConfigHistoryDetail(vo.ConfigHistoryDetailParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group})
+	assert.NotNil(t, err)
+
+	historyItem, err := client.GetConfigHistoryDetail(vo.ConfigHistoryDetailParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group,
+		Nid:    1})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello history", historyItem.Content)
+
+	success, err := client.RollbackConfig(vo.ConfigHistoryDetailParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group,
+		Nid:    1})
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func TestGetConfigAs(t *testing.T) {
+	client := createConfigClientTest()
+	var content string
+	err := client.GetConfigAs(vo.ConfigParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group}, &content)
+	assert.NotNil(t, err)
+
+	format.RegisterDecoder("words", format.DecoderFunc(func(content []byte, v interface{}) error {
+		*(v.(*[]string)) = strings.Fields(string(content))
+		return nil
+	}))
+	var words []string
+	err = client.GetConfigAs(vo.ConfigParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group,
+		Type:   "words"}, &words)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"hello", "world"}, words)
+
+	err = client.ListenConfigAs(vo.ConfigParam{
+		DataId: localConfigTest.DataId,
+		Group:  localConfigTest.Group}, nil, nil)
+	assert.NotNil(t, err)
+
+	received := make(chan interface{}, 1)
+	err = client.ListenConfigAs(vo.ConfigParam{
+		DataId: "app.json",
+		Group:  localConfigTest.Group}, func() interface{} {
+		return &map[string]int{}
+	}, func(namespace, group, dataId string, value interface{}, err error) {
+		assert.Nil(t, err)
+		received <- value
+	})
+	assert.Nil(t, err)
+	v, ok := client.cacheMap.Get(util.GetConfigCacheKey("app.json", localConfigTest.Group, ""))
+	assert.True(t, ok)
+	v.(cacheData).cacheDataListener.listeners[0].listener("", localConfigTest.Group, "app.json", `{"a":1}`)
+	assert.Equal(t, &map[string]int{"a": 1}, <-received)
+}
+
+func TestListenConfigWithOnChangeV2(t *testing.T) {
+	client := createConfigClientTest()
+	events := make(chan vo.ConfigChangeEvent, 1)
+	err := client.ListenConfig(vo.ConfigParam{
+		DataId: "app.properties",
+		Group:  localConfigTest.Group,
+		OnChangeV2: func(event vo.ConfigChangeEvent) {
+			events <- event
+		},
+	})
+	assert.Nil(t, err)
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey("app.properties", localConfigTest.Group, ""))
+	cData := v.(cacheData)
+	cData.cacheDataListener.lastContent = "a=1\nb=2\n"
+	cData.content = "a=1\nb=3\nc=4\n"
+	cData.md5 = util.Md5(cData.content)
+	cData.executeListener()
+
+	event := <-events
+	assert.Equal(t, "a=1\nb=2\n", event.OldContent)
+	assert.Equal(t, cData.content, event.NewContent)
+	assert.Equal(t, []vo.DiffLine{{Op: " ", Text: "a=1"}, {Op: "-", Text: "b=2"}, {Op: "+", Text: "b=3"},
+		{Op: "+", Text: "c=4"}}, event.Diff)
+	assert.Equal(t, map[string]vo.ConfigChangeItem{
+		"b": {Key: "b", OldValue: "2", NewValue: "3", Type: vo.ConfigModified},
+		"c": {Key: "c", NewValue: "4", Type: vo.ConfigAdded},
+	}, event.ChangedKeys)
+	assert.Equal(t, cData.content, cData.cacheDataListener.lastContent)
+}
+
+func TestBuildConfigChangeEvent(t *testing.T) {
+	event := buildConfigChangeEvent("", "group", "app", "yaml", "a:\n  b: 1\nl: [x]\n", "a:\n  c: 2\nl: [x, y]\n")
+	assert.Equal(t, map[string]vo.ConfigChangeItem{
+		"a.b":  {Key: "a.b", OldValue: "1", Type: vo.ConfigDeleted},
+		"a.c":  {Key: "a.c", NewValue: "2", Type: vo.ConfigAdded},
+		"l[1]": {Key: "l[1]", NewValue: "y", Type: vo.ConfigAdded},
+	}, event.ChangedKeys)
+
+	event = buildConfigChangeEvent("", "group", "app", "text", "hello", "world")
+	assert.Nil(t, event.ChangedKeys)
+	assert.Equal(t, []vo.DiffLine{{Op: "-", Text: "hello"}, {Op: "+", Text: "world"}}, event.Diff)
+}
+
+func TestBatchConfig(t *testing.T) {
+	client := createConfigClientTest()
+	params := make([]vo.ConfigParam, 0, 40)
+	for i := 0; i < 40; i++ {
+		params = append(params, vo.ConfigParam{DataId: "batch-" + strconv.Itoa(i), Content: "hello world"})
+	}
+	params = append(params, vo.ConfigParam{Group: "group", Content: "hello world"})
+
+	results := client.BatchPublishConfig(params)
+	assert.Equal(t, len(params), len(results))
+	for i := 0; i < 40; i++ {
+		assert.Equal(t, "batch-"+strconv.Itoa(i), results[i].DataId)
+		assert.Equal(t, constant.DEFAULT_GROUP, results[i].Group)
+		assert.True(t, results[i].Success)
+		assert.Nil(t, results[i].Err)
+	}
+	assert.False(t, results[40].Success)
+	assert.NotNil(t, results[40].Err)
+
+	results = client.BatchGetConfig(params[:2])
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "hello world", results[1].Content)
+	assert.True(t, results[1].Success)
+}
+
+func TestExportImportConfig(t *testing.T) {
+	client := createConfigClientTest()
+	zipData, err := client.ExportConfig(vo.ExportConfigParam{Group: localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, "PK", string(zipData))
+
+	_, err = client.ImportConfig(vo.ImportConfigParam{Policy: vo.ImportSkip})
+	assert.NotNil(t, err)
+	_, err = client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader(""), Policy: vo.ImportSkip})
+	assert.NotNil(t, err)
+	_, err = client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader("PK"), Policy: "REPLACE"})
+	assert.NotNil(t, err)
+
+	importResult, err := client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader("PK"), Policy: vo.ImportOverwrite})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, importResult.SuccCount)
+
+	proxy := &importRecordProxy{}
+	client.configProxy = proxy
+	_, err = client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader("PK")})
+	assert.Nil(t, err)
+	_, err = client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader("PK"), NamespaceId: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{clientConfigWithOptions.NamespaceId, "dev"}, proxy.tenants)
+	assert.Equal(t, []vo.ImportPolicy{vo.ImportAbort, vo.ImportAbort}, proxy.policies)
+}
+
+type importRecordProxy struct {
+	MockConfigProxy
+	tenants  []string
+	policies []vo.ImportPolicy
+}
+
+func (m *importRecordProxy) importConfigProxy(zipData []byte, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error) {
+	m.tenants = append(m.tenants, tenant)
+	m.policies = append(m.policies, policy)
+	return m.MockConfigProxy.importConfigProxy(zipData, policy, tenant)
+}
+
+func TestRefreshContentWithStaleMd5(t *testing.T) {
+	client := createConfigClientTest()
+	cacheKey := util.GetConfigCacheKey(localConfigTest.DataId, localConfigTest.Group, "")
+	listener := &cacheDataListener{lastMd5: util.Md5("hello world")}
+	data := cacheData{
+		dataId:            localConfigTest.DataId,
+		group:             localConfigTest.Group,
+		content:           "hello world",
+		md5:               util.Md5("hello world"),
+		cacheDataListener: listener,
+		configClient:      client,
+	}
+	client.cacheMap.Set(cacheKey, data)
+
+	// changed and changed back to the cached content
+	assert.False(t, client.isStaleContent(data, &rpc_response.ConfigQueryResponse{Content: "hello world"}))
+	assert.False(t, client.isStaleContent(data, &rpc_response.ConfigQueryResponse{Content: "hello world", Md5: util.Md5("hello world")}))
+	assert.True(t, client.isStaleContent(data, &rpc_response.ConfigQueryResponse{Content: "new", Md5: util.Md5("old")}))
+	assert.False(t, client.isStaleContent(data, &rpc_response.ConfigQueryResponse{Content: "new", Md5: util.Md5("new")}))
+
+	client.refreshContentAndCheck(data, false)
+	assert.False(t, client.isMd5Retrying(cacheKey))
+
+	staleData := data
+	staleData.dataId = "stale-md5"
+	staleKey := util.GetConfigCacheKey(staleData.dataId, staleData.group, "")
+	client.cacheMap.Set(staleKey, staleData)
+	client.refreshContentAndCheck(staleData, false)
+	assert.True(t, client.isMd5Retrying(staleKey))
+	client.CloseClient()
+
+	client = createConfigClientTest()
+	client.md5RetryTasks[cacheKey] = constant.DEFAULT_MD5_RETRY_TIMES
+	assert.False(t, client.scheduleMd5Retry(data, false))
+	assert.False(t, client.isMd5Retrying(cacheKey))
+}
+
+func TestCallbackExecutor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor := newCallbackExecutor(ctx, 2, 1)
+
+	var result []int
+	done := make(chan struct{})
+	executor.submit("key", func() {
+		panic("callback panic")
+	})
+	for i := 0; i < 10; i++ {
+		index := i
+		executor.submit("key", func() {
+			result = append(result, index)
+		})
+	}
+	executor.submit("key", func() {
+		close(done)
+	})
+	<-done
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+
+	// a blocked worker doesn't block the submit, the spilled callbacks keep the order
+	block := make(chan struct{})
+	executor.submit("key", func() { <-block })
+	result = nil
+	done = make(chan struct{})
+	submitted := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			index := i
+			executor.submit("key", func() {
+				result = append(result, index)
+			})
+		}
+		executor.submit("key", func() {
+			close(done)
+		})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("submit is blocked by the full queue")
+	}
+	close(block)
+	<-done
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+}
+
+func TestPublishConfigCas(t *testing.T) {
+	client := createConfigClientTest()
+	_, err := client.PublishConfigCas(localConfigTest, "")
+	assert.NotNil(t, err)
+
+	success, err := client.PublishConfigCas(localConfigTest, util.Md5("hello world"))
+	assert.Nil(t, err)
+	assert.True(t, success)
+
+	success, err = client.PublishConfigCas(localConfigTest, "stale")
+	assert.False(t, success)
+	assert.True(t, errors.Is(err, ErrCasConflict))
+}
+
+func TestGetConfigAdvanceInfo(t *testing.T) {
+	client := createConfigClientTest()
+	_, err := client.GetConfigAdvanceInfo(vo.ConfigParam{})
+	assert.NotNil(t, err)
+
+	advanceInfo, err := client.GetConfigAdvanceInfo(vo.ConfigParam{DataId: localConfigTest.DataId})
+	assert.Nil(t, err)
+	assert.Equal(t, constant.DEFAULT_GROUP, advanceInfo.Group)
+	assert.Equal(t, "a,b", advanceInfo.ConfigTags)
+	assert.Equal(t, "desc", advanceInfo.Desc)
+}
+
+func TestListenConfigWithNamespaceId(t *testing.T) {
+	client := createConfigClientTest()
+	for _, namespaceId := range []string{"", "dev", "test"} {
+		err := client.ListenConfig(vo.ConfigParam{
+			DataId:      localConfigTest.DataId,
+			Group:       localConfigTest.Group,
+			NamespaceId: namespaceId,
+			OnChange: func(namespace, group, dataId, data string) {
+			},
+		})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 3, client.cacheMap.Count())
+	value, ok := client.cacheMap.Get(util.GetConfigCacheKey(localConfigTest.DataId, localConfigTest.Group, "dev"))
+	assert.True(t, ok)
+	assert.Equal(t, "dev", value.(cacheData).tenant)
+
+	err := client.CancelListenConfig(vo.ConfigParam{DataId: localConfigTest.DataId, Group: localConfigTest.Group, NamespaceId: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, client.cacheMap.Count())
+}
+
+func TestLocalOverride(t *testing.T) {
+	dataId := "local-override.properties"
+	dir := t.TempDir()
+	groupDir := filepath.Join(dir, localConfigTest.Group)
+	assert.Nil(t, os.MkdirAll(groupDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(groupDir, dataId), []byte("local content"), 0644))
+
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	clientConfig := *clientConfigWithOptions
+	clientConfig.LocalConfigDir = dir
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	client.configProxy = &MockConfigProxy{}
+	defer client.CloseClient()
+
+	content, err := client.GetConfig(vo.ConfigParam{DataId: dataId, Group: localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, "local content", content)
+
+	changed := make(chan string, 1)
+	err = client.ListenConfig(vo.ConfigParam{
+		DataId: dataId,
+		Group:  localConfigTest.Group,
+		OnChange: func(namespace, group, dataId, data string) {
+			changed <- data
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(groupDir, dataId), []byte("local changed"), 0644))
+	select {
+	case data := <-changed:
+		assert.Equal(t, "local changed", data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("local override change is not notified")
+	}
+	value, ok := client.cacheMap.Get(util.GetConfigCacheKey(dataId, localConfigTest.Group, ""))
+	assert.True(t, ok)
+	assert.Equal(t, util.Md5("local changed"), value.(cacheData).md5)
+
+	assert.Nil(t, os.Remove(filepath.Join(groupDir, dataId)))
+	select {
+	case data := <-changed:
+		assert.Equal(t, "hello world", data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("local override removal is not notified")
+	}
+
+	tenantDir := filepath.Join(dir, "dev", localConfigTest.Group)
+	assert.Nil(t, os.MkdirAll(tenantDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(tenantDir, dataId), []byte("dev content"), 0644))
+	content, err = client.GetConfig(vo.ConfigParam{DataId: dataId, Group: localConfigTest.Group, NamespaceId: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, "dev content", content)
+	for _, segment := range []string{"", ".", "..", "a/b", `a\b`} {
+		_, ok := client.localOverride.path("", segment, dataId)
+		assert.False(t, ok, segment)
+		_, ok = client.localOverride.path(segment, localConfigTest.Group, dataId)
+		assert.Equal(t, len(segment) == 0, ok, segment)
+	}
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	values := map[string]string{
+		"db.host": "127.0.0.1",
+		"db.url":  "mysql://${db.host}:${db.port:3306}",
+		"a":       "${b}",
+		"b":       "${a}",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+	resolved, err := resolvePlaceholders("url=${db.url}", lookup, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "url=mysql://127.0.0.1:3306", resolved)
+
+	_, err = resolvePlaceholders("${a}", lookup, nil)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "a -> b -> a"))
+
+	_, err = resolvePlaceholders("${unknown}", lookup, nil)
+	assert.NotNil(t, err)
+
+	t.Setenv("NACOS_RESOLVER_TEST", "from env")
+	client := createConfigClientTest()
+	resolver := NewConfigResolver(client, vo.ConfigParam{DataId: "shared.properties", Group: localConfigTest.Group})
+	resolved, err = resolver.ResolveConfig(vo.ConfigParam{DataId: "app.properties"}, "name=${NACOS_RESOLVER_TEST}\nref=${name}")
+	assert.Nil(t, err)
+	assert.Equal(t, "name=from env\nref=from env", resolved)
+}
+
+func TestWatch(t *testing.T) {
+	client := createConfigClientTest()
+	_, err := client.Watch(vo.ConfigParam{})
+	assert.NotNil(t, err)
+
+	ref, err := client.Watch(vo.ConfigParam{DataId: localConfigTest.DataId, Group: localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", ref.Get())
+
+	ref.update("first")
+	ref.update("second")
+	assert.Equal(t, "second", ref.Get())
+	assert.Equal(t, "second", <-ref.Changes())
+
+	assert.Nil(t, ref.Stop())
+	assert.Nil(t, ref.Stop())
+	_, ok := <-ref.Changes()
+	assert.False(t, ok)
+	assert.Equal(t, 0, client.cacheMap.Count())
+
+	// the watches only remove their own listeners, and a new watch receives the content notified before
+	param := vo.ConfigParam{DataId: "watch.properties", Group: localConfigTest.Group}
+	ref, err = client.Watch(param)
+	assert.Nil(t, err)
+	param.OnChange = func(namespace, group, dataId, data string) {}
+	assert.Nil(t, client.ListenConfig(param))
+	key := util.GetConfigCacheKey(param.DataId, param.Group, "")
+	value, _ := client.cacheMap.Get(key)
+	data := value.(cacheData)
+	data.md5 = util.Md5("latest")
+	data.cacheDataListener.lastMd5 = data.md5
+	data.cacheDataListener.lastContent = "latest"
+	client.cacheMap.Set(key, data)
+	other, err := client.Watch(param)
+	assert.Nil(t, err)
+	select {
+	case content := <-other.Changes():
+		assert.Equal(t, "latest", content)
+	case <-time.After(time.Second):
+		t.Fatal("the current content is not sent to the new watch")
+	}
+	assert.Nil(t, ref.Stop())
+	assert.Equal(t, 2, client.ListListeners()[0].ListenerCount)
+	assert.Nil(t, other.Stop())
+	assert.Equal(t, 1, client.ListListeners()[0].ListenerCount)
+	assert.Nil(t, client.CancelListenConfig(param))
+	assert.Equal(t, 0, client.cacheMap.Count())
+}
+
+type upperConfigFilter struct {
+}
+
+func (f *upperConfigFilter) DoFilter(param *vo.ConfigParam) error {
+	if param.DataId == "filter-dataId" && param.UsageType == vo.ResponseType {
+		param.Content = strings.ToUpper(param.Content)
+	}
+	return nil
+}
+
+func (f *upperConfigFilter) GetOrder() int {
+	return 0
+}
+
+func (f *upperConfigFilter) GetFilterName() string {
+	return "upperConfigFilter"
+}
+
+func TestRegisterConfigFilter(t *testing.T) {
+	assert.NotNil(t, RegisterConfigFilter(nil, 1))
+	assert.Nil(t, RegisterConfigFilter(&upperConfigFilter{}, 10))
+	assert.NotNil(t, RegisterConfigFilter(&upperConfigFilter{}, 10))
+
+	client := createConfigClientTest()
+	content, err := client.GetConfig(vo.ConfigParam{DataId: "filter-dataId", Group: localConfigTest.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, "HELLO WORLD", content)
+	filters := client.configFilterChainManager.GetFilters()
+	assert.Equal(t, 10, filters[len(filters)-1].GetOrder())
+}
+
+func TestDeleteConfigWithScope(t *testing.T) {
+	client := createConfigClientTest()
+	_, err := client.DeleteConfigWithScope(vo.DeleteConfigParam{})
+	assert.NotNil(t, err)
+	_, err = client.DeleteConfigWithScope(vo.DeleteConfigParam{DataId: localConfigTest.DataId, Scope: vo.DeleteScopeTag})
+	assert.NotNil(t, err)
+	_, err = client.DeleteConfigWithScope(vo.DeleteConfigParam{DataId: localConfigTest.DataId, Scope: "UNKNOWN"})
+	assert.NotNil(t, err)
+
+	for _, param := range []vo.DeleteConfigParam{
+		{DataId: localConfigTest.DataId},
+		{DataId: localConfigTest.DataId, Scope: vo.DeleteScopeBeta},
+		{DataId: localConfigTest.DataId, Scope: vo.DeleteScopeTag, Tag: "gray"},
+	} {
+		success, err := client.DeleteConfigWithScope(param)
+		assert.Nil(t, err)
+		assert.True(t, success)
+	}
+}
+
+func TestWaitForInitialSync(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	param := vo.ConfigParam{DataId: "sync-dataId", Group: "sync-group", OnChange: func(namespace, group, dataId, data string) {}}
+	assert.Nil(t, client.ListenConfig(param))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, client.WaitForInitialSync(ctx))
+
+	value, ok := client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, ""))
+	assert.True(t, ok)
+	go client.refreshContentAndCheck(value.(cacheData), false)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, client.WaitForInitialSync(ctx))
+
+	assert.Nil(t, client.CancelListenConfig(param))
+	assert.Nil(t, client.WaitForInitialSync(context.Background()))
+}
+
+type recordConfigMetrics struct {
+	mutex       sync.Mutex
+	publish     []error
+	md5Mismatch int
+}
+
+func (m *recordConfigMetrics) ObservePublish(dataId, group string, cost time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.publish = append(m.publish, err)
+}
+func (m *recordConfigMetrics) ObserveQuery(dataId, group string, cost time.Duration, err error) {}
+func (m *recordConfigMetrics) ObserveNotify(dataId, group string, cost time.Duration)           {}
+func (m *recordConfigMetrics) IncFailoverRead(dataId, group string)                             {}
+func (m *recordConfigMetrics) IncCacheHit(dataId, group string)                                 {}
+func (m *recordConfigMetrics) IncMd5Mismatch(dataId, group string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.md5Mismatch++
+}
+
+func TestConfigMetrics(t *testing.T) {
+	metrics := &recordConfigMetrics{}
+	monitor.SetConfigMetrics(metrics)
+	defer monitor.SetConfigMetrics(nil)
+	client := createConfigClientTest()
+	defer client.CloseClient()
+
+	_, err := client.PublishConfig(vo.ConfigParam{DataId: localConfigTest.DataId, Group: "metrics-group", Content: "hello world"})
+	assert.Nil(t, err)
+	_, err = client.PublishConfigCas(vo.ConfigParam{DataId: localConfigTest.DataId, Group: "metrics-group", Content: "hello world"}, "stale")
+	assert.Equal(t, ErrCasConflict, err)
+	assert.Equal(t, []error{nil, ErrCasConflict}, metrics.publish)
+
+	data := cacheData{dataId: "stale-md5", group: "metrics-group", md5: util.Md5("hello world"),
+		cacheDataListener: &cacheDataListener{lastMd5: util.Md5("hello world")}, configClient: client}
+	client.refreshContentAndCheck(data, false)
+	assert.Equal(t, 1, metrics.md5Mismatch)
+}
+
+func TestPublishConfigWithType(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	_, err := client.PublishConfig(vo.ConfigParam{DataId: "type-dataId", Group: "group", Type: "json", Content: `{"a":}`})
+	assert.NotNil(t, err)
+	var syntaxErr *format.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	success, err := client.PublishConfig(vo.ConfigParam{DataId: "type-dataId", Group: "group", Type: "json", Content: `{"a":1}`})
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func TestListenConfigFanOut(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	received := make(chan string, 3)
+	for i := 0; i < 3; i++ {
+		index := strconv.Itoa(i)
+		err := client.ListenConfig(vo.ConfigParam{DataId: "fanout-dataId", Group: "group", OnChange: func(namespace, group, dataId, data string) {
+			received <- index + ":" + data
+		}})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []model.ConfigListenerInfo{{DataId: "fanout-dataId", Group: "group", ListenerCount: 3}}, client.ListListeners())
+
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey("fanout-dataId", "group", ""))
+	client.refreshContentAndCheck(v.(cacheData), false)
+	var results []string
+	for i := 0; i < 3; i++ {
+		results = append(results, <-received)
+	}
+	assert.Equal(t, []string{"0:hello world", "1:hello world", "2:hello world"}, results)
+}
+
+func TestCloneConfig(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	_, err := client.CloneConfig("dev", "dev", []vo.CloneConfigItem{{DataId: "dataId"}}, vo.ImportSkip)
+	assert.NotNil(t, err)
+	_, err = client.CloneConfig("dev", "prod", nil, vo.ImportSkip)
+	assert.NotNil(t, err)
+	_, err = client.CloneConfig("dev", "prod", []vo.CloneConfigItem{{DataId: "dataId"}}, "REPLACE")
+	assert.NotNil(t, err)
+
+	result, err := client.CloneConfig("dev", "prod", []vo.CloneConfigItem{{DataId: "dataId"},
+		{DataId: "dataId", Group: "group", TargetDataId: "prod-dataId"}}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.SuccCount)
+}
+
+type flakyPublishProxy struct {
+	MockConfigProxy
+	failures   int
+	requestIds []string
+}
+
+func (m *flakyPublishProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if publishRequest, ok := request.(*rpc_request.ConfigPublishRequest); ok {
+		m.requestIds = append(m.requestIds, publishRequest.RequestId)
+		if m.failures > 0 {
+			m.failures--
+			return nil, errors.New("connection reset")
+		}
+	}
+	return m.MockConfigProxy.requestProxy(rpcClient, request, timeoutMills)
+}
+
+func TestPublishConfigWithRetry(t *testing.T) {
+	nc := nacos_client.NacosClient{}
+	clientConfig := *clientConfigWithOptions
+	clientConfig.PublishRetryCount = 3
+	clientConfig.PublishBackoffMs = 1
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, _ := NewConfigClient(&nc)
+	defer client.CloseClient()
+
+	proxy := &flakyPublishProxy{failures: 2}
+	client.configProxy = proxy
+	success, err := client.PublishConfig(vo.ConfigParam{DataId: "retry-dataId", Group: "group", Content: "new content"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Equal(t, 3, len(proxy.requestIds))
+	assert.NotEmpty(t, proxy.requestIds[0])
+	assert.Equal(t, proxy.requestIds[0], proxy.requestIds[2])
+
+	// the mock server already has the content, so the failed attempt is taken as published
+	proxy = &flakyPublishProxy{failures: 1}
+	client.configProxy = proxy
+	success, err = client.PublishConfig(vo.ConfigParam{DataId: "retry-dataId", Group: "group", Content: "hello world"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Equal(t, 1, len(proxy.requestIds))
+
+	proxy = &flakyPublishProxy{failures: 4}
+	client.configProxy = proxy
+	_, err = client.PublishConfig(vo.ConfigParam{DataId: "retry-dataId", Group: "group", Content: "new content"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, len(proxy.requestIds))
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	nc := nacos_client.NacosClient{}
+	clientConfig := *clientConfigWithOptions
+	clientConfig.ReadOnly = true
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, _ := NewConfigClient(&nc)
+	client.configProxy = &MockConfigProxy{}
+	defer client.CloseClient()
+
+	param := vo.ConfigParam{DataId: "readonly-dataId", Group: "group", Content: "hello world"}
+	_, err := client.PublishConfig(param)
+	assert.Equal(t, ErrReadOnlyClient, err)
+	_, err = client.PublishConfigCas(param, "md5")
+	assert.Equal(t, ErrReadOnlyClient, err)
+	_, err = client.DeleteConfig(param)
+	assert.Equal(t, ErrReadOnlyClient, err)
+	_, err = client.DeleteConfigWithScope(vo.DeleteConfigParam{DataId: param.DataId, Scope: vo.DeleteScopeBeta})
+	assert.Equal(t, ErrReadOnlyClient, err)
+	_, err = client.ImportConfig(vo.ImportConfigParam{ZipReader: strings.NewReader("PK"), Policy: vo.ImportSkip})
+	assert.Equal(t, ErrReadOnlyClient, err)
+	result := client.BatchPublishConfig([]vo.ConfigParam{param})
+	assert.Equal(t, ErrReadOnlyClient, result[0].Err)
+
+	content, err := client.GetConfig(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", content)
+}
+
+func TestNamespace(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	namespaces, err := client.GetAllNamespaces()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(namespaces))
+
+	_, err = client.CreateNamespace(vo.NamespaceParam{NamespaceId: "dev"})
+	assert.NotNil(t, err)
+	success, err := client.CreateNamespace(vo.NamespaceParam{NamespaceId: "dev", NamespaceName: "dev"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	_, err = client.UpdateNamespace(vo.NamespaceParam{NamespaceName: "dev"})
+	assert.NotNil(t, err)
+	success, err = client.UpdateNamespace(vo.NamespaceParam{NamespaceId: "dev", NamespaceName: "develop"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	_, err = client.DeleteNamespace("")
+	assert.NotNil(t, err)
+	success, err = client.DeleteNamespace("dev")
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func TestListenerCheckpoint(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	received := make(chan string, 1)
+	listen := func(dataId string) cacheData {
+		assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: dataId, Group: "group", OnChange: func(namespace, group, dataId, data string) {
+			received <- data
+		}}))
+		v, _ := client.cacheMap.Get(util.GetConfigCacheKey(dataId, "group", ""))
+		return v.(cacheData)
+	}
+
+	// the listeners have received the server content before the restart
+	ackedKey := util.GetConfigCacheKey("checkpoint-acked", "group", "")
+	assert.Nil(t, cache.WriteListenerCheckpoint(ackedKey, client.configCacheDir, util.Md5("hello world")))
+	client.refreshContentAndCheck(listen("checkpoint-acked"), false)
+	select {
+	case data := <-received:
+		t.Fatalf("unexpected notify: %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	staleKey := util.GetConfigCacheKey("checkpoint-stale", "group", "")
+	assert.Nil(t, cache.WriteListenerCheckpoint(staleKey, client.configCacheDir, util.Md5("old content")))
+	client.refreshContentAndCheck(listen("checkpoint-stale"), false)
+	assert.Equal(t, "hello world", <-received)
+	assert.Eventually(t, func() bool {
+		return cache.ReadListenerCheckpoint(staleKey, client.configCacheDir) == util.Md5("hello world")
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, client.CancelListenConfig(vo.ConfigParam{DataId: "checkpoint-stale", Group: "group"}))
+	assert.Equal(t, "", cache.ReadListenerCheckpoint(staleKey, client.configCacheDir))
+}
+
+func TestListenKeys(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	assert.NotNil(t, client.ListenKeys(vo.ConfigParam{DataId: "keys.properties", Group: "group"}, nil, nil))
+
+	received := make(chan map[string]vo.ConfigChangeItem, 2)
+	err := client.ListenKeys(vo.ConfigParam{DataId: "keys.properties", Group: "group"}, []string{"a", "db"},
+		func(namespace, group, dataId string, changes map[string]vo.ConfigChangeItem) {
+			received <- changes
+		})
+	assert.Nil(t, err)
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey("keys.properties", "group", ""))
+	cData := v.(cacheData)
+	cData.cacheDataListener.lastContent = "a=1\nb=2\ndb.url=x\ndbname=y"
+	notify := func(content string) {
+		cData.content = content
+		cData.md5 = util.Md5(content)
+		cData.executeListener()
+	}
+
+	notify("a=1\nb=3\ndb.url=x\ndbname=z")
+	notify("a=2\nb=3\ndb.url=x\ndbname=z")
+	assert.Equal(t, map[string]vo.ConfigChangeItem{"a": {Key: "a", OldValue: "1", NewValue: "2", Type: vo.ConfigModified}}, <-received)
+	notify("a=2\nb=3\ndb.url=w\ndbname=z")
+	assert.Equal(t, map[string]vo.ConfigChangeItem{"db.url": {Key: "db.url", OldValue: "x", NewValue: "w", Type: vo.ConfigModified}}, <-received)
+	select {
+	case changes := <-received:
+		t.Fatalf("unexpected changes: %v", changes)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGetConfigWithResult(t *testing.T) {
+	param := vo.ConfigParam{DataId: "source-dataId", Group: "source-group"}
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	result, err := client.GetConfigWithResult(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", result.Content)
+	assert.Equal(t, constant.CONFIG_SOURCE_SERVER, result.Source)
+	assert.Equal(t, util.Md5("hello world"), result.Md5)
+
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	clientConfig := *clientConfigWithOptions
+	clientConfig.CacheDir = t.TempDir()
+	clientConfig.ConfigSourcePriority = []string{constant.CONFIG_SOURCE_SNAPSHOT, constant.CONFIG_SOURCE_SERVER}
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	snapshotClient, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	snapshotClient.configProxy = &MockConfigProxy{}
+	defer snapshotClient.CloseClient()
+
+	param.DataId = "source-snapshot-dataId"
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
+	assert.Nil(t, cache.WriteConfigToFile(cacheKey, snapshotClient.configCacheDir, "snapshot content"))
+	result, err = snapshotClient.GetConfigWithResult(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot content", result.Content)
+	assert.Equal(t, constant.CONFIG_SOURCE_SNAPSHOT, result.Source)
+	assert.False(t, result.LastModified.IsZero())
+}
+
+func TestWatchGroup(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	combiner := func(contents map[string]string) (interface{}, error) {
+		if contents["group-b@@DEFAULT_GROUP@@"] == "invalid" {
+			return nil, errors.New("invalid content")
+		}
+		return contents["group-a@@DEFAULT_GROUP@@"] + "|" + contents["group-b@@DEFAULT_GROUP@@"], nil
+	}
+	_, err := client.WatchGroup(nil, combiner)
+	assert.NotNil(t, err)
+	_, err = client.WatchGroup([]vo.ConfigParam{{DataId: "group-a"}}, nil)
+	assert.NotNil(t, err)
+
+	ref, err := client.WatchGroup([]vo.ConfigParam{{DataId: "group-a"}, {DataId: "group-b"}}, combiner)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world|hello world", ref.Get())
+
+	ref.update("group-a@@DEFAULT_GROUP@@", "a1")
+	ref.update("group-b@@DEFAULT_GROUP@@", "b1")
+	select {
+	case value := <-ref.Changes():
+		assert.Equal(t, "a1|b1", value)
+	case <-time.After(time.Second):
+		t.Fatal("the combined value is not published")
+	}
+	assert.Equal(t, "a1|b1", ref.Get())
+
+	ref.update("group-b@@DEFAULT_GROUP@@", "invalid")
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, "a1|b1", ref.Get())
+
+	assert.Nil(t, ref.Stop())
+	assert.Nil(t, ref.Stop())
+	_, ok := <-ref.Changes()
+	assert.False(t, ok)
+	assert.Equal(t, 0, client.cacheMap.Count())
+}
+
+func TestConfigEventBus(t *testing.T) {
+	bus := GetConfigEventBus()
+	_, err := bus.Subscribe("", "", nil)
+	assert.NotNil(t, err)
+	_, err = bus.Subscribe("(", "", func(event model.ConfigEvent) {})
+	assert.NotNil(t, err)
+
+	events := make(chan model.ConfigEvent, 4)
+	sub, err := bus.Subscribe("^audit-", "", func(event model.ConfigEvent) {
+		events <- event
+	})
+	assert.Nil(t, err)
+	defer sub.Unsubscribe()
+
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	handler := &ConfigChangeNotifyRequestHandler{client: client}
+	handler.RequestReply(rpc_request.NewConfigChangeNotifyRequest("other-group", "event-dataId", ""), &rpc.RpcClient{})
+	handler.RequestReply(rpc_request.NewConfigChangeNotifyRequest("audit-group", "event-dataId", ""), &rpc.RpcClient{})
+	client.handleFuzzyWatchChange("", "event-added+audit-group+public", ConfigEventAdded)
+	select {
+	case event := <-events:
+		assert.Equal(t, ConfigEventChanged, event.Type)
+		assert.Equal(t, "audit-group", event.Group)
+		assert.Equal(t, "event-dataId", event.DataId)
+		assert.False(t, event.Time.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("the change event is not published")
+	}
+	select {
+	case event := <-events:
+		assert.Equal(t, ConfigEventAdded, event.Type)
+		assert.Equal(t, "event-added", event.DataId)
+	case <-time.After(time.Second):
+		t.Fatal("the fuzzy watch event is not published")
+	}
+	sub.Unsubscribe()
+	bus.Publish(model.ConfigEvent{Type: ConfigEventChanged, DataId: "event-dataId", Group: "audit-group"})
+}
+
+type recordRequestHook struct {
+	infos []ConfigRequestInfo
+	errs  []error
+}
+
+type requestHookKey struct{}
+
+func (h *recordRequestHook) OnRequest(ctx context.Context, info ConfigRequestInfo) context.Context {
+	return context.WithValue(ctx, requestHookKey{}, info.DataId)
+}
+
+func (h *recordRequestHook) OnResponse(ctx context.Context, info ConfigRequestInfo, latency time.Duration, err error) {
+	if ctx.Value(requestHookKey{}) == info.DataId {
+		h.infos = append(h.infos, info)
+		h.errs = append(h.errs, err)
+	}
+}
+
+func TestConfigRequestHook(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	assert.NotNil(t, client.AddRequestHook(nil))
+
+	proxy, err := NewConfigProxy(context.Background(), []constant.ServerConfig{*serverConfigWithOptions},
+		*clientConfigWithOptions, &http_agent.HttpAgent{})
+	assert.Nil(t, err)
+	hook := &recordRequestHook{}
+	proxy.addRequestHook(hook)
+	_, err = proxy.requestProxy(&rpc.RpcClient{}, rpc_request.NewConfigQueryRequest("hook-group", "hook-dataId", "hook-tenant"), 30)
+	assert.NotNil(t, err)
+	assert.Equal(t, []ConfigRequestInfo{{RequestType: "ConfigQueryRequest", DataId: "hook-dataId", Group: "hook-group",
+		Tenant: "hook-tenant"}}, hook.infos)
+	assert.Equal(t, err, hook.errs[0])
+}
+
+type pluginPushRequest struct {
+	*rpc_request.InternalRequest
+}
+
+func (r *pluginPushRequest) GetRequestType() string {
+	return "PluginPushRequest"
+}
+
+func TestConfigClient_RegisterServerRequestHandler(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	handler := &rpc.ClientDetectionRequestHandler{}
+	assert.NotNil(t, client.RegisterServerRequestHandler(nil, handler))
+	for _, request := range []func() rpc_request.IRequest{
+		func() rpc_request.IRequest { return rpc_request.NewConfigChangeNotifyRequest("", "", "") },
+		func() rpc_request.IRequest {
+			return &rpc_request.ConnectResetRequest{InternalRequest: rpc_request.NewInternalRequest()}
+		},
+		func() rpc_request.IRequest {
+			return &rpc_request.SetupAckRequest{InternalRequest: rpc_request.NewInternalRequest()}
+		},
+	} {
+		assert.NotNil(t, client.RegisterServerRequestHandler(request, handler))
+	}
+	assert.Nil(t, client.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return &pluginPushRequest{InternalRequest: rpc_request.NewInternalRequest()}
+	}, handler))
+}
+
+func TestGetConfigStaleWhileRevalidate(t *testing.T) {
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	clientConfig := *clientConfigWithOptions
+	clientConfig.CacheDir = t.TempDir()
+	clientConfig.SnapshotTtlMs = 60 * 1000
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	client.configProxy = &MockConfigProxy{}
+	defer client.CloseClient()
+
+	param := vo.ConfigParam{DataId: "swr-dataId", Group: "swr-group"}
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
+	assert.Nil(t, cache.WriteConfigToFile(cacheKey, client.configCacheDir, "snapshot content"))
+	result, err := client.GetConfigWithResult(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot content", result.Content)
+	assert.Equal(t, constant.CONFIG_SOURCE_SNAPSHOT, result.Source)
+
+	staleTime := time.Now().Add(-2 * time.Minute)
+	assert.Nil(t, os.Chtimes(cache.GetFileName(cacheKey, client.configCacheDir), staleTime, staleTime))
+	result, err = client.GetConfigWithResult(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", result.Content)
+	assert.Equal(t, constant.CONFIG_SOURCE_SERVER, result.Source)
+}
+
+func TestLegacyConfigProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case "/nacos" + constant.SERVER_STATE_PATH:
+			_, _ = w.Write([]byte(`{"version":"1.4.1"}`))
+		case "/nacos" + constant.CONFIG_PATH:
+			if r.Form.Get("dataId") != "legacy" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set(constant.CONFIG_TYPE_HEADER, "yaml")
+			w.Header().Set(constant.ENCRYPTED_DATA_KEY_HEADER, "data-key")
+			_, _ = w.Write([]byte("legacy-content"))
+		case "/nacos" + constant.CONFIG_LISTEN_PATH:
+			assert.Equal(t, "true", r.Header.Get("Long-Pulling-Timeout-No-Hangup"))
+			assert.Equal(t, "legacy\x02group\x02md5\x02tenant\x01", r.Form.Get("Listening-Configs"))
+			_, _ = w.Write([]byte(url.QueryEscape("legacy\x02group\x02tenant\x01")))
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	serverConfig := *constant.NewServerConfig(serverURL.Hostname(), uint64(port))
+
+	iProxy, err := NewConfigProxy(context.Background(), []constant.ServerConfig{serverConfig},
+		*constant.NewClientConfig(constant.WithTimeoutMs(3000)), &http_agent.HttpAgent{})
+	assert.Nil(t, err)
+	// the server version is detected when grpc fails, unless the budget of the request runs out
+	assert.Equal(t, constant.GRPC, iProxy.activeProtocol())
+	proxy := iProxy.(*ConfigProxy)
+	assert.False(t, proxy.fallbackToLegacy(&rpc.RpcClient{}, errors.New("not connected"), 0))
+
+	response, err := proxy.requestProxy(&rpc.RpcClient{}, rpc_request.NewConfigQueryRequest("group", "legacy", "tenant"), 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, "legacy-content", response.(*rpc_response.ConfigQueryResponse).Content)
+	assert.Equal(t, constant.CONFIG_COMPAT_MODE_HTTP, iProxy.activeProtocol())
+	assert.Equal(t, "yaml", response.(*rpc_response.ConfigQueryResponse).ContentType)
+	assert.Equal(t, "data-key", response.(*rpc_response.ConfigQueryResponse).EncryptedDataKey)
+	queryResponse, err := proxy.queryConfigByHttp("legacy", "group", "tenant", 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, "yaml", queryResponse.ContentType)
+	assert.Equal(t, "data-key", queryResponse.EncryptedDataKey)
+	response, err = proxy.requestProxy(&rpc.RpcClient{}, rpc_request.NewConfigQueryRequest("group", "missing", "tenant"), 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, response.GetErrorCode())
+
+	listen := rpc_request.NewConfigBatchListenRequest(1)
+	listen.ConfigListenContexts = append(listen.ConfigListenContexts,
+		model.ConfigListenContext{DataId: "legacy", Group: "group", Md5: "md5", Tenant: "tenant"})
+	response, err = proxy.requestProxy(&rpc.RpcClient{}, listen, 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ConfigContext{{DataId: "legacy", Group: "group", Tenant: "tenant"}},
+		response.(*rpc_response.ConfigChangeBatchListenResponse).ChangedConfigs)
+
+	_, err = proxy.requestProxy(&rpc.RpcClient{}, rpc_request.NewConfigFuzzyWatchRequest("*", "", nil), 3000)
+	assert.True(t, errors.Is(err, ErrLegacyNotSupported))
+
+	// the connection watchers are added to the rpc clients of all the tasks, including the ones created later
+	client := createConfigClientTest()
+	first := proxy.createRpcClient(context.Background(), "0", client)
+	callback := func(event model.ConnectionEvent) {}
+	proxy.watchConnection(&callback)
+	second := proxy.createRpcClient(context.Background(), "1", client)
+	watchers := func(rpcClient *rpc.RpcClient) int {
+		return reflect.ValueOf(rpcClient).Elem().FieldByName("connectionWatchers").Len()
+	}
+	assert.Equal(t, 1, watchers(first))
+	assert.Equal(t, 1, watchers(second))
+	proxy.unwatchConnection(&callback)
+	assert.Equal(t, 0, watchers(first))
+	assert.Equal(t, 0, watchers(second))
+	assert.Nil(t, proxy.closeRpcClients(context.Background()))
+}
+
+type listenRecordProxy struct {
+	MockConfigProxy
+	mutex   sync.Mutex
+	listens []bool
+}
+
+func (p *listenRecordProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if listenRequest, ok := request.(*rpc_request.ConfigBatchListenRequest); ok {
+		p.mutex.Lock()
+		p.listens = append(p.listens, listenRequest.Listen)
+		p.mutex.Unlock()
+	}
+	return p.MockConfigProxy.requestProxy(rpcClient, request, timeoutMills)
+}
+
+func TestCancelListenConfigInOrder(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &listenRecordProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "ordered", Group: "group", OnChange: func(namespace, group, dataId, data string) {}}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfigWithOptions.NamespaceId)
+
+	assert.Nil(t, client.ListenConfig(param))
+	assert.Nil(t, client.CancelListenConfig(param))
+	// the remove-listen request of the cancel is sent before the config is listened again
+	assert.Nil(t, client.ListenConfig(param))
+	assert.True(t, client.cacheMap.Has(key))
+	assert.Equal(t, []bool{false}, proxy.listens)
+
+	assert.Nil(t, client.CancelListenConfig(param))
+	<-client.listenQueue.Submit(key, func() {})
+	assert.False(t, client.cacheMap.Has(key))
+	assert.Equal(t, []bool{false, false}, proxy.listens)
+
+	// the remove-listen request is skipped if the config is listened again before it's sent
+	data := cacheData{dataId: param.DataId, group: param.Group, tenant: clientConfigWithOptions.NamespaceId}
+	client.cacheMap.Set(key, data)
+	client.removeListenOnServer(key, data)
+	<-client.listenQueue.Submit(key, func() {})
+	assert.Equal(t, 2, len(proxy.listens))
+}
+
+type inFlightListenProxy struct {
+	listenRecordProxy
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *inFlightListenProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	listenRequest, ok := request.(*rpc_request.ConfigBatchListenRequest)
+	if !ok {
+		return p.listenRecordProxy.requestProxy(rpcClient, request, timeoutMills)
+	}
+	_, _ = p.listenRecordProxy.requestProxy(rpcClient, request, timeoutMills)
+	if listenRequest.Listen {
+		select {
+		case p.started <- struct{}{}:
+		default:
+		}
+		<-p.release
+	}
+	return &rpc_response.ConfigChangeBatchListenResponse{Response: &rpc_response.Response{ResultCode: 200, Success: true}}, nil
+}
+
+func TestCancelListenConfigDuringListen(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &inFlightListenProxy{started: make(chan struct{}, 1), release: make(chan struct{})}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "in-flight", Group: "group", OnChange: func(namespace, group, dataId, data string) {}}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfigWithOptions.NamespaceId)
+	assert.Nil(t, client.ListenConfig(param))
+
+	done := make(chan struct{})
+	go func() {
+		client.executeConfigListen()
+		close(done)
+	}()
+	<-proxy.started
+	// the cancel isn't blocked by the listen request in flight
+	assert.Nil(t, client.CancelListenConfig(param))
+	assert.False(t, client.cacheMap.Has(key))
+	close(proxy.release)
+	<-done
+	<-client.listenQueue.Submit(key, func() {})
+
+	// the remove-listen request is sent again after the listen response, and the config isn't written back
+	assert.False(t, client.cacheMap.Has(key))
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	assert.Equal(t, []bool{true, false, false}, proxy.listens)
+}
+
+type blockingQueryProxy struct {
+	MockConfigProxy
+	armed   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingQueryProxy) queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	if atomic.CompareAndSwapInt32(&p.armed, 1, 0) {
+		close(p.started)
+		<-p.release
+		return &rpc_response.ConfigQueryResponse{Content: "changed", Response: &rpc_response.Response{Success: true}}, nil
+	}
+	return p.MockConfigProxy.queryConfig(dataId, group, tenant, timeout, notify, client)
+}
+
+func TestCancelListenConfigDuringQuery(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &blockingQueryProxy{started: make(chan struct{}), release: make(chan struct{})}
+	client.configProxy = proxy
+	var notified int32
+	param := vo.ConfigParam{DataId: "in-flight-query", Group: "group", OnChange: func(namespace, group, dataId, data string) {
+		atomic.AddInt32(&notified, 1)
+	}}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfigWithOptions.NamespaceId)
+	assert.Nil(t, client.ListenConfig(param))
+	v, ok := client.cacheMap.Get(key)
+	assert.True(t, ok)
+
+	atomic.StoreInt32(&proxy.armed, 1)
+	done := make(chan struct{})
+	go func() {
+		client.refreshContentAndCheck(v.(cacheData), true)
+		close(done)
+	}()
+	<-proxy.started
+	assert.Nil(t, client.CancelListenConfig(param))
+	close(proxy.release)
+	<-done
+
+	// the canceled config isn't written back
+	assert.False(t, client.cacheMap.Has(key))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&notified))
+
+	// a config listened again during the query isn't overwritten by the stale listener
+	proxy.started, proxy.release = make(chan struct{}), make(chan struct{})
+	assert.Nil(t, client.ListenConfig(param))
+	v, _ = client.cacheMap.Get(key)
+	atomic.StoreInt32(&proxy.armed, 1)
+	done = make(chan struct{})
+	go func() {
+		client.refreshContentAndCheck(v.(cacheData), true)
+		close(done)
+	}()
+	<-proxy.started
+	assert.Nil(t, client.CancelListenConfig(param))
+	assert.Nil(t, client.ListenConfig(param))
+	relistened, _ := client.cacheMap.Get(key)
+	close(proxy.release)
+	<-done
+	current, ok := client.cacheMap.Get(key)
+	assert.True(t, ok)
+	assert.True(t, current.(cacheData).cacheDataListener == relistened.(cacheData).cacheDataListener)
+	assert.NotEqual(t, util.Md5("changed"), current.(cacheData).md5)
+}
+
+func TestExecuteListenerWhileListening(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	param := vo.ConfigParam{DataId: "race-listen", Group: "group", OnChange: func(namespace, group, dataId, data string) {}}
+	assert.Nil(t, client.ListenConfig(param))
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, clientConfigWithOptions.NamespaceId))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cData := v.(cacheData)
+		for i := 0; i < 50; i++ {
+			cData.content = "content-" + strconv.Itoa(i)
+			cData.md5 = util.Md5(cData.content)
+			cData.executeListener()
+		}
+	}()
+	// the listeners added meanwhile read the last notified content on the listenQueue
+	for i := 0; i < 50; i++ {
+		assert.Nil(t, client.ListenConfig(param))
+	}
+	<-done
+	lastMd5, lastContent := v.(cacheData).cacheDataListener.lastNotified()
+	assert.Equal(t, "content-49", lastContent)
+	assert.Equal(t, util.Md5(lastContent), lastMd5)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+)
+
+const (
+	ConfigEventChanged = "CHANGED"
+	ConfigEventAdded   = fuzzyWatchAddConfig
+	ConfigEventDeleted = fuzzyWatchDeleteConfig
+
+	configEventQueueSize = 256
+)
+
+var defaultConfigEventBus = NewConfigEventBus()
+
+// GetConfigEventBus return the global bus where the config change pushes of all the config clients are
+// published, including the configs which are not listened.
+func GetConfigEventBus() *ConfigEventBus {
+	return defaultConfigEventBus
+}
+
+// ConfigEventBus dispatch the config events to the subscriptions whose filters match.
+type ConfigEventBus struct {
+	mutex         sync.RWMutex
+	subscriptions map[*ConfigEventSubscription]struct{}
+}
+
+// ConfigEventSubscription is a subscription of the ConfigEventBus, the handler is called in order
+// in a goroutine of its own, events are dropped when the handler can't keep up.
+type ConfigEventSubscription struct {
+	bus     *ConfigEventBus
+	group   *regexp.Regexp
+	dataId  *regexp.Regexp
+	handler func(event model.ConfigEvent)
+	events  chan model.ConfigEvent
+	once    sync.Once
+}
+
+func NewConfigEventBus() *ConfigEventBus {
+	return &ConfigEventBus{subscriptions: make(map[*ConfigEventSubscription]struct{})}
+}
+
+// Subscribe receive the events whose group and dataId match the regexps, an empty pattern matches all.
+func (bus *ConfigEventBus) Subscribe(groupPattern, dataIdPattern string, handler func(event model.ConfigEvent)) (
+	*ConfigEventSubscription, error) {
+	if handler == nil {
+		return nil, errors.New("[ConfigEventBus.Subscribe] handler can not be nil")
+	}
+	sub := &ConfigEventSubscription{bus: bus, handler: handler, events: make(chan model.ConfigEvent, configEventQueueSize)}
+	var err error
+	if sub.group, err = compileEventPattern(groupPattern); err != nil {
+		return nil, errors.WithMessage(err, "[ConfigEventBus.Subscribe] invalid group pattern")
+	}
+	if sub.dataId, err = compileEventPattern(dataIdPattern); err != nil {
+		return nil, errors.WithMessage(err, "[ConfigEventBus.Subscribe] invalid dataId pattern")
+	}
+	bus.mutex.Lock()
+	bus.subscriptions[sub] = struct{}{}
+	bus.mutex.Unlock()
+	go sub.run()
+	return sub, nil
+}
+
+func compileEventPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) <= 0 {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// Publish dispatch the event without blocking.
+func (bus *ConfigEventBus) Publish(event model.ConfigEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+	for sub := range bus.subscriptions {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			logger.Warnf("[ConfigEventBus] the subscription is full, drop the %s event of dataId=%s, group=%s",
+				event.Type, event.DataId, event.Group)
+		}
+	}
+}
+
+// Unsubscribe stop receiving the events, the queued events are discarded.
+func (sub *ConfigEventSubscription) Unsubscribe() {
+	sub.once.Do(func() {
+		sub.bus.mutex.Lock()
+		delete(sub.bus.subscriptions, sub)
+		sub.bus.mutex.Unlock()
+		close(sub.events)
+	})
+}
+
+func (sub *ConfigEventSubscription) matches(event model.ConfigEvent) bool {
+	return (sub.group == nil || sub.group.MatchString(event.Group)) &&
+		(sub.dataId == nil || sub.dataId.MatchString(event.DataId))
+}
+
+func (sub *ConfigEventSubscription) run() {
+	for event := range sub.events {
+		sub.handler(event)
+	}
+}
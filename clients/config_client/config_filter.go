@@ -0,0 +1,72 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/filter"
+)
+
+var (
+	configFiltersMutex sync.Mutex
+	configFilters      []filter.IConfigFilter
+)
+
+// orderedConfigFilter override the order of a registered filter, the smaller order runs first, the
+// encryption filter is 0.
+type orderedConfigFilter struct {
+	filter.IConfigFilter
+	order int
+}
+
+func (f *orderedConfigFilter) GetOrder() int {
+	return f.order
+}
+
+// RegisterConfigFilter register a filter for the clients created after it, the filter is called with
+// vo.RequestType params on publish and vo.ResponseType params on get and listen, and can rewrite the content.
+func RegisterConfigFilter(configFilter filter.IConfigFilter, order int) error {
+	if configFilter == nil {
+		return errors.New("[RegisterConfigFilter] filter can not be nil")
+	}
+	if len(configFilter.GetFilterName()) <= 0 {
+		return errors.New("[RegisterConfigFilter] filter name can not be empty")
+	}
+	configFiltersMutex.Lock()
+	defer configFiltersMutex.Unlock()
+	for _, registered := range configFilters {
+		if registered.GetFilterName() == configFilter.GetFilterName() {
+			return errors.Errorf("[RegisterConfigFilter] filter %s is already registered", configFilter.GetFilterName())
+		}
+	}
+	configFilters = append(configFilters, &orderedConfigFilter{IConfigFilter: configFilter, order: order})
+	return nil
+}
+
+func (client *ConfigClient) registerConfigFilters() error {
+	configFiltersMutex.Lock()
+	defer configFiltersMutex.Unlock()
+	for _, configFilter := range configFilters {
+		if err := filter.RegisterConfigFilterToChain(client.configFilterChainManager, configFilter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
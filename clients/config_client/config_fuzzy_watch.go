@@ -0,0 +1,328 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+	"github.com/pkg/errors"
+)
+
+const (
+	fuzzyWatchPatternSplitter = ">>"
+	fuzzyWatchWildcard        = "*"
+	fuzzyWatchTypeWatch       = "WATCH"
+	fuzzyWatchTypeCancel      = "CANCEL_WATCH"
+	fuzzyWatchAddConfig       = "ADD_CONFIG"
+	fuzzyWatchDeleteConfig    = "DELETE_CONFIG"
+)
+
+type fuzzyWatcher struct {
+	groupKeyPattern string
+	dataIdPattern   string
+	group           string
+	tenant          string
+	listener        vo.Listener
+	receivedKeys    map[string]uint64 // the groupKeys and the ids of the listeners added to them
+}
+
+func (w *fuzzyWatcher) matches(dataId, group, tenant string) bool {
+	return w.group == group && normalizeTenant(w.tenant) == normalizeTenant(tenant) &&
+		matchWildcard(w.dataIdPattern, dataId)
+}
+
+func (w *fuzzyWatcher) receivedGroupKeys() []string {
+	keys := make([]string, 0, len(w.receivedKeys))
+	for k := range w.receivedKeys {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// ListenConfigWithPrefix watch all configs of a group whose dataId matches the pattern.
+// A pattern without '*' is treated as a prefix.
+func (client *ConfigClient) ListenConfigWithPrefix(param vo.ConfigParam) (err error) {
+	if len(param.DataId) <= 0 {
+		err = errors.New("[client.ListenConfigWithPrefix] DataId can not be empty")
+		return err
+	}
+	if len(param.Group) <= 0 {
+		err = errors.New("[client.ListenConfigWithPrefix] Group can not be empty")
+		return err
+	}
+	if param.OnChange == nil {
+		err = errors.New("[client.ListenConfigWithPrefix] OnChange can not be nil")
+		return err
+	}
+	clientConfig, err := client.GetClientConfig()
+	if err != nil {
+		err = errors.New("[checkConfigInfo.GetClientConfig] failed")
+		return err
+	}
+
+	dataIdPattern := param.DataId
+	if !strings.Contains(dataIdPattern, fuzzyWatchWildcard) {
+		dataIdPattern += fuzzyWatchWildcard
+	}
+	watcher := &fuzzyWatcher{
+		groupKeyPattern: generateFuzzyWatchPattern(dataIdPattern, param.Group, resolveNamespaceId(clientConfig, param.NamespaceId)),
+		dataIdPattern:   dataIdPattern,
+		group:           param.Group,
+		tenant:          resolveNamespaceId(clientConfig, param.NamespaceId),
+		listener:        param.OnChange,
+		receivedKeys:    make(map[string]uint64),
+	}
+
+	client.fuzzyMutex.Lock()
+	if _, ok := client.fuzzyWatchers[watcher.groupKeyPattern]; ok {
+		client.fuzzyMutex.Unlock()
+		return errors.Errorf("[client.ListenConfigWithPrefix] pattern %s is already watched", watcher.groupKeyPattern)
+	}
+	client.fuzzyWatchers[watcher.groupKeyPattern] = watcher
+	client.fuzzyMutex.Unlock()
+
+	request := rpc_request.NewConfigFuzzyWatchRequest(watcher.groupKeyPattern, fuzzyWatchTypeWatch, []string{})
+	request.IsInitializing = true
+	if _, err = client.requestFuzzyWatch(request); err != nil {
+		client.fuzzyMutex.Lock()
+		delete(client.fuzzyWatchers, watcher.groupKeyPattern)
+		client.fuzzyMutex.Unlock()
+		return err
+	}
+	logger.Infof("Listen config with prefix DataId:%s Group:%s", dataIdPattern, param.Group)
+	return nil
+}
+
+// CancelListenConfigWithPrefix cancel a watch created by ListenConfigWithPrefix, and stop listening
+// all configs it matched.
+func (client *ConfigClient) CancelListenConfigWithPrefix(param vo.ConfigParam) (err error) {
+	clientConfig, err := client.GetClientConfig()
+	if err != nil {
+		logger.Errorf("[checkConfigInfo.GetClientConfig] failed,err:%+v", err)
+		return
+	}
+	dataIdPattern := param.DataId
+	if !strings.Contains(dataIdPattern, fuzzyWatchWildcard) {
+		dataIdPattern += fuzzyWatchWildcard
+	}
+	groupKeyPattern := generateFuzzyWatchPattern(dataIdPattern, param.Group, resolveNamespaceId(clientConfig, param.NamespaceId))
+
+	client.fuzzyMutex.Lock()
+	watcher, ok := client.fuzzyWatchers[groupKeyPattern]
+	delete(client.fuzzyWatchers, groupKeyPattern)
+	client.fuzzyMutex.Unlock()
+	if !ok {
+		return nil
+	}
+	for groupKey, id := range watcher.receivedKeys {
+		if dataId, group, tenant, parseErr := parseGroupKey(groupKey); parseErr == nil {
+			_ = client.cancelListener(vo.ConfigParam{DataId: dataId, Group: group, NamespaceId: tenant}, id)
+		}
+	}
+	request := rpc_request.NewConfigFuzzyWatchRequest(groupKeyPattern, fuzzyWatchTypeCancel, []string{})
+	_, err = client.requestFuzzyWatch(request)
+	logger.Infof("Cancel listen config with prefix DataId:%s Group:%s", dataIdPattern, param.Group)
+	return err
+}
+
+func (client *ConfigClient) requestFuzzyWatch(request *rpc_request.ConfigFuzzyWatchRequest) (bool, error) {
+	rpcClient := client.configProxy.getRpcClient(client)
+	if rpcClient != nil && rpcClient.ServerAbility(constant.ABILITY_FUZZY_WATCH) == rpc.ABILITY_NOT_SUPPORTED {
+		return false, errors.New("ConfigFuzzyWatchRequest failure, fuzzy watch is not supported by the server")
+	}
+	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
+	if err != nil {
+		return false, err
+	}
+	if response == nil {
+		return false, errors.New("ConfigFuzzyWatchRequest failure, response is nil")
+	}
+	return client.buildResponse(response)
+}
+
+// syncFuzzyWatchers re-send all watched patterns with the groupKeys already received, so the server
+// can push the difference after a reconnect.
+func (client *ConfigClient) syncFuzzyWatchers() {
+	client.fuzzyMutex.Lock()
+	requests := make([]*rpc_request.ConfigFuzzyWatchRequest, 0, len(client.fuzzyWatchers))
+	for pattern, watcher := range client.fuzzyWatchers {
+		requests = append(requests, rpc_request.NewConfigFuzzyWatchRequest(pattern, fuzzyWatchTypeWatch,
+			watcher.receivedGroupKeys()))
+	}
+	client.fuzzyMutex.Unlock()
+	for _, request := range requests {
+		if _, err := client.requestFuzzyWatch(request); err != nil {
+			logger.Warnf("ConfigFuzzyWatchRequest failure, pattern:%s, err:%v", request.GroupKeyPattern, err)
+		}
+	}
+}
+
+// handleFuzzyWatchChange apply an added or deleted groupKey to the watchers it matches.
+// An empty groupKeyPattern matches the groupKey against every watcher.
+func (client *ConfigClient) handleFuzzyWatchChange(groupKeyPattern, groupKey, changeType string) {
+	dataId, group, tenant, err := parseGroupKey(groupKey)
+	if err != nil {
+		logger.Warnf("[fuzzy-watch] invalid groupKey:%s, err:%v", groupKey, err)
+		return
+	}
+	GetConfigEventBus().Publish(model.ConfigEvent{Type: changeType, DataId: dataId, Group: group, Tenant: tenant})
+	type watchedListener struct {
+		listener vo.Listener
+		id       uint64
+	}
+	var listeners []watchedListener
+	client.fuzzyMutex.Lock()
+	for pattern, watcher := range client.fuzzyWatchers {
+		if groupKeyPattern != "" && pattern != groupKeyPattern {
+			continue
+		}
+		if !watcher.matches(dataId, group, tenant) {
+			continue
+		}
+		id, received := watcher.receivedKeys[groupKey]
+		switch changeType {
+		case fuzzyWatchAddConfig:
+			if !received {
+				id = atomic.AddUint64(&listenerSeq, 1)
+				watcher.receivedKeys[groupKey] = id
+				listeners = append(listeners, watchedListener{listener: watcher.listener, id: id})
+			}
+		case fuzzyWatchDeleteConfig:
+			if received {
+				delete(watcher.receivedKeys, groupKey)
+				listeners = append(listeners, watchedListener{listener: watcher.listener, id: id})
+			}
+		}
+	}
+	client.fuzzyMutex.Unlock()
+
+	for _, l := range listeners {
+		param := vo.ConfigParam{DataId: dataId, Group: group, NamespaceId: tenant, OnChange: l.listener}
+		if changeType == fuzzyWatchAddConfig {
+			if err = client.listenConfig(param, l.id); err != nil {
+				logger.Warnf("[fuzzy-watch] listen config failed, dataId=%s, group=%s, err:%v", dataId, group, err)
+			}
+			continue
+		}
+		_ = client.cancelListener(param, l.id)
+		deletedListener := l.listener
+		client.callbackExecutor.submit(util.GetConfigCacheKey(dataId, group, tenant), func() {
+			deletedListener(tenant, group, dataId, "")
+		})
+	}
+}
+
+func generateFuzzyWatchPattern(dataIdPattern, group, tenant string) string {
+	return normalizeTenant(tenant) + fuzzyWatchPatternSplitter + group + fuzzyWatchPatternSplitter + dataIdPattern
+}
+
+// parseGroupKey split a server groupKey of dataId+group+tenant, in which '+' and '%' are escaped.
+func parseGroupKey(groupKey string) (dataId, group, tenant string, err error) {
+	parts := strings.Split(groupKey, "+")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", errors.Errorf("invalid groupKey: %s", groupKey)
+	}
+	unescape := func(s string) string {
+		return strings.NewReplacer("%2B", "+", "%25", "%").Replace(s)
+	}
+	dataId, group = unescape(parts[0]), unescape(parts[1])
+	if len(parts) == 3 {
+		tenant = unescape(parts[2])
+	}
+	return dataId, group, tenant, nil
+}
+
+func normalizeTenant(tenant string) string {
+	if tenant == "" {
+		return constant.DEFAULT_NAMESPACE_ID
+	}
+	return tenant
+}
+
+// matchWildcard report whether s matches pattern, where '*' matches any sequence of characters.
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, fuzzyWatchWildcard)
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for i := 1; i < len(parts)-1; i++ {
+		idx := strings.Index(s, parts[i])
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(parts[i]):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+type ConfigFuzzyWatchChangeNotifyRequestHandler struct {
+	client *ConfigClient
+}
+
+func (c *ConfigFuzzyWatchChangeNotifyRequestHandler) Name() string {
+	return "ConfigFuzzyWatchChangeNotifyRequestHandler"
+}
+
+func (c *ConfigFuzzyWatchChangeNotifyRequestHandler) RequestReply(request rpc_request.IRequest, rpcClient *rpc.RpcClient) rpc_response.IResponse {
+	notifyRequest, ok := request.(*rpc_request.ConfigFuzzyWatchChangeNotifyRequest)
+	if !ok {
+		return nil
+	}
+	logger.Infof("%s [server-push] fuzzy watch config changed. groupKey=%s, changeType=%s", rpcClient.Name(),
+		notifyRequest.GroupKey, notifyRequest.ChangeType)
+	c.client.handleFuzzyWatchChange("", notifyRequest.GroupKey, notifyRequest.ChangeType)
+	return &rpc_response.ConfigFuzzyWatchChangeNotifyResponse{
+		Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS},
+	}
+}
+
+type ConfigFuzzyWatchSyncRequestHandler struct {
+	client *ConfigClient
+}
+
+func (c *ConfigFuzzyWatchSyncRequestHandler) Name() string {
+	return "ConfigFuzzyWatchSyncRequestHandler"
+}
+
+func (c *ConfigFuzzyWatchSyncRequestHandler) RequestReply(request rpc_request.IRequest, rpcClient *rpc.RpcClient) rpc_response.IResponse {
+	syncRequest, ok := request.(*rpc_request.ConfigFuzzyWatchSyncRequest)
+	if !ok {
+		return nil
+	}
+	logger.Infof("%s [server-push] fuzzy watch sync. pattern=%s, syncType=%s, batch=%d/%d, size=%d", rpcClient.Name(),
+		syncRequest.GroupKeyPattern, syncRequest.SyncType, syncRequest.CurrentBatch, syncRequest.TotalBatch,
+		len(syncRequest.Contexts))
+	for _, context := range syncRequest.Contexts {
+		c.client.handleFuzzyWatchChange(syncRequest.GroupKeyPattern, context.GroupKey, context.ChangedType)
+	}
+	return &rpc_response.ConfigFuzzyWatchSyncResponse{
+		Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS},
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// ConfigGroupRef is a live handle of the combined value of several configs returned by WatchGroup.
+type ConfigGroupRef struct {
+	client       *ConfigClient
+	params       []vo.ConfigParam
+	listenerIds  []uint64
+	combiner     vo.ConfigCombiner
+	debounce     time.Duration
+	value        atomic.Value
+	mutex        sync.Mutex
+	combineMutex sync.Mutex
+	contents     map[string]string
+	timer        *time.Timer
+	changes      chan interface{}
+	stopped      bool
+}
+
+// groupValue wraps the combined value since atomic.Value can't store nil or values of different types.
+type groupValue struct {
+	value interface{}
+}
+
+// Get return the latest combined value without locking.
+func (ref *ConfigGroupRef) Get() interface{} {
+	return ref.value.Load().(groupValue).value
+}
+
+// Changes return the channel of the combined values, it only keeps the latest value which is not received
+// yet, and it is closed by Stop.
+func (ref *ConfigGroupRef) Changes() <-chan interface{} {
+	return ref.changes
+}
+
+// Stop cancel the listening of all the configs in the group and close the changes channel.
+func (ref *ConfigGroupRef) Stop() error {
+	ref.mutex.Lock()
+	defer ref.mutex.Unlock()
+	if ref.stopped {
+		return nil
+	}
+	ref.stopped = true
+	if ref.timer != nil {
+		ref.timer.Stop()
+	}
+	close(ref.changes)
+	return ref.cancelListen(len(ref.params))
+}
+
+// cancelListen remove the listeners of the first n configs.
+func (ref *ConfigGroupRef) cancelListen(n int) (err error) {
+	for i, param := range ref.params[:n] {
+		if cancelErr := ref.client.cancelListener(param, ref.listenerIds[i]); cancelErr != nil && err == nil {
+			err = cancelErr
+		}
+	}
+	return err
+}
+
+// update record the content of a member and schedule a re-combine after the debounce window, so the
+// changes of the members published together are combined once.
+func (ref *ConfigGroupRef) update(key, content string) {
+	ref.mutex.Lock()
+	defer ref.mutex.Unlock()
+	if ref.stopped || ref.contents[key] == content {
+		return
+	}
+	ref.contents[key] = content
+	if ref.timer == nil {
+		ref.timer = time.AfterFunc(ref.debounce, ref.recombine)
+		return
+	}
+	ref.timer.Reset(ref.debounce)
+}
+
+func (ref *ConfigGroupRef) recombine() {
+	ref.combineMutex.Lock()
+	defer ref.combineMutex.Unlock()
+	ref.mutex.Lock()
+	contents := make(map[string]string, len(ref.contents))
+	for key, content := range ref.contents {
+		contents[key] = content
+	}
+	ref.mutex.Unlock()
+
+	value, err := ref.combiner(contents)
+	if err != nil {
+		logger.Errorf("combine config group failed, keep the previous value, err:%v", err)
+		return
+	}
+	ref.mutex.Lock()
+	defer ref.mutex.Unlock()
+	if ref.stopped {
+		return
+	}
+	ref.value.Store(groupValue{value: value})
+	for {
+		select {
+		case ref.changes <- value:
+			return
+		default:
+		}
+		// drop the stale value to make room for the latest one.
+		select {
+		case <-ref.changes:
+		default:
+		}
+	}
+}
+
+// WatchGroup get the configs and keep their combined value up to date, the combiner is re-run when any of
+// them changes and the result is swapped in as a whole. A failed combine keeps the previous value.
+func (client *ConfigClient) WatchGroup(params []vo.ConfigParam, combiner vo.ConfigCombiner) (*ConfigGroupRef, error) {
+	if len(params) <= 0 {
+		return nil, errors.New("[client.WatchGroup] params can not be empty")
+	}
+	if combiner == nil {
+		return nil, errors.New("[client.WatchGroup] combiner can not be nil")
+	}
+	clientConfig, _ := client.GetClientConfig()
+	debounceMs := clientConfig.WatchDebounceMs
+	if debounceMs <= 0 {
+		debounceMs = constant.DEFAULT_WATCH_DEBOUNCE_MILLS
+	}
+	ref := &ConfigGroupRef{
+		client:   client,
+		combiner: combiner,
+		debounce: time.Duration(debounceMs) * time.Millisecond,
+		contents: make(map[string]string, len(params)),
+		changes:  make(chan interface{}, 1),
+	}
+	keys := make([]string, 0, len(params))
+	for _, param := range params {
+		if len(param.DataId) <= 0 {
+			return nil, errors.New("[client.WatchGroup] param.dataId can not be empty")
+		}
+		if len(param.Group) <= 0 {
+			param.Group = constant.DEFAULT_GROUP
+		}
+		content, err := client.GetConfig(param)
+		if err != nil {
+			return nil, err
+		}
+		key := util.GetConfigCacheKey(param.DataId, param.Group, resolveNamespaceId(clientConfig, param.NamespaceId))
+		ref.contents[key] = content
+		ref.params = append(ref.params, param)
+		ref.listenerIds = append(ref.listenerIds, atomic.AddUint64(&listenerSeq, 1))
+		keys = append(keys, key)
+	}
+	value, err := combiner(ref.contents)
+	if err != nil {
+		return nil, errors.WithMessage(err, "[client.WatchGroup] combine configs failed")
+	}
+	ref.value.Store(groupValue{value: value})
+	for i := range ref.params {
+		key := keys[i]
+		param := ref.params[i]
+		param.OnChangeV2 = nil
+		param.OnChange = func(namespace, group, dataId, data string) {
+			ref.update(key, data)
+		}
+		if err = client.listenConfig(param, ref.listenerIds[i]); err != nil {
+			_ = ref.cancelListen(i)
+			return nil, err
+		}
+	}
+	return ref, nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+)
+
+// markSynced record that the listened config has got its first value, from the snapshot, the local override
+// or the server, and wake up the callers of WaitForInitialSync.
+func (client *ConfigClient) markSynced(cacheKey string) {
+	client.syncMutex.Lock()
+	defer client.syncMutex.Unlock()
+	if _, ok := client.syncedKeys[cacheKey]; ok {
+		return
+	}
+	if client.syncedKeys == nil {
+		client.syncedKeys = make(map[string]struct{})
+	}
+	client.syncedKeys[cacheKey] = struct{}{}
+	client.notifySyncChangedLocked()
+}
+
+func (client *ConfigClient) unmarkSynced(cacheKey string) {
+	client.syncMutex.Lock()
+	defer client.syncMutex.Unlock()
+	delete(client.syncedKeys, cacheKey)
+	client.notifySyncChangedLocked()
+}
+
+func (client *ConfigClient) notifySyncChangedLocked() {
+	if client.syncChanged != nil {
+		close(client.syncChanged)
+		client.syncChanged = nil
+	}
+}
+
+// pendingSync return the number of listened configs without a value, and a channel closed on the next change.
+func (client *ConfigClient) pendingSync() (int, <-chan struct{}) {
+	client.syncMutex.Lock()
+	defer client.syncMutex.Unlock()
+	pending := 0
+	for _, key := range client.cacheMap.Keys() {
+		if _, ok := client.syncedKeys[key]; !ok {
+			pending++
+		}
+	}
+	if client.syncChanged == nil {
+		client.syncChanged = make(chan struct{})
+	}
+	return pending, client.syncChanged
+}
+
+// WaitForInitialSync blocks until all the listened configs have received their first value, from the local
+// snapshot or the server, or the ctx is done.
+func (client *ConfigClient) WaitForInitialSync(ctx context.Context) error {
+	for {
+		pending, changed := client.pendingSync()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-client.ctx.Done():
+			return client.ctx.Err()
+		}
+	}
+}
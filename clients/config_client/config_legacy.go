@@ -0,0 +1,264 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+const (
+	// legacyDetectInterval limits the detection of the server version after the grpc failures
+	legacyDetectInterval = time.Minute
+	longPollingTimeout   = "30000"
+)
+
+// ErrLegacyNotSupported is returned for the requests the 1.x servers have no http api for.
+var ErrLegacyNotSupported = errors.New("the request is not supported by the http protocol of the 1.x servers")
+
+// compatMode return the config compat mode of the client config, the unknown modes are taken as auto.
+func (cp *ConfigProxy) compatMode() string {
+	switch mode := cp.clientConfig.ConfigCompatMode; mode {
+	case constant.CONFIG_COMPAT_MODE_HTTP, constant.CONFIG_COMPAT_MODE_GRPC:
+		return mode
+	case "", constant.CONFIG_COMPAT_MODE_AUTO:
+	default:
+		logger.Warnf("unknown config compat mode:%s, the server version is detected when grpc fails", mode)
+	}
+	return constant.CONFIG_COMPAT_MODE_AUTO
+}
+
+// detectLegacy return whether the server is 1.x by its version within the timeout, false if it's unknown.
+func (cp *ConfigProxy) detectLegacy(timeoutMills uint64) bool {
+	if timeoutMills > cp.clientConfig.TimeoutMs && cp.clientConfig.TimeoutMs > 0 {
+		timeoutMills = cp.clientConfig.TimeoutMs
+	}
+	result, err := cp.nacosServer.ReqConfigApi(constant.SERVER_STATE_PATH, map[string]string{}, cp.buildAkSkHeaders(),
+		http.MethodGet, timeoutMills)
+	if err != nil {
+		logger.Warnf("failed to detect the server version, grpc is used for config, err:%+v", err)
+		return false
+	}
+	version, err := jsonparser.GetString([]byte(result), "version")
+	if err != nil {
+		logger.Warnf("get 'version' from <%s> error, grpc is used for config, err:%+v", result, err)
+		return false
+	}
+	return util.IsLegacyServerVersion(version)
+}
+
+func (cp *ConfigProxy) isLegacy() bool {
+	return atomic.LoadInt32(&cp.legacy) == 1
+}
+
+// activeProtocol return the protocol of the config requests, grpc or http.
+func (cp *ConfigProxy) activeProtocol() string {
+	if cp.isLegacy() {
+		return constant.CONFIG_COMPAT_MODE_HTTP
+	}
+	return constant.GRPC
+}
+
+// fallbackToLegacy detect the server version within the timeout in the auto mode when the grpc request failed as
+// the server doesn't serve grpc, and switch to http if it's a 1.x server. The version is detected lazily, so the
+// clients of the 2.x servers don't request it.
+func (cp *ConfigProxy) fallbackToLegacy(rpcClient *rpc.RpcClient, err error, timeoutMills uint64) bool {
+	if cp.isLegacy() || timeoutMills == 0 || cp.compatMode() != constant.CONFIG_COMPAT_MODE_AUTO {
+		return false
+	}
+	if status.Code(err) != codes.Unimplemented && rpcClient.IsRunning() {
+		return false
+	}
+	last := atomic.LoadInt64(&cp.lastLegacyDetect)
+	if time.Since(time.Unix(0, last)) < legacyDetectInterval ||
+		!atomic.CompareAndSwapInt64(&cp.lastLegacyDetect, last, time.Now().UnixNano()) {
+		return false
+	}
+	if !cp.detectLegacy(timeoutMills) || !atomic.CompareAndSwapInt32(&cp.legacy, 0, 1) {
+		return false
+	}
+	logger.Infof("the server is 1.x, config requests are sent by http from now on, err:%v", err)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := cp.closeRpcClients(ctx); err != nil {
+			logger.Warnf("close the config rpc clients failed, err:%v", err)
+		}
+	}()
+	return true
+}
+
+// requestByHttp send the config request to the http api of the 1.x servers, and convert the result to the rpc response.
+func (cp *ConfigProxy) requestByHttp(request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	switch r := request.(type) {
+	case *rpc_request.ConfigQueryRequest:
+		return cp.legacyQuery(r, timeoutMills)
+	case *rpc_request.ConfigPublishRequest:
+		return cp.legacyPublish(r, timeoutMills)
+	case *rpc_request.ConfigRemoveRequest:
+		return cp.legacyRemove(r, timeoutMills)
+	case *rpc_request.ConfigBatchListenRequest:
+		return cp.legacyListen(r, timeoutMills)
+	}
+	return nil, errors.Wrap(ErrLegacyNotSupported, request.GetRequestType())
+}
+
+func (cp *ConfigProxy) legacyQuery(request *rpc_request.ConfigQueryRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	params := cp.buildConfigParams(request.DataId, request.Group, request.Tenant)
+	if len(request.Tag) > 0 {
+		params["tag"] = request.Tag
+	}
+	result, header, err := cp.nacosServer.ReqConfigApiWithHeader(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, timeoutMills)
+	if code := legacyErrorCode(err); code == http.StatusNotFound {
+		return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_FAIL,
+			ErrorCode: 300, Message: "config data not exist"}}, nil
+	} else if code == http.StatusConflict {
+		return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_FAIL,
+			ErrorCode: 400, Message: "config data is being modified"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildHttpQueryResponse(result, header), nil
+}
+
+func (cp *ConfigProxy) legacyPublish(request *rpc_request.ConfigPublishRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if len(request.CasMd5) > 0 {
+		return nil, errors.Wrap(ErrLegacyNotSupported, "cas publish")
+	}
+	params := cp.buildConfigParams(request.DataId, request.Group, request.Tenant)
+	params["content"] = request.Content
+	headers := cp.buildAkSkHeaders()
+	for k, v := range request.AdditionMap {
+		if len(v) == 0 {
+			continue
+		}
+		if k == "betaIps" {
+			headers[k] = v
+			continue
+		}
+		params[k] = v
+	}
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, headers, http.MethodPost, timeoutMills)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_response.ConfigPublishResponse{Response: legacyResult(result)}, nil
+}
+
+func (cp *ConfigProxy) legacyRemove(request *rpc_request.ConfigRemoveRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	params := cp.buildConfigParams(request.DataId, request.Group, request.Tenant)
+	if len(request.Tag) > 0 {
+		params["tag"] = request.Tag
+	}
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodDelete, timeoutMills)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_response.ConfigRemoveResponse{Response: legacyResult(result)}, nil
+}
+
+// legacyListen compare the md5 of the configs with the listener api, it returns at once instead of holding the
+// request, the configs are polled by the listen loop of the client.
+func (cp *ConfigProxy) legacyListen(request *rpc_request.ConfigBatchListenRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	response := &rpc_response.ConfigChangeBatchListenResponse{
+		Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true},
+	}
+	if !request.Listen || len(request.ConfigListenContexts) == 0 {
+		return response, nil
+	}
+	headers := cp.buildAkSkHeaders()
+	headers["Long-Pulling-Timeout"] = longPollingTimeout
+	headers["Long-Pulling-Timeout-No-Hangup"] = "true"
+	params := map[string]string{"Listening-Configs": buildListeningConfigs(request.ConfigListenContexts)}
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_LISTEN_PATH, params, headers, http.MethodPost, timeoutMills)
+	if err != nil {
+		return nil, err
+	}
+	response.ChangedConfigs = parseChangedConfigs(result)
+	return response, nil
+}
+
+func buildListeningConfigs(contexts []model.ConfigListenContext) string {
+	var builder strings.Builder
+	for _, context := range contexts {
+		builder.WriteString(context.DataId + constant.SPLIT_CONFIG_INNER + context.Group + constant.SPLIT_CONFIG_INNER + context.Md5)
+		if len(context.Tenant) > 0 {
+			builder.WriteString(constant.SPLIT_CONFIG_INNER + context.Tenant)
+		}
+		builder.WriteString(constant.SPLIT_CONFIG)
+	}
+	return builder.String()
+}
+
+// parseChangedConfigs parse the url encoded dataId^2group[^2tenant]^1 lines of the listener api.
+func parseChangedConfigs(result string) []model.ConfigContext {
+	decoded, err := url.QueryUnescape(strings.TrimSpace(result))
+	if err != nil {
+		logger.Warnf("decode the changed configs <%s> failed, err:%v", result, err)
+		return nil
+	}
+	var changed []model.ConfigContext
+	for _, line := range strings.Split(decoded, constant.SPLIT_CONFIG) {
+		items := strings.Split(line, constant.SPLIT_CONFIG_INNER)
+		if len(items) < 2 {
+			continue
+		}
+		config := model.ConfigContext{DataId: items[0], Group: items[1]}
+		if len(items) > 2 {
+			config.Tenant = items[2]
+		}
+		changed = append(changed, config)
+	}
+	return changed
+}
+
+func legacyResult(result string) *rpc_response.Response {
+	if strings.TrimSpace(result) == "true" {
+		return &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true}
+	}
+	return &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_FAIL, Message: result}
+}
+
+// legacyErrorCode return the http status code of the failed request, or 0 if it's not a response of the server.
+func legacyErrorCode(err error) int {
+	var nacosErr *nacos_error.NacosError
+	if err == nil || !errors.As(err, &nacosErr) {
+		return 0
+	}
+	code, _ := strconv.Atoi(nacosErr.ErrorCode())
+	return code
+}
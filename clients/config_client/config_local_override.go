@@ -0,0 +1,222 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+// localOverride read the configs from ${LocalConfigDir}/{group}/{dataId} for the default namespace and from
+// ${LocalConfigDir}/{tenant}/{group}/{dataId} for the others, a config with a local file is never read from
+// the server or the snapshot, and the listeners are notified when the file changes.
+type localOverride struct {
+	dir     string
+	mutex   sync.Mutex
+	watcher *fsnotify.Watcher
+}
+
+func newLocalOverride(dir string) *localOverride {
+	return &localOverride{dir: dir}
+}
+
+// path return the local file of the config, ok is false when a segment could escape from the dir.
+func (o *localOverride) path(tenant, group, dataId string) (string, bool) {
+	if !validSegment(group) || !validSegment(dataId) {
+		return "", false
+	}
+	if isDefaultTenant(tenant) {
+		return filepath.Join(o.dir, group, dataId), true
+	}
+	if !validSegment(tenant) {
+		return "", false
+	}
+	return filepath.Join(o.dir, tenant, group, dataId), true
+}
+
+func validSegment(segment string) bool {
+	return len(segment) > 0 && segment != "." && segment != ".." && !strings.ContainsAny(segment, `/\`)
+}
+
+func isDefaultTenant(tenant string) bool {
+	return len(tenant) == 0 || tenant == constant.DEFAULT_NAMESPACE_ID
+}
+
+func sameTenant(tenant, other string) bool {
+	return tenant == other || isDefaultTenant(tenant) && isDefaultTenant(other)
+}
+
+// read return the content of the local file, ok is false when there is no such file.
+func (o *localOverride) read(tenant, group, dataId string) (content string, ok bool) {
+	path, ok := o.path(tenant, group, dataId)
+	if !ok {
+		return "", false
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("[local-override] read file failed, tenant=%s, group=%s, dataId=%s, err:%v", tenant, group, dataId, err)
+		}
+		return "", false
+	}
+	return string(bytes), true
+}
+
+// start watch the dir and the group and tenant dirs in it, onChange is called with the tenant, group and dataId
+// of the changed file.
+func (o *localOverride) start(done <-chan struct{}, onChange func(tenant, group, dataId string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err = watcher.Add(o.dir); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			o.addDir(watcher, filepath.Join(o.dir, entry.Name()), false, nil)
+		}
+	}
+	o.mutex.Lock()
+	o.watcher = watcher
+	o.mutex.Unlock()
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				o.handleEvent(watcher, event, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("[local-override] watch dir failed, err:%v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// addDir watch a dir in the root dir or a group dir of a tenant, the sub dirs of a dir in the root dir are the
+// group dirs of the tenant. The files found in the dir are reported with onFile when it's not nil, they may be
+// created before the dir is watched.
+func (o *localOverride) addDir(watcher *fsnotify.Watcher, dir string, tenantGroup bool, onFile func(parts []string)) {
+	if err := watcher.Add(dir); err != nil {
+		logger.Warnf("[local-override] watch dir %s failed, err:%v", dir, err)
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		child := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir() && !tenantGroup:
+			o.addDir(watcher, child, true, onFile)
+		case !entry.IsDir() && onFile != nil:
+			if rel, relErr := filepath.Rel(o.dir, child); relErr == nil {
+				onFile(strings.Split(filepath.ToSlash(rel), "/"))
+			}
+		}
+	}
+}
+
+func (o *localOverride) handleEvent(watcher *fsnotify.Watcher, event fsnotify.Event, onChange func(tenant, group, dataId string)) {
+	rel, err := filepath.Rel(o.dir, event.Name)
+	if err != nil {
+		return
+	}
+	onFile := func(parts []string) {
+		switch len(parts) {
+		case 2:
+			onChange("", parts[0], parts[1])
+		case 3:
+			onChange(parts[0], parts[1], parts[2])
+		}
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if event.Op&fsnotify.Create != 0 && len(parts) < 3 {
+		if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+			// a new group dir or tenant dir
+			o.addDir(watcher, event.Name, len(parts) == 2, onFile)
+			return
+		}
+	}
+	if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+		onFile(parts)
+	}
+}
+
+// onLocalOverrideChange notify the listeners of the changed file, the config is read from the server again
+// when the file is removed.
+func (client *ConfigClient) onLocalOverrideChange(tenant, group, dataId string) {
+	for _, v := range client.cacheMap.Items() {
+		data, ok := v.(cacheData)
+		if !ok || data.group != group || data.dataId != dataId || !sameTenant(data.tenant, tenant) {
+			continue
+		}
+		content, exists := client.localOverride.read(data.tenant, group, dataId)
+		if !exists {
+			logger.Infof("[local-override] config removed, dataId=%s, group=%s, tenant=%s", dataId, group, data.tenant)
+			// the md5 of the override is never on the server, it's cleared so the next listen doesn't compare with it
+			data.md5 = ""
+			data.isSyncWithServer = false
+			if client.writeBack(data) {
+				client.refreshContentAndCheck(data, true)
+			}
+			continue
+		}
+		logger.Infof("[local-override] config changed, dataId=%s, group=%s, tenant=%s", dataId, group, data.tenant)
+		data.content = content
+		data.contentType = ""
+		data.encryptedDataKey = ""
+		data.md5 = util.Md5(content)
+		if data.md5 != data.cacheDataListener.getLastMd5() {
+			data.executeListener()
+		} else {
+			client.writeBack(data)
+		}
+	}
+}
+
+func (client *ConfigClient) readLocalOverride(tenant, group, dataId string) (string, bool) {
+	if client.localOverride == nil {
+		return "", false
+	}
+	return client.localOverride.read(tenant, group, dataId)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+// isStaleContent check the queried content of a config which the server reported as changed, the content is
+// stale when it doesn't match the md5 returned by the server. The content same as the cached one is accepted,
+// the config may be changed and changed back, or the notify is redundant.
+func (client *ConfigClient) isStaleContent(data cacheData, response *rpc_response.ConfigQueryResponse) bool {
+	return len(response.Md5) > 0 && response.Md5 != util.Md5(response.Content)
+}
+
+func (client *ConfigClient) md5RetryPolicy() (int, time.Duration) {
+	clientConfig, _ := client.GetClientConfig()
+	retryTimes := clientConfig.Md5RetryTimes
+	if retryTimes == 0 {
+		retryTimes = constant.DEFAULT_MD5_RETRY_TIMES
+	}
+	interval := clientConfig.Md5RetryIntervalMs
+	if interval == 0 {
+		interval = constant.DEFAULT_MD5_RETRY_INTERVAL_MILLS
+	}
+	return retryTimes, time.Duration(interval) * time.Millisecond
+}
+
+// scheduleMd5Retry query the config again with an exponential backoff, it returns false when the retry is
+// disabled or the retry times is used up, then the caller should accept the content as it is.
+func (client *ConfigClient) scheduleMd5Retry(data cacheData, notify bool) bool {
+	cacheKey := util.GetConfigCacheKey(data.dataId, data.group, data.tenant)
+	retryTimes, interval := client.md5RetryPolicy()
+
+	client.md5RetryMutex.Lock()
+	attempt := client.md5RetryTasks[cacheKey]
+	if attempt >= retryTimes {
+		delete(client.md5RetryTasks, cacheKey)
+		client.md5RetryMutex.Unlock()
+		if retryTimes > 0 {
+			logger.Errorf("config content is still stale after %d retries, dataId=%s, group=%s, tenant=%s", attempt,
+				data.dataId, data.group, data.tenant)
+		}
+		return false
+	}
+	client.md5RetryTasks[cacheKey] = attempt + 1
+	client.md5RetryMutex.Unlock()
+
+	delay := interval << uint(attempt)
+	if delay <= 0 || delay > constant.MAX_MD5_RETRY_INTERVAL {
+		delay = constant.MAX_MD5_RETRY_INTERVAL
+	}
+	logger.Warnf("config content is stale, retry %d after %s, dataId=%s, group=%s, tenant=%s", attempt+1, delay,
+		data.dataId, data.group, data.tenant)
+	time.AfterFunc(delay, func() {
+		select {
+		case <-client.ctx.Done():
+			return
+		default:
+		}
+		value, ok := client.cacheMap.Get(cacheKey)
+		if !ok {
+			client.finishMd5Retry(cacheKey)
+			return
+		}
+		client.refreshContentAndCheck(value.(cacheData), notify)
+	})
+	return true
+}
+
+func (client *ConfigClient) isMd5Retrying(cacheKey string) bool {
+	client.md5RetryMutex.Lock()
+	defer client.md5RetryMutex.Unlock()
+	_, ok := client.md5RetryTasks[cacheKey]
+	return ok
+}
+
+func (client *ConfigClient) finishMd5Retry(cacheKey string) {
+	client.md5RetryMutex.Lock()
+	defer client.md5RetryMutex.Unlock()
+	delete(client.md5RetryTasks, cacheKey)
+}
@@ -17,10 +17,14 @@
 package config_client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,6 +34,7 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
 
 	"github.com/jun3372/nacos-sdk-go/clients/cache"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
@@ -39,11 +44,31 @@ import (
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
 	"github.com/jun3372/nacos-sdk-go/vo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ConfigProxy struct {
 	nacosServer  *nacos_server.NacosServer
 	clientConfig constant.ClientConfig
+	limiter      *requestLimiter
+	requestHooks configRequestHooks
+	rpcClients   sync.Map // the rpc clients created by createRpcClient, keyed by the client name
+	legacy       int32    // 1 when the requests are sent by http to the 1.x servers
+	// lastLegacyDetect is the unix nanoseconds when the server version is detected
+	lastLegacyDetect int64
+	// extensions are installed on the rpc clients, including the ones created later
+	extensions struct {
+		sync.Mutex
+		interceptors []rpc.Interceptor
+		handlers     []serverRequestHandler
+		watchers     []*func(event model.ConnectionEvent)
+	}
+}
+
+type serverRequestHandler struct {
+	request func() rpc_request.IRequest
+	handler rpc.IServerRequestHandler
 }
 
 func NewConfigProxy(ctx context.Context, serverConfig []constant.ServerConfig, clientConfig constant.ClientConfig, httpAgent http_agent.IHttpAgent) (IConfigProxy, error) {
@@ -51,21 +76,120 @@ func NewConfigProxy(ctx context.Context, serverConfig []constant.ServerConfig, c
 	var err error
 	proxy.nacosServer, err = nacos_server.NewNacosServer(ctx, serverConfig, clientConfig, httpAgent, clientConfig.TimeoutMs, clientConfig.Endpoint, nil)
 	proxy.clientConfig = clientConfig
+	proxy.limiter = newRequestLimiter(clientConfig.RequestQps)
+	if proxy.compatMode() == constant.CONFIG_COMPAT_MODE_HTTP {
+		logger.Infof("config requests are sent by http")
+		proxy.legacy = 1
+	}
 	return &proxy, err
 }
 
 func (cp *ConfigProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	deadline := time.Now().Add(time.Duration(timeoutMills) * time.Millisecond)
+	finish := cp.requestHooks.begin(request)
+	if !cp.limiter.allow(request.GetRequestType()) {
+		logger.Warnf("config request is rate limited, type:%s", request.GetRequestType())
+		finish(nil, ErrRateLimited)
+		return nil, ErrRateLimited
+	}
+	if cp.isLegacy() {
+		response, err := cp.requestByHttp(request, timeoutMills)
+		finish(response, err)
+		return response, err
+	}
+	token := cp.nacosServer.AccessToken()
+	response, err := cp.requestByRpc(rpcClient, request, timeoutMills)
+	if err == nil && response != nil && !response.IsSuccess() && cp.nacosServer.ReAuth(token, response.GetErrorCode(), response.GetMessage()) {
+		if remaining := remainingMs(deadline); remaining > 0 {
+			logger.Infof("replay the config request after re-login, type:%s", request.GetRequestType())
+			response, err = cp.requestByRpc(rpcClient, request, remaining)
+		}
+	}
+	if err != nil && cp.fallbackToLegacy(rpcClient, err, remainingMs(deadline)) {
+		if remaining := remainingMs(deadline); remaining > 0 {
+			response, err = cp.requestByHttp(request, remaining)
+		}
+	}
+	finish(response, err)
+	return response, err
+}
+
+// remainingMs return the milliseconds left before the deadline, 0 if it has passed.
+func remainingMs(deadline time.Time) uint64 {
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return 0
+	}
+	return uint64(remaining)
+}
+
+func (cp *ConfigProxy) requestByRpc(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
 	start := time.Now()
 	cp.nacosServer.InjectSecurityInfo(request.GetHeaders())
 	cp.injectCommHeader(request.GetHeaders())
-	cp.nacosServer.InjectSkAk(request.GetHeaders(), cp.clientConfig)
-	signHeaders := nacos_server.GetSignHeadersFromRequest(request.(rpc_request.IConfigRequest), cp.clientConfig.SecretKey)
+	credentials := cp.nacosServer.Credentials()
+	cp.nacosServer.InjectSkAkWithCredentials(request.GetHeaders(), credentials)
+	signHeaders := nacos_server.GetSignHeadersFromRequest(request.(rpc_request.IConfigRequest), credentials.SecretKey)
 	request.PutAllHeaders(signHeaders)
 	response, err := rpcClient.Request(request, int64(timeoutMills))
 	monitor.GetConfigRequestMonitor(constant.GRPC, request.GetRequestType(), rpc_response.GetGrpcResponseStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
 	return response, err
 }
 
+func (cp *ConfigProxy) addRequestHook(hook ConfigRequestHook) {
+	cp.requestHooks.add(hook)
+}
+
+// addInterceptor install the interceptor on the rpc clients created and the ones to be created.
+func (cp *ConfigProxy) addInterceptor(interceptor rpc.Interceptor) {
+	cp.extensions.Lock()
+	defer cp.extensions.Unlock()
+	cp.extensions.interceptors = append(cp.extensions.interceptors, interceptor)
+	cp.rpcClients.Range(func(_, value interface{}) bool {
+		value.(*rpc.RpcClient).AddInterceptor(interceptor)
+		return true
+	})
+}
+
+// registerServerRequestHandler register the handler on the rpc clients created and the ones to be created.
+func (cp *ConfigProxy) registerServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) {
+	cp.extensions.Lock()
+	defer cp.extensions.Unlock()
+	cp.extensions.handlers = append(cp.extensions.handlers, serverRequestHandler{request: request, handler: handler})
+	cp.rpcClients.Range(func(_, value interface{}) bool {
+		value.(*rpc.RpcClient).RegisterServerRequestHandler(request, handler)
+		return true
+	})
+}
+
+// watchConnection add the callback on the rpc clients created and the ones to be created.
+func (cp *ConfigProxy) watchConnection(callback *func(event model.ConnectionEvent)) {
+	cp.extensions.Lock()
+	defer cp.extensions.Unlock()
+	cp.extensions.watchers = append(cp.extensions.watchers, callback)
+	cp.rpcClients.Range(func(_, value interface{}) bool {
+		value.(*rpc.RpcClient).WatchConnectionEvents(callback)
+		return true
+	})
+}
+
+// unwatchConnection remove the callback added by watchConnection from all the rpc clients.
+func (cp *ConfigProxy) unwatchConnection(callback *func(event model.ConnectionEvent)) {
+	cp.extensions.Lock()
+	defer cp.extensions.Unlock()
+	var watchers []*func(event model.ConnectionEvent)
+	for _, watcher := range cp.extensions.watchers {
+		if watcher != callback {
+			watchers = append(watchers, watcher)
+		}
+	}
+	cp.extensions.watchers = watchers
+	cp.rpcClients.Range(func(_, value interface{}) bool {
+		value.(*rpc.RpcClient).UnwatchConnectionEvents(callback)
+		return true
+	})
+}
+
 func (cp *ConfigProxy) injectCommHeader(param map[string]string) {
 	now := strconv.FormatInt(util.CurrentMillis(), 10)
 	param[constant.CLIENT_APPNAME_HEADER] = cp.clientConfig.AppName
@@ -75,7 +199,42 @@ func (cp *ConfigProxy) injectCommHeader(param map[string]string) {
 	param[constant.CHARSET_KEY] = "utf-8"
 }
 
-func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+// searchConfigProxy search the configs over grpc, and fall back to the http api when the server doesn't
+// support the search request, the unsupported request isn't retried by the rpc client so it falls back at once.
+func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant string, client *ConfigClient) (*model.ConfigPage, error) {
+	request := rpc_request.NewConfigSearchRequest(param.Group, param.DataId, tenant)
+	request.Search = param.Search
+	request.Tag = param.Tag
+	request.AppName = param.AppName
+	request.PageNo = param.PageNo
+	request.PageSize = param.PageSize
+	iResponse, err := cp.requestProxy(cp.getRpcClient(client), request, cp.clientConfig.TimeoutMs)
+	if err == nil {
+		response, ok := iResponse.(*rpc_response.ConfigSearchResponse)
+		if !ok {
+			return nil, errors.New("ConfigSearchRequest returns type error")
+		}
+		if response.IsSuccess() {
+			return &model.ConfigPage{
+				TotalCount:     response.TotalCount,
+				PageNumber:     response.PageNumber,
+				PagesAvailable: response.PagesAvailable,
+				PageItems:      response.PageItems,
+			}, nil
+		}
+		if response.GetErrorCode() != constant.NO_HANDLER {
+			return nil, errors.Errorf("search config failed, code:%d, message:%s", response.GetErrorCode(), response.GetMessage())
+		}
+		err = errors.Wrap(rpc.ErrRequestNotSupported, response.GetMessage())
+	}
+	if err == ErrRateLimited {
+		return nil, err
+	}
+	logger.Warnf("search config over grpc failed, fall back to http, err:%v", err)
+	return cp.searchConfigByHttp(param, tenant)
+}
+
+func (cp *ConfigProxy) searchConfigByHttp(param vo.SearchConfigParam, tenant string) (*model.ConfigPage, error) {
 	params := util.TransformObject2Param(param)
 	if len(tenant) > 0 {
 		params["tenant"] = tenant
@@ -86,10 +245,7 @@ func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, acc
 	if _, ok := params["dataId"]; !ok {
 		params["dataId"] = ""
 	}
-	var headers = map[string]string{}
-	headers["accessKey"] = accessKey
-	headers["secretKey"] = secretKey
-	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, headers, http.MethodGet, cp.clientConfig.TimeoutMs)
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +257,248 @@ func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, acc
 	return &configPage, nil
 }
 
+// restResult is the common body of the v1 console apis.
+type restResult struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (cp *ConfigProxy) buildConfigParams(dataId, group, tenant string) map[string]string {
+	params := map[string]string{
+		"dataId": dataId,
+		"group":  group,
+	}
+	if len(tenant) > 0 {
+		params["tenant"] = tenant
+	}
+	return params
+}
+
+func (cp *ConfigProxy) buildAkSkHeaders() map[string]string {
+	credentials := cp.nacosServer.Credentials()
+	return map[string]string{
+		constant.KEY_ACCESS_KEY:     credentials.AccessKey,
+		constant.KEY_SECRET_KEY:     credentials.SecretKey,
+		constant.KEY_SECURITY_TOKEN: credentials.SecurityToken,
+	}
+}
+
+func (cp *ConfigProxy) queryConfigBetaProxy(dataId, group, tenant string) (*model.ConfigBetaItem, error) {
+	params := cp.buildConfigParams(dataId, group, tenant)
+	params["beta"] = "true"
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return nil, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return nil, errors.Errorf("query config beta failed, code:%d, message:%s", restResult.Code, restResult.Message)
+	}
+	if len(restResult.Data) == 0 || string(restResult.Data) == "null" {
+		return nil, nil
+	}
+	var betaItem model.ConfigBetaItem
+	if err = json.Unmarshal(restResult.Data, &betaItem); err != nil {
+		return nil, err
+	}
+	return &betaItem, nil
+}
+
+func (cp *ConfigProxy) stopConfigBetaProxy(dataId, group, tenant string) (bool, error) {
+	params := cp.buildConfigParams(dataId, group, tenant)
+	params["beta"] = "true"
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodDelete, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return false, err
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return false, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return false, errors.Errorf("stop config beta failed, code:%d, message:%s", restResult.Code, restResult.Message)
+	}
+	return true, nil
+}
+
+func (cp *ConfigProxy) queryConfigAdvanceInfoProxy(dataId, group, tenant string) (*model.ConfigAdvanceInfo, error) {
+	params := cp.buildConfigParams(dataId, group, tenant)
+	params["show"] = "all"
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	var advanceInfo model.ConfigAdvanceInfo
+	if err = json.Unmarshal([]byte(result), &advanceInfo); err != nil {
+		return nil, err
+	}
+	return &advanceInfo, nil
+}
+
+func (cp *ConfigProxy) configHistoryProxy(param vo.ConfigHistoryParam, tenant string) (*model.ConfigHistoryPage, error) {
+	params := cp.buildConfigParams(param.DataId, param.Group, tenant)
+	params["search"] = "accurate"
+	params["pageNo"] = strconv.Itoa(param.PageNo)
+	params["pageSize"] = strconv.Itoa(param.PageSize)
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_HISTORY_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	var historyPage model.ConfigHistoryPage
+	if err = json.Unmarshal([]byte(result), &historyPage); err != nil {
+		return nil, err
+	}
+	return &historyPage, nil
+}
+
+func (cp *ConfigProxy) configHistoryDetailProxy(param vo.ConfigHistoryDetailParam, tenant string) (*model.ConfigHistoryItem, error) {
+	params := cp.buildConfigParams(param.DataId, param.Group, tenant)
+	params["nid"] = strconv.FormatInt(param.Nid, 10)
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_HISTORY_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	var historyItem model.ConfigHistoryItem
+	if err = json.Unmarshal([]byte(result), &historyItem); err != nil {
+		return nil, err
+	}
+	return &historyItem, nil
+}
+
+func (cp *ConfigProxy) exportConfigProxy(param vo.ExportConfigParam, tenant string) ([]byte, error) {
+	params := cp.buildConfigParams(param.DataId, param.Group, tenant)
+	params["exportV2"] = "true"
+	params["appName"] = param.AppName
+	result, err := cp.nacosServer.ReqConfigApi(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(result), nil
+}
+
+func (cp *ConfigProxy) importConfigProxy(zipData []byte, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "nacos_config_import.zip")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(zipData); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"import":    "true",
+		"namespace": tenant,
+		"policy":    string(policy),
+	}
+	result, err := cp.nacosServer.ReqConfigApiWithBody(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodPost,
+		cp.clientConfig.TimeoutMs, body.Bytes(), writer.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return nil, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return nil, errors.Errorf("import config failed, code:%d, message:%s", restResult.Code, restResult.Message)
+	}
+	var importResult model.ConfigImportResult
+	if len(restResult.Data) > 0 && string(restResult.Data) != "null" {
+		if err = json.Unmarshal(restResult.Data, &importResult); err != nil {
+			return nil, err
+		}
+	}
+	return &importResult, nil
+}
+
+// cloneConfigBean is the body item of the clone api, CfgId is the id of the source config.
+type cloneConfigBean struct {
+	CfgId  json.Number `json:"cfgId"`
+	DataId string      `json:"dataId"`
+	Group  string      `json:"group"`
+}
+
+func (cp *ConfigProxy) cloneConfigProxy(beans []cloneConfigBean, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error) {
+	body, err := json.Marshal(beans)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"clone":    "true",
+		"tenant":   tenant,
+		"policy":   string(policy),
+		"src_user": cp.clientConfig.AppName,
+	}
+	result, err := cp.nacosServer.ReqConfigApiWithBody(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodPost,
+		cp.clientConfig.TimeoutMs, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return nil, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return nil, errors.Errorf("clone config failed, code:%d, message:%s", restResult.Code, restResult.Message)
+	}
+	var cloneResult model.ConfigImportResult
+	if len(restResult.Data) > 0 && string(restResult.Data) != "null" {
+		if err = json.Unmarshal(restResult.Data, &cloneResult); err != nil {
+			return nil, err
+		}
+	}
+	return &cloneResult, nil
+}
+
+func (cp *ConfigProxy) getNamespacesProxy() ([]model.Namespace, error) {
+	result, err := cp.nacosServer.ReqConfigApi(constant.NAMESPACE_PATH, map[string]string{}, cp.buildAkSkHeaders(),
+		http.MethodGet, cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return nil, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return nil, errors.Errorf("get namespaces failed, code:%d, message:%s", restResult.Code, restResult.Message)
+	}
+	var namespaces []model.Namespace
+	if err = json.Unmarshal(restResult.Data, &namespaces); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+// modifyNamespaceProxy create, update or delete a namespace, the console api returns a bare boolean.
+func (cp *ConfigProxy) modifyNamespaceProxy(method string, params map[string]string) (bool, error) {
+	result, err := cp.nacosServer.ReqConfigApi(constant.NAMESPACE_PATH, params, cp.buildAkSkHeaders(), method,
+		cp.clientConfig.TimeoutMs)
+	if err != nil {
+		return false, err
+	}
+	result = strings.TrimSpace(result)
+	if result == "true" || result == "false" {
+		return result == "true", nil
+	}
+	var restResult restResult
+	if err = json.Unmarshal([]byte(result), &restResult); err != nil {
+		return false, err
+	}
+	if restResult.Code != constant.RESPONSE_CODE_SUCCESS {
+		return false, errors.Errorf("%s namespace failed, code:%d, message:%s", method, restResult.Code, restResult.Message)
+	}
+	return string(restResult.Data) == "true", nil
+}
+
 func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
 	if group == "" {
 		group = constant.DEFAULT_GROUP
@@ -113,7 +511,15 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 		// return error when check limited
 		return nil, errors.New("ConfigQueryRequest is limited")
 	}
+	start := time.Now()
 	iResponse, err := cp.requestProxy(cp.getRpcClient(client), configQueryRequest, timeout)
+	if status.Code(err) == codes.ResourceExhausted {
+		// the server has no chunked query api, the http api is not limited by the grpc message size.
+		logger.Warnf("the config is too large for grpc, query it over http, dataId=%s, group=%s, tenant=%s, err:%v",
+			dataId, group, tenant, err)
+		iResponse, err = cp.queryConfigByHttp(dataId, group, tenant, timeout)
+	}
+	monitor.GetConfigMetrics().ObserveQuery(dataId, group, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +556,27 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 	return response, nil
 }
 
+func (cp *ConfigProxy) queryConfigByHttp(dataId, group, tenant string, timeout uint64) (*rpc_response.ConfigQueryResponse, error) {
+	params := cp.buildConfigParams(dataId, group, tenant)
+	result, header, err := cp.nacosServer.ReqConfigApiWithHeader(constant.CONFIG_PATH, params, cp.buildAkSkHeaders(), http.MethodGet, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return buildHttpQueryResponse(result, header), nil
+}
+
+// buildHttpQueryResponse convert the result of the http config api to the rpc response, the type and the data
+// key of the config are returned in the headers.
+func buildHttpQueryResponse(result string, header http.Header) *rpc_response.ConfigQueryResponse {
+	return &rpc_response.ConfigQueryResponse{
+		Response:         &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true},
+		Content:          result,
+		Md5:              util.Md5(result),
+		ContentType:      header.Get(constant.CONFIG_TYPE_HEADER),
+		EncryptedDataKey: header.Get(constant.ENCRYPTED_DATA_KEY_HEADER),
+	}
+}
+
 func appName(client *ConfigClient) string {
 	if clientConfig, err := client.GetClientConfig(); err == nil {
 		appName := clientConfig.AppName
@@ -159,22 +586,51 @@ func appName(client *ConfigClient) string {
 }
 
 func (cp *ConfigProxy) createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
-	labels := map[string]string{
-		constant.LABEL_SOURCE:   constant.LABEL_SOURCE_SDK,
-		constant.LABEL_MODULE:   constant.LABEL_MODULE_CONFIG,
-		constant.APPNAME_HEADER: appName(client),
-		"taskId":                taskId,
-	}
+	labels := rpc.NewConnectionLabels(cp.clientConfig, constant.LABEL_MODULE_CONFIG)
+	labels[constant.APPNAME_HEADER] = appName(client)
+	labels[constant.LABEL_TASK_ID] = taskId
 
-	iRpcClient, _ := rpc.CreateClient(ctx, "config-"+taskId+"-"+client.uid, rpc.GRPC, labels, cp.nacosServer)
+	clientName := "config-" + taskId + "-" + client.uid
+	iRpcClient, _ := rpc.CreateClient(ctx, clientName, rpc.GRPC, labels, cp.nacosServer)
 	rpcClient := iRpcClient.GetRpcClient()
 	if rpcClient.IsInitialized() {
 		rpcClient.RegisterServerRequestHandler(func() rpc_request.IRequest {
 			// TODO fix the group/dataId empty problem
 			return rpc_request.NewConfigChangeNotifyRequest("", "", "")
 		}, &ConfigChangeNotifyRequestHandler{client: client})
+		rpcClient.RegisterServerRequestHandler(func() rpc_request.IRequest {
+			return rpc_request.NewConfigFuzzyWatchChangeNotifyRequest()
+		}, &ConfigFuzzyWatchChangeNotifyRequestHandler{client: client})
+		rpcClient.RegisterServerRequestHandler(func() rpc_request.IRequest {
+			return rpc_request.NewConfigFuzzyWatchSyncRequest()
+		}, &ConfigFuzzyWatchSyncRequestHandler{client: client})
 		rpcClient.Tenant = cp.clientConfig.NamespaceId
-		rpcClient.Start()
+		rpcClient.CompressThreshold = rpc.GetCompressThreshold(cp.clientConfig)
+		rpcClient.MaxCallRecvMsgSize = cp.clientConfig.GrpcMaxCallRecvMsgSize
+		rpcClient.MaxCallSendMsgSize = cp.clientConfig.GrpcMaxCallSendMsgSize
+		rpcClient.TLSConfig = cp.clientConfig.TLSCfg
+		rpcClient.Dialer = cp.clientConfig.GrpcDialer
+		rpcClient.ProxyURL = cp.clientConfig.ProxyURL
+		rpcClient.WireLog = cp.clientConfig.WireLog
+		rpcClient.DialOptions = cp.clientConfig.GrpcDialOptions
+		rpcClient.ConnectionParams = rpc.NewConnectionParams(cp.clientConfig)
+		rpcClient.RetryPolicy = retry.NewPolicy(cp.clientConfig)
+		cp.extensions.Lock()
+		for _, interceptor := range cp.extensions.interceptors {
+			rpcClient.AddInterceptor(interceptor)
+		}
+		for _, h := range cp.extensions.handlers {
+			rpcClient.RegisterServerRequestHandler(h.request, h.handler)
+		}
+		for _, watcher := range cp.extensions.watchers {
+			rpcClient.WatchConnectionEvents(watcher)
+		}
+		cp.rpcClients.Store(clientName, rpcClient)
+		cp.extensions.Unlock()
+		// the client isn't connected to the 1.x servers, it keeps the handlers and the watchers only
+		if !cp.isLegacy() {
+			rpcClient.Start()
+		}
 	}
 	return rpcClient
 }
@@ -183,6 +639,22 @@ func (cp *ConfigProxy) getRpcClient(client *ConfigClient) *rpc.RpcClient {
 	return cp.createRpcClient(client.ctx, "0", client)
 }
 
+// closeRpcClients shutdown all the rpc clients of the proxy, the pending requests are waited until ctx is done.
+func (cp *ConfigProxy) closeRpcClients(ctx context.Context) error {
+	var errs []string
+	cp.rpcClients.Range(func(key, value interface{}) bool {
+		cp.rpcClients.Delete(key)
+		if err := value.(*rpc.RpcClient).Shutdown(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+		return true
+	})
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 type ConfigChangeNotifyRequestHandler struct {
 	client *ConfigClient
 }
@@ -198,6 +670,8 @@ func (c *ConfigChangeNotifyRequestHandler) RequestReply(request rpc_request.IReq
 	}
 	logger.Infof("%s [server-push] config changed. dataId=%s, group=%s,tenant=%s", rpcClient.Name(),
 		configChangeNotifyRequest.DataId, configChangeNotifyRequest.Group, configChangeNotifyRequest.Tenant)
+	GetConfigEventBus().Publish(model.ConfigEvent{Type: ConfigEventChanged, DataId: configChangeNotifyRequest.DataId,
+		Group: configChangeNotifyRequest.Group, Tenant: configChangeNotifyRequest.Tenant})
 
 	cacheKey := util.GetConfigCacheKey(configChangeNotifyRequest.DataId, configChangeNotifyRequest.Group,
 		configChangeNotifyRequest.Tenant)
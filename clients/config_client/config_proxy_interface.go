@@ -12,8 +12,25 @@ import (
 
 type IConfigProxy interface {
 	queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error)
-	searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error)
+	searchConfigProxy(param vo.SearchConfigParam, tenant string, client *ConfigClient) (*model.ConfigPage, error)
 	requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error)
+	queryConfigBetaProxy(dataId, group, tenant string) (*model.ConfigBetaItem, error)
+	stopConfigBetaProxy(dataId, group, tenant string) (bool, error)
+	queryConfigAdvanceInfoProxy(dataId, group, tenant string) (*model.ConfigAdvanceInfo, error)
+	configHistoryProxy(param vo.ConfigHistoryParam, tenant string) (*model.ConfigHistoryPage, error)
+	configHistoryDetailProxy(param vo.ConfigHistoryDetailParam, tenant string) (*model.ConfigHistoryItem, error)
+	exportConfigProxy(param vo.ExportConfigParam, tenant string) ([]byte, error)
+	importConfigProxy(zipData []byte, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error)
+	cloneConfigProxy(beans []cloneConfigBean, policy vo.ImportPolicy, tenant string) (*model.ConfigImportResult, error)
+	getNamespacesProxy() ([]model.Namespace, error)
+	modifyNamespaceProxy(method string, params map[string]string) (bool, error)
 	createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient
 	getRpcClient(client *ConfigClient) *rpc.RpcClient
+	closeRpcClients(ctx context.Context) error
+	activeProtocol() string
+	addRequestHook(hook ConfigRequestHook)
+	addInterceptor(interceptor rpc.Interceptor)
+	registerServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler)
+	watchConnection(callback *func(event model.ConnectionEvent))
+	unwatchConnection(callback *func(event model.ConnectionEvent))
 }
@@ -0,0 +1,86 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/inner/uuid"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+func (client *ConfigClient) publishRetryPolicy() (int, time.Duration) {
+	clientConfig, _ := client.GetClientConfig()
+	backoff := clientConfig.PublishBackoffMs
+	if backoff == 0 {
+		backoff = constant.DEFAULT_PUBLISH_BACKOFF_MILLS
+	}
+	return clientConfig.PublishRetryCount, time.Duration(backoff) * time.Millisecond
+}
+
+// publishWithRetry send the publish request again on rpc errors, all the attempts share one request id as the
+// idempotency key. Before each retry it checks whether the failed attempt has taken effect on the server, so
+// the same content is not published twice and the listeners are not notified again.
+func (client *ConfigClient) publishWithRetry(rpcClient *rpc.RpcClient, request *rpc_request.ConfigPublishRequest) (rpc_response.IResponse, error) {
+	retryCount, backoff := client.publishRetryPolicy()
+	if retryCount > 0 && len(request.RequestId) <= 0 {
+		if uid, err := uuid.NewV4(); err == nil {
+			request.RequestId = uid.String()
+		}
+	}
+	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
+	for attempt := 0; err != nil && err != ErrRateLimited && attempt < retryCount; attempt++ {
+		delay := backoff << uint(attempt)
+		if delay <= 0 || delay > constant.MAX_PUBLISH_BACKOFF {
+			delay = constant.MAX_PUBLISH_BACKOFF
+		}
+		logger.Warnf("publish config failed, retry %d after %s, dataId=%s, group=%s, requestId=%s, err:%v", attempt+1,
+			delay, request.DataId, request.Group, request.RequestId, err)
+		select {
+		case <-time.After(delay):
+		case <-client.ctx.Done():
+			return nil, err
+		}
+		if client.isPublished(request) {
+			logger.Infof("the failed publish has taken effect, dataId=%s, group=%s, requestId=%s", request.DataId,
+				request.Group, request.RequestId)
+			return &rpc_response.ConfigPublishResponse{Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS,
+				Success: true}}, nil
+		}
+		response, err = client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
+	}
+	return response, err
+}
+
+// isPublished report whether the formal content on the server is the content of the request, the beta and tag
+// publishes are not checked since their content can't be read back as the formal one.
+func (client *ConfigClient) isPublished(request *rpc_request.ConfigPublishRequest) bool {
+	if len(request.AdditionMap["betaIps"]) > 0 || len(request.AdditionMap["tag"]) > 0 {
+		return false
+	}
+	response, err := client.configProxy.queryConfig(request.DataId, request.Group, request.Tenant,
+		constant.DEFAULT_TIMEOUT_MILLS, false, client)
+	if err != nil || response == nil || (response.Response != nil && !response.IsSuccess()) {
+		return false
+	}
+	return util.Md5(response.Content) == util.Md5(request.Content)
+}
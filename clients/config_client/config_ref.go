@@ -0,0 +1,107 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// ConfigRef is a live handle of a config returned by Watch.
+type ConfigRef struct {
+	client  *ConfigClient
+	param   vo.ConfigParam
+	value   atomic.Value
+	mutex   sync.Mutex
+	changes chan string
+	stopped bool
+	id      uint64
+}
+
+// Get return the latest content of the config without locking.
+func (ref *ConfigRef) Get() string {
+	return ref.value.Load().(string)
+}
+
+// Changes return the channel of the changed contents, it only keeps the latest change which is not received
+// yet, and it is closed by Stop.
+func (ref *ConfigRef) Changes() <-chan string {
+	return ref.changes
+}
+
+// Stop cancel the listening of the config and close the changes channel.
+func (ref *ConfigRef) Stop() error {
+	ref.mutex.Lock()
+	defer ref.mutex.Unlock()
+	if ref.stopped {
+		return nil
+	}
+	ref.stopped = true
+	close(ref.changes)
+	return ref.client.cancelListener(ref.param, ref.id)
+}
+
+func (ref *ConfigRef) update(content string) {
+	ref.mutex.Lock()
+	defer ref.mutex.Unlock()
+	if ref.stopped || ref.Get() == content {
+		return
+	}
+	ref.value.Store(content)
+	for {
+		select {
+		case ref.changes <- content:
+			return
+		default:
+		}
+		// drop the stale change to make room for the latest one.
+		select {
+		case <-ref.changes:
+		default:
+		}
+	}
+}
+
+// Watch get the config and keep it up to date, it replaces the boilerplate of GetConfig, ListenConfig and
+// caching the content, param.OnChange and param.OnChangeV2 are ignored.
+func (client *ConfigClient) Watch(param vo.ConfigParam) (*ConfigRef, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.Watch] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	content, err := client.GetConfig(param)
+	if err != nil {
+		return nil, err
+	}
+	ref := &ConfigRef{client: client, param: param, changes: make(chan string, 1), id: atomic.AddUint64(&listenerSeq, 1)}
+	ref.value.Store(content)
+	param.OnChangeV2 = nil
+	param.OnChange = func(namespace, group, dataId, data string) {
+		ref.update(data)
+	}
+	if err = client.listenConfig(param, ref.id); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
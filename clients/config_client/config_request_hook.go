@@ -0,0 +1,115 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+)
+
+// ConfigRequestInfo describe a grpc request sent by the config client, the dataId, group and tenant are
+// empty for the requests of multiple configs such as ConfigBatchListenRequest.
+type ConfigRequestInfo struct {
+	RequestType string
+	DataId      string
+	Group       string
+	Tenant      string
+}
+
+// ConfigRequestHook is called around every grpc request of the config client, e.g. to record tracing spans.
+// The context returned by OnRequest is passed to OnResponse of the same hook.
+type ConfigRequestHook interface {
+	OnRequest(ctx context.Context, info ConfigRequestInfo) context.Context
+	OnResponse(ctx context.Context, info ConfigRequestInfo, latency time.Duration, err error)
+}
+
+type configRequestHooks struct {
+	mutex sync.RWMutex
+	hooks []ConfigRequestHook
+}
+
+func (h *configRequestHooks) add(hook ConfigRequestHook) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// begin call OnRequest of the hooks and return the function to call OnResponse when the request finishes.
+func (h *configRequestHooks) begin(request rpc_request.IRequest) func(response rpc_response.IResponse, err error) {
+	h.mutex.RLock()
+	hooks := h.hooks
+	h.mutex.RUnlock()
+	if len(hooks) <= 0 {
+		return func(rpc_response.IResponse, error) {}
+	}
+	info := ConfigRequestInfo{RequestType: request.GetRequestType()}
+	if configRequest, ok := request.(rpc_request.IConfigRequest); ok {
+		info.DataId, info.Group, info.Tenant = configRequest.GetDataId(), configRequest.GetGroup(), configRequest.GetTenant()
+	}
+	contexts := make([]context.Context, len(hooks))
+	for i, hook := range hooks {
+		contexts[i] = hook.OnRequest(context.Background(), info)
+	}
+	start := time.Now()
+	return func(response rpc_response.IResponse, err error) {
+		latency := time.Since(start)
+		if err == nil && response != nil && !response.IsSuccess() {
+			err = errors.Errorf("code:%d, message:%s", response.GetErrorCode(), response.GetMessage())
+		}
+		for i, hook := range hooks {
+			hook.OnResponse(contexts[i], info, latency, err)
+		}
+	}
+}
+
+// AddRequestHook register a hook called around every grpc request of the config client.
+func (client *ConfigClient) AddRequestHook(hook ConfigRequestHook) error {
+	if hook == nil {
+		return errors.New("[client.AddRequestHook] hook can not be nil")
+	}
+	client.configProxy.addRequestHook(hook)
+	return nil
+}
+
+// AddInterceptor install an interceptor called around every grpc request of the config client.
+func (client *ConfigClient) AddInterceptor(interceptor rpc.Interceptor) error {
+	if interceptor == nil {
+		return errors.New("[client.AddInterceptor] interceptor can not be nil")
+	}
+	client.configProxy.addInterceptor(interceptor)
+	return nil
+}
+
+// RegisterServerRequestHandler handle the server push requests of the type returned by request, e.g. the
+// requests of the custom plugins on the server, the built-in types handled by the client are rejected.
+func (client *ConfigClient) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	if request == nil || handler == nil {
+		return errors.New("[client.RegisterServerRequestHandler] request and handler can not be nil")
+	}
+	if requestType := request().GetRequestType(); rpc.IsBuiltInServerRequest(requestType) {
+		return errors.Errorf("[client.RegisterServerRequestHandler] %s is handled by the client", requestType)
+	}
+	client.configProxy.registerServerRequestHandler(request, handler)
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/format"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+const (
+	placeholderPrefix       = "${"
+	placeholderSuffix       = "}"
+	placeholderDefaultSplit = ":"
+)
+
+// ConfigResolver resolve the ${key} and ${key:default} placeholders in config content. A key is looked up in
+// the config itself, then in the sources in order, then in the environment variables, and the default is
+// used when it is found nowhere. The sources are read on every resolve, so a config resolved in a listener
+// sees the latest sources, but a change of the sources doesn't notify the listener.
+type ConfigResolver struct {
+	client  IConfigClient
+	sources []vo.ConfigParam
+}
+
+func NewConfigResolver(client IConfigClient, sources ...vo.ConfigParam) *ConfigResolver {
+	return &ConfigResolver{client: client, sources: sources}
+}
+
+// GetConfig get the config and resolve the placeholders in it.
+func (r *ConfigResolver) GetConfig(param vo.ConfigParam) (string, error) {
+	content, err := r.client.GetConfig(param)
+	if err != nil {
+		return "", err
+	}
+	return r.ResolveConfig(param, content)
+}
+
+// ListenConfig listen the config, param.OnChange receives the resolved content, the changes which can not
+// be resolved are dropped with an error log.
+func (r *ConfigResolver) ListenConfig(param vo.ConfigParam) error {
+	if param.OnChange == nil {
+		return errors.New("[ConfigResolver.ListenConfig] OnChange can not be nil")
+	}
+	onChange, declared := param.OnChange, param
+	param.OnChange = func(namespace, group, dataId, data string) {
+		resolved, err := r.ResolveConfig(declared, data)
+		if err != nil {
+			logger.Errorf("[ConfigResolver] resolve config failed, dataId=%s, group=%s, err:%v", dataId, group, err)
+			return
+		}
+		onChange(namespace, group, dataId, resolved)
+	}
+	return r.client.ListenConfig(param)
+}
+
+// ResolveConfig resolve the placeholders of content, which is the content of the config param.
+func (r *ConfigResolver) ResolveConfig(param vo.ConfigParam, content string) (string, error) {
+	if !strings.Contains(content, placeholderPrefix) {
+		return content, nil
+	}
+	layers := []map[string]string{flattenQuietly(param, content)}
+	for _, source := range r.sources {
+		sourceContent, err := r.client.GetConfig(source)
+		if err != nil {
+			return "", errors.Wrapf(err, "[ConfigResolver] get source config failed, dataId=%s, group=%s", source.DataId, source.Group)
+		}
+		layers = append(layers, flattenQuietly(source, sourceContent))
+	}
+	return resolvePlaceholders(content, func(key string) (string, bool) {
+		for _, layer := range layers {
+			if value, ok := layer[key]; ok {
+				return value, true
+			}
+		}
+		return os.LookupEnv(key)
+	}, nil)
+}
+
+// flattenQuietly return the keys of the config, a text config or a bad one has no keys.
+func flattenQuietly(param vo.ConfigParam, content string) map[string]string {
+	values, err := format.Flatten(format.DetectFormat(param.Type, param.DataId, content), content)
+	if err != nil {
+		return nil
+	}
+	return values
+}
+
+// resolvePlaceholders replace the placeholders of text recursively, refs is the keys being resolved, which
+// is used to find the circular references.
+func resolvePlaceholders(text string, lookup func(key string) (string, bool), refs []string) (string, error) {
+	var builder strings.Builder
+	for {
+		start := strings.Index(text, placeholderPrefix)
+		if start < 0 {
+			builder.WriteString(text)
+			return builder.String(), nil
+		}
+		end := strings.Index(text[start:], placeholderSuffix)
+		if end < 0 {
+			builder.WriteString(text)
+			return builder.String(), nil
+		}
+		end += start
+		builder.WriteString(text[:start])
+
+		key, defaultValue, hasDefault := text[start+len(placeholderPrefix):end], "", false
+		if index := strings.Index(key, placeholderDefaultSplit); index >= 0 {
+			key, defaultValue, hasDefault = key[:index], key[index+1:], true
+		}
+		for _, ref := range refs {
+			if ref == key {
+				return "", errors.Errorf("[ConfigResolver] circular placeholder reference: %s -> %s", strings.Join(refs, " -> "), key)
+			}
+		}
+		value, ok := lookup(key)
+		if !ok {
+			if !hasDefault {
+				return "", errors.Errorf("[ConfigResolver] can not resolve placeholder ${%s}", key)
+			}
+			value = defaultValue
+		}
+		resolved, err := resolvePlaceholders(value, lookup, append(refs[:len(refs):len(refs)], key))
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(resolved)
+		text = text[end+len(placeholderSuffix):]
+	}
+}
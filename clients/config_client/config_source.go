@@ -0,0 +1,193 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/clients/cache"
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	nacos_inner_encryption "github.com/jun3372/nacos-sdk-go/common/encryption"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+	"github.com/pkg/errors"
+)
+
+var defaultConfigSourcePriority = []string{constant.CONFIG_SOURCE_FAILOVER, constant.CONFIG_SOURCE_SERVER,
+	constant.CONFIG_SOURCE_SNAPSHOT}
+
+// configSourcePriority return the sources to get config from in order, the snapshot is skipped when
+// DisableUseSnapShot is set.
+func configSourcePriority(clientConfig constant.ClientConfig) []string {
+	priority := clientConfig.ConfigSourcePriority
+	if len(priority) <= 0 {
+		priority = defaultConfigSourcePriority
+	}
+	sources := make([]string, 0, len(priority))
+	for _, source := range priority {
+		if source == constant.CONFIG_SOURCE_SNAPSHOT && clientConfig.DisableUseSnapShot {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// getConfigFromSources try the sources by priority until one of them has the config, the local override
+// always comes first. A definite failure response of the server stops the chain, e.g. the config doesn't exist.
+func (client *ConfigClient) getConfigFromSources(param vo.ConfigParam) (*model.GetConfigResult, string, error) {
+	if len(param.DataId) <= 0 {
+		return nil, "", errors.New("[client.GetConfig] param.dataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	clientConfig, _ := client.GetClientConfig()
+	namespaceId := resolveNamespaceId(clientConfig, param.NamespaceId)
+	if content, ok := client.readLocalOverride(namespaceId, param.Group, param.DataId); ok {
+		logger.Debugf("%s %s %s is using local override content!", namespaceId, param.Group, param.DataId)
+		return &model.GetConfigResult{Content: content, Source: constant.CONFIG_SOURCE_LOCAL, Md5: util.Md5(content)}, "", nil
+	}
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, namespaceId)
+	var sourceErrors []string
+	for _, source := range configSourcePriority(clientConfig) {
+		var (
+			result           *model.GetConfigResult
+			encryptedDataKey string
+			err              error
+		)
+		switch source {
+		case constant.CONFIG_SOURCE_FAILOVER:
+			result, encryptedDataKey = client.getConfigFromFailover(cacheKey)
+			if result != nil {
+				logger.Warnf("%s %s %s is using failover content!", namespaceId, param.Group, param.DataId)
+				monitor.GetConfigMetrics().IncFailoverRead(param.DataId, param.Group)
+			}
+		case constant.CONFIG_SOURCE_SERVER:
+			if result, encryptedDataKey = client.getFreshSnapshot(param, namespaceId, cacheKey, clientConfig); result != nil {
+				monitor.GetConfigMetrics().IncCacheHit(param.DataId, param.Group)
+				break
+			}
+			var definite bool
+			result, encryptedDataKey, definite, err = client.getConfigFromServer(param, namespaceId, clientConfig.TimeoutMs)
+			if definite {
+				return result, encryptedDataKey, err
+			}
+		case constant.CONFIG_SOURCE_SNAPSHOT:
+			result, encryptedDataKey, err = client.getConfigFromSnapshot(cacheKey, param.DataId)
+			if result != nil {
+				logger.Warnf("read config from cache success, dataId=%s, group=%s, namespaceId=%s", param.DataId,
+					param.Group, namespaceId)
+				monitor.GetConfigMetrics().IncCacheHit(param.DataId, param.Group)
+			}
+		default:
+			err = errors.Errorf("unknown config source:%s", source)
+		}
+		if result != nil {
+			return result, encryptedDataKey, nil
+		}
+		if err != nil {
+			sourceErrors = append(sourceErrors, source+": "+err.Error())
+		}
+	}
+	return nil, "", errors.Errorf("[client.GetConfig] get config from all the sources failed, dataId=%s, group=%s, "+
+		"namespaceId=%s, errors: [%s]", param.DataId, param.Group, namespaceId, strings.Join(sourceErrors, "; "))
+}
+
+func (client *ConfigClient) getConfigFromFailover(cacheKey string) (*model.GetConfigResult, string) {
+	content := cache.GetFailover(cacheKey, client.configCacheDir)
+	if len(content) <= 0 {
+		return nil, ""
+	}
+	result := &model.GetConfigResult{Content: content, Source: constant.CONFIG_SOURCE_FAILOVER, Md5: util.Md5(content),
+		LastModified: fileModTime(cache.GetConfigFailOverContentFileName(cacheKey, client.configCacheDir))}
+	return result, cache.GetFailoverEncryptedDataKey(cacheKey, client.configCacheDir)
+}
+
+// getConfigFromServer return definite as true when the server answers, even if the answer is a failure.
+func (client *ConfigClient) getConfigFromServer(param vo.ConfigParam, namespaceId string, timeoutMs uint64) (
+	result *model.GetConfigResult, encryptedDataKey string, definite bool, err error) {
+	response, err := client.configProxy.queryConfig(param.DataId, param.Group, namespaceId, timeoutMs, false, client)
+	if err != nil {
+		logger.Errorf("get config from server error:%v, dataId=%s, group=%s, namespaceId=%s", err,
+			param.DataId, param.Group, namespaceId)
+		return nil, "", false, err
+	}
+	if response != nil && response.Response != nil && !response.IsSuccess() {
+		return nil, "", true, errors.New(response.GetMessage())
+	}
+	result = &model.GetConfigResult{Content: response.Content, Source: constant.CONFIG_SOURCE_SERVER, Md5: response.Md5}
+	if len(result.Md5) <= 0 {
+		result.Md5 = util.Md5(response.Content)
+	}
+	if response.LastModified > 0 {
+		result.LastModified = time.UnixMilli(response.LastModified)
+	}
+	return result, response.EncryptedDataKey, true, nil
+}
+
+// getFreshSnapshot return the snapshot younger than ClientConfig.SnapshotTtlMs and refresh it from the server
+// in background, the refresh writes the snapshot again on success.
+func (client *ConfigClient) getFreshSnapshot(param vo.ConfigParam, namespaceId, cacheKey string,
+	clientConfig constant.ClientConfig) (*model.GetConfigResult, string) {
+	if clientConfig.SnapshotTtlMs <= 0 || clientConfig.DisableUseSnapShot {
+		return nil, ""
+	}
+	result, encryptedDataKey, err := client.getConfigFromSnapshot(cacheKey, param.DataId)
+	if err != nil || time.Since(result.LastModified) >= time.Duration(clientConfig.SnapshotTtlMs)*time.Millisecond {
+		return nil, ""
+	}
+	if _, refreshing := client.snapshotRefreshing.LoadOrStore(cacheKey, struct{}{}); !refreshing {
+		go func() {
+			defer client.snapshotRefreshing.Delete(cacheKey)
+			if _, _, _, err := client.getConfigFromServer(param, namespaceId, clientConfig.TimeoutMs); err != nil {
+				logger.Warnf("refresh snapshot from server failed, dataId=%s, group=%s, namespaceId=%s, err:%v",
+					param.DataId, param.Group, namespaceId, err)
+			}
+		}()
+	}
+	return result, encryptedDataKey
+}
+
+func (client *ConfigClient) getConfigFromSnapshot(cacheKey, dataId string) (*model.GetConfigResult, string, error) {
+	content, err := cache.ReadConfigFromFile(cacheKey, client.configCacheDir)
+	if err != nil {
+		return nil, "", err
+	}
+	var encryptedDataKey string
+	if strings.HasPrefix(dataId, nacos_inner_encryption.CipherPrefix) {
+		if encryptedDataKey, err = cache.ReadEncryptedDataKeyFromFile(cacheKey, client.configCacheDir); err != nil {
+			return nil, "", err
+		}
+	}
+	result := &model.GetConfigResult{Content: content, Source: constant.CONFIG_SOURCE_SNAPSHOT, Md5: util.Md5(content),
+		LastModified: fileModTime(cache.GetFileName(cacheKey, client.configCacheDir))}
+	return result, encryptedDataKey, nil
+}
+
+func fileModTime(fileName string) time.Time {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -17,10 +17,12 @@
 package config_client
 
 import (
+	"math"
 	"sync"
 	"time"
 
 	"github.com/jun3372/nacos-sdk-go/clients/cache"
+	"github.com/pkg/errors"
 	"golang.org/x/time/rate"
 )
 
@@ -54,3 +56,49 @@ func IsLimited(checkKey string) bool {
 	add := time.Now().Add(time.Second)
 	return !limiter.AllowN(add, 1)
 }
+
+// ErrRateLimited means the request is rejected by the client side limiter configured with ClientConfig.RequestQps.
+var ErrRateLimited = errors.New("[client.requestProxy] request is rate limited by the client")
+
+// requestLimiter is a token bucket limiter for each config request type.
+type requestLimiter struct {
+	limiters       map[string]*rate.Limiter
+	defaultLimiter *rate.Limiter
+}
+
+func newRequestLimiter(requestQps map[string]float64) *requestLimiter {
+	if len(requestQps) == 0 {
+		return nil
+	}
+	l := &requestLimiter{limiters: make(map[string]*rate.Limiter, len(requestQps))}
+	for requestType, qps := range requestQps {
+		if qps <= 0 {
+			continue
+		}
+		burst := int(math.Ceil(qps))
+		if requestType == "*" {
+			l.defaultLimiter = rate.NewLimiter(rate.Limit(qps), burst)
+			continue
+		}
+		l.limiters[requestType] = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+	return l
+}
+
+// unlimitedRequestTypes are sent by the client itself to keep the listeners working, limiting them only delays
+// the notifications or leaves the listeners on the server after they are removed.
+var unlimitedRequestTypes = map[string]bool{
+	"ConfigBatchListenRequest": true,
+}
+
+// allow return false when the request of the type has used up its tokens.
+func (l *requestLimiter) allow(requestType string) bool {
+	if l == nil || unlimitedRequestTypes[requestType] {
+		return true
+	}
+	limiter, ok := l.limiters[requestType]
+	if !ok {
+		limiter = l.defaultLimiter
+	}
+	return limiter == nil || limiter.Allow()
+}
@@ -19,6 +19,7 @@ package config_client
 import (
 	"testing"
 
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/vo"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +47,24 @@ func TestLimiter(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestLimiter(t *testing.T) {
+	var unlimited *requestLimiter
+	assert.True(t, unlimited.allow("ConfigPublishRequest"))
+	assert.Nil(t, newRequestLimiter(nil))
+
+	limiter := newRequestLimiter(map[string]float64{"ConfigPublishRequest": 2, "*": 1})
+	assert.True(t, limiter.allow("ConfigPublishRequest"))
+	assert.True(t, limiter.allow("ConfigPublishRequest"))
+	assert.False(t, limiter.allow("ConfigPublishRequest"))
+	assert.True(t, limiter.allow("ConfigQueryRequest"))
+	assert.False(t, limiter.allow("ConfigRemoveRequest"))
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allow(rpc_request.NewConfigBatchListenRequest(1).GetRequestType()))
+	}
+
+	cp := &ConfigProxy{limiter: newRequestLimiter(map[string]float64{"*": 0.001})}
+	cp.limiter.allow("ConfigQueryRequest")
+	_, err := cp.requestProxy(nil, rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Equal(t, ErrRateLimited, err)
+}
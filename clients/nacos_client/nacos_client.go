@@ -75,6 +75,7 @@ func (client *NacosClient) SetServerConfig(configs []constant.ServerConfig) (err
 		return
 	}
 
+	constant.NormalizeServerConfigs(configs)
 	for i := 0; i < len(configs); i++ {
 		if len(configs[i].IpAddr) <= 0 || configs[i].Port <= 0 || configs[i].Port > 65535 {
 			err = errors.New("[client.SetServerConfig] configs[" + strconv.Itoa(i) + "] is invalid")
@@ -0,0 +1,178 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// Balancer choose one instance for SelectOneHealthyInstance, it's selected by
+// vo.SelectOneHealthInstanceParam.Strategy.
+type Balancer interface {
+	// Pick choose one of the instances, which are healthy, enabled and not empty.
+	Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance
+}
+
+var (
+	balancerMutex sync.RWMutex
+	balancers     = map[string]Balancer{
+		constant.BALANCER_WEIGHTED_RANDOM:   weightedRandomBalancer{},
+		constant.BALANCER_ROUND_ROBIN:       &roundRobinBalancer{},
+		constant.BALANCER_LEAST_CONNECTIONS: NewLeastConnectionsBalancer(),
+		constant.BALANCER_CONSISTENT_HASH:   consistentHashBalancer{},
+		constant.BALANCER_ZONE_AFFINITY:     zoneAffinityBalancer{},
+	}
+)
+
+// RegisterBalancer add a custom balancer or replace a built-in one.
+func RegisterBalancer(strategy string, balancer Balancer) error {
+	if len(strategy) <= 0 {
+		return errors.New("balancer strategy can not be empty")
+	}
+	if balancer == nil {
+		return errors.New("balancer can not be nil")
+	}
+	balancerMutex.Lock()
+	defer balancerMutex.Unlock()
+	balancers[strategy] = balancer
+	return nil
+}
+
+// GetBalancer return the balancer of the strategy, the weighted random one for the empty strategy.
+func GetBalancer(strategy string) (Balancer, error) {
+	if len(strategy) <= 0 {
+		strategy = constant.BALANCER_WEIGHTED_RANDOM
+	}
+	balancerMutex.RLock()
+	defer balancerMutex.RUnlock()
+	balancer, ok := balancers[strategy]
+	if !ok {
+		return nil, errors.Errorf("unknown balancer strategy:%s", strategy)
+	}
+	return balancer, nil
+}
+
+type weightedRandomBalancer struct{}
+
+func (weightedRandomBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	return newChooser(instances).pick()
+}
+
+type roundRobinBalancer struct {
+	counters sync.Map
+}
+
+// Pick choose the instances in the order of ip and port in turn, the weights are ignored.
+func (b *roundRobinBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	sorted := make([]model.Instance, len(instances))
+	copy(sorted, instances)
+	sort.Slice(sorted, func(i, j int) bool {
+		return instanceAddress(sorted[i]) < instanceAddress(sorted[j])
+	})
+	counter, _ := b.counters.LoadOrStore(util.GetGroupName(param.ServiceName, param.GroupName), new(uint64))
+	next := atomic.AddUint64(counter.(*uint64), 1)
+	return sorted[(next-1)%uint64(len(sorted))]
+}
+
+// LeastConnectionsBalancer choose the instance with the fewest picks which are not done, the callers must
+// call Done when they finish using the instance.
+type LeastConnectionsBalancer struct {
+	mutex       sync.Mutex
+	connections map[string]int
+}
+
+func NewLeastConnectionsBalancer() *LeastConnectionsBalancer {
+	return &LeastConnectionsBalancer{connections: make(map[string]int)}
+}
+
+func (b *LeastConnectionsBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	picked := 0
+	for i := 1; i < len(instances); i++ {
+		if b.connections[instanceAddress(instances[i])] < b.connections[instanceAddress(instances[picked])] {
+			picked = i
+		}
+	}
+	b.connections[instanceAddress(instances[picked])]++
+	return instances[picked]
+}
+
+// Done release a connection of the instance returned by Pick.
+func (b *LeastConnectionsBalancer) Done(instance model.Instance) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	address := instanceAddress(instance)
+	if b.connections[address] <= 1 {
+		delete(b.connections, address)
+		return
+	}
+	b.connections[address]--
+}
+
+type consistentHashBalancer struct{}
+
+// Pick choose the instance by weighted rendezvous hashing of param.HashKey, so a key keeps the same instance
+// and only the keys of a removed instance move.
+func (consistentHashBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	picked := 0
+	maxScore := math.Inf(-1)
+	for i, instance := range instances {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(param.HashKey + "#" + instanceAddress(instance)))
+		// map the hash into (0, 1) and weight it, see https://en.wikipedia.org/wiki/Rendezvous_hashing.
+		u := (float64(h.Sum64()>>11) + 0.5) / float64(uint64(1)<<53)
+		score := instance.Weight / -math.Log(u)
+		if score > maxScore {
+			picked, maxScore = i, score
+		}
+	}
+	return instances[picked]
+}
+
+type zoneAffinityBalancer struct{}
+
+// Pick prefer the instances whose zone metadata equals param.Zone, all the instances are used when none of
+// them is in the zone.
+func (zoneAffinityBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	var zoned []model.Instance
+	for _, instance := range instances {
+		if instance.Metadata[constant.INSTANCE_ZONE_KEY] == param.Zone {
+			zoned = append(zoned, instance)
+		}
+	}
+	if len(zoned) <= 0 {
+		zoned = instances
+	}
+	return newChooser(zoned).pick()
+}
+
+func instanceAddress(instance model.Instance) string {
+	return instance.Ip + ":" + strconv.FormatUint(instance.Port, 10)
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/file"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+// the names and the intervals are the same as the java client, so the failover files are interchangeable.
+const (
+	failoverDirName        = "failover"
+	failoverSwitchFileName = "00-00---000-VIPSRV_FAILOVER_SWITCH-000---00-00"
+	failoverModeOn         = "1"
+	failoverModeOff        = "0"
+	failoverSwitchInterval = 5 * time.Second
+	failoverBackupDelay    = 30 * time.Minute
+	failoverBackupInterval = 24 * time.Hour
+)
+
+// FailoverReactor serve the services from the failover files while the failover switch is on, the operators
+// pin the instances during an outage of the server by writing 1 to the switch file of the failover dir.
+type FailoverReactor struct {
+	failoverDir      string
+	holder           *ServiceInfoHolder
+	mutex            sync.RWMutex
+	switchOn         bool
+	switchModifiedAt time.Time
+	services         map[string]model.Service
+}
+
+func newFailoverReactor(holder *ServiceInfoHolder) *FailoverReactor {
+	return &FailoverReactor{
+		failoverDir: holder.cacheDir + string(os.PathSeparator) + failoverDirName,
+		holder:      holder,
+	}
+}
+
+func (f *FailoverReactor) run(ctx context.Context) {
+	switchTicker := time.NewTicker(failoverSwitchInterval)
+	defer switchTicker.Stop()
+	backupTimer := time.NewTimer(failoverBackupDelay)
+	defer backupTimer.Stop()
+	for {
+		f.refreshSwitch()
+		select {
+		case <-ctx.Done():
+			return
+		case <-switchTicker.C:
+		case <-backupTimer.C:
+			f.backup()
+			backupTimer.Reset(failoverBackupInterval)
+		}
+	}
+}
+
+// IsFailoverSwitch return true when the services are served from the failover files
+func (f *FailoverReactor) IsFailoverSwitch() bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.switchOn
+}
+
+// GetService return the service of the failover files, false if the switch is off or the service isn't in them.
+func (f *FailoverReactor) GetService(cacheKey string) (model.Service, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	if !f.switchOn {
+		return model.Service{}, false
+	}
+	service, ok := f.services[cacheKey]
+	return service, ok
+}
+
+// refreshSwitch reload the switch and the failover files when the switch file is modified.
+func (f *FailoverReactor) refreshSwitch() {
+	switchFile := f.failoverDir + string(os.PathSeparator) + failoverSwitchFileName
+	stat, err := os.Stat(switchFile)
+	if err != nil {
+		f.setSwitch(false, time.Time{}, nil)
+		return
+	}
+	f.mutex.RLock()
+	modified := !stat.ModTime().Equal(f.switchModifiedAt)
+	f.mutex.RUnlock()
+	if !modified {
+		return
+	}
+	content, err := os.ReadFile(switchFile)
+	if err != nil {
+		logger.Errorf("read failover switch file:%s failed, err:%v", switchFile, err)
+		return
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		switch strings.TrimSpace(line) {
+		case failoverModeOn:
+			f.setSwitch(true, stat.ModTime(), f.readServices())
+			return
+		case failoverModeOff:
+			f.setSwitch(false, stat.ModTime(), nil)
+			return
+		}
+	}
+}
+
+func (f *FailoverReactor) setSwitch(on bool, modifiedAt time.Time, services map[string]model.Service) {
+	f.mutex.Lock()
+	changed := f.switchOn != on
+	previous := f.services
+	f.switchOn, f.switchModifiedAt, f.services = on, modifiedAt, services
+	f.mutex.Unlock()
+	if !changed {
+		return
+	}
+	logger.Infof("failover switch is turned on:%t, failover services:%d", on, len(services))
+	// notify the subscribers of the failover services with the instances they are served from now on
+	if on {
+		for cacheKey, service := range services {
+			service := service
+			f.holder.subCallback.ServiceChangedWithDiff(cacheKey, &service, f.holder.cachedService(cacheKey))
+		}
+		return
+	}
+	for cacheKey, service := range previous {
+		if cached := f.holder.cachedService(cacheKey); cached != nil {
+			service := service
+			f.holder.subCallback.ServiceChangedWithDiff(cacheKey, cached, &service)
+		}
+	}
+}
+
+// readServices read the services of the failover dir, the files are the json of the services as the java
+// client writes them.
+func (f *FailoverReactor) readServices() map[string]model.Service {
+	files, err := os.ReadDir(f.failoverDir)
+	if err != nil {
+		logger.Errorf("read failover dir:%s failed, err:%v", f.failoverDir, err)
+		return nil
+	}
+	services := make(map[string]model.Service, len(files))
+	for _, entry := range files {
+		if entry.IsDir() || entry.Name() == failoverSwitchFileName {
+			continue
+		}
+		fileName := f.failoverDir + string(os.PathSeparator) + entry.Name()
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			logger.Errorf("read failover file:%s failed, err:%v", fileName, err)
+			continue
+		}
+		service := util.JsonToService(string(content))
+		if service == nil {
+			continue
+		}
+		// the service name of the java client 1.x contains the group
+		if len(service.GroupName) == 0 {
+			if i := strings.Index(service.Name, constant.SERVICE_INFO_SPLITER); i >= 0 {
+				service.GroupName, service.Name = service.Name[:i], service.Name[i+len(constant.SERVICE_INFO_SPLITER):]
+			} else {
+				service.GroupName = constant.DEFAULT_GROUP
+			}
+		}
+		cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
+		services[cacheKey] = *service
+	}
+	logger.Infof("load %d services from failover dir:%s", len(services), f.failoverDir)
+	return services
+}
+
+// backup write the cached services to the failover dir, the files are named by the url encoded cache keys
+// as the java client does. The failover files are kept while the switch is on.
+func (f *FailoverReactor) backup() {
+	if f.IsFailoverSwitch() {
+		return
+	}
+	if err := file.MkdirIfNecessary(f.failoverDir); err != nil {
+		logger.Errorf("mkdir failover dir:%s failed, err:%v", f.failoverDir, err)
+		return
+	}
+	f.holder.ServiceInfoMap.Range(func(key, value interface{}) bool {
+		service := value.(model.Service)
+		content, err := json.Marshal(service)
+		if err != nil {
+			return true
+		}
+		fileName := f.failoverDir + string(os.PathSeparator) + url.QueryEscape(key.(string))
+		if err = os.WriteFile(fileName, content, 0666); err != nil {
+			logger.Errorf("write failover file:%s failed, err:%v", fileName, err)
+		}
+		return true
+	})
+}
@@ -17,12 +17,14 @@
 package naming_cache
 
 import (
+	"context"
 	"os"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/clients/cache"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
@@ -38,6 +40,11 @@ type ServiceInfoHolder struct {
 	notLoadCacheAtStart  bool
 	subCallback          *SubscribeCallback
 	UpdateTimeMap        sync.Map
+	protectThreshold     float64
+	protectGraceMs       uint64
+	protectSinceMap      sync.Map
+	lastPushTimeMap      sync.Map
+	failoverReactor      *FailoverReactor
 }
 
 func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notLoadCacheAtStart bool) *ServiceInfoHolder {
@@ -50,6 +57,7 @@ func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notL
 		UpdateTimeMap:        sync.Map{},
 		ServiceInfoMap:       sync.Map{},
 	}
+	serviceInfoHolder.failoverReactor = newFailoverReactor(serviceInfoHolder)
 
 	if !notLoadCacheAtStart {
 		serviceInfoHolder.loadCacheFromDisk()
@@ -57,6 +65,19 @@ func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notL
 	return serviceInfoHolder
 }
 
+// SetPushProtection keep the previous instances for graceMs when a push drops the healthy instances below
+// threshold of the previous ones or to zero, graceMs 0 disables the protection.
+func (s *ServiceInfoHolder) SetPushProtection(threshold float64, graceMs uint64) {
+	s.protectThreshold = threshold
+	s.protectGraceMs = graceMs
+}
+
+// StartFailover watch the failover switch until the ctx is done, the services are served from the failover
+// files while the switch is on.
+func (s *ServiceInfoHolder) StartFailover(ctx context.Context) {
+	go s.failoverReactor.run(ctx)
+}
+
 func (s *ServiceInfoHolder) loadCacheFromDisk() {
 	serviceMap := cache.ReadServicesFromFile(s.cacheDir)
 	if serviceMap == nil || len(serviceMap) == 0 {
@@ -72,14 +93,19 @@ func (s *ServiceInfoHolder) ProcessServiceJson(data string) {
 }
 
 func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
+	s.processService(service)
+}
+
+// processService update the cache with the service, false if it's dropped, e.g. it's out of date.
+func (s *ServiceInfoHolder) processService(service *model.Service) bool {
 	if service == nil {
-		return
+		return false
 	}
 	if !s.updateCacheWhenEmpty {
 		//if instance list is empty,not to update cache
 		if service.Hosts == nil || len(service.Hosts) == 0 {
 			logger.Warnf("instance list is empty, updateCacheWhenEmpty is set to false, callback is not triggered. service name:%s", service.Name)
-			return
+			return false
 		}
 	}
 
@@ -87,7 +113,10 @@ func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
 	oldDomain, ok := s.ServiceInfoMap.Load(cacheKey)
 	if ok && oldDomain.(model.Service).LastRefTime >= service.LastRefTime {
 		logger.Warnf("out of date data received, old-t: %d, new-t: %d", oldDomain.(model.Service).LastRefTime, service.LastRefTime)
-		return
+		return false
+	}
+	if ok && s.isPushProtected(cacheKey, oldDomain.(model.Service), service) {
+		return false
 	}
 
 	s.UpdateTimeMap.Store(cacheKey, uint64(util.CurrentMillis()))
@@ -95,7 +124,16 @@ func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
 	if !ok || checkInstanceChanged(oldDomain, *service) {
 		logger.Infof("service key:%s was updated to:%s", cacheKey, util.ToJsonString(service))
 		cache.WriteServicesToFile(service, cacheKey, s.cacheDir)
-		s.subCallback.ServiceChanged(cacheKey, service)
+		var previous *model.Service
+		if ok {
+			oldService := oldDomain.(model.Service)
+			previous = &oldService
+		}
+		if _, ok := s.failoverReactor.GetService(cacheKey); ok {
+			logger.Warnf("failover switch is on, service key:%s is served from the failover file", cacheKey)
+		} else {
+			s.subCallback.ServiceChangedWithDiff(cacheKey, service, previous)
+		}
 	}
 	var count int
 	s.ServiceInfoMap.Range(func(key, value interface{}) bool {
@@ -103,11 +141,78 @@ func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
 		return true
 	})
 	monitor.GetServiceInfoMapSizeMonitor().Set(float64(count))
+	return true
+}
+
+// isPushProtected return true when the push should be ignored to keep serving the previous instances.
+func (s *ServiceInfoHolder) isPushProtected(cacheKey string, oldService model.Service, service *model.Service) bool {
+	if s.protectGraceMs == 0 {
+		return false
+	}
+	oldHealthy := healthyCount(oldService.Hosts)
+	newHealthy := healthyCount(service.Hosts)
+	if oldHealthy == 0 || (newHealthy > 0 && float64(newHealthy)/float64(oldHealthy) >= s.protectThreshold) {
+		s.protectSinceMap.Delete(cacheKey)
+		return false
+	}
+	now := util.CurrentMillis()
+	since, _ := s.protectSinceMap.LoadOrStore(cacheKey, now)
+	if uint64(now-since.(int64)) < s.protectGraceMs {
+		logger.Warnf("push protection is triggered, healthy instances of service key:%s dropped from %d to %d, keep the previous instances",
+			cacheKey, oldHealthy, newHealthy)
+		return true
+	}
+	logger.Warnf("push protection of service key:%s expired after %dms, healthy instances dropped from %d to %d",
+		cacheKey, s.protectGraceMs, oldHealthy, newHealthy)
+	s.protectSinceMap.Delete(cacheKey)
+	return false
+}
+
+func healthyCount(instances []model.Instance) int {
+	var count int
+	for _, instance := range instances {
+		if instance.Healthy && instance.Enable && instance.Weight > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// ProcessPushService process a service pushed by the server, it records the push time and observes the lag
+// from the server refreshes the service to the cache is updated. The lag isn't observed for the pushes which
+// are dropped, e.g. the stale ones redelivered after a reconnect.
+func (s *ServiceInfoHolder) ProcessPushService(service *model.Service) {
+	if service == nil {
+		return
+	}
+	monitor.GetNamingMetrics().IncPush(service.Name, service.GroupName, len(service.Hosts) == 0)
+	applied := s.processService(service)
+	now := time.Now()
+	cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
+	s.lastPushTimeMap.Store(cacheKey, now)
+	if applied && service.LastRefTime > 0 {
+		lag := now.Sub(time.UnixMilli(int64(service.LastRefTime)))
+		if lag < 0 {
+			lag = 0
+		}
+		monitor.GetNamingMetrics().ObservePushLag(service.Name, service.GroupName, lag)
+	}
+}
+
+// GetLastPushTime return the time of the last push of the service, false if it is never pushed.
+func (s *ServiceInfoHolder) GetLastPushTime(serviceName, groupName, clusters string) (time.Time, bool) {
+	cacheKey := util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)
+	if v, ok := s.lastPushTimeMap.Load(cacheKey); ok {
+		return v.(time.Time), true
+	}
+	return time.Time{}, false
 }
 
 func (s *ServiceInfoHolder) GetServiceInfo(serviceName, groupName, clusters string) (model.Service, bool) {
 	cacheKey := util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)
-	//todo FailoverReactor
+	if service, ok := s.failoverReactor.GetService(cacheKey); ok {
+		return service, ok
+	}
 	service, ok := s.ServiceInfoMap.Load(cacheKey)
 	if ok {
 		return service.(model.Service), ok
@@ -115,6 +220,55 @@ func (s *ServiceInfoHolder) GetServiceInfo(serviceName, groupName, clusters stri
 	return model.Service{}, ok
 }
 
+// DumpServices return a snapshot of the cached services sorted by the cache keys.
+func (s *ServiceInfoHolder) DumpServices() []model.Service {
+	var keys []string
+	services := make(map[string]model.Service)
+	s.ServiceInfoMap.Range(func(key, value interface{}) bool {
+		keys = append(keys, key.(string))
+		services[key.(string)] = value.(model.Service)
+		return true
+	})
+	sort.Strings(keys)
+	snapshot := make([]model.Service, 0, len(keys))
+	for _, key := range keys {
+		service := services[key]
+		service.Hosts = append([]model.Instance(nil), service.Hosts...)
+		snapshot = append(snapshot, service)
+	}
+	return snapshot
+}
+
+// LoadServices add the services of a snapshot missing from the cache and return the number of them, the
+// cached services are kept since they are newer. The loaded services are not marked as updated, so they
+// are refreshed from the server as soon as possible.
+func (s *ServiceInfoHolder) LoadServices(snapshot []model.Service) int {
+	var loaded int
+	for i := range snapshot {
+		service := snapshot[i]
+		cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
+		if _, ok := s.ServiceInfoMap.LoadOrStore(cacheKey, service); ok {
+			continue
+		}
+		loaded++
+		if _, ok := s.failoverReactor.GetService(cacheKey); !ok {
+			s.subCallback.ServiceChangedWithDiff(cacheKey, &service, nil)
+		}
+	}
+	if loaded > 0 {
+		logger.Infof("load %d services from the snapshot", loaded)
+	}
+	return loaded
+}
+
+func (s *ServiceInfoHolder) cachedService(cacheKey string) *model.Service {
+	if service, ok := s.ServiceInfoMap.Load(cacheKey); ok {
+		cached := service.(model.Service)
+		return &cached
+	}
+	return nil
+}
+
 func (s *ServiceInfoHolder) RegisterCallback(serviceName string, clusters string, callbackFunc *func(services []model.Instance, err error)) {
 	s.subCallback.AddCallbackFunc(serviceName, clusters, callbackFunc)
 }
@@ -123,6 +277,16 @@ func (s *ServiceInfoHolder) DeregisterCallback(serviceName string, clusters stri
 	s.subCallback.RemoveCallbackFunc(serviceName, clusters, callbackFunc)
 }
 
+func (s *ServiceInfoHolder) RegisterDiffCallback(serviceName string, clusters string,
+	callbackFunc *func(services []model.Instance, diff model.InstancesDiff, err error)) {
+	s.subCallback.AddDiffCallbackFunc(serviceName, clusters, callbackFunc)
+}
+
+func (s *ServiceInfoHolder) DeregisterDiffCallback(serviceName string, clusters string,
+	callbackFunc *func(services []model.Instance, diff model.InstancesDiff, err error)) {
+	s.subCallback.RemoveDiffCallbackFunc(serviceName, clusters, callbackFunc)
+}
+
 func (s *ServiceInfoHolder) StopUpdateIfContain(serviceName, clusters string) {
 	cacheKey := util.GetServiceCacheKey(serviceName, clusters)
 	s.ServiceInfoMap.Delete(cacheKey)
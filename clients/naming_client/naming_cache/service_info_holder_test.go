@@ -18,10 +18,13 @@ package naming_cache
 import (
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/stretchr/testify/assert"
 )
@@ -141,6 +144,82 @@ func TestHostReactor_isServiceInstanceChangedWithUnOrdered(t *testing.T) {
 	assert.True(t, changed)
 }
 
+func TestServiceInfoHolder_PushProtection(t *testing.T) {
+	holder := NewServiceInfoHolder("public", t.TempDir(), true, true)
+	holder.SetPushProtection(0.5, 100)
+	hosts := func(healthy ...bool) []model.Instance {
+		var instances []model.Instance
+		for i, h := range healthy {
+			instances = append(instances, model.Instance{Ip: "127.0.0.1", Port: uint64(8000 + i), Weight: 1, Enable: true, Healthy: h})
+		}
+		return instances
+	}
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts(true, true, true, true)})
+
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 2, Hosts: hosts()})
+	service, _ := holder.GetServiceInfo("DEMO", "DEFAULT_GROUP", "")
+	assert.Equal(t, 4, len(service.Hosts))
+
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 3, Hosts: hosts(true, false, false, false)})
+	service, _ = holder.GetServiceInfo("DEMO", "DEFAULT_GROUP", "")
+	assert.Equal(t, 4, healthyCount(service.Hosts))
+
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 4, Hosts: hosts(true, true, false)})
+	service, _ = holder.GetServiceInfo("DEMO", "DEFAULT_GROUP", "")
+	assert.Equal(t, 3, len(service.Hosts))
+
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 5, Hosts: hosts()})
+	time.Sleep(150 * time.Millisecond)
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 6, Hosts: hosts()})
+	service, _ = holder.GetServiceInfo("DEMO", "DEFAULT_GROUP", "")
+	assert.Equal(t, 0, len(service.Hosts))
+}
+
+type lagNamingMetrics struct {
+	lags   []time.Duration
+	pushes []bool
+}
+
+func (m *lagNamingMetrics) ObservePushLag(serviceName, groupName string, lag time.Duration) {
+	m.lags = append(m.lags, lag)
+}
+
+func (m *lagNamingMetrics) IncPush(serviceName, groupName string, empty bool) {
+	m.pushes = append(m.pushes, empty)
+}
+
+func (m *lagNamingMetrics) AddSubscriptions(delta int) {}
+
+func (m *lagNamingMetrics) ObserveSelect(serviceName, groupName string, cost time.Duration, err error) {
+}
+
+func TestServiceInfoHolder_ProcessPushService(t *testing.T) {
+	metrics := &lagNamingMetrics{}
+	monitor.SetNamingMetrics(metrics)
+	defer monitor.SetNamingMetrics(nil)
+	holder := NewServiceInfoHolder("public", t.TempDir(), true, true)
+	holder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "127.0.0.1", Port: 8080, Weight: 1, Enable: true, Healthy: true}}})
+	_, ok := holder.GetLastPushTime("DEMO", "DEFAULT_GROUP", "")
+	assert.False(t, ok)
+
+	refTime := time.Now().Add(-time.Second)
+	holder.ProcessPushService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: uint64(refTime.UnixMilli()),
+		Hosts: []model.Instance{{Ip: "127.0.0.1", Port: 8081, Weight: 1, Enable: true, Healthy: true}}})
+	pushTime, ok := holder.GetLastPushTime("DEMO", "DEFAULT_GROUP", "")
+	assert.True(t, ok)
+	assert.True(t, time.Since(pushTime) < time.Second)
+	assert.Equal(t, 1, len(metrics.lags))
+	assert.True(t, metrics.lags[0] >= time.Second)
+	assert.Equal(t, []bool{false}, metrics.pushes)
+
+	// the stale push is counted but its lag isn't observed
+	holder.ProcessPushService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 2,
+		Hosts: []model.Instance{{Ip: "127.0.0.1", Port: 8082, Weight: 1, Enable: true, Healthy: true}}})
+	assert.Equal(t, 1, len(metrics.lags))
+	assert.Equal(t, []bool{false, false}, metrics.pushes)
+}
+
 // create random ip addr
 func createRandomIp() string {
 	ip := fmt.Sprintf("%d.%d.%d.%d", rand.Intn(255), rand.Intn(255), rand.Intn(255), rand.Intn(255))
@@ -150,3 +229,75 @@ func createRandomIp() string {
 func creatRandomPort() uint64 {
 	return rand.Uint64()
 }
+
+func TestServiceInfoHolder_Failover(t *testing.T) {
+	holder := NewServiceInfoHolder("public", t.TempDir(), true, true)
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true, Weight: 1}}})
+	var notified []model.Instance
+	callback := func(services []model.Instance, err error) {
+		notified = services
+	}
+	holder.RegisterCallback("DEFAULT_GROUP@@demo", "", &callback)
+
+	failoverDir := filepath.Join(holder.cacheDir, "failover")
+	assert.Nil(t, os.MkdirAll(failoverDir, 0755))
+	// the service file written by the java client 1.x, the name contains the group
+	javaService := `{"name":"DEFAULT_GROUP@@demo","clusters":"","cacheMillis":10000,"lastRefTime":1,"checksum":"",` +
+		`"allIPs":false,"hosts":[{"ip":"10.0.0.2","port":80,"weight":1.0,"healthy":true,"enabled":true}]}`
+	assert.Nil(t, os.WriteFile(filepath.Join(failoverDir, "DEFAULT_GROUP%40%40demo"), []byte(javaService), 0644))
+	switchFile := filepath.Join(failoverDir, failoverSwitchFileName)
+	assert.Nil(t, os.WriteFile(switchFile, []byte("1\n"), 0644))
+
+	holder.failoverReactor.refreshSwitch()
+	assert.True(t, holder.failoverReactor.IsFailoverSwitch())
+	service, ok := holder.GetServiceInfo("demo", "DEFAULT_GROUP", "")
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.2", service.Hosts[0].Ip)
+	assert.Equal(t, "10.0.0.2", notified[0].Ip)
+
+	// the pushes keep updating the cache without overriding the failover instances
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 2,
+		Hosts: []model.Instance{{Ip: "10.0.0.3", Port: 80, Healthy: true, Enable: true, Weight: 1}}})
+	service, _ = holder.GetServiceInfo("demo", "DEFAULT_GROUP", "")
+	assert.Equal(t, "10.0.0.2", service.Hosts[0].Ip)
+	assert.Equal(t, "10.0.0.2", notified[0].Ip)
+
+	assert.Nil(t, os.WriteFile(switchFile, []byte("0\n"), 0644))
+	assert.Nil(t, os.Chtimes(switchFile, time.Now().Add(time.Second), time.Now().Add(time.Second)))
+	holder.failoverReactor.refreshSwitch()
+	assert.False(t, holder.failoverReactor.IsFailoverSwitch())
+	service, _ = holder.GetServiceInfo("demo", "DEFAULT_GROUP", "")
+	assert.Equal(t, "10.0.0.3", service.Hosts[0].Ip)
+	assert.Equal(t, "10.0.0.3", notified[0].Ip)
+
+	holder.failoverReactor.backup()
+	_, err := os.Stat(filepath.Join(failoverDir, "DEFAULT_GROUP%40%40demo"))
+	assert.Nil(t, err)
+}
+
+func TestServiceInfoHolder_DumpAndLoadServices(t *testing.T) {
+	holder := NewServiceInfoHolder("public", t.TempDir(), true, true)
+	holder.ProcessService(&model.Service{Name: "b", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.1", Port: 80}}})
+	holder.ProcessService(&model.Service{Name: "a", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.2", Port: 80}}})
+	snapshot := holder.DumpServices()
+	assert.Equal(t, 2, len(snapshot))
+	assert.Equal(t, "a", snapshot[0].Name)
+
+	peer := NewServiceInfoHolder("public", t.TempDir(), true, true)
+	peer.ProcessService(&model.Service{Name: "a", GroupName: "DEFAULT_GROUP", LastRefTime: 2,
+		Hosts: []model.Instance{{Ip: "10.0.0.3", Port: 80}}})
+	var notified []model.Instance
+	callback := func(services []model.Instance, err error) {
+		notified = services
+	}
+	peer.RegisterCallback("DEFAULT_GROUP@@b", "", &callback)
+	assert.Equal(t, 1, peer.LoadServices(snapshot))
+	assert.Equal(t, "10.0.0.1", notified[0].Ip)
+	service, _ := peer.GetServiceInfo("a", "DEFAULT_GROUP", "")
+	assert.Equal(t, "10.0.0.3", service.Hosts[0].Ip)
+	_, updated := peer.UpdateTimeMap.Load("DEFAULT_GROUP@@b")
+	assert.False(t, updated)
+}
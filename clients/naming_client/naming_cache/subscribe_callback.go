@@ -17,6 +17,8 @@
 package naming_cache
 
 import (
+	"reflect"
+	"strconv"
 	"sync"
 
 	"github.com/jun3372/nacos-sdk-go/clients/cache"
@@ -26,17 +28,22 @@ import (
 )
 
 type SubscribeCallback struct {
-	callbackFuncMap cache.ConcurrentMap
-	mux             *sync.Mutex
+	callbackFuncMap     cache.ConcurrentMap
+	diffCallbackFuncMap cache.ConcurrentMap
+	mux                 *sync.Mutex
 }
 
 func NewSubscribeCallback() *SubscribeCallback {
-	return &SubscribeCallback{callbackFuncMap: cache.NewConcurrentMap(), mux: new(sync.Mutex)}
+	return &SubscribeCallback{callbackFuncMap: cache.NewConcurrentMap(), diffCallbackFuncMap: cache.NewConcurrentMap(),
+		mux: new(sync.Mutex)}
 }
 
 func (ed *SubscribeCallback) IsSubscribed(serviceName, clusters string) bool {
 	key := util.GetServiceCacheKey(serviceName, clusters)
 	_, ok := ed.callbackFuncMap.Get(key)
+	if !ok {
+		_, ok = ed.diffCallbackFuncMap.Get(key)
+	}
 	return ok
 }
 
@@ -64,16 +71,106 @@ func (ed *SubscribeCallback) RemoveCallbackFunc(serviceName string, clusters str
 				newFuncs = append(newFuncs, funcItem)
 			}
 		}
+		if len(newFuncs) == 0 {
+			ed.callbackFuncMap.Remove(key)
+			return
+		}
 		ed.callbackFuncMap.Set(key, newFuncs)
 	}
 
 }
 
+func (ed *SubscribeCallback) AddDiffCallbackFunc(serviceName string, clusters string,
+	callbackFunc *func(services []model.Instance, diff model.InstancesDiff, err error)) {
+	key := util.GetServiceCacheKey(serviceName, clusters)
+	defer ed.mux.Unlock()
+	ed.mux.Lock()
+	var funcSlice []*func(services []model.Instance, diff model.InstancesDiff, err error)
+	old, ok := ed.diffCallbackFuncMap.Get(key)
+	if ok {
+		funcSlice = append(funcSlice, old.([]*func(services []model.Instance, diff model.InstancesDiff, err error))...)
+	}
+	funcSlice = append(funcSlice, callbackFunc)
+	ed.diffCallbackFuncMap.Set(key, funcSlice)
+}
+
+func (ed *SubscribeCallback) RemoveDiffCallbackFunc(serviceName string, clusters string,
+	callbackFunc *func(services []model.Instance, diff model.InstancesDiff, err error)) {
+	key := util.GetServiceCacheKey(serviceName, clusters)
+	defer ed.mux.Unlock()
+	ed.mux.Lock()
+	funcs, ok := ed.diffCallbackFuncMap.Get(key)
+	if !ok || funcs == nil {
+		return
+	}
+	var newFuncs []*func(services []model.Instance, diff model.InstancesDiff, err error)
+	for _, funcItem := range funcs.([]*func(services []model.Instance, diff model.InstancesDiff, err error)) {
+		if funcItem != callbackFunc {
+			newFuncs = append(newFuncs, funcItem)
+		}
+	}
+	if len(newFuncs) == 0 {
+		ed.diffCallbackFuncMap.Remove(key)
+		return
+	}
+	ed.diffCallbackFuncMap.Set(key, newFuncs)
+}
+
 func (ed *SubscribeCallback) ServiceChanged(cacheKey string, service *model.Service) {
+	ed.ServiceChangedWithDiff(cacheKey, service, nil)
+}
+
+// ServiceChangedWithDiff notify the callbacks of the service, the diff callbacks receive the change from the
+// previous service, which is nil for the first notification.
+func (ed *SubscribeCallback) ServiceChangedWithDiff(cacheKey string, service *model.Service, previous *model.Service) {
 	funcs, ok := ed.callbackFuncMap.Get(cacheKey)
 	if ok {
 		for _, funcItem := range funcs.([]*func(services []model.Instance, err error)) {
 			(*funcItem)(service.Hosts, nil)
 		}
 	}
+	diffFuncs, ok := ed.diffCallbackFuncMap.Get(cacheKey)
+	if !ok {
+		return
+	}
+	var previousHosts []model.Instance
+	if previous != nil {
+		previousHosts = previous.Hosts
+	}
+	diff := DiffInstances(previousHosts, service.Hosts)
+	for _, funcItem := range diffFuncs.([]*func(services []model.Instance, diff model.InstancesDiff, err error)) {
+		(*funcItem)(service.Hosts, diff, nil)
+	}
+}
+
+// DiffInstances return the change from the previous instances to the current ones.
+func DiffInstances(previous, current []model.Instance) model.InstancesDiff {
+	var diff model.InstancesDiff
+	previousMap := make(map[string]model.Instance, len(previous))
+	for _, instance := range previous {
+		previousMap[instanceKey(instance)] = instance
+	}
+	for _, instance := range current {
+		key := instanceKey(instance)
+		old, ok := previousMap[key]
+		if !ok {
+			diff.Added = append(diff.Added, instance)
+			continue
+		}
+		delete(previousMap, key)
+		if !reflect.DeepEqual(old, instance) {
+			diff.Modified = append(diff.Modified, instance)
+		}
+	}
+	// keep the order of the previous instances for the removed ones.
+	for _, instance := range previous {
+		if _, ok := previousMap[instanceKey(instance)]; ok {
+			diff.Removed = append(diff.Removed, instance)
+		}
+	}
+	return diff
+}
+
+func instanceKey(instance model.Instance) string {
+	return instance.Ip + ":" + strconv.FormatUint(instance.Port, 10) + "@" + instance.ClusterName
 }
@@ -173,3 +173,36 @@ func TestSubscribeCallback_ServiceChanged(t *testing.T) {
 	cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
 	ed.ServiceChanged(cacheKey, &service)
 }
+
+func TestEventDispatcher_ServiceChangedWithDiff(t *testing.T) {
+	kept := model.Instance{Ip: "127.0.0.1", Port: 8080, Weight: 1, ClusterName: "default"}
+	removed := model.Instance{Ip: "127.0.0.2", Port: 8080, Weight: 1, ClusterName: "default"}
+	modified := model.Instance{Ip: "127.0.0.3", Port: 8080, Weight: 1, ClusterName: "default"}
+	added := model.Instance{Ip: "127.0.0.4", Port: 8080, Weight: 1, ClusterName: "default"}
+	previous := model.Service{Hosts: []model.Instance{kept, removed, modified}}
+	modified.Weight = 2
+	current := model.Service{Hosts: []model.Instance{kept, modified, added}}
+
+	ed := NewSubscribeCallback()
+	var diffs []model.InstancesDiff
+	param := vo.SubscribeParam{
+		ServiceName: "Test",
+		GroupName:   "public",
+		SubscribeDiffCallback: func(services []model.Instance, diff model.InstancesDiff, err error) {
+			diffs = append(diffs, diff)
+		},
+	}
+	serviceName := util.GetGroupName(param.ServiceName, param.GroupName)
+	ed.AddDiffCallbackFunc(serviceName, "", &param.SubscribeDiffCallback)
+	assert.True(t, ed.IsSubscribed(serviceName, ""))
+	cacheKey := util.GetServiceCacheKey(serviceName, "")
+	ed.ServiceChanged(cacheKey, &previous)
+	ed.ServiceChangedWithDiff(cacheKey, &current, &previous)
+	assert.Equal(t, 2, len(diffs))
+	assert.Equal(t, []model.Instance{kept, removed, previous.Hosts[2]}, diffs[0].Added)
+	assert.Equal(t, model.InstancesDiff{Added: []model.Instance{added}, Removed: []model.Instance{removed},
+		Modified: []model.Instance{modified}}, diffs[1])
+
+	ed.RemoveDiffCallbackFunc(serviceName, "", &param.SubscribeDiffCallback)
+	assert.False(t, ed.IsSubscribed(serviceName, ""))
+}
@@ -20,7 +20,10 @@ import (
 	"context"
 	"math"
 	"math/rand"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,18 +33,53 @@ import (
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_proxy"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
 	"github.com/jun3372/nacos-sdk-go/vo"
 )
 
+// ErrServiceNotCached means the service isn't in the cache when SelectInstancesParam.CacheOnly is set.
+var ErrServiceNotCached = errors.New("[client.SelectInstances] the service is not subscribed")
+
 // NamingClient ...
 type NamingClient struct {
 	nacos_client.INacosClient
-	ctx               context.Context
-	cancel            context.CancelFunc
-	serviceProxy      naming_proxy.INamingProxy
-	serviceInfoHolder *naming_cache.ServiceInfoHolder
+	ctx                context.Context
+	cancel             context.CancelFunc
+	serviceProxy       naming_proxy.INamingProxy
+	serviceInfoHolder  *naming_cache.ServiceInfoHolder
+	watchMutex         sync.Mutex
+	servicesWatchers   map[string]*servicesWatcher
+	healthMutex        sync.Mutex
+	healthCheckers     map[string]*healthChecker
+	registeredMutex    sync.Mutex
+	registeredServices map[string]*registeredService
+	subscribeMutex     sync.Mutex
+	subscriptions      map[subscriptionKey]subscription
+	healthWatchMutex   sync.Mutex
+	healthWatchers     map[*vo.WatchInstanceHealthParam]context.CancelFunc
+	selectCacheTtl     time.Duration
+	selectCache        sync.Map
+	namespaceId        string
+	namespaceMutex     sync.Mutex
+	namespaces         map[string]*NamingClient
+	closeOnce          sync.Once
+}
+
+// subscriptionKey identify a subscription by the param and the service.
+type subscriptionKey struct {
+	param   *vo.SubscribeParam
+	service string
+}
+
+// subscription is the clusters subscribed and the callbacks registered for them.
+type subscription struct {
+	clusters     string
+	callback     *func(services []model.Instance, err error)
+	diffCallback *func(services []model.Instance, diff model.InstancesDiff, err error)
 }
 
 // NewNamingClient ...
@@ -72,14 +110,9 @@ func NewNamingClient(nc nacos_client.INacosClient) (*NamingClient, error) {
 		clientConfig.NamespaceId = constant.DEFAULT_NAMESPACE_ID
 	}
 
-	naming.serviceInfoHolder = naming_cache.NewServiceInfoHolder(clientConfig.NamespaceId, clientConfig.CacheDir,
-		clientConfig.UpdateCacheWhenEmpty, clientConfig.NotLoadCacheAtStart)
-
+	naming.init(clientConfig)
 	naming.serviceProxy, err = NewNamingProxyDelegate(ctx, clientConfig, serverConfig, httpAgent, naming.serviceInfoHolder)
-
-	if clientConfig.AsyncUpdateService {
-		go NewServiceInfoUpdater(ctx, naming.serviceInfoHolder, clientConfig.UpdateThreadNum, naming.serviceProxy).asyncUpdateService()
-	}
+	naming.startTasks(clientConfig)
 	if err != nil {
 		return naming, err
 	}
@@ -87,12 +120,44 @@ func NewNamingClient(nc nacos_client.INacosClient) (*NamingClient, error) {
 	return naming, nil
 }
 
+// init create the cache of the namespace of the client config.
+func (sc *NamingClient) init(clientConfig constant.ClientConfig) {
+	sc.namespaceId = clientConfig.NamespaceId
+	sc.serviceInfoHolder = naming_cache.NewServiceInfoHolder(clientConfig.NamespaceId, clientConfig.CacheDir,
+		clientConfig.UpdateCacheWhenEmpty, clientConfig.NotLoadCacheAtStart)
+	sc.serviceInfoHolder.SetPushProtection(clientConfig.PushProtectThreshold, clientConfig.PushProtectGraceMs)
+	sc.serviceInfoHolder.StartFailover(sc.ctx)
+	sc.selectCacheTtl = time.Duration(clientConfig.SelectCacheTtlMs) * time.Millisecond
+}
+
+// startTasks start the background tasks enabled by the client config.
+func (sc *NamingClient) startTasks(clientConfig constant.ClientConfig) {
+	if clientConfig.ReconcileIntervalMs > 0 {
+		go sc.runReconciler(sc.ctx, time.Duration(clientConfig.ReconcileIntervalMs)*time.Millisecond)
+	}
+	if clientConfig.SubscribeWatchdogMs > 0 {
+		go sc.runSubscribeWatchdog(sc.ctx, time.Duration(clientConfig.SubscribeWatchdogMs)*time.Millisecond,
+			clientConfig.SubscribeMissedChecks)
+	}
+	// the 1.x servers keep pushing to the udp port only while the services are queried
+	if delegate, ok := sc.serviceProxy.(*NamingProxyDelegate); clientConfig.AsyncUpdateService || (ok && delegate.legacy) {
+		go NewServiceInfoUpdater(sc.ctx, sc.serviceInfoHolder, clientConfig.UpdateThreadNum, sc.serviceProxy).asyncUpdateService()
+	}
+}
+
 func initLogger(clientConfig constant.ClientConfig) error {
 	return logger.InitLogger(logger.BuildLoggerConfig(clientConfig))
 }
 
 // RegisterInstance ...
 func (sc *NamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.RegisterInstance(param)
+	}
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
@@ -102,6 +167,12 @@ func (sc *NamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool,
 	if param.Metadata == nil {
 		param.Metadata = make(map[string]string)
 	}
+	if err := detectIp(&param); err != nil {
+		return false, err
+	}
+	if err := preserveMetadata(&param); err != nil {
+		return false, err
+	}
 	instance := model.Instance{
 		Ip:          param.Ip,
 		Port:        param.Port,
@@ -112,10 +183,89 @@ func (sc *NamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool,
 		Weight:      param.Weight,
 		Ephemeral:   param.Ephemeral,
 	}
-	return sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
+	// the health checker of the previous registration must not report the health of the new one
+	sc.stopHealthCheck(param.ServiceName, param.GroupName, instance.Ip, instance.Port, instance.ClusterName)
+	if param.HealthSupplier == nil {
+		success, err := sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
+		if err == nil {
+			sc.recordRegistered(param.ServiceName, param.GroupName, false, instance)
+		}
+		return success, err
+	}
+	checker := &healthChecker{instance: instance, supplier: param.HealthSupplier}
+	healthy := checker.check()
+	success, err := sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, checker.reportedInstance(healthy))
+	if err != nil {
+		return success, err
+	}
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	sc.recordRegisteredLocked(param.ServiceName, param.GroupName, false, instance)
+	sc.startHealthCheck(param.ServiceName, param.GroupName, instance, param.HealthSupplier, healthy)
+	return success, nil
+}
+
+// detectIp fill the ip of the param from the sources to detect it when it's empty.
+func detectIp(param *vo.RegisterInstanceParam) error {
+	if len(param.Ip) > 0 || (len(param.IpEnv) == 0 && len(param.IpInterface) == 0 && len(param.IpPreferredNetworks) == 0) {
+		return nil
+	}
+	ip, err := util.DetectIP(param.IpEnv, param.IpInterface, param.IpPreferredNetworks)
+	if err != nil {
+		return errors.Wrap(err, "detect the ip of the instance")
+	}
+	logger.Infof("detected ip %s of the instance of service %s", ip, param.ServiceName)
+	param.Ip = ip
+	return nil
+}
+
+// preserveMetadata set the preserved metadata of the typed options of the param, the metadata of the caller
+// is copied before it's changed.
+func preserveMetadata(param *vo.RegisterInstanceParam) error {
+	preserved := map[string]string{}
+	for key, duration := range map[string]time.Duration{
+		constant.HEART_BEAT_INTERVAL: param.HeartBeatInterval,
+		constant.HEART_BEAT_TIMEOUT:  param.HeartBeatTimeout,
+		constant.IP_DELETE_TIMEOUT:   param.IpDeleteTimeout,
+	} {
+		if duration < 0 {
+			return errors.Errorf("%s cannot be negative!", key)
+		}
+		if duration > 0 {
+			preserved[key] = strconv.FormatInt(duration.Milliseconds(), 10)
+		}
+	}
+	switch param.InstanceIdGenerator {
+	case "":
+	case constant.INSTANCE_ID_GENERATOR_SIMPLE, constant.INSTANCE_ID_GENERATOR_SNOWFLAKE:
+		preserved[constant.INSTANCE_ID_GENERATOR] = param.InstanceIdGenerator
+	default:
+		return errors.Errorf("unknown instance id generator:%s", param.InstanceIdGenerator)
+	}
+	if len(preserved) == 0 {
+		return nil
+	}
+	metadata := util.DeepCopyMap(param.Metadata)
+	for key, value := range preserved {
+		metadata[key] = value
+	}
+	// the server rejects the instances whose heartbeat interval is not less than the timeouts
+	instance := model.Instance{Metadata: metadata}
+	if instance.GetHeartBeatInterval() >= instance.GetHeartBeatTimeout() || instance.GetHeartBeatInterval() >= instance.GetIpDeleteTimeout() {
+		return errors.New("heart beat interval must be less than heart beat timeout and ip delete timeout!")
+	}
+	param.Metadata = metadata
+	return nil
 }
 
 func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.BatchRegisterInstance(param)
+	}
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
@@ -130,6 +280,12 @@ func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstancePara
 		if !param.Ephemeral {
 			return false, errors.Errorf("Batch registration does not allow persistent instance registration! instance:%+v", param)
 		}
+		if err := detectIp(&param); err != nil {
+			return false, err
+		}
+		if err := preserveMetadata(&param); err != nil {
+			return false, err
+		}
 		modelInstances = append(modelInstances, model.Instance{
 			Ip:          param.Ip,
 			Port:        param.Port,
@@ -142,11 +298,22 @@ func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstancePara
 		})
 	}
 
-	return sc.serviceProxy.BatchRegisterInstance(param.ServiceName, param.GroupName, modelInstances)
+	success, err := sc.serviceProxy.BatchRegisterInstance(param.ServiceName, param.GroupName, modelInstances)
+	if err == nil {
+		sc.recordRegistered(param.ServiceName, param.GroupName, true, modelInstances...)
+	}
+	return success, err
 }
 
 // DeregisterInstance ...
 func (sc *NamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.DeregisterInstance(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
@@ -156,11 +323,58 @@ func (sc *NamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bo
 		ClusterName: param.Cluster,
 		Ephemeral:   param.Ephemeral,
 	}
-	return sc.serviceProxy.DeregisterInstance(param.ServiceName, param.GroupName, instance)
+	removed, _ := sc.removeRegistered(param.ServiceName, param.GroupName, instance)
+	success, err := sc.serviceProxy.DeregisterInstance(param.ServiceName, param.GroupName, instance)
+	if err != nil {
+		sc.restoreRegistered(param.ServiceName, param.GroupName, false, removed...)
+	}
+	return success, err
+}
+
+// BatchDeregisterInstance ...
+func (sc *NamingClient) BatchDeregisterInstance(param vo.BatchDeregisterInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.BatchDeregisterInstance(param)
+	}
+	if param.ServiceName == "" {
+		return false, errors.New("serviceName cannot be empty!")
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	if len(param.Instances) == 0 {
+		return false, errors.New("instances cannot be empty!")
+	}
+	modelInstances := make([]model.Instance, 0, len(param.Instances))
+	for _, param := range param.Instances {
+		modelInstances = append(modelInstances, model.Instance{
+			Ip:          param.Ip,
+			Port:        param.Port,
+			ClusterName: param.Cluster,
+			Ephemeral:   true,
+		})
+	}
+	removed, batch := sc.removeRegistered(param.ServiceName, param.GroupName, modelInstances...)
+	success, err := sc.serviceProxy.BatchDeregisterInstance(param.ServiceName, param.GroupName, modelInstances)
+	if err != nil {
+		sc.restoreRegistered(param.ServiceName, param.GroupName, batch, removed...)
+	}
+	return success, err
 }
 
 // UpdateInstance ...
 func (sc *NamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.UpdateInstance(param)
+	}
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
@@ -185,17 +399,83 @@ func (sc *NamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, erro
 
 }
 
+// GetLastPushTime return the time the service is pushed by the server last time, false if it is never pushed
+func (sc *NamingClient) GetLastPushTime(param vo.GetServiceParam) (time.Time, bool) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return client.GetLastPushTime(param)
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	return sc.serviceInfoHolder.GetLastPushTime(param.ServiceName, param.GroupName, strings.Join(param.Clusters, ","))
+}
+
+// UpdateInstanceMetadata patch the metadata of a registered instance without re-registering it
+func (sc *NamingClient) UpdateInstanceMetadata(param vo.UpdateInstanceMetadataParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.UpdateInstanceMetadata(param)
+	}
+	if param.ServiceName == "" {
+		return false, errors.New("serviceName cannot be empty!")
+	}
+	if len(param.Metadata) == 0 {
+		return false, errors.New("metadata cannot be empty!")
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	instance := model.Instance{
+		Ip:          param.Ip,
+		Port:        param.Port,
+		ClusterName: param.ClusterName,
+		Ephemeral:   param.Ephemeral,
+		Metadata:    param.Metadata,
+	}
+	success, err := sc.serviceProxy.UpdateInstanceMetadata(param.ServiceName, param.GroupName, instance)
+	if err != nil {
+		return success, err
+	}
+	patch := func(registered *model.Instance) {
+		for k, v := range param.Metadata {
+			registered.Metadata[k] = v
+		}
+	}
+	sc.updateRegistered(param.ServiceName, param.GroupName, instance, patch)
+	sc.updateHealthChecker(param.ServiceName, param.GroupName, instance, patch)
+	return success, nil
+}
+
 // GetService Get service info by Group and DataId, clusters was optional
 func (sc *NamingClient) GetService(param vo.GetServiceParam) (service model.Service, err error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return service, err
+		}
+		return client.GetService(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
+	filter, err := sc.labelFilter(param.Selector)
+	if err != nil {
+		return service, err
+	}
 	var ok bool
 	clusters := strings.Join(param.Clusters, ",")
 	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
 	if !ok {
 		service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
 	}
+	service.Hosts = filterInstances(service.Hosts, filter)
 	return service, err
 }
 
@@ -217,15 +497,22 @@ func (sc *NamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (mod
 }
 
 // SelectAllInstances Get all instance by DataId 和 Group
-func (sc *NamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
+func (sc *NamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) (_ []model.Instance, err error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return nil, err
+		}
+		return client.SelectAllInstances(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
+	defer observeSelect(param.ServiceName, param.GroupName, time.Now(), &err)
 	clusters := strings.Join(param.Clusters, ",")
 	var (
 		service model.Service
 		ok      bool
-		err     error
 	)
 
 	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
@@ -235,31 +522,89 @@ func (sc *NamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]
 	if err != nil || service.Hosts == nil || len(service.Hosts) == 0 {
 		return []model.Instance{}, err
 	}
-	return service.Hosts, err
+	// the hosts are shared by the cache
+	return append([]model.Instance(nil), service.Hosts...), err
 }
 
 // SelectInstances Get all instance by DataId, Group and Health
-func (sc *NamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+func (sc *NamingClient) SelectInstances(param vo.SelectInstancesParam) (_ []model.Instance, err error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return nil, err
+		}
+		return client.SelectInstances(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
+	defer observeSelect(param.ServiceName, param.GroupName, time.Now(), &err)
 	var (
 		service model.Service
 		ok      bool
-		err     error
 	)
 	clusters := strings.Join(param.Clusters, ",")
 	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
+	if !ok && param.CacheOnly {
+		return nil, ErrServiceNotCached
+	}
 	if !ok {
 		service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
 		if err != nil {
 			return nil, err
 		}
+	} else if len(param.MetadataSelector) == 0 && param.SubsetSize <= 0 {
+		return sc.cachedSelectInstances(param.ServiceName, param.GroupName, clusters, service, param.HealthyOnly)
+	}
+	instances, err := sc.selectInstancesWithFilter(service, param.HealthyOnly, metadataFilter(param.MetadataSelector))
+	if err == nil && param.HealthyOnly && param.SubsetSize > 0 {
+		instances = subsetInstances(instances, param.SubsetSize, sc.subsetKey(param.SubsetKey))
+	}
+	return instances, err
+}
+
+// SelectInstancesWithFilter Get the instances by DataId, Group and Health which the filter accepts, the filter
+// is called on the cached instances.
+func (sc *NamingClient) SelectInstancesWithFilter(param vo.SelectInstancesParam, filter func(instance model.Instance) bool) (_ []model.Instance, err error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return nil, err
+		}
+		return client.SelectInstancesWithFilter(param, filter)
+	}
+	if filter == nil {
+		return nil, errors.New("filter cannot be nil!")
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	defer observeSelect(param.ServiceName, param.GroupName, time.Now(), &err)
+	clusters := strings.Join(param.Clusters, ",")
+	service, ok := sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
+	if !ok && param.CacheOnly {
+		return nil, ErrServiceNotCached
+	}
+	if !ok {
+		if service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters); err != nil {
+			return nil, err
+		}
 	}
-	return sc.selectInstances(service, param.HealthyOnly)
+	selector := metadataFilter(param.MetadataSelector)
+	return sc.selectInstancesWithFilter(service, param.HealthyOnly, func(instance model.Instance) bool {
+		return (selector == nil || selector(instance)) && filter(instance)
+	})
+}
+
+func observeSelect(serviceName, groupName string, start time.Time, err *error) {
+	monitor.GetNamingMetrics().ObserveSelect(serviceName, groupName, time.Since(start), *err)
 }
 
 func (sc *NamingClient) selectInstances(service model.Service, healthy bool) ([]model.Instance, error) {
+	return sc.selectInstancesWithFilter(service, healthy, nil)
+}
+
+func (sc *NamingClient) selectInstancesWithFilter(service model.Service, healthy bool, filter func(instance model.Instance) bool) ([]model.Instance, error) {
 	if service.Hosts == nil || len(service.Hosts) == 0 {
 		return []model.Instance{}, errors.New("instance list is empty!")
 	}
@@ -267,22 +612,44 @@ func (sc *NamingClient) selectInstances(service model.Service, healthy bool) ([]
 	var result []model.Instance
 	logger.Infof("select instances with options: [healthy:<%s>], with service:<%s>", healthy, util.GetGroupName(service.Name, service.GroupName))
 	for _, host := range hosts {
-		if host.Healthy == healthy && host.Enable && host.Weight > 0 {
+		if host.Healthy == healthy && host.Enable && host.Weight > 0 && (filter == nil || filter(host)) {
 			result = append(result, host)
 		}
 	}
 	return result, nil
 }
 
+// metadataFilter return the filter of the instances having all the metadata, nil for the empty selector.
+func metadataFilter(selector map[string]string) func(instance model.Instance) bool {
+	if len(selector) == 0 {
+		return nil
+	}
+	return func(instance model.Instance) bool {
+		for k, v := range selector {
+			if value, ok := instance.Metadata[k]; !ok || value != v {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // SelectOneHealthyInstance Get one healthy instance by DataId and Group
-func (sc *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
+func (sc *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (_ *model.Instance, err error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return nil, err
+		}
+		return client.SelectOneHealthyInstance(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
+	defer observeSelect(param.ServiceName, param.GroupName, time.Now(), &err)
 	var (
 		service model.Service
 		ok      bool
-		err     error
 	)
 	clusters := strings.Join(param.Clusters, ",")
 	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
@@ -293,10 +660,67 @@ func (sc *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanc
 		}
 	}
 
-	return sc.selectOneHealthyInstances(service)
+	balancer, err := GetBalancer(param.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	result, err := healthyInstances(service)
+	if err != nil {
+		return nil, err
+	}
+	if selector := metadataFilter(param.MetadataSelector); selector != nil {
+		var selected []model.Instance
+		for _, instance := range result {
+			if selector(instance) {
+				selected = append(selected, instance)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errors.New("no healthy instance matches the metadata selector!")
+		}
+		result = selected
+	}
+	if param.SubsetSize > 0 {
+		result = subsetInstances(result, param.SubsetSize, sc.subsetKey(param.SubsetKey))
+	}
+	if len(param.Excluded) > 0 {
+		if result = excludeInstances(result, param.Excluded); len(result) == 0 {
+			return nil, errors.New("all the healthy instances are excluded!")
+		}
+	}
+	instance := balancer.Pick(param, result)
+	return &instance, nil
+}
+
+// excludeInstances return the instances whose ip:port is not in the excluded addresses.
+func excludeInstances(instances []model.Instance, excluded []string) []model.Instance {
+	result := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		address := net.JoinHostPort(instance.Ip, strconv.FormatUint(instance.Port, 10))
+		skip := false
+		for _, e := range excluded {
+			if e == address {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, instance)
+		}
+	}
+	return result
 }
 
 func (sc *NamingClient) selectOneHealthyInstances(service model.Service) (*model.Instance, error) {
+	result, err := healthyInstances(service)
+	if err != nil {
+		return nil, err
+	}
+	instance := newChooser(result).pick()
+	return &instance, nil
+}
+
+func healthyInstances(service model.Service) ([]model.Instance, error) {
 	if service.Hosts == nil || len(service.Hosts) == 0 {
 		return nil, errors.New("instance list is empty!")
 	}
@@ -315,32 +739,110 @@ func (sc *NamingClient) selectOneHealthyInstances(service model.Service) (*model
 	if len(result) == 0 {
 		return nil, errors.New("healthy instance list is empty!")
 	}
-
-	instance := newChooser(result).pick()
-	return &instance, nil
+	return result, nil
 }
 
 // Subscribe ...
 func (sc *NamingClient) Subscribe(param *vo.SubscribeParam) error {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return err
+		}
+		return client.Subscribe(param)
+	}
 	if len(param.GroupName) == 0 {
 		param.GroupName = constant.DEFAULT_GROUP
 	}
+	if param.SubscribeCallback == nil && param.SubscribeDiffCallback == nil {
+		return errors.New("subscribeCallback cannot be nil!")
+	}
+	filter, err := sc.labelFilter(param.Selector)
+	if err != nil {
+		return err
+	}
 	clusters := strings.Join(param.Clusters, ",")
-	sc.serviceInfoHolder.RegisterCallback(util.GetGroupName(param.ServiceName, param.GroupName), clusters, &param.SubscribeCallback)
-	_, err := sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
+	serviceFullName := util.GetGroupName(param.ServiceName, param.GroupName)
+	key := subscriptionKey{param: param, service: serviceFullName}
+	sc.subscribeMutex.Lock()
+	old, resubscribe := sc.subscriptions[key]
+	if sc.subscriptions == nil {
+		sc.subscriptions = make(map[subscriptionKey]subscription)
+	}
+	sub := old
+	if !resubscribe {
+		sub = sc.newSubscription(param, filter)
+	}
+	sub.clusters = clusters
+	sc.subscriptions[key] = sub
+	sc.subscribeMutex.Unlock()
+	if resubscribe && old.clusters != clusters {
+		// the clusters of the param are changed, move the callbacks to the new clusters
+		if err := sc.unsubscribe(param, old); err != nil {
+			logger.Warnf("unsubscribe service:%s clusters:%s failed: %v", serviceFullName, old.clusters, err)
+		}
+	}
+	if sub.callback != nil {
+		sc.serviceInfoHolder.RegisterCallback(serviceFullName, clusters, sub.callback)
+	}
+	if sub.diffCallback != nil {
+		sc.serviceInfoHolder.RegisterDiffCallback(serviceFullName, clusters, sub.diffCallback)
+	}
+	_, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
 	return err
 }
 
 // Unsubscribe ...
 func (sc *NamingClient) Unsubscribe(param *vo.SubscribeParam) (err error) {
-	clusters := strings.Join(param.Clusters, ",")
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return err
+		}
+		return client.Unsubscribe(param)
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	key := subscriptionKey{param: param, service: util.GetGroupName(param.ServiceName, param.GroupName)}
+	sc.subscribeMutex.Lock()
+	sub, ok := sc.subscriptions[key]
+	if ok {
+		delete(sc.subscriptions, key)
+	} else {
+		sub = subscription{clusters: strings.Join(param.Clusters, ","), callback: &param.SubscribeCallback}
+		if param.SubscribeDiffCallback != nil {
+			sub.diffCallback = &param.SubscribeDiffCallback
+		}
+	}
+	sc.subscribeMutex.Unlock()
+	return sc.unsubscribe(param, sub)
+}
+
+// unsubscribe remove the callbacks of the subscription, the service is unsubscribed when no callback is left.
+func (sc *NamingClient) unsubscribe(param *vo.SubscribeParam, sub subscription) error {
 	serviceFullName := util.GetGroupName(param.ServiceName, param.GroupName)
-	sc.serviceInfoHolder.DeregisterCallback(serviceFullName, clusters, &param.SubscribeCallback)
+	clusters := sub.clusters
+	if sub.callback != nil {
+		sc.serviceInfoHolder.DeregisterCallback(serviceFullName, clusters, sub.callback)
+	}
+	if sub.diffCallback != nil {
+		sc.serviceInfoHolder.DeregisterDiffCallback(serviceFullName, clusters, sub.diffCallback)
+	}
 	if sc.serviceInfoHolder.IsSubscribed(serviceFullName, clusters) {
-		err = sc.serviceProxy.Unsubscribe(param.ServiceName, param.GroupName, clusters)
+		return nil
 	}
+	return sc.serviceProxy.Unsubscribe(param.ServiceName, param.GroupName, clusters)
+}
 
-	return err
+// DumpCache return a snapshot of the services in the cache for debugging or warming up another client
+func (sc *NamingClient) DumpCache() []model.Service {
+	return sc.serviceInfoHolder.DumpServices()
+}
+
+// LoadCache warm up the cache with a snapshot returned by DumpCache, the services already cached are kept
+func (sc *NamingClient) LoadCache(snapshot []model.Service) int {
+	return sc.serviceInfoHolder.LoadServices(snapshot)
 }
 
 // ServerHealthy ...
@@ -348,8 +850,55 @@ func (sc *NamingClient) ServerHealthy() bool {
 	return sc.serviceProxy.ServerHealthy()
 }
 
-// CloseClient ...
+// ActiveProtocol ...
+func (sc *NamingClient) ActiveProtocol() string {
+	if delegate, ok := sc.serviceProxy.(*NamingProxyDelegate); ok && delegate.legacy {
+		return constant.NAMING_COMPAT_MODE_HTTP
+	}
+	return constant.GRPC
+}
+
+// WatchConnection ...
+func (sc *NamingClient) WatchConnection(param *vo.WatchConnectionParam) error {
+	if param.Callback == nil {
+		return errors.New("callback cannot be nil!")
+	}
+	return sc.serviceProxy.WatchConnection(&param.Callback)
+}
+
+// UnwatchConnection ...
+func (sc *NamingClient) UnwatchConnection(param *vo.WatchConnectionParam) error {
+	sc.serviceProxy.UnwatchConnection(&param.Callback)
+	return nil
+}
+
+// AddInterceptor ...
+func (sc *NamingClient) AddInterceptor(interceptor rpc.Interceptor) error {
+	if interceptor == nil {
+		return errors.New("interceptor cannot be nil!")
+	}
+	return sc.serviceProxy.AddInterceptor(interceptor)
+}
+
+// RegisterServerRequestHandler ...
+func (sc *NamingClient) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	if request == nil || handler == nil {
+		return errors.New("request and handler cannot be nil!")
+	}
+	if requestType := request().GetRequestType(); rpc.IsBuiltInServerRequest(requestType) {
+		return errors.Errorf("%s is handled by the client", requestType)
+	}
+	return sc.serviceProxy.RegisterServerRequestHandler(request, handler)
+}
+
+// CloseClient close the clients of the other namespaces and the connection, it's a no-op once the client is
+// closed, e.g. by Shutdown.
 func (sc *NamingClient) CloseClient() {
-	sc.serviceProxy.CloseClient()
-	sc.cancel()
+	sc.closeOnce.Do(func() {
+		for _, client := range sc.namespaceClients() {
+			client.CloseClient()
+		}
+		sc.serviceProxy.CloseClient()
+		sc.cancel()
+	})
 }
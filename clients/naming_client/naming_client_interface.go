@@ -17,6 +17,11 @@
 package naming_client
 
 import (
+	"context"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/vo"
 )
@@ -36,7 +41,9 @@ type INamingClient interface {
 	// ClusterName  optional,default:DEFAULT
 	// ServiceName require
 	// GroupName optional,default:DEFAULT_GROUP
+	// NamespaceId optional,default:the namespace of the client, the other namespaces share its connection
 	// Ephemeral optional
+	// HealthSupplier optional,the instance is re-registered as unhealthy and disabled while it returns false
 	RegisterInstance(param vo.RegisterInstanceParam) (bool, error)
 
 	// BatchRegisterInstance use to batch register instance
@@ -56,6 +63,12 @@ type INamingClient interface {
 	// Ephemeral optional
 	DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error)
 
+	// BatchDeregisterInstance use to deregister instances registered by BatchRegisterInstance
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	// Instances require,the ip and port of the instances to deregister, the others registered are kept
+	BatchDeregisterInstance(param vo.BatchDeregisterInstanceParam) (bool, error)
+
 	// UpdateInstance use to update instance
 	// Ip  require
 	// Port  require
@@ -69,6 +82,76 @@ type INamingClient interface {
 	// Ephemeral optional
 	UpdateInstance(param vo.UpdateInstanceParam) (bool, error)
 
+	// GetLastPushTime return the time the service is pushed by the server last time, false if it is never pushed
+	// Clusters optional,default:DEFAULT
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	GetLastPushTime(param vo.GetServiceParam) (time.Time, bool)
+
+	// ListRegisteredInstances return the instances registered by the client, each of them can be deregistered
+	// on its own, the other instances of the same service are kept
+	ListRegisteredInstances() []*RegisteredInstance
+
+	// WatchInstanceHealth use to be notified when the server sees an instance registered by the client turn
+	// healthy, unhealthy or missing
+	// IntervalMs optional,default:BeatInterval
+	// Callback require
+	WatchInstanceHealth(param *vo.WatchInstanceHealthParam) error
+
+	// UnwatchInstanceHealth use to stop the notifications of the param passed to WatchInstanceHealth
+	UnwatchInstanceHealth(param *vo.WatchInstanceHealthParam) error
+
+	// SetInstanceWeight change the weight of an instance registered by the client
+	// Ip  require
+	// Port  require
+	// ClusterName  optional,default:DEFAULT
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	// Weight  require,0 to stop the traffic
+	SetInstanceWeight(param vo.SetInstanceWeightParam) (bool, error)
+
+	// EnableInstance enable an instance registered by the client
+	EnableInstance(param vo.ToggleInstanceParam) (bool, error)
+
+	// DisableInstance disable an instance registered by the client, it keeps registered
+	DisableInstance(param vo.ToggleInstanceParam) (bool, error)
+
+	// UpdateInstanceMetadata use to patch the metadata of a registered instance
+	// Ip  require
+	// Port  require
+	// ClusterName  optional,default:DEFAULT
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	// Ephemeral optional
+	// Metadata require,the keys not in it are kept
+	UpdateInstanceMetadata(param vo.UpdateInstanceMetadataParam) (bool, error)
+
+	// CreateService use to create a service with the service level settings
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	// ProtectThreshold optional,default:0
+	// Metadata optional
+	// Selector optional,default:none
+	CreateService(param vo.CreateServiceParam) (bool, error)
+
+	// UpdateService use to update the service level settings of a service
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	// ProtectThreshold optional,default:0
+	// Metadata optional,it replaces the metadata of the service
+	// Selector optional,default:none
+	UpdateService(param vo.UpdateServiceParam) (bool, error)
+
+	// DeleteService use to delete a service, it fails when the service has instances
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	DeleteService(param vo.DeleteServiceParam) (bool, error)
+
+	// GetServiceDetail use to get the service level settings and the clusters of a service
+	// ServiceName require
+	// GroupName optional,default:DEFAULT_GROUP
+	GetServiceDetail(param vo.GetServiceDetailParam) (model.ServiceDefinition, error)
+
 	// GetService use to get service
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
@@ -86,9 +169,15 @@ type INamingClient interface {
 	// Clusters optional,default:DEFAULT
 	// GroupName optional,default:DEFAULT_GROUP
 	// HealthyOnly optional
+	// MetadataSelector optional
+	// CacheOnly optional,only select from the cache without subscribing
 	SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error)
 
-	// SelectOneHealthyInstance return one instance by WRR strategy for load balance
+	// SelectInstancesWithFilter return the instances of SelectInstances which the filter accepts
+	// The filter is called on the cached instances, MetadataSelector is applied as well
+	SelectInstancesWithFilter(param vo.SelectInstancesParam, filter func(instance model.Instance) bool) ([]model.Instance, error)
+
+	// SelectOneHealthyInstance return one instance by the balancer of Strategy, default is WRR strategy for load balance
 	// And the instance should be health=true,enable=true and weight>0
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
@@ -99,7 +188,8 @@ type INamingClient interface {
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
 	// GroupName optional,default:DEFAULT_GROUP
-	// SubscribeCallback require
+	// SubscribeCallback require,unless SubscribeDiffCallback is set
+	// SubscribeDiffCallback optional,receive the added, removed and modified instances as well
 	Subscribe(param *vo.SubscribeParam) error
 
 	// Unsubscribe use to unsubscribe service change event
@@ -112,9 +202,70 @@ type INamingClient interface {
 	// GetAllServicesInfo use to get all service info by page
 	GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error)
 
+	// IterateServices use to iterate all the services of a group without managing the pages
+	// NameSpace optional,default:public
+	// GroupName optional,default:DEFAULT_GROUP
+	// PageSize optional,default:500
+	// Parallelism optional,the number of goroutines fetching the pages
+	IterateServices(param vo.ServicesIteratorParam) *ServicesIterator
+
+	// GetAllServiceNames use to get the names of all the services of a group
+	GetAllServiceNames(param vo.ServicesIteratorParam) ([]string, error)
+
+	// DumpCache use to get a snapshot of the services in the cache, it can be marshaled to json
+	DumpCache() []model.Service
+
+	// LoadCache use to warm up the cache with a snapshot of another client before the first server sync,
+	// the services already cached are kept, it returns the number of the services loaded
+	LoadCache(snapshot []model.Service) int
+
+	// WatchServices use to watch the services of a group being created or removed
+	// NameSpace optional,default:public
+	// GroupName optional,default:DEFAULT_GROUP
+	// Callback require
+	WatchServices(param *vo.WatchServicesParam) error
+
+	// UnwatchServices use to remove the callback of WatchServices
+	UnwatchServices(param *vo.WatchServicesParam) error
+
 	// ServerHealthy use to check the connectivity to server
 	ServerHealthy() bool
 
+	// ActiveProtocol use to get the protocol of the naming requests, grpc, or http with the udp pushes of the 1.x servers
+	ActiveProtocol() string
+
+	// WatchConnection use to be notified when the grpc connection is connected, disconnected, reconnected to
+	// the same server or switched to another one, it's not supported by the http compat mode
+	// Callback require,it must not block
+	WatchConnection(param *vo.WatchConnectionParam) error
+
+	// UnwatchConnection use to remove the callback of WatchConnection
+	UnwatchConnection(param *vo.WatchConnectionParam) error
+
+	// AddInterceptor use to install an interceptor called around every grpc request, e.g. for metrics, tracing,
+	// audit logging or chaos injection, the interceptors are shared by the clients of the other namespaces,
+	// it's not supported by the http compat mode
+	AddInterceptor(interceptor rpc.Interceptor) error
+
+	// RegisterServerRequestHandler use to handle the server push requests of a custom type, request returns a new
+	// request of the type to decode the push into, the built-in types handled by the client are rejected, it's
+	// shared by the clients of the other namespaces and not supported by the http compat mode
+	RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error
+
 	//CloseClient close the GRPC client
 	CloseClient()
+
+	// Shutdown disable the instances registered by the client, wait the drain window,
+	// then deregister them and close the client
+	Shutdown(ctx context.Context, drain time.Duration) error
+
+	// ShutdownOnSignal use to Shutdown the client and exit when the process receives the signals, the returned
+	// func stops handling them
+	// Signals optional,default:SIGTERM and SIGINT
+	// DrainMs optional
+	// TimeoutMs optional,default:unlimited
+	// BeforeShutdown optional,run before disabling the instances
+	// AfterShutdown optional,run after closing the client
+	// Exit optional,default:os.Exit
+	ShutdownOnSignal(param vo.ShutdownOnSignalParam) (stop func())
 }
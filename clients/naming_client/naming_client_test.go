@@ -17,14 +17,23 @@
 package naming_client
 
 import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
 
 	"github.com/jun3372/nacos-sdk-go/clients/nacos_client"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
 	"github.com/jun3372/nacos-sdk-go/vo"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,6 +56,14 @@ func (m *MockNamingProxy) BatchRegisterInstance(serviceName string, groupName st
 	return true, nil
 }
 
+func (m *MockNamingProxy) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	return true, nil
+}
+
+func (m *MockNamingProxy) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	return true, nil
+}
+
 func (m *MockNamingProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	return true, nil
 }
@@ -71,6 +88,36 @@ func (m *MockNamingProxy) Unsubscribe(serviceName, groupName, clusters string) e
 	return nil
 }
 
+func (m *MockNamingProxy) CreateService(service model.ServiceDefinition) (bool, error) {
+	return true, nil
+}
+
+func (m *MockNamingProxy) UpdateService(service model.ServiceDefinition) (bool, error) {
+	return true, nil
+}
+
+func (m *MockNamingProxy) DeleteService(serviceName, groupName string) (bool, error) {
+	return true, nil
+}
+
+func (m *MockNamingProxy) GetServiceDetail(serviceName, groupName string) (model.ServiceDefinition, error) {
+	return model.ServiceDefinition{Name: serviceName, GroupName: groupName}, nil
+}
+
+func (m *MockNamingProxy) WatchConnection(callback *func(event model.ConnectionEvent)) error {
+	return nil
+}
+
+func (m *MockNamingProxy) UnwatchConnection(callback *func(event model.ConnectionEvent)) {}
+
+func (m *MockNamingProxy) AddInterceptor(interceptor rpc.Interceptor) error {
+	return nil
+}
+
+func (m *MockNamingProxy) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	return nil
+}
+
 func (m *MockNamingProxy) CloseClient() {}
 
 func NewTestNamingClient() *NamingClient {
@@ -140,6 +187,18 @@ func TestNamingProxy_DeregisterService_WithGroupName(t *testing.T) {
 	assert.Equal(t, true, success)
 }
 
+func TestNamingProxy_BatchDeregisterService(t *testing.T) {
+	client := NewTestNamingClient()
+	_, err := client.BatchDeregisterInstance(vo.BatchDeregisterInstanceParam{ServiceName: "DEMO7"})
+	assert.NotNil(t, err)
+	success, err := client.BatchDeregisterInstance(vo.BatchDeregisterInstanceParam{
+		ServiceName: "DEMO7",
+		Instances:   []vo.DeregisterInstanceParam{{Ip: "10.0.0.10", Port: 80}, {Ip: "10.0.0.11", Port: 80}},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, success)
+}
+
 func TestNamingClient_SelectOneHealthyInstance_SameWeight(t *testing.T) {
 	services := model.Service{
 		Name:        "DEFAULT_GROUP@@DEMO",
@@ -211,6 +270,92 @@ func TestNamingClient_SelectOneHealthyInstance_SameWeight(t *testing.T) {
 	assert.NotNil(t, instance2)
 }
 
+func TestNamingClient_Balancers(t *testing.T) {
+	instances := []model.Instance{
+		{Ip: "10.10.10.12", Port: 80, Weight: 1, Metadata: map[string]string{"zone": "b"}},
+		{Ip: "10.10.10.10", Port: 80, Weight: 1, Metadata: map[string]string{"zone": "a"}},
+		{Ip: "10.10.10.11", Port: 80, Weight: 1, Metadata: map[string]string{"zone": "a"}},
+	}
+	_, err := GetBalancer("unknown")
+	assert.NotNil(t, err)
+
+	roundRobin, err := GetBalancer(constant.BALANCER_ROUND_ROBIN)
+	assert.Nil(t, err)
+	param := vo.SelectOneHealthInstanceParam{ServiceName: "DEMO-RR", GroupName: constant.DEFAULT_GROUP}
+	for _, ip := range []string{"10.10.10.10", "10.10.10.11", "10.10.10.12", "10.10.10.10"} {
+		assert.Equal(t, ip, roundRobin.Pick(param, instances).Ip)
+	}
+
+	leastConnections := NewLeastConnectionsBalancer()
+	first := leastConnections.Pick(param, instances)
+	second := leastConnections.Pick(param, instances)
+	assert.NotEqual(t, first.Ip, second.Ip)
+	leastConnections.Done(first)
+	assert.Equal(t, first.Ip, leastConnections.Pick(param, instances).Ip)
+
+	consistentHash, err := GetBalancer(constant.BALANCER_CONSISTENT_HASH)
+	assert.Nil(t, err)
+	param.HashKey = "user-1"
+	picked := consistentHash.Pick(param, instances)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, picked.Ip, consistentHash.Pick(param, instances).Ip)
+	}
+	// only the keys of the removed instance move.
+	for i := 0; i < 100; i++ {
+		param.HashKey = "user-" + strconv.Itoa(i)
+		before := consistentHash.Pick(param, instances)
+		after := consistentHash.Pick(param, instances[1:])
+		if before.Ip != instances[0].Ip {
+			assert.Equal(t, before.Ip, after.Ip)
+		}
+	}
+
+	zoneAffinity, err := GetBalancer(constant.BALANCER_ZONE_AFFINITY)
+	assert.Nil(t, err)
+	param.Zone = "b"
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "10.10.10.12", zoneAffinity.Pick(param, instances).Ip)
+	}
+	param.Zone = "c"
+	assert.NotEmpty(t, zoneAffinity.Pick(param, instances).Ip)
+}
+
+func TestOutlierEjectionBalancer(t *testing.T) {
+	instances := []model.Instance{
+		{Ip: "10.10.10.10", Port: 80, Weight: 1, ServiceName: "DEFAULT_GROUP@@DEMO"},
+		{Ip: "10.10.10.11", Port: 80, Weight: 1, ServiceName: "DEFAULT_GROUP@@DEMO"},
+		{Ip: "10.10.10.12", Port: 80, Weight: 1, ServiceName: "DEFAULT_GROUP@@DEMO"},
+	}
+	now := time.Now()
+	b := NewOutlierEjectionBalancer(&roundRobinBalancer{}, OutlierEjectionConfig{MinRequests: 2, Interval: time.Second,
+		BaseEjectionTime: time.Minute, MaxEjectionTime: 3 * time.Minute})
+	b.now = func() time.Time { return now }
+	param := vo.SelectOneHealthInstanceParam{ServiceName: "DEMO", GroupName: constant.DEFAULT_GROUP}
+
+	b.Feedback(instances[0], nil, time.Millisecond)
+	b.Feedback(instances[0], errors.New("refused"), time.Millisecond)
+	for i := 0; i < 6; i++ {
+		assert.NotEqual(t, "10.10.10.10", b.Pick(param, instances).Ip)
+	}
+	// at most one instance is ejected by the max ejection percent, the latest ejection wins
+	now = now.Add(time.Millisecond)
+	b.Feedback(instances[1], errors.New("refused"), time.Millisecond)
+	b.Feedback(instances[1], errors.New("refused"), time.Millisecond)
+	for i := 0; i < 6; i++ {
+		assert.NotEqual(t, "10.10.10.11", b.Pick(param, instances).Ip)
+	}
+
+	// the ejection time doubles when the instance keeps failing
+	now = now.Add(time.Minute)
+	b.Feedback(instances[0], errors.New("refused"), time.Millisecond)
+	b.Feedback(instances[0], errors.New("refused"), time.Millisecond)
+	assert.Equal(t, now.Add(2*time.Minute), b.stats[outlierKey(instances[0])].ejectedUntil)
+	now = now.Add(2 * time.Minute)
+	b.Feedback(instances[0], errors.New("refused"), time.Millisecond)
+	b.Feedback(instances[0], errors.New("refused"), time.Millisecond)
+	assert.Equal(t, now.Add(3*time.Minute), b.stats[outlierKey(instances[0])].ejectedUntil)
+}
+
 func TestNamingClient_SelectOneHealthyInstance_Empty(t *testing.T) {
 	services := model.Service{
 		Name:        "DEFAULT_GROUP@@DEMO",
@@ -223,6 +368,24 @@ func TestNamingClient_SelectOneHealthyInstance_Empty(t *testing.T) {
 	assert.Nil(t, instance)
 }
 
+func TestNamingClient_SelectOneHealthyInstance_Excluded(t *testing.T) {
+	client := NewTestNamingClient()
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{
+			{Ip: "10.0.0.1", Port: 80, Weight: 1, Enable: true, Healthy: true},
+			{Ip: "10.0.0.2", Port: 80, Weight: 1, Enable: true, Healthy: true},
+		}})
+	param := vo.SelectOneHealthInstanceParam{ServiceName: "DEMO", Excluded: []string{"10.0.0.1:80"}}
+	for i := 0; i < 10; i++ {
+		instance, err := client.SelectOneHealthyInstance(param)
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.2", instance.Ip)
+	}
+	param.Excluded = append(param.Excluded, "10.0.0.2:80")
+	_, err := client.SelectOneHealthyInstance(param)
+	assert.NotNil(t, err)
+}
+
 func TestNamingClient_SelectInstances_Healthy(t *testing.T) {
 	services := model.Service{
 		Name:        "DEFAULT_GROUP@@DEMO",
@@ -291,6 +454,65 @@ func TestNamingClient_SelectInstances_Healthy(t *testing.T) {
 	assert.Equal(t, 2, len(instances))
 }
 
+func TestNamingClient_SelectInstances_Cached(t *testing.T) {
+	client := NewTestNamingClient()
+	client.selectCacheTtl = time.Minute
+	hosts := []model.Instance{
+		{Ip: "10.0.0.10", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"zone": "a"}},
+		{Ip: "10.0.0.11", Port: 80, Weight: 1, Healthy: false, Enable: true},
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts})
+	param := vo.SelectInstancesParam{ServiceName: "DEMO", HealthyOnly: true}
+	first, err := client.SelectInstances(param)
+	assert.Nil(t, err)
+	second, err := client.SelectInstances(param)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(second))
+	assert.Equal(t, first, second)
+	// the callers receive copies of the cached results
+	first[0].Ip = "10.0.0.99"
+	second, err = client.SelectInstances(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.10", second[0].Ip)
+	all, err := client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	all[0].Ip = "10.0.0.99"
+	cached, _ := client.serviceInfoHolder.GetServiceInfo("DEMO", "DEFAULT_GROUP", "")
+	assert.Equal(t, "10.0.0.10", cached.Hosts[0].Ip)
+
+	unhealthy, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.11", unhealthy[0].Ip)
+
+	// a push invalidates the results
+	hosts = append([]model.Instance{}, hosts...)
+	hosts[1].Healthy = true
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 2, Hosts: hosts})
+	third, err := client.SelectInstances(param)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(third))
+
+	selected, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "DEMO", HealthyOnly: true,
+		MetadataSelector: map[string]string{"zone": "a"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(selected))
+}
+
+func BenchmarkNamingClient_SelectInstances_Cached(b *testing.B) {
+	client := NewTestNamingClient()
+	client.selectCacheTtl = time.Minute
+	hosts := make([]model.Instance, 0, 100)
+	for i := 0; i < 100; i++ {
+		hosts = append(hosts, model.Instance{Ip: "10.0.0." + strconv.Itoa(i), Port: 80, Weight: 1, Healthy: i%2 == 0, Enable: true})
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts})
+	param := vo.SelectInstancesParam{ServiceName: "DEMO", HealthyOnly: true}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = client.SelectInstances(param)
+	}
+}
+
 func TestNamingClient_SelectInstances_Unhealthy(t *testing.T) {
 	services := model.Service{
 		Name:        "DEFAULT_GROUP@@DEMO",
@@ -371,6 +593,38 @@ func TestNamingClient_SelectInstances_Empty(t *testing.T) {
 	assert.Equal(t, 0, len(instances))
 }
 
+func TestNamingClient_SelectInstances_WithFilter(t *testing.T) {
+	services := model.Service{
+		Name:        "DEFAULT_GROUP@@DEMO",
+		CacheMillis: 1000,
+		Hosts: []model.Instance{
+			{Ip: "10.10.10.10", Port: 80, Weight: 1, Enable: true, Healthy: true, ClusterName: "a",
+				Metadata: map[string]string{"version": "v1", "region": "us-east"}},
+			{Ip: "10.10.10.11", Port: 80, Weight: 1, Enable: true, Healthy: true, ClusterName: "b",
+				Metadata: map[string]string{"version": "v2", "region": "us-east"}},
+			{Ip: "10.10.10.12", Port: 80, Weight: 1, Enable: true, Healthy: true, ClusterName: "b",
+				Metadata: map[string]string{"version": "v2", "region": "us-west"}},
+		},
+		LastRefTime: 1528787794594, Clusters: "a"}
+	client := NewTestNamingClient()
+
+	selector := metadataFilter(map[string]string{"version": "v2", "region": "us-east"})
+	instances, err := client.selectInstancesWithFilter(services, true, selector)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Equal(t, "10.10.10.11", instances[0].Ip)
+
+	instances, err = client.selectInstancesWithFilter(services, true, func(instance model.Instance) bool {
+		return instance.ClusterName == "b"
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(instances))
+
+	assert.Nil(t, metadataFilter(nil))
+	_, err = client.SelectInstancesWithFilter(vo.SelectInstancesParam{ServiceName: "DEMO"}, nil)
+	assert.NotNil(t, err)
+}
+
 func TestNamingClient_GetAllServicesInfo(t *testing.T) {
 	result, err := NewTestNamingClient().GetAllServicesInfo(vo.GetAllServiceInfoParam{
 		GroupName: "DEFAULT_GROUP",
@@ -452,3 +706,768 @@ func BenchmarkNamingClient_SelectOneHealthyInstances(b *testing.B) {
 	}
 
 }
+
+type servicesNamingProxy struct {
+	MockNamingProxy
+	mutex    sync.Mutex
+	services []string
+}
+
+func (m *servicesNamingProxy) GetServiceList(pageNo uint32, pageSize uint32, groupName, namespaceId string, selector *model.ExpressionSelector) (model.ServiceList, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return model.ServiceList{Count: int64(len(m.services)), Doms: m.services}, nil
+}
+
+func (m *servicesNamingProxy) setServices(services ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.services = services
+}
+
+func TestNamingClient_WatchServices(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &servicesNamingProxy{services: []string{"service-a", "service-b"}}
+	client.serviceProxy = proxy
+	assert.NotNil(t, client.WatchServices(&vo.WatchServicesParam{}))
+
+	type change struct {
+		added, removed []string
+	}
+	changes := make(chan change, 4)
+	param := &vo.WatchServicesParam{
+		Callback: func(added, removed []string, err error) {
+			changes <- change{added: added, removed: removed}
+		},
+	}
+	assert.Nil(t, client.WatchServices(param))
+	assert.Equal(t, change{added: []string{"service-a", "service-b"}}, <-changes)
+
+	proxy.setServices("service-b", "service-c")
+	watcher := client.servicesWatchers[client.servicesWatchKey(param)]
+	client.syncServices(watcher)
+	assert.Equal(t, change{added: []string{"service-c"}, removed: []string{"service-a"}}, <-changes)
+	client.syncServices(watcher)
+	assert.Equal(t, 0, len(changes))
+
+	assert.Nil(t, client.UnwatchServices(param))
+	assert.Equal(t, 0, len(client.servicesWatchers))
+}
+
+type recordNamingProxy struct {
+	MockNamingProxy
+	instances chan model.Instance
+}
+
+func (m *recordNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.instances <- instance
+	return true, nil
+}
+
+func TestNamingClient_RegisterInstanceWithHealthSupplier(t *testing.T) {
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{serverConfigTest})
+	clientConfig := clientConfigTest
+	clientConfig.BeatInterval = 10
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, _ := NewNamingClient(&nc)
+	defer client.CloseClient()
+	proxy := &recordNamingProxy{instances: make(chan model.Instance, 8)}
+	client.serviceProxy = proxy
+
+	var mutex sync.Mutex
+	healthy := false
+	supplier := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return healthy
+	}
+	success, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+		HealthSupplier: supplier,
+	})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	instance := <-proxy.instances
+	assert.False(t, instance.Healthy)
+	assert.False(t, instance.Enable)
+
+	mutex.Lock()
+	healthy = true
+	mutex.Unlock()
+	select {
+	case instance = <-proxy.instances:
+		assert.True(t, instance.Healthy)
+		assert.True(t, instance.Enable)
+	case <-time.After(time.Second):
+		t.Fatal("the instance is not re-registered")
+	}
+
+	_, _ = client.DeregisterInstance(vo.DeregisterInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Ephemeral: true})
+	mutex.Lock()
+	healthy = false
+	mutex.Unlock()
+	select {
+	case <-proxy.instances:
+		t.Fatal("the deregistered instance is re-registered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// registering again without a supplier replaces the health checker of the previous registration
+	param := vo.RegisterInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true,
+		Ephemeral: true, HealthSupplier: supplier}
+	_, err = client.RegisterInstance(param)
+	assert.Nil(t, err)
+	<-proxy.instances
+	param.HealthSupplier = nil
+	_, err = client.RegisterInstance(param)
+	assert.Nil(t, err)
+	<-proxy.instances
+	mutex.Lock()
+	healthy = true
+	mutex.Unlock()
+	select {
+	case <-proxy.instances:
+		t.Fatal("the replaced health checker re-registers the instance")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+type shutdownNamingProxy struct {
+	MockNamingProxy
+	mutex  sync.Mutex
+	calls  []string
+	closed int
+}
+
+func (m *shutdownNamingProxy) CloseClient() {
+	m.closed++
+}
+
+func (m *shutdownNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.calls = append(m.calls, "register:"+strconv.FormatBool(instance.Enable))
+	return true, nil
+}
+
+func (m *shutdownNamingProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.calls = append(m.calls, "deregister")
+	return true, nil
+}
+
+func TestNamingClient_Shutdown(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &shutdownNamingProxy{}
+	client.serviceProxy = proxy
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+	_, err = client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.11", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+	_, err = client.DeregisterInstance(vo.DeregisterInstanceParam{Ip: "10.0.0.11", Port: 80, ServiceName: "DEMO", Ephemeral: true})
+	assert.Nil(t, err)
+
+	start := time.Now()
+	assert.Nil(t, client.Shutdown(context.Background(), 50*time.Millisecond))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Equal(t, []string{"register:true", "register:true", "deregister", "register:false", "deregister"}, proxy.calls)
+}
+
+func TestNamingClient_ShutdownOnSignal(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &shutdownNamingProxy{}
+	client.serviceProxy = proxy
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+
+	var stages []string
+	code := -1
+	client.shutdownOnSignal(vo.ShutdownOnSignalParam{
+		BeforeShutdown: []func(ctx context.Context) error{
+			func(ctx context.Context) error {
+				stages = append(stages, "before:"+strings.Join(proxy.calls, ","))
+				return errors.New("failed hook")
+			},
+			func(ctx context.Context) error {
+				stages = append(stages, "before")
+				return nil
+			},
+		},
+		AfterShutdown: []func(ctx context.Context) error{
+			func(ctx context.Context) error {
+				stages = append(stages, "after:"+strings.Join(proxy.calls, ","))
+				return nil
+			},
+		},
+		Exit: func(c int) { code = c },
+	})
+	assert.Equal(t, []string{"before:register:true", "before", "after:register:true,register:false,deregister"}, stages)
+	assert.Equal(t, 0, code)
+
+	stop := NewTestNamingClient().ShutdownOnSignal(vo.ShutdownOnSignalParam{})
+	stop()
+	stop()
+}
+
+func TestNamingClient_ListRegisteredInstances(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &shutdownNamingProxy{}
+	client.serviceProxy = proxy
+	for _, port := range []uint64{8081, 8080} {
+		_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+			Ip: "10.0.0.10", Port: port, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+		})
+		assert.Nil(t, err)
+	}
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 8080, ServiceName: "DEMO", ClusterName: "b", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+
+	registered := client.ListRegisteredInstances()
+	assert.Equal(t, 3, len(registered))
+	assert.Equal(t, uint64(8080), registered[0].Instance.Port)
+	assert.Equal(t, "", registered[0].Instance.ClusterName)
+	assert.Equal(t, "b", registered[1].Instance.ClusterName)
+	assert.Equal(t, "DEFAULT_GROUP", registered[2].GroupName)
+
+	_, err = registered[1].Deregister()
+	assert.Nil(t, err)
+	registered = client.ListRegisteredInstances()
+	assert.Equal(t, 2, len(registered))
+	assert.Equal(t, uint64(8080), registered[0].Instance.Port)
+	assert.Equal(t, uint64(8081), registered[1].Instance.Port)
+}
+
+type pagedNamingProxy struct {
+	MockNamingProxy
+	services []string
+}
+
+func (m *pagedNamingProxy) GetServiceList(pageNo uint32, pageSize uint32, groupName, namespaceId string, selector *model.ExpressionSelector) (model.ServiceList, error) {
+	start := int((pageNo - 1) * pageSize)
+	if start > len(m.services) {
+		start = len(m.services)
+	}
+	end := start + int(pageSize)
+	if end > len(m.services) {
+		end = len(m.services)
+	}
+	return model.ServiceList{Count: int64(len(m.services)), Doms: m.services[start:end]}, nil
+}
+
+func TestNamingClient_IterateServices(t *testing.T) {
+	client := NewTestNamingClient()
+	var services []string
+	for i := 0; i < 23; i++ {
+		services = append(services, "service-"+strconv.Itoa(i))
+	}
+	client.serviceProxy = &pagedNamingProxy{services: services}
+
+	names, err := client.GetAllServiceNames(vo.ServicesIteratorParam{PageSize: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, services, names)
+
+	names, err = client.GetAllServiceNames(vo.ServicesIteratorParam{PageSize: 4, Parallelism: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, services, names)
+
+	it := client.IterateServices(vo.ServicesIteratorParam{PageSize: 5, Parallelism: 2})
+	assert.True(t, it.Next())
+	assert.Equal(t, "service-0", it.Service())
+	it.Close()
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Err())
+}
+
+func TestNamingClient_SetInstanceWeightAndToggle(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &recordNamingProxy{instances: make(chan model.Instance, 8)}
+	client.serviceProxy = proxy
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+		Metadata: map[string]string{"zone": "a"},
+	})
+	assert.Nil(t, err)
+	<-proxy.instances
+
+	_, err = client.SetInstanceWeight(vo.SetInstanceWeightParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 0})
+	assert.Nil(t, err)
+	instance := <-proxy.instances
+	assert.Equal(t, float64(0), instance.Weight)
+	assert.True(t, instance.Enable)
+	assert.Equal(t, "a", instance.Metadata["zone"])
+
+	_, err = client.DisableInstance(vo.ToggleInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	instance = <-proxy.instances
+	assert.False(t, instance.Enable)
+	assert.Equal(t, float64(0), instance.Weight)
+	assert.False(t, client.ListRegisteredInstances()[0].Instance.Enable)
+
+	_, err = client.EnableInstance(vo.ToggleInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	assert.True(t, (<-proxy.instances).Enable)
+
+	_, err = client.DisableInstance(vo.ToggleInstanceParam{Ip: "10.0.0.11", Port: 80, ServiceName: "DEMO"})
+	assert.NotNil(t, err)
+	_, err = client.SetInstanceWeight(vo.SetInstanceWeightParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: -1})
+	assert.NotNil(t, err)
+
+	// the registered instance is kept when the server rejects the update
+	client.serviceProxy = &failingRegisterNamingProxy{}
+	_, err = client.DisableInstance(vo.ToggleInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.NotNil(t, err)
+	assert.True(t, client.ListRegisteredInstances()[0].Instance.Enable)
+}
+
+type failingRegisterNamingProxy struct {
+	MockNamingProxy
+}
+
+func (m *failingRegisterNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	return false, errors.New("register failed")
+}
+
+func TestPreserveMetadata(t *testing.T) {
+	metadata := map[string]string{"version": "v1"}
+	param := vo.RegisterInstanceParam{Metadata: metadata, HeartBeatInterval: 3 * time.Second,
+		HeartBeatTimeout: 10 * time.Second, InstanceIdGenerator: constant.INSTANCE_ID_GENERATOR_SNOWFLAKE}
+	assert.Nil(t, preserveMetadata(&param))
+	assert.Equal(t, map[string]string{"version": "v1"}, metadata)
+	assert.Equal(t, "3000", param.Metadata[constant.HEART_BEAT_INTERVAL])
+	instance := model.Instance{Metadata: param.Metadata}
+	assert.Equal(t, 3*time.Second, instance.GetHeartBeatInterval())
+	assert.Equal(t, 10*time.Second, instance.GetHeartBeatTimeout())
+	assert.Equal(t, constant.DEFAULT_IP_DELETE_TIMEOUT, instance.GetIpDeleteTimeout())
+	assert.Equal(t, constant.INSTANCE_ID_GENERATOR_SNOWFLAKE, instance.GetInstanceIdGenerator())
+	assert.Equal(t, constant.INSTANCE_ID_GENERATOR_SIMPLE, model.Instance{}.GetInstanceIdGenerator())
+
+	assert.NotNil(t, preserveMetadata(&vo.RegisterInstanceParam{HeartBeatInterval: 20 * time.Second}))
+	assert.NotNil(t, preserveMetadata(&vo.RegisterInstanceParam{InstanceIdGenerator: "uuid"}))
+	param = vo.RegisterInstanceParam{}
+	assert.Nil(t, preserveMetadata(&param))
+	assert.Nil(t, param.Metadata)
+}
+
+type queryNamingProxy struct {
+	MockNamingProxy
+	hosts []model.Instance
+	err   error
+}
+
+func (m *queryNamingProxy) QueryInstancesOfService(serviceName, groupName, clusters string, udpPort int, healthyOnly bool) (*model.Service, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &model.Service{Name: serviceName, GroupName: groupName, LastRefTime: uint64(util.CurrentMillis()), Hosts: m.hosts}, nil
+}
+
+type reconcileNamingProxy struct {
+	queryNamingProxy
+	registered []string
+}
+
+func (m *reconcileNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.registered = append(m.registered, instance.Ip)
+	return true, nil
+}
+
+func TestNamingClient_Reconcile(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &reconcileNamingProxy{}
+	client.serviceProxy = proxy
+	for _, ip := range []string{"10.0.0.10", "10.0.0.11"} {
+		_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+			Ip: ip, Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+		})
+		assert.Nil(t, err)
+	}
+	proxy.registered = nil
+	proxy.hosts = []model.Instance{{Ip: "10.0.0.10", Port: 80, ClusterName: "DEFAULT", Weight: 1, Healthy: true, Enable: true}}
+	assert.Equal(t, 1, client.reconcile())
+	assert.Equal(t, []string{"10.0.0.11"}, proxy.registered)
+	// an instance deregistered after the snapshot of the reconcile isn't registered again
+	_, err := client.DeregisterInstance(vo.DeregisterInstanceParam{Ip: "10.0.0.11", Port: 80, ServiceName: "DEMO", Ephemeral: true})
+	assert.Nil(t, err)
+	assert.False(t, client.reconcileInstance(registeredService{serviceName: "DEMO", groupName: constant.DEFAULT_GROUP},
+		model.Instance{Ip: "10.0.0.11", Port: 80}))
+	_, err = client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.11", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+
+	// the subscribed service missing a push is updated
+	var pushed []model.Instance
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "OTHER", SubscribeCallback: func(services []model.Instance, err error) {
+		pushed = services
+	}}))
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "OTHER", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.20", Port: 80, Weight: 1, Healthy: true, Enable: true}}})
+	proxy.registered = nil
+	proxy.hosts = append(proxy.hosts, model.Instance{Ip: "10.0.0.11", Port: 80, ClusterName: "DEFAULT", Weight: 1, Healthy: true, Enable: true})
+	assert.Equal(t, 1, client.reconcile())
+	assert.Nil(t, proxy.registered)
+	assert.Equal(t, proxy.hosts, pushed)
+	assert.Equal(t, 0, client.reconcile())
+}
+
+func TestNamingClient_CheckInstanceHealth(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &queryNamingProxy{hosts: []model.Instance{{Ip: "10.0.0.10", Port: 80, ClusterName: "DEFAULT", Healthy: true}}}
+	client.serviceProxy = proxy
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, client.WatchInstanceHealth(&vo.WatchInstanceHealthParam{}))
+
+	states := make(map[string]instanceHealthState)
+	assert.Equal(t, 0, len(client.checkInstanceHealth(states)))
+	proxy.hosts[0].Healthy = false
+	events := client.checkInstanceHealth(states)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "DEMO", events[0].ServiceName)
+	assert.False(t, events[0].Healthy)
+	assert.False(t, events[0].Missing)
+	assert.Equal(t, 0, len(client.checkInstanceHealth(states)))
+
+	// the states are kept when the service query fails
+	proxy.err = errors.New("query failed")
+	assert.Equal(t, 0, len(client.checkInstanceHealth(states)))
+	assert.Equal(t, 1, len(states))
+	proxy.err = nil
+	assert.Equal(t, 0, len(client.checkInstanceHealth(states)))
+	proxy.hosts[0].Healthy = true
+	events = client.checkInstanceHealth(states)
+	assert.Equal(t, 1, len(events))
+	assert.True(t, events[0].Healthy)
+
+	proxy.hosts = nil
+	events = client.checkInstanceHealth(states)
+	assert.Equal(t, 1, len(events))
+	assert.True(t, events[0].Missing)
+
+	param := &vo.WatchInstanceHealthParam{IntervalMs: 10, Callback: func(event model.InstanceHealthEvent) {}}
+	assert.Nil(t, client.WatchInstanceHealth(param))
+	assert.NotNil(t, client.WatchInstanceHealth(param))
+	assert.Nil(t, client.UnwatchInstanceHealth(param))
+	assert.NotNil(t, client.UnwatchInstanceHealth(param))
+}
+
+type subscribeNamingProxy struct {
+	MockNamingProxy
+	calls []string
+}
+
+func (m *subscribeNamingProxy) Subscribe(serviceName, groupName, clusters string) (model.Service, error) {
+	m.calls = append(m.calls, "subscribe:"+clusters)
+	return model.Service{}, nil
+}
+
+func (m *subscribeNamingProxy) Unsubscribe(serviceName, groupName, clusters string) error {
+	m.calls = append(m.calls, "unsubscribe:"+clusters)
+	return nil
+}
+
+func TestNamingClient_SelectInstances_CacheOnly(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &subscribeNamingProxy{}
+	client.serviceProxy = proxy
+	_, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "UNKNOWN", HealthyOnly: true, CacheOnly: true})
+	assert.Equal(t, ErrServiceNotCached, err)
+	_, err = client.SelectInstancesWithFilter(vo.SelectInstancesParam{ServiceName: "UNKNOWN", CacheOnly: true},
+		func(instance model.Instance) bool { return true })
+	assert.Equal(t, ErrServiceNotCached, err)
+	assert.Empty(t, proxy.calls)
+
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "CACHED", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.10", Port: 80, Weight: 1, Healthy: true, Enable: true}}})
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "CACHED", HealthyOnly: true, CacheOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Empty(t, proxy.calls)
+}
+
+func TestNamingClient_SubscribeClustersChanged(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &subscribeNamingProxy{}
+	client.serviceProxy = proxy
+	callback := func(services []model.Instance, err error) {}
+	param := &vo.SubscribeParam{ServiceName: "DEMO", Clusters: []string{"a"}, SubscribeCallback: callback}
+	other := &vo.SubscribeParam{ServiceName: "DEMO", Clusters: []string{"a"}, SubscribeCallback: callback}
+	assert.Nil(t, client.Subscribe(param))
+	assert.Nil(t, client.Subscribe(other))
+
+	param.Clusters = []string{"b"}
+	assert.Nil(t, client.Subscribe(param))
+	assert.True(t, client.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", "a"))
+	assert.Nil(t, client.Unsubscribe(other))
+	assert.False(t, client.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", "a"))
+
+	other.Clusters = []string{"c"}
+	assert.Nil(t, client.Unsubscribe(other))
+	param.Clusters = []string{"c"}
+	assert.Nil(t, client.Unsubscribe(param))
+	assert.False(t, client.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", "b"))
+	assert.Equal(t, []string{"subscribe:a", "subscribe:a", "subscribe:b", "unsubscribe:a", "unsubscribe:c", "unsubscribe:b"}, proxy.calls)
+}
+
+func TestNamingClient_SubscribeSubset(t *testing.T) {
+	client := NewTestNamingClient()
+	client.serviceProxy = &subscribeNamingProxy{}
+	var hosts []model.Instance
+	for i := 0; i < 10; i++ {
+		hosts = append(hosts, model.Instance{Ip: "10.0.0." + strconv.Itoa(i), Port: 80, Weight: 1, Healthy: true, Enable: true})
+	}
+	var subset []model.Instance
+	var diff model.InstancesDiff
+	param := &vo.SubscribeParam{ServiceName: "DEMO", SubsetSize: 3, SubsetKey: "client-1",
+		SubscribeCallback: func(services []model.Instance, err error) {
+			subset = services
+		},
+		SubscribeDiffCallback: func(services []model.Instance, d model.InstancesDiff, err error) {
+			diff = d
+		},
+	}
+	assert.Nil(t, client.Subscribe(param))
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts})
+	assert.Equal(t, 3, len(subset))
+	assert.Equal(t, 3, len(diff.Added))
+	assert.Equal(t, subsetInstances(hosts, 3, "client-1"), subset)
+
+	// removing an instance out of the subset keeps the subset
+	var removed int
+	for i, host := range hosts {
+		if host.Ip != subset[0].Ip && host.Ip != subset[1].Ip && host.Ip != subset[2].Ip {
+			removed = i
+			break
+		}
+	}
+	previous := subset
+	rest := append(append([]model.Instance{}, hosts[:removed]...), hosts[removed+1:]...)
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 2, Hosts: rest})
+	assert.Equal(t, previous, subset)
+	assert.Equal(t, model.InstancesDiff{}, diff)
+
+	// the subset is picked from the healthy instances
+	unhealthy := append([]model.Instance{}, rest...)
+	for i := range unhealthy {
+		if unhealthy[i].Ip == subset[0].Ip {
+			unhealthy[i].Healthy = false
+		}
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 3, Hosts: unhealthy})
+	assert.Equal(t, 3, len(subset))
+	for _, instance := range subset {
+		assert.True(t, instance.Healthy)
+	}
+
+	// the select paths pick the same subset from the healthy instances
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "DEMO", HealthyOnly: true, SubsetSize: 3, SubsetKey: "client-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, subset, instances)
+	for i := 0; i < 10; i++ {
+		instance, err := client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "DEMO", SubsetSize: 3, SubsetKey: "client-1"})
+		assert.Nil(t, err)
+		assert.Contains(t, subset, *instance)
+	}
+	clientConfig, _ := client.GetClientConfig()
+	clientConfig.NamingSubsetKey = "client-1"
+	_ = client.SetClientConfig(clientConfig)
+	instances, err = client.SelectInstances(vo.SelectInstancesParam{ServiceName: "DEMO", HealthyOnly: true, SubsetSize: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, subset, instances)
+
+	assert.Nil(t, client.Unsubscribe(param))
+	assert.False(t, client.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", ""))
+}
+
+func TestNamingClient_SubscribeSelector(t *testing.T) {
+	client := NewTestNamingClient()
+	client.serviceProxy = &subscribeNamingProxy{}
+	hosts := []model.Instance{
+		{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"zone": "a", "env": "prod"}},
+		{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"zone": "a", "env": "test"}},
+		{Ip: "10.0.0.3", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"zone": "b", "env": "prod"}},
+	}
+	var selected []model.Instance
+	param := &vo.SubscribeParam{ServiceName: "DEMO",
+		Selector: &model.ExpressionSelector{Type: "label", Expression: "PROVIDER.label.zone = a & env != test"},
+		SubscribeCallback: func(services []model.Instance, err error) {
+			selected = services
+		},
+	}
+	assert.Nil(t, client.Subscribe(param))
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts})
+	assert.Equal(t, hosts[:1], selected)
+
+	service, err := client.GetService(vo.GetServiceParam{ServiceName: "DEMO",
+		Selector: &model.ExpressionSelector{Type: "label", Expression: "zone = b"}})
+	assert.Nil(t, err)
+	assert.Equal(t, hosts[2:], service.Hosts)
+	assert.Nil(t, client.Unsubscribe(param))
+
+	// the syntax of the server matches the labels of the client
+	clientConfig, _ := client.GetClientConfig()
+	clientConfig.ConsumerLabels = map[string]string{"zone": "b", "env": "prod"}
+	assert.Nil(t, client.SetClientConfig(clientConfig))
+	for _, expression := range []string{"CONSUMER.label.zone = PROVIDER.label.zone & CONSUMER.label.env = PROVIDER.label.env",
+		"PROVIDER.label.zone=CONSUMER.label.zone"} {
+		service, err = client.GetService(vo.GetServiceParam{ServiceName: "DEMO",
+			Selector: &model.ExpressionSelector{Type: "label", Expression: expression}})
+		assert.Nil(t, err)
+		assert.Equal(t, hosts[2:], service.Hosts, expression)
+	}
+	service, err = client.GetService(vo.GetServiceParam{ServiceName: "DEMO",
+		Selector: &model.ExpressionSelector{Type: "label", Expression: "CONSUMER.label.region = PROVIDER.label.region"}})
+	assert.Nil(t, err)
+	assert.Equal(t, hosts, service.Hosts)
+
+	invalid := []*model.ExpressionSelector{
+		{Type: "cmdb", Expression: "zone = a"},
+		{Type: "label", Expression: "zone"},
+		{Type: "label", Expression: "CONSUMER.label.zone = a"},
+		{Type: "label", Expression: "CONSUMER.label.zone = PROVIDER.label.env"},
+		{Type: "label", Expression: "CONSUMER.label.zone = CONSUMER.label.zone"},
+	}
+	for _, selector := range invalid {
+		assert.NotNil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "DEMO", Selector: selector, SubscribeCallback: param.SubscribeCallback}))
+	}
+}
+
+func TestNamingClient_SubscribeWatchdog(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &subscribeNamingProxy{}
+	client.serviceProxy = proxy
+	callback := func(services []model.Instance, err error) {}
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "DEMO", Clusters: []string{"a"}, SubscribeCallback: callback}))
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "OTHER", SubscribeCallback: callback}))
+	client.serviceInfoHolder.UpdateTimeMap.Store("DEFAULT_GROUP@@OTHER", uint64(util.CurrentMillis()))
+
+	client.checkSubscriptions(time.Minute)
+	assert.Equal(t, []string{"subscribe:a", "subscribe:", "subscribe:a"}, proxy.calls)
+	client.checkSubscriptions(time.Minute)
+	assert.Equal(t, 3, len(proxy.calls))
+}
+
+func TestIsLegacyVersion(t *testing.T) {
+	assert.True(t, util.IsLegacyServerVersion("1.4.1"))
+	assert.True(t, util.IsLegacyServerVersion(" 0.9.0"))
+	assert.False(t, util.IsLegacyServerVersion("2.3.0"))
+	assert.False(t, util.IsLegacyServerVersion("3.0.0-BETA"))
+	assert.False(t, util.IsLegacyServerVersion(""))
+}
+
+type serviceDefinitionNamingProxy struct {
+	MockNamingProxy
+	created model.ServiceDefinition
+	deleted string
+}
+
+func (m *serviceDefinitionNamingProxy) CreateService(service model.ServiceDefinition) (bool, error) {
+	m.created = service
+	return true, nil
+}
+
+func (m *serviceDefinitionNamingProxy) DeleteService(serviceName, groupName string) (bool, error) {
+	m.deleted = util.GetGroupName(serviceName, groupName)
+	return true, nil
+}
+
+func TestNamingClient_ServiceDefinition(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &serviceDefinitionNamingProxy{}
+	client.serviceProxy = proxy
+	selector := &model.ExpressionSelector{Type: "label", Expression: "CONSUMER.label.zone = PROVIDER.label.zone"}
+	success, err := client.CreateService(vo.CreateServiceParam{ServiceName: "DEMO", ProtectThreshold: 0.5,
+		Metadata: map[string]string{"owner": "team"}, Selector: selector})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Equal(t, model.ServiceDefinition{Name: "DEMO", GroupName: "DEFAULT_GROUP", ProtectThreshold: 0.5,
+		Metadata: map[string]string{"owner": "team"}, Selector: *selector}, proxy.created)
+
+	_, err = client.UpdateService(vo.UpdateServiceParam{ServiceName: "DEMO", ProtectThreshold: 1.5})
+	assert.NotNil(t, err)
+	_, err = client.CreateService(vo.CreateServiceParam{})
+	assert.NotNil(t, err)
+
+	success, err = client.DeleteService(vo.DeleteServiceParam{ServiceName: "DEMO", GroupName: "g"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Equal(t, "g@@DEMO", proxy.deleted)
+
+	service, err := client.GetServiceDetail(vo.GetServiceDetailParam{ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	assert.Equal(t, "DEFAULT_GROUP", service.GroupName)
+}
+
+func TestNamingClient_Namespaces(t *testing.T) {
+	client := NewTestNamingClient()
+	tenant := NewTestNamingClient()
+	tenant.namespaceId = "tenant"
+	proxy := &shutdownNamingProxy{}
+	tenant.serviceProxy = proxy
+	client.namespaces = map[string]*NamingClient{"tenant": tenant}
+
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{NamespaceId: "tenant",
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true, Ephemeral: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(client.ListRegisteredInstances()))
+	assert.Equal(t, 1, len(tenant.ListRegisteredInstances()))
+
+	param := &vo.SubscribeParam{NamespaceId: "tenant", ServiceName: "DEMO", SubscribeCallback: func(services []model.Instance, err error) {}}
+	assert.Nil(t, client.Subscribe(param))
+	assert.True(t, tenant.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", ""))
+	assert.False(t, client.serviceInfoHolder.IsSubscribed("DEFAULT_GROUP@@DEMO", ""))
+	tenant.serviceInfoHolder.ProcessService(&model.Service{Name: "DEMO", GroupName: "DEFAULT_GROUP", LastRefTime: 1,
+		Hosts: []model.Instance{{Ip: "10.0.0.10", Port: 80, Weight: 1, Healthy: true, Enable: true}}})
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{NamespaceId: "tenant", ServiceName: "DEMO", HealthyOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(instances))
+	assert.Nil(t, client.Unsubscribe(param))
+
+	// the instance methods are routed to the namespace of the param
+	_, err = client.DisableInstance(vo.ToggleInstanceParam{NamespaceId: "tenant", Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	assert.False(t, tenant.ListRegisteredInstances()[0].Instance.Enable)
+	_, err = client.EnableInstance(vo.ToggleInstanceParam{NamespaceId: "tenant", Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.Nil(t, err)
+	_, err = client.DisableInstance(vo.ToggleInstanceParam{Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO"})
+	assert.NotNil(t, err)
+	proxy.calls = nil
+
+	// the clients of the other namespaces are shut down together, and closed once
+	assert.Nil(t, client.Shutdown(context.Background(), 0))
+	assert.Equal(t, []string{"register:false", "deregister"}, proxy.calls)
+	client.CloseClient()
+	assert.Equal(t, 1, proxy.closed)
+
+	_, err = NewTestNamingClient().RegisterInstance(vo.RegisterInstanceParam{NamespaceId: "tenant",
+		Ip: "10.0.0.10", Port: 80, ServiceName: "DEMO", Weight: 1, Enable: true, Healthy: true})
+	assert.NotNil(t, err)
+}
+
+func TestNamingClient_RegisterServerRequestHandler(t *testing.T) {
+	client := NewTestNamingClient()
+	handler := &rpc.ClientDetectionRequestHandler{}
+	assert.NotNil(t, client.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return &rpc_request.NotifySubscriberRequest{NamingRequest: &rpc_request.NamingRequest{}}
+	}, handler))
+	assert.NotNil(t, client.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return &rpc_request.ClientDetectionRequest{InternalRequest: rpc_request.NewInternalRequest()}
+	}, handler))
+	assert.Nil(t, client.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return rpc_request.NewHealthCheckRequest()
+	}, handler))
+}
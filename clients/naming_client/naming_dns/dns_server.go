@@ -0,0 +1,315 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_dns
+
+import (
+	"math"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+const (
+	defaultDomain = "nacos"
+	defaultTTL    = 5
+	maxUdpSize    = 512
+)
+
+// Server is a dns server for the apps that can't use the sdk, it resolves <service>.<group>.<namespace>.<domain>
+// to the A, AAAA and SRV records of the healthy instances in the cache of the naming clients. The SRV targets are
+// <ip with dashes>.<service>.<group>.<namespace>.<domain>, which are resolved to the ip. Only the services subscribed
+// by the apps are resolved, the queries never subscribe or request the server.
+type Server struct {
+	addr    string
+	domain  string
+	ttl     uint32
+	clients map[string]naming_client.INamingClient
+	mutex   sync.Mutex
+	conn    net.PacketConn
+}
+
+// Option ...
+type Option func(*Server)
+
+// WithDomain set the domain suffix of the service names, default is nacos
+func WithDomain(domain string) Option {
+	return func(s *Server) {
+		s.domain = strings.Trim(domain, ".")
+	}
+}
+
+// WithTTL set the ttl of the records in seconds, default is 5
+func WithTTL(ttl uint32) Option {
+	return func(s *Server) {
+		s.ttl = ttl
+	}
+}
+
+// NewServer create a dns server listening on the udp addr, clients is the naming client of each namespace.
+func NewServer(addr string, clients map[string]naming_client.INamingClient, opts ...Option) *Server {
+	s := &Server{
+		addr:    addr,
+		domain:  defaultDomain,
+		ttl:     defaultTTL,
+		clients: clients,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start listen on the udp addr and serve the queries in background.
+func (s *Server) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn != nil {
+		return errors.New("dns server is already started")
+	}
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return errors.Wrapf(err, "listen dns server on %s", s.addr)
+	}
+	s.conn = conn
+	logger.Infof("dns server is listening on %s for domain %s", conn.LocalAddr(), s.domain)
+	go s.serve(conn)
+	return nil
+}
+
+// Addr return the address listened, nil if the server is not started.
+func (s *Server) Addr() net.Addr {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+// Close stop the server.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *Server) serve(conn net.PacketConn) {
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			logger.Warnf("dns server read failed: %v", err)
+			continue
+		}
+		resp, err := s.handle(buf[:n])
+		if err != nil {
+			logger.Warnf("dns server handle query from %s failed: %v", addr, err)
+			continue
+		}
+		if _, err = conn.WriteTo(resp, addr); err != nil {
+			logger.Warnf("dns server reply to %s failed: %v", addr, err)
+		}
+	}
+}
+
+// answer is the result of a query, host is set for the SRV targets.
+type answer struct {
+	rcode     dnsmessage.RCode
+	instances []model.Instance
+	host      net.IP
+}
+
+func (s *Server) handle(req []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(req)
+	if err != nil {
+		return nil, err
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+	result := s.lookup(question.Name.String())
+	header.Response = true
+	header.Authoritative = result.rcode == dnsmessage.RCodeSuccess
+	header.RecursionAvailable = false
+	header.RCode = result.rcode
+	limit := len(result.instances)
+	for {
+		resp, err := s.build(header, question, result, limit)
+		if err != nil || len(resp) <= maxUdpSize || limit == 0 {
+			return resp, err
+		}
+		header.Truncated = true
+		limit /= 2
+	}
+}
+
+func (s *Server) lookup(name string) answer {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	domain := strings.Split(s.domain, ".")
+	if len(labels) < len(domain)+3 || !strings.EqualFold(strings.Join(labels[len(labels)-len(domain):], "."), s.domain) {
+		return answer{rcode: dnsmessage.RCodeRefused}
+	}
+	labels = labels[:len(labels)-len(domain)]
+	client, ok := s.clients[labels[len(labels)-1]]
+	if !ok {
+		return answer{rcode: dnsmessage.RCodeNameError}
+	}
+	if len(labels) > 3 {
+		if ip := parseTargetIp(labels[0]); ip != nil {
+			// a SRV target is only resolved to the ip of a healthy instance of its service
+			instances, err := selectInstances(client, labels[1:])
+			if err == nil {
+				for _, instance := range instances {
+					if ip.Equal(net.ParseIP(instance.Ip)) {
+						return answer{rcode: dnsmessage.RCodeSuccess, host: ip}
+					}
+				}
+			}
+			return answer{rcode: dnsmessage.RCodeNameError}
+		}
+	}
+	instances, err := selectInstances(client, labels)
+	if err != nil {
+		return answer{rcode: dnsmessage.RCodeNameError}
+	}
+	return answer{rcode: dnsmessage.RCodeSuccess, instances: instances}
+}
+
+// selectInstances select the cached healthy instances of <service>.<group>.<namespace> in the labels.
+func selectInstances(client naming_client.INamingClient, labels []string) ([]model.Instance, error) {
+	return client.SelectInstances(vo.SelectInstancesParam{
+		ServiceName: strings.Join(labels[:len(labels)-2], "."),
+		GroupName:   labels[len(labels)-2],
+		HealthyOnly: true,
+		CacheOnly:   true,
+	})
+}
+
+func (s *Server) build(header dnsmessage.Header, question dnsmessage.Question, result answer, limit int) ([]byte, error) {
+	builder := dnsmessage.NewBuilder(make([]byte, 0, maxUdpSize), header)
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+	if result.host != nil {
+		if err := s.addressResource(&builder, question.Name, question.Type, result.host); err != nil {
+			return nil, err
+		}
+		return builder.Finish()
+	}
+	instances := result.instances[:limit]
+	for _, instance := range instances {
+		var err error
+		if question.Type == dnsmessage.TypeSRV || question.Type == dnsmessage.TypeALL {
+			var target dnsmessage.Name
+			if target, err = srvTarget(instance.Ip, question.Name); err != nil {
+				return nil, err
+			}
+			err = builder.SRVResource(s.resourceHeader(question.Name, dnsmessage.TypeSRV), dnsmessage.SRVResource{
+				Weight: srvWeight(instance.Weight),
+				Port:   uint16(instance.Port),
+				Target: target,
+			})
+		} else {
+			err = s.addressResource(&builder, question.Name, question.Type, net.ParseIP(instance.Ip))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if question.Type == dnsmessage.TypeSRV {
+		if err := builder.StartAdditionals(); err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			target, err := srvTarget(instance.Ip, question.Name)
+			if err != nil {
+				return nil, err
+			}
+			if err = s.addressResource(&builder, target, dnsmessage.TypeALL, net.ParseIP(instance.Ip)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return builder.Finish()
+}
+
+// addressResource add the A or AAAA record of the ip if it matches the query type.
+func (s *Server) addressResource(builder *dnsmessage.Builder, name dnsmessage.Name, qtype dnsmessage.Type, ip net.IP) error {
+	if ip4 := ip.To4(); ip4 != nil {
+		if qtype != dnsmessage.TypeA && qtype != dnsmessage.TypeALL {
+			return nil
+		}
+		var a dnsmessage.AResource
+		copy(a.A[:], ip4)
+		return builder.AResource(s.resourceHeader(name, dnsmessage.TypeA), a)
+	}
+	if ip16 := ip.To16(); ip16 != nil && (qtype == dnsmessage.TypeAAAA || qtype == dnsmessage.TypeALL) {
+		var aaaa dnsmessage.AAAAResource
+		copy(aaaa.AAAA[:], ip16)
+		return builder.AAAAResource(s.resourceHeader(name, dnsmessage.TypeAAAA), aaaa)
+	}
+	return nil
+}
+
+func (s *Server) resourceHeader(name dnsmessage.Name, rtype dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: name, Type: rtype, Class: dnsmessage.ClassINET, TTL: s.ttl}
+}
+
+func srvTarget(ip string, name dnsmessage.Name) (dnsmessage.Name, error) {
+	label := strings.NewReplacer(".", "-", ":", "-").Replace(ip)
+	return dnsmessage.NewName(label + "." + name.String())
+}
+
+// parseTargetIp parse the first label of a SRV target, nil if it's not an ip with dashes.
+func parseTargetIp(label string) net.IP {
+	if ip := net.ParseIP(strings.ReplaceAll(label, "-", ".")); ip != nil {
+		return ip
+	}
+	return net.ParseIP(strings.ReplaceAll(label, "-", ":"))
+}
+
+func srvWeight(weight float64) uint16 {
+	if weight >= math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(math.Ceil(weight))
+}
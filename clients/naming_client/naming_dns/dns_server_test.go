@@ -0,0 +1,115 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+type mockNamingClient struct {
+	naming_client.INamingClient
+}
+
+func (m *mockNamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	if !param.CacheOnly {
+		return nil, errors.New("the dns queries must not subscribe")
+	}
+	if param.ServiceName != "demo.api" || param.GroupName != "DEFAULT_GROUP" || !param.HealthyOnly {
+		return nil, errors.New("instance list is empty!")
+	}
+	return []model.Instance{
+		{Ip: "10.0.0.1", Port: 8080, Weight: 1},
+		{Ip: "10.0.0.2", Port: 8081, Weight: 2.5},
+	}, nil
+}
+
+func query(t *testing.T, addr net.Addr, name string, qtype dnsmessage.Type) (dnsmessage.Message, error) {
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 1, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	req, err := msg.Pack()
+	assert.Nil(t, err)
+	conn, err := net.Dial("udp", addr.String())
+	assert.Nil(t, err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err = conn.Write(req); err != nil {
+		return dnsmessage.Message{}, err
+	}
+	buf := make([]byte, maxUdpSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return dnsmessage.Message{}, err
+	}
+	var resp dnsmessage.Message
+	err = resp.Unpack(buf[:n])
+	return resp, err
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer("127.0.0.1:0", map[string]naming_client.INamingClient{"public": &mockNamingClient{}}, WithTTL(3))
+	assert.Nil(t, server.Start())
+	defer server.Close()
+	assert.NotNil(t, server.Start())
+
+	resp, err := query(t, server.Addr(), "demo.api.DEFAULT_GROUP.public.nacos.", dnsmessage.TypeA)
+	assert.Nil(t, err)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode)
+	assert.Equal(t, 2, len(resp.Answers))
+	assert.Equal(t, [4]byte{10, 0, 0, 1}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+	assert.Equal(t, uint32(3), resp.Answers[0].Header.TTL)
+
+	resp, err = query(t, server.Addr(), "demo.api.DEFAULT_GROUP.public.nacos.", dnsmessage.TypeSRV)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(resp.Answers))
+	srv := resp.Answers[1].Body.(*dnsmessage.SRVResource)
+	assert.Equal(t, uint16(8081), srv.Port)
+	assert.Equal(t, uint16(3), srv.Weight)
+	assert.Equal(t, "10-0-0-2.demo.api.DEFAULT_GROUP.public.nacos.", srv.Target.String())
+	assert.Equal(t, 2, len(resp.Additionals))
+
+	resp, err = query(t, server.Addr(), srv.Target.String(), dnsmessage.TypeA)
+	assert.Nil(t, err)
+	assert.Equal(t, [4]byte{10, 0, 0, 2}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+	for _, name := range []string{"10-0-0-9.demo.api.DEFAULT_GROUP.public.nacos.", "10-0-0-2.other.DEFAULT_GROUP.public.nacos."} {
+		resp, err = query(t, server.Addr(), name, dnsmessage.TypeA)
+		assert.Nil(t, err)
+		assert.Equal(t, dnsmessage.RCodeNameError, resp.RCode, name)
+		assert.Equal(t, 0, len(resp.Answers), name)
+	}
+
+	resp, err = query(t, server.Addr(), "demo.DEFAULT_GROUP.public.nacos.", dnsmessage.TypeA)
+	assert.Nil(t, err)
+	assert.Equal(t, dnsmessage.RCodeNameError, resp.RCode)
+	resp, err = query(t, server.Addr(), "demo.api.DEFAULT_GROUP.dev.nacos.", dnsmessage.TypeA)
+	assert.Nil(t, err)
+	assert.Equal(t, dnsmessage.RCodeNameError, resp.RCode)
+	resp, err = query(t, server.Addr(), "www.example.com.", dnsmessage.TypeA)
+	assert.Nil(t, err)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.RCode)
+}
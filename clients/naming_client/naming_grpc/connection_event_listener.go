@@ -105,6 +105,85 @@ func (c *ConnectionEventListener) CacheInstancesForRedo(serviceName, groupName s
 	c.registeredInstanceCached.Set(key, instances)
 }
 
+// GetBatchInstancesForRedo return the instances cached by CacheInstancesForRedo.
+func (c *ConnectionEventListener) GetBatchInstancesForRedo(serviceName, groupName string) ([]model.Instance, bool) {
+	v, ok := c.registeredInstanceCached.Get(util.GetGroupName(serviceName, groupName))
+	if !ok {
+		return nil, false
+	}
+	instances, ok := v.([]model.Instance)
+	return instances, ok
+}
+
+// GetInstancesForRedo return the instances of the service cached for redo, either a single or a batch.
+func (c *ConnectionEventListener) GetInstancesForRedo(serviceName, groupName string) []model.Instance {
+	v, ok := c.registeredInstanceCached.Get(util.GetGroupName(serviceName, groupName))
+	if !ok {
+		return nil
+	}
+	if cached, ok := v.(model.Instance); ok {
+		return []model.Instance{cached}
+	}
+	instances, _ := v.([]model.Instance)
+	return instances
+}
+
+// IsInstanceCachedForRedo report whether an instance of the same ip and port is cached for redo.
+func (c *ConnectionEventListener) IsInstanceCachedForRedo(serviceName, groupName string, instance model.Instance) bool {
+	v, ok := c.registeredInstanceCached.Get(util.GetGroupName(serviceName, groupName))
+	if !ok {
+		return false
+	}
+	if cached, ok := v.(model.Instance); ok {
+		return cached.Ip == instance.Ip && cached.Port == instance.Port
+	}
+	instances, _ := v.([]model.Instance)
+	for _, cached := range instances {
+		if cached.Ip == instance.Ip && cached.Port == instance.Port {
+			return true
+		}
+	}
+	return false
+}
+
+// PatchInstanceMetadataForRedo merge the metadata into the cached instance of the same ip and port, it returns
+// false when the instance is not cached.
+func (c *ConnectionEventListener) PatchInstanceMetadataForRedo(serviceName, groupName string, instance model.Instance) bool {
+	key := util.GetGroupName(serviceName, groupName)
+	v, ok := c.registeredInstanceCached.Get(key)
+	if !ok {
+		return false
+	}
+	patch := func(cached model.Instance) model.Instance {
+		cached.Metadata = util.DeepCopyMap(cached.Metadata)
+		for k, value := range instance.Metadata {
+			cached.Metadata[k] = value
+		}
+		return cached
+	}
+	if cached, ok := v.(model.Instance); ok {
+		if cached.Ip != instance.Ip || cached.Port != instance.Port {
+			return false
+		}
+		c.registeredInstanceCached.Set(key, patch(cached))
+		return true
+	}
+	cachedInstances, _ := v.([]model.Instance)
+	instances := make([]model.Instance, len(cachedInstances))
+	var found bool
+	for i, cached := range cachedInstances {
+		if cached.Ip == instance.Ip && cached.Port == instance.Port {
+			cached = patch(cached)
+			found = true
+		}
+		instances[i] = cached
+	}
+	if found {
+		c.registeredInstanceCached.Set(key, instances)
+	}
+	return found
+}
+
 func (c *ConnectionEventListener) RemoveInstanceForRedo(serviceName, groupName string, instance model.Instance) {
 	key := util.GetGroupName(serviceName, groupName)
 	_, ok := c.registeredInstanceCached.Get(key)
@@ -5,7 +5,9 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_proxy"
+	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestRedoSubscribe(t *testing.T) {
@@ -32,3 +34,67 @@ func TestRedoSubscribe(t *testing.T) {
 		evListener.RemoveSubscriberForRedo(fullServiceName, v.clusters)
 	}
 }
+
+func TestRetainInstances(t *testing.T) {
+	evListener := NewConnectionEventListener(nil)
+	_, ok := evListener.GetBatchInstancesForRedo("service-a", "group-a")
+	assert.False(t, ok)
+	registered := []model.Instance{{Ip: "10.0.0.1", Port: 80}, {Ip: "10.0.0.2", Port: 80}, {Ip: "10.0.0.2", Port: 81}}
+	evListener.CacheInstancesForRedo("service-a", "group-a", registered)
+	instances, ok := evListener.GetBatchInstancesForRedo("service-a", "group-a")
+	assert.True(t, ok)
+	assert.Equal(t, registered, instances)
+
+	retained := retainInstances(instances, []model.Instance{{Ip: "10.0.0.2", Port: 80}})
+	assert.Equal(t, []model.Instance{{Ip: "10.0.0.1", Port: 80}, {Ip: "10.0.0.2", Port: 81}}, retained)
+	assert.Equal(t, 0, len(retainInstances(instances, registered)))
+}
+
+func TestIsInstanceCachedForRedo(t *testing.T) {
+	evListener := NewConnectionEventListener(nil)
+	instance := model.Instance{Ip: "10.0.0.1", Port: 80, Ephemeral: true}
+	assert.False(t, evListener.IsInstanceCachedForRedo("service-a", "group-a", instance))
+	evListener.CacheInstanceForRedo("service-a", "group-a", instance)
+	assert.True(t, evListener.IsInstanceCachedForRedo("service-a", "group-a", model.Instance{Ip: "10.0.0.1", Port: 80}))
+	assert.False(t, evListener.IsInstanceCachedForRedo("service-a", "group-a", model.Instance{Ip: "10.0.0.1", Port: 81}))
+
+	evListener.CacheInstancesForRedo("service-b", "group-b", []model.Instance{{Ip: "10.0.0.2", Port: 80}})
+	assert.True(t, evListener.IsInstanceCachedForRedo("service-b", "group-b", model.Instance{Ip: "10.0.0.2", Port: 80}))
+	evListener.RemoveInstanceForRedo("service-b", "group-b", model.Instance{})
+	assert.False(t, evListener.IsInstanceCachedForRedo("service-b", "group-b", model.Instance{Ip: "10.0.0.2", Port: 80}))
+}
+
+func TestPatchInstanceMetadataForRedo(t *testing.T) {
+	evListener := NewConnectionEventListener(nil)
+	patch := model.Instance{Ip: "10.0.0.1", Port: 80, Metadata: map[string]string{"version": "v2"}}
+	assert.False(t, evListener.PatchInstanceMetadataForRedo("service-a", "group-a", patch))
+
+	metadata := map[string]string{"version": "v1", "region": "us-east"}
+	evListener.CacheInstanceForRedo("service-a", "group-a", model.Instance{Ip: "10.0.0.1", Port: 80, Metadata: metadata})
+	assert.True(t, evListener.PatchInstanceMetadataForRedo("service-a", "group-a", patch))
+	cached, _ := evListener.registeredInstanceCached.Get("group-a@@service-a")
+	assert.Equal(t, map[string]string{"version": "v2", "region": "us-east"}, cached.(model.Instance).Metadata)
+	assert.Equal(t, "v1", metadata["version"])
+
+	evListener.CacheInstancesForRedo("service-b", "group-b", []model.Instance{{Ip: "10.0.0.1", Port: 80}, {Ip: "10.0.0.2", Port: 80}})
+	assert.True(t, evListener.PatchInstanceMetadataForRedo("service-b", "group-b", patch))
+	instances, _ := evListener.GetBatchInstancesForRedo("service-b", "group-b")
+	assert.Equal(t, "v2", instances[0].Metadata["version"])
+	assert.Nil(t, instances[1].Metadata)
+	assert.False(t, evListener.PatchInstanceMetadataForRedo("service-b", "group-b", model.Instance{Ip: "10.0.0.3", Port: 80}))
+}
+
+func TestGetInstancesForRedo(t *testing.T) {
+	evListener := NewConnectionEventListener(nil)
+	assert.Nil(t, evListener.GetInstancesForRedo("service-a", "group-a"))
+	first := model.Instance{Ip: "10.0.0.1", Port: 80, Ephemeral: true}
+	second := model.Instance{Ip: "10.0.0.1", Port: 81, Ephemeral: true}
+	evListener.CacheInstanceForRedo("service-a", "group-a", first)
+	cached := evListener.GetInstancesForRedo("service-a", "group-a")
+	assert.Equal(t, []model.Instance{first}, cached)
+	assert.Equal(t, 0, len(retainInstances(cached, []model.Instance{first})))
+	assert.Equal(t, []model.Instance{first}, retainInstances(cached, []model.Instance{second}))
+
+	evListener.CacheInstancesForRedo("service-a", "group-a", []model.Instance{first, second})
+	assert.Equal(t, []model.Instance{first, second}, evListener.GetInstancesForRedo("service-a", "group-a"))
+}
@@ -18,8 +18,11 @@ package naming_grpc
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
@@ -28,6 +31,7 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
 	"github.com/jun3372/nacos-sdk-go/inner/uuid"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
@@ -40,6 +44,10 @@ type NamingGrpcProxy struct {
 	rpcClient         rpc.IRpcClient
 	eventListener     *ConnectionEventListener
 	serviceInfoHolder *naming_cache.ServiceInfoHolder
+	pushHandler       *rpc.NamingPushRequestHandler
+	batchMutex        sync.Mutex
+	// shared is true for the proxies of the other namespaces, they don't own the connection
+	shared bool
 }
 
 // NewNamingGrpcProxy create naming grpc proxy
@@ -56,10 +64,7 @@ func NewNamingGrpcProxy(ctx context.Context, clientCfg constant.ClientConfig, na
 		return nil, err
 	}
 
-	labels := map[string]string{
-		constant.LABEL_SOURCE: constant.LABEL_SOURCE_SDK,
-		constant.LABEL_MODULE: constant.LABEL_MODULE_NAMING,
-	}
+	labels := rpc.NewConnectionLabels(clientCfg, constant.LABEL_MODULE_NAMING)
 
 	iRpcClient, err := rpc.CreateClient(ctx, uid.String(), rpc.GRPC, labels, srvProxy.nacosServer)
 	if err != nil {
@@ -69,11 +74,22 @@ func NewNamingGrpcProxy(ctx context.Context, clientCfg constant.ClientConfig, na
 	srvProxy.rpcClient = iRpcClient
 
 	rpcClient := srvProxy.rpcClient.GetRpcClient()
+	rpcClient.MaxCallRecvMsgSize = clientCfg.GrpcMaxCallRecvMsgSize
+	rpcClient.MaxCallSendMsgSize = clientCfg.GrpcMaxCallSendMsgSize
+	rpcClient.TLSConfig = clientCfg.TLSCfg
+	rpcClient.CompressThreshold = rpc.GetCompressThreshold(clientCfg)
+	rpcClient.Dialer = clientCfg.GrpcDialer
+	rpcClient.ProxyURL = clientCfg.ProxyURL
+	rpcClient.WireLog = clientCfg.WireLog
+	rpcClient.DialOptions = clientCfg.GrpcDialOptions
+	rpcClient.ConnectionParams = rpc.NewConnectionParams(clientCfg)
+	rpcClient.RetryPolicy = retry.NewPolicy(clientCfg)
 	rpcClient.Start()
 
+	srvProxy.pushHandler = &rpc.NamingPushRequestHandler{ServiceInfoHolder: serviceInfoHolder}
 	rpcClient.RegisterServerRequestHandler(func() rpc_request.IRequest {
 		return &rpc_request.NotifySubscriberRequest{NamingRequest: &rpc_request.NamingRequest{}}
-	}, &rpc.NamingPushRequestHandler{ServiceInfoHolder: serviceInfoHolder})
+	}, srvProxy.pushHandler)
 
 	srvProxy.eventListener = NewConnectionEventListener(&srvProxy)
 	rpcClient.RegisterConnectionListener(srvProxy.eventListener)
@@ -81,19 +97,59 @@ func NewNamingGrpcProxy(ctx context.Context, clientCfg constant.ClientConfig, na
 	return &srvProxy, nil
 }
 
+// ForNamespace return the proxy of another namespace sharing the connection, the pushes of the namespace
+// are processed by serviceInfoHolder and its registrations and subscriptions are redone on reconnect.
+func (proxy *NamingGrpcProxy) ForNamespace(namespaceId string, serviceInfoHolder *naming_cache.ServiceInfoHolder) *NamingGrpcProxy {
+	clientConfig := proxy.clientConfig
+	clientConfig.NamespaceId = namespaceId
+	srvProxy := &NamingGrpcProxy{
+		clientConfig:      clientConfig,
+		nacosServer:       proxy.nacosServer,
+		rpcClient:         proxy.rpcClient,
+		serviceInfoHolder: serviceInfoHolder,
+		pushHandler:       proxy.pushHandler,
+		shared:            true,
+	}
+	srvProxy.eventListener = NewConnectionEventListener(srvProxy)
+	proxy.rpcClient.GetRpcClient().RegisterConnectionListener(srvProxy.eventListener)
+	proxy.pushHandler.AddNamespace(namespaceId, serviceInfoHolder)
+	return srvProxy
+}
+
+// requestToServer sends the request, it's replayed once after a re-login if the token is rejected.
 func (proxy *NamingGrpcProxy) requestToServer(request rpc_request.IRequest) (rpc_response.IResponse, error) {
+	token := proxy.nacosServer.AccessToken()
+	response, err := proxy.requestOnce(request)
+	if err == nil && response != nil && !response.IsSuccess() && proxy.nacosServer.ReAuth(token, response.GetErrorCode(), response.GetMessage()) {
+		logger.Infof("replay the naming request after re-login, type:%s", request.GetRequestType())
+		response, err = proxy.requestOnce(request)
+	}
+	return response, err
+}
+
+func (proxy *NamingGrpcProxy) requestOnce(request rpc_request.IRequest) (rpc_response.IResponse, error) {
 	start := time.Now()
-	proxy.nacosServer.InjectSign(request, request.GetHeaders(), proxy.clientConfig)
+	proxy.nacosServer.InjectSignWithCredentials(request, request.GetHeaders(), proxy.nacosServer.Credentials())
 	proxy.nacosServer.InjectSecurityInfo(request.GetHeaders())
 	response, err := proxy.rpcClient.GetRpcClient().Request(request, int64(proxy.clientConfig.TimeoutMs))
 	monitor.GetNamingRequestMonitor(constant.GRPC, request.GetRequestType(), rpc_response.GetGrpcResponseStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
 	return response, err
 }
 
-// RegisterInstance ...
+// RegisterInstance register the instance, the server keeps only one ephemeral instance of a service for
+// a connection, so the instances of another ip or port registered before are batch registered together.
 func (proxy *NamingGrpcProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	logger.Infof("register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, util.ToJsonString(instance))
+	if !instance.Ephemeral {
+		return proxy.requestPersistentInstance(serviceName, groupName, "registerInstance", instance)
+	}
+	proxy.batchMutex.Lock()
+	defer proxy.batchMutex.Unlock()
+	others := retainInstances(proxy.eventListener.GetInstancesForRedo(serviceName, groupName), []model.Instance{instance})
+	if len(others) > 0 {
+		return proxy.batchRegister(serviceName, groupName, append(others, instance))
+	}
 	proxy.eventListener.CacheInstanceForRedo(serviceName, groupName, instance)
 	instanceRequest := rpc_request.NewInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "registerInstance", instance)
 	response, err := proxy.requestToServer(instanceRequest)
@@ -107,6 +163,24 @@ func (proxy *NamingGrpcProxy) RegisterInstance(serviceName string, groupName str
 func (proxy *NamingGrpcProxy) BatchRegisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
 	logger.Infof("batch register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, util.ToJsonString(instances))
+	for _, instance := range instances {
+		if !instance.Ephemeral && !proxy.SupportPersistentInstance() {
+			return false, errors.New("batch register persistent instances is not supported by the server")
+		}
+	}
+	proxy.batchMutex.Lock()
+	defer proxy.batchMutex.Unlock()
+	return proxy.batchRegister(serviceName, groupName, instances)
+}
+
+// SupportPersistentInstance return false if the server reported it can't register the persistent instances by grpc,
+// the servers before 2.2 don't report their abilities, so it's assumed to be supported.
+func (proxy *NamingGrpcProxy) SupportPersistentInstance() bool {
+	return proxy.rpcClient.GetRpcClient().ServerAbility(constant.ABILITY_PERSISTENT_BY_GRPC) != rpc.ABILITY_NOT_SUPPORTED
+}
+
+// batchRegister cache the instances for redo and batch register them, it must be called with batchMutex held.
+func (proxy *NamingGrpcProxy) batchRegister(serviceName string, groupName string, instances []model.Instance) (bool, error) {
 	proxy.eventListener.CacheInstancesForRedo(serviceName, groupName, instances)
 	batchInstanceRequest := rpc_request.NewBatchInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "batchRegisterInstance", instances)
 	response, err := proxy.requestToServer(batchInstanceRequest)
@@ -116,10 +190,68 @@ func (proxy *NamingGrpcProxy) BatchRegisterInstance(serviceName string, groupNam
 	return response.IsSuccess(), err
 }
 
-// DeregisterInstance ...
+// BatchDeregisterInstance deregister the instances of a BatchRegisterInstance, the server replaces the batch with
+// the instances retained, just like the java client does.
+func (proxy *NamingGrpcProxy) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	proxy.batchMutex.Lock()
+	defer proxy.batchMutex.Unlock()
+	registered, ok := proxy.eventListener.GetBatchInstancesForRedo(serviceName, groupName)
+	if !ok {
+		return false, errors.Errorf("batch deregister instance failed, the instances of service %s are not batch registered",
+			util.GetGroupName(serviceName, groupName))
+	}
+	logger.Infof("batch deregister instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
+		proxy.clientConfig.NamespaceId, serviceName, util.ToJsonString(instances))
+	retained := retainInstances(registered, instances)
+	batchInstanceRequest := rpc_request.NewBatchInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "batchRegisterInstance", retained)
+	response, err := proxy.requestToServer(batchInstanceRequest)
+	if err != nil {
+		return false, err
+	}
+	if len(retained) > 0 {
+		proxy.eventListener.CacheInstancesForRedo(serviceName, groupName, retained)
+	} else {
+		proxy.eventListener.RemoveInstanceForRedo(serviceName, groupName, model.Instance{})
+	}
+	return response.IsSuccess(), err
+}
+
+// retainInstances return the registered instances whose ip and port are not in the deregistered ones.
+func retainInstances(registered, deregistered []model.Instance) []model.Instance {
+	retained := make([]model.Instance, 0, len(registered))
+	for _, instance := range registered {
+		var found bool
+		for _, deregister := range deregistered {
+			if instance.Ip == deregister.Ip && instance.Port == deregister.Port {
+				found = true
+				break
+			}
+		}
+		if !found {
+			retained = append(retained, instance)
+		}
+	}
+	return retained
+}
+
+// DeregisterInstance deregister the instance, the other instances of the service registered by the client
+// are batch registered again to keep them.
 func (proxy *NamingGrpcProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	logger.Infof("deregister instance namespaceId:<%s>,serviceName:<%s> with instance:<%s:%d@%s>",
 		proxy.clientConfig.NamespaceId, serviceName, instance.Ip, instance.Port, instance.ClusterName)
+	if !instance.Ephemeral {
+		return proxy.requestPersistentInstance(serviceName, groupName, "deregisterInstance", instance)
+	}
+	proxy.batchMutex.Lock()
+	defer proxy.batchMutex.Unlock()
+	cached := proxy.eventListener.GetInstancesForRedo(serviceName, groupName)
+	if others := retainInstances(cached, []model.Instance{instance}); len(others) > 0 {
+		if len(others) == len(cached) {
+			return false, errors.Errorf("deregister instance failed, the instance %s:%d of service %s is not registered",
+				instance.Ip, instance.Port, util.GetGroupName(serviceName, groupName))
+		}
+		return proxy.batchRegister(serviceName, groupName, others)
+	}
 	instanceRequest := rpc_request.NewInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "deregisterInstance", instance)
 	response, err := proxy.requestToServer(instanceRequest)
 	proxy.eventListener.RemoveInstanceForRedo(serviceName, groupName, instance)
@@ -129,6 +261,30 @@ func (proxy *NamingGrpcProxy) DeregisterInstance(serviceName string, groupName s
 	return response.IsSuccess(), err
 }
 
+// requestPersistentInstance send a PersistentInstanceRequest, the persistent instances are kept by the server
+// after the connection is lost, so they are not cached for redo.
+func (proxy *NamingGrpcProxy) requestPersistentInstance(serviceName, groupName, requestType string, instance model.Instance) (bool, error) {
+	request := rpc_request.NewPersistentInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, requestType, instance)
+	response, err := proxy.requestToServer(request)
+	if err != nil {
+		return false, err
+	}
+	return response.IsSuccess(), err
+}
+
+// UpdateInstanceMetadata patch the metadata of the instance cached for redo, so reconnects don't revert it.
+// There is no grpc request to update the metadata, the server is updated by the http proxy.
+func (proxy *NamingGrpcProxy) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	proxy.batchMutex.Lock()
+	defer proxy.batchMutex.Unlock()
+	return proxy.eventListener.PatchInstanceMetadataForRedo(serviceName, groupName, instance), nil
+}
+
+// IsInstanceCachedForRedo report whether the instance is registered as an ephemeral instance by this proxy.
+func (proxy *NamingGrpcProxy) IsInstanceCachedForRedo(serviceName, groupName string, instance model.Instance) bool {
+	return proxy.eventListener.IsInstanceCachedForRedo(serviceName, groupName, instance)
+}
+
 // GetServiceList ...
 func (proxy *NamingGrpcProxy) GetServiceList(pageNo uint32, pageSize uint32, groupName, namespaceId string, selector *model.ExpressionSelector) (model.ServiceList, error) {
 	var selectorStr string
@@ -176,6 +332,9 @@ func (proxy *NamingGrpcProxy) IsSubscribed(serviceName, groupName string, cluste
 func (proxy *NamingGrpcProxy) Subscribe(serviceName, groupName string, clusters string) (model.Service, error) {
 	logger.Infof("Subscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, groupName, clusters)
+	if !proxy.eventListener.IsSubscriberCached(util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)) {
+		monitor.GetNamingMetrics().AddSubscriptions(1)
+	}
 	proxy.eventListener.CacheSubscriberForRedo(util.GetGroupName(serviceName, groupName), clusters)
 	request := rpc_request.NewSubscribeServiceRequest(proxy.clientConfig.NamespaceId, serviceName,
 		groupName, clusters, true)
@@ -192,13 +351,67 @@ func (proxy *NamingGrpcProxy) Subscribe(serviceName, groupName string, clusters
 func (proxy *NamingGrpcProxy) Unsubscribe(serviceName, groupName, clusters string) error {
 	logger.Infof("Unsubscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, groupName, clusters)
+	if proxy.eventListener.IsSubscriberCached(util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)) {
+		monitor.GetNamingMetrics().AddSubscriptions(-1)
+	}
 	proxy.eventListener.RemoveSubscriberForRedo(util.GetGroupName(serviceName, groupName), clusters)
 	_, err := proxy.requestToServer(rpc_request.NewSubscribeServiceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName,
 		clusters, false))
 	return err
 }
 
+// CreateService is not supported by grpc, the services are created by the http api.
+func (proxy *NamingGrpcProxy) CreateService(service model.ServiceDefinition) (bool, error) {
+	return false, errors.New("create service is not supported by grpc")
+}
+
+// UpdateService is not supported by grpc, the services are updated by the http api.
+func (proxy *NamingGrpcProxy) UpdateService(service model.ServiceDefinition) (bool, error) {
+	return false, errors.New("update service is not supported by grpc")
+}
+
+// DeleteService is not supported by grpc, the services are deleted by the http api.
+func (proxy *NamingGrpcProxy) DeleteService(serviceName, groupName string) (bool, error) {
+	return false, errors.New("delete service is not supported by grpc")
+}
+
+// GetServiceDetail is not supported by grpc, the services are queried by the http api.
+func (proxy *NamingGrpcProxy) GetServiceDetail(serviceName, groupName string) (model.ServiceDefinition, error) {
+	return model.ServiceDefinition{}, errors.New("get service detail is not supported by grpc")
+}
+
+// WatchConnection add a callback of the events of the rpc connection, it's shared by the namespaces.
+func (proxy *NamingGrpcProxy) WatchConnection(callback *func(event model.ConnectionEvent)) error {
+	proxy.rpcClient.GetRpcClient().WatchConnectionEvents(callback)
+	return nil
+}
+
+func (proxy *NamingGrpcProxy) UnwatchConnection(callback *func(event model.ConnectionEvent)) {
+	proxy.rpcClient.GetRpcClient().UnwatchConnectionEvents(callback)
+}
+
+// AddInterceptor install the interceptor on the grpc client, which is shared with the other namespaces.
+func (proxy *NamingGrpcProxy) AddInterceptor(interceptor rpc.Interceptor) error {
+	proxy.rpcClient.GetRpcClient().AddInterceptor(interceptor)
+	return nil
+}
+
+// RegisterServerRequestHandler register the handler on the grpc client, which is shared with the other namespaces.
+func (proxy *NamingGrpcProxy) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	proxy.rpcClient.GetRpcClient().RegisterServerRequestHandler(request, handler)
+	return nil
+}
+
 func (proxy *NamingGrpcProxy) CloseClient() {
+	if proxy.shared {
+		proxy.rpcClient.GetRpcClient().RemoveConnectionListener(proxy.eventListener)
+		proxy.pushHandler.RemoveNamespace(proxy.clientConfig.NamespaceId)
+		return
+	}
 	logger.Info("Close Nacos Go SDK Client...")
-	proxy.rpcClient.GetRpcClient().Shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), constant.DEFAULT_TIMEOUT_MILLS*time.Millisecond)
+	defer cancel()
+	if err := proxy.rpcClient.GetRpcClient().Shutdown(ctx); err != nil {
+		logger.Warnf("close naming grpc client failed:%+v", err)
+	}
 }
@@ -13,6 +13,14 @@ func (m *MockNamingGrpc) BatchRegisterInstance(serviceName string, groupName str
 	return true, nil
 }
 
+func (m *MockNamingGrpc) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	return true, nil
+}
+
+func (m *MockNamingGrpc) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	return true, nil
+}
+
 func (m *MockNamingGrpc) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	return true, nil
 }
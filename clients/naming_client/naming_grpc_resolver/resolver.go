@@ -0,0 +1,156 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_grpc_resolver
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// Scheme is the scheme of the dial targets resolved by the nacos builder.
+const Scheme = "nacos"
+
+type weightKey struct{}
+
+// Builder build the resolvers of the targets nacos:///group/service and nacos:///service, the clusters can be
+// set by the query, e.g. nacos:///group/service?clusters=a,b. The addresses are the healthy and enabled
+// instances, they are updated on every push of the subscribed service.
+type Builder struct {
+	client naming_client.INamingClient
+}
+
+// NewBuilder create a builder resolving the targets with the naming client.
+func NewBuilder(client naming_client.INamingClient) *Builder {
+	return &Builder{client: client}
+}
+
+// Register create a builder with the naming client and register it to the grpc resolvers, so that
+// grpc.Dial("nacos:///group/service") works without the grpc.WithResolvers option.
+func Register(client naming_client.INamingClient) {
+	resolver.Register(NewBuilder(client))
+}
+
+// Weight return the weight of the instance of the address, 0 if it's not resolved by nacos.
+func Weight(addr resolver.Address) float64 {
+	weight, _ := addr.Attributes.Value(weightKey{}).(float64)
+	return weight
+}
+
+func (b *Builder) Scheme() string {
+	return Scheme
+}
+
+func (b *Builder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	param, err := parseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	r := &nacosResolver{client: b.client, cc: cc, param: param}
+	param.SubscribeCallback = r.update
+	if err = b.client.Subscribe(param); err != nil {
+		return nil, errors.Wrapf(err, "subscribe service %s of group %s", param.ServiceName, param.GroupName)
+	}
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	return r, nil
+}
+
+func parseTarget(target resolver.Target) (*vo.SubscribeParam, error) {
+	path := strings.Trim(target.URL.Path, "/")
+	if path == "" {
+		path = strings.Trim(target.URL.Opaque, "/")
+	}
+	param := &vo.SubscribeParam{GroupName: constant.DEFAULT_GROUP}
+	if index := strings.Index(path, "/"); index >= 0 {
+		param.GroupName = path[:index]
+		param.ServiceName = path[index+1:]
+	} else {
+		param.ServiceName = path
+	}
+	if param.ServiceName == "" || param.GroupName == "" {
+		return nil, errors.Errorf("invalid nacos target %s, it must be nacos:///group/service", target.URL.String())
+	}
+	if clusters := target.URL.Query().Get("clusters"); clusters != "" {
+		param.Clusters = strings.Split(clusters, ",")
+	}
+	return param, nil
+}
+
+type nacosResolver struct {
+	client naming_client.INamingClient
+	cc     resolver.ClientConn
+	param  *vo.SubscribeParam
+	mutex  sync.Mutex
+	closed bool
+}
+
+// ResolveNow update the addresses from the cache of the subscribed service.
+func (r *nacosResolver) ResolveNow(resolver.ResolveNowOptions) {
+	// an empty service is not an error of resolving, it updates no addresses to close the connections
+	instances, _ := r.client.SelectInstances(vo.SelectInstancesParam{
+		ServiceName: r.param.ServiceName,
+		GroupName:   r.param.GroupName,
+		Clusters:    r.param.Clusters,
+		HealthyOnly: true,
+	})
+	r.update(instances, nil)
+}
+
+func (r *nacosResolver) update(instances []model.Instance, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return
+	}
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+	addresses := make([]resolver.Address, 0, len(instances))
+	for _, instance := range instances {
+		if !instance.Healthy || !instance.Enable || instance.Weight <= 0 {
+			continue
+		}
+		addresses = append(addresses, resolver.Address{
+			Addr:       net.JoinHostPort(instance.Ip, strconv.FormatUint(instance.Port, 10)),
+			Attributes: attributes.New(weightKey{}, instance.Weight),
+		})
+	}
+	if err = r.cc.UpdateState(resolver.State{Addresses: addresses}); err != nil {
+		logger.Warnf("update grpc addresses of service %s failed: %v", r.param.ServiceName, err)
+	}
+}
+
+func (r *nacosResolver) Close() {
+	r.mutex.Lock()
+	r.closed = true
+	r.mutex.Unlock()
+	if err := r.client.Unsubscribe(r.param); err != nil {
+		logger.Warnf("unsubscribe service %s of group %s failed: %v", r.param.ServiceName, r.param.GroupName, err)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_grpc_resolver
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+type mockNamingClient struct {
+	naming_client.INamingClient
+	subscribed   *vo.SubscribeParam
+	unsubscribed *vo.SubscribeParam
+	instances    []model.Instance
+}
+
+func (m *mockNamingClient) Subscribe(param *vo.SubscribeParam) error {
+	m.subscribed = param
+	return nil
+}
+
+func (m *mockNamingClient) Unsubscribe(param *vo.SubscribeParam) error {
+	m.unsubscribed = param
+	return nil
+}
+
+func (m *mockNamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	return m.instances, nil
+}
+
+type mockClientConn struct {
+	resolver.ClientConn
+	states []resolver.State
+}
+
+func (m *mockClientConn) UpdateState(state resolver.State) error {
+	m.states = append(m.states, state)
+	return nil
+}
+
+func (m *mockClientConn) ReportError(err error) {}
+
+func target(t *testing.T, dial string) resolver.Target {
+	u, err := url.Parse(dial)
+	assert.Nil(t, err)
+	return resolver.Target{URL: *u}
+}
+
+func TestBuilder(t *testing.T) {
+	client := &mockNamingClient{instances: []model.Instance{{Ip: "10.0.0.1", Port: 8080, Weight: 2, Enable: true, Healthy: true}}}
+	builder := NewBuilder(client)
+	assert.Equal(t, "nacos", builder.Scheme())
+	cc := &mockClientConn{}
+	r, err := builder.Build(target(t, "nacos:///group-a/service-a?clusters=a,b"), cc, resolver.BuildOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "service-a", client.subscribed.ServiceName)
+	assert.Equal(t, "group-a", client.subscribed.GroupName)
+	assert.Equal(t, []string{"a", "b"}, client.subscribed.Clusters)
+	assert.Equal(t, 1, len(cc.states))
+	assert.Equal(t, "10.0.0.1:8080", cc.states[0].Addresses[0].Addr)
+	assert.Equal(t, float64(2), Weight(cc.states[0].Addresses[0]))
+
+	client.subscribed.SubscribeCallback([]model.Instance{
+		{Ip: "10.0.0.1", Port: 8080, Weight: 1, Enable: true, Healthy: false},
+		{Ip: "10.0.0.2", Port: 8080, Weight: 1, Enable: true, Healthy: true},
+		{Ip: "10.0.0.3", Port: 8080, Weight: 1, Enable: false, Healthy: true},
+	}, nil)
+	assert.Equal(t, 2, len(cc.states))
+	assert.Equal(t, 1, len(cc.states[1].Addresses))
+	assert.Equal(t, "10.0.0.2:8080", cc.states[1].Addresses[0].Addr)
+
+	r.Close()
+	assert.Equal(t, client.subscribed, client.unsubscribed)
+	client.subscribed.SubscribeCallback(nil, nil)
+	assert.Equal(t, 2, len(cc.states))
+
+	_, err = builder.Build(target(t, "nacos:///service-b"), &mockClientConn{}, resolver.BuildOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "DEFAULT_GROUP", client.subscribed.GroupName)
+	_, err = builder.Build(target(t, "nacos:///"), &mockClientConn{}, resolver.BuildOptions{})
+	assert.NotNil(t, err)
+}
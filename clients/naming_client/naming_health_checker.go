@@ -0,0 +1,179 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+const defaultHealthCheckInterval = 5 * time.Second
+
+// healthChecker re-register the local instance when its health supplier changes, the flags registered by
+// the user are kept while the supplier reports healthy.
+type healthChecker struct {
+	serviceName string
+	groupName   string
+	mutex       sync.Mutex
+	instance    model.Instance
+	supplier    func() bool
+	healthy     bool
+	cancel      context.CancelFunc
+}
+
+func healthCheckKey(serviceName, groupName, ip string, port uint64, clusterName string) string {
+	return fmt.Sprintf("%s#%s:%d#%s", util.GetGroupName(serviceName, groupName), ip, port, clusterName)
+}
+
+// reportedInstance return the instance to register for the health reported by the supplier.
+func (h *healthChecker) reportedInstance(healthy bool) model.Instance {
+	h.mutex.Lock()
+	instance := h.instance
+	h.mutex.Unlock()
+	instance.Healthy = instance.Healthy && healthy
+	instance.Enable = instance.Enable && healthy
+	return instance
+}
+
+// update apply the update to the instance and return the instance to register for the current health.
+func (h *healthChecker) update(update func(instance *model.Instance)) model.Instance {
+	h.mutex.Lock()
+	h.instance.Metadata = util.DeepCopyMap(h.instance.Metadata)
+	update(&h.instance)
+	healthy := h.healthy
+	h.mutex.Unlock()
+	return h.reportedInstance(healthy)
+}
+
+func (h *healthChecker) isHealthy() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.healthy
+}
+
+func (h *healthChecker) setHealthy(healthy bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.healthy = healthy
+}
+
+func (h *healthChecker) check() (healthy bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("health supplier of instance %s:%d panic: %v", h.instance.Ip, h.instance.Port, r)
+			healthy = false
+		}
+	}()
+	return h.supplier()
+}
+
+// startHealthCheck replace the health checker of the instance, it must be called after the instance is registered.
+func (sc *NamingClient) startHealthCheck(serviceName, groupName string, instance model.Instance, supplier func() bool, healthy bool) {
+	ctx, cancel := context.WithCancel(sc.ctx)
+	checker := &healthChecker{
+		serviceName: serviceName,
+		groupName:   groupName,
+		instance:    instance,
+		supplier:    supplier,
+		healthy:     healthy,
+		cancel:      cancel,
+	}
+	key := healthCheckKey(serviceName, groupName, instance.Ip, instance.Port, instance.ClusterName)
+	sc.healthMutex.Lock()
+	if sc.healthCheckers == nil {
+		sc.healthCheckers = make(map[string]*healthChecker)
+	}
+	if old, ok := sc.healthCheckers[key]; ok {
+		old.cancel()
+	}
+	sc.healthCheckers[key] = checker
+	sc.healthMutex.Unlock()
+
+	interval := defaultHealthCheckInterval
+	if clientConfig, err := sc.GetClientConfig(); err == nil && clientConfig.BeatInterval > 0 {
+		interval = time.Duration(clientConfig.BeatInterval) * time.Millisecond
+	}
+	go sc.runHealthCheck(ctx, checker, interval)
+}
+
+func (sc *NamingClient) stopHealthCheck(serviceName, groupName, ip string, port uint64, clusterName string) {
+	key := healthCheckKey(serviceName, groupName, ip, port, clusterName)
+	sc.healthMutex.Lock()
+	defer sc.healthMutex.Unlock()
+	if checker, ok := sc.healthCheckers[key]; ok {
+		checker.cancel()
+		delete(sc.healthCheckers, key)
+	}
+}
+
+func (sc *NamingClient) runHealthCheck(ctx context.Context, checker *healthChecker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		healthy := checker.check()
+		if healthy == checker.isHealthy() {
+			continue
+		}
+		logger.Infof("health supplier of instance %s:%d in service %s reports healthy:%t", checker.instance.Ip,
+			checker.instance.Port, util.GetGroupName(checker.serviceName, checker.groupName), healthy)
+		if !sc.reportHealth(ctx, checker, healthy, interval) {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		checker.setHealthy(healthy)
+	}
+}
+
+// reportHealth register the instance with the health, it holds the registeredMutex so a deregistration which
+// stops the checker waits for it, and it's not sent once the checker is stopped.
+func (sc *NamingClient) reportHealth(ctx context.Context, checker *healthChecker, healthy bool, interval time.Duration) bool {
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	if ctx.Err() != nil {
+		return false
+	}
+	if _, err := sc.serviceProxy.RegisterInstance(checker.serviceName, checker.groupName, checker.reportedInstance(healthy)); err != nil {
+		logger.Errorf("update health of instance %s:%d failed, retry in %v: %v", checker.instance.Ip, checker.instance.Port, interval, err)
+		return false
+	}
+	return true
+}
+
+// updateHealthChecker apply the update to the instance of the health checker, false if it has no health checker.
+func (sc *NamingClient) updateHealthChecker(serviceName, groupName string, target model.Instance,
+	update func(instance *model.Instance)) (model.Instance, bool) {
+	sc.healthMutex.Lock()
+	defer sc.healthMutex.Unlock()
+	checker, ok := sc.healthCheckers[healthCheckKey(serviceName, groupName, target.Ip, target.Port, target.ClusterName)]
+	if !ok {
+		return model.Instance{}, false
+	}
+	return checker.update(update), true
+}
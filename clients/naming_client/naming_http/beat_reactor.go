@@ -100,6 +100,24 @@ func (br *BeatReactor) RemoveBeatInfo(serviceName string, ip string, port uint64
 
 }
 
+// UpdateBeatMetadata restart the beat of the instance with the metadata merged, the running beat
+// is not modified in place as it's being sent concurrently.
+func (br *BeatReactor) UpdateBeatMetadata(serviceName string, ip string, port uint64, metadata map[string]string) {
+	k := buildKey(serviceName, ip, port)
+	data, exist := br.beatMap.Get(k)
+	if !exist {
+		return
+	}
+	beatInfo := *data.(*model.BeatInfo)
+	beatInfo.State = model.StateRunning
+	beatInfo.Metadata = util.DeepCopyMap(beatInfo.Metadata)
+	for key, value := range metadata {
+		beatInfo.Metadata[key] = value
+	}
+	br.RemoveBeatInfo(serviceName, ip, port)
+	br.AddBeatInfo(serviceName, &beatInfo)
+}
+
 func (br *BeatReactor) sendInstanceBeat(k string, beatInfo *model.BeatInfo) {
 	t := time.NewTimer(beatInfo.Period)
 	defer t.Stop()
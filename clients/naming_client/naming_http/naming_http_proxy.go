@@ -18,9 +18,10 @@ package naming_http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -29,7 +30,10 @@ import (
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_server"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
 )
@@ -40,6 +44,8 @@ type NamingHttpProxy struct {
 	nacosServer       *nacos_server.NacosServer
 	beatReactor       BeatReactor
 	serviceInfoHolder *naming_cache.ServiceInfoHolder
+	pushReceiver      *PushReceiver
+	subscribed        sync.Map // the cache keys of the subscribed services, to count the subscriptions
 }
 
 // NewNamingHttpProxy  create naming http proxy
@@ -49,15 +55,33 @@ func NewNamingHttpProxy(ctx context.Context, clientCfg constant.ClientConfig, na
 		clientConfig:      clientCfg,
 		nacosServer:       nacosServer,
 		serviceInfoHolder: serviceInfoHolder,
+		pushReceiver:      NewPushReceiver(ctx, serviceInfoHolder),
 	}
 
 	srvProxy.beatReactor = NewBeatReactor(ctx, clientCfg, nacosServer)
 
-	NewPushReceiver(ctx, serviceInfoHolder).startServer()
-
 	return &srvProxy, nil
 }
 
+// StartPushReceiver start the udp server receiving the pushes of the 1.x servers, the services queried
+// afterwards are pushed to it.
+func (proxy *NamingHttpProxy) StartPushReceiver() {
+	proxy.pushReceiver.startServer()
+}
+
+// ServerVersion return the version of the nacos server, such as 1.4.1.
+func (proxy *NamingHttpProxy) ServerVersion() (string, error) {
+	result, err := proxy.nacosServer.ReqApi(constant.SERVER_STATE_PATH, map[string]string{}, http.MethodGet, proxy.clientConfig)
+	if err != nil {
+		return "", err
+	}
+	version, err := jsonparser.GetString([]byte(result), "version")
+	if err != nil {
+		return "", errors.Wrapf(err, "get 'version' from <%s> error", result)
+	}
+	return version, nil
+}
+
 // RegisterInstance ...
 func (proxy *NamingHttpProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	logger.Infof("register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
@@ -88,7 +112,7 @@ func (proxy *NamingHttpProxy) RegisterInstance(serviceName string, groupName str
 			ServiceName: util.GetGroupName(serviceName, groupName),
 			Cluster:     instance.ClusterName,
 			Weight:      instance.Weight,
-			Period:      util.GetDurationWithDefault(instance.Metadata, constant.HEART_BEAT_INTERVAL, time.Second*5),
+			Period:      instance.GetHeartBeatInterval(),
 			State:       model.StateRunning,
 		}
 		proxy.beatReactor.AddBeatInfo(util.GetGroupName(serviceName, groupName), beatInfo)
@@ -97,7 +121,41 @@ func (proxy *NamingHttpProxy) RegisterInstance(serviceName string, groupName str
 }
 
 func (proxy *NamingHttpProxy) BatchRegisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
-	panic("implement me")
+	return false, errors.New("batch register instances is not supported by http")
+}
+
+func (proxy *NamingHttpProxy) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	return false, errors.New("batch deregister instances is not supported by http")
+}
+
+// UpdateInstanceMetadata patch the metadata of a registered instance by the metadata batch update api,
+// the keys not in instance.Metadata are kept.
+func (proxy *NamingHttpProxy) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	serviceName = util.GetGroupName(serviceName, groupName)
+	logger.Infof("update instance metadata namespaceId:<%s>,serviceName:<%s> with instance:<%s:%d@%s>,metadata:<%s>",
+		proxy.clientConfig.NamespaceId, serviceName, instance.Ip, instance.Port, instance.ClusterName, util.ToJsonString(instance.Metadata))
+	target := map[string]interface{}{"ip": instance.Ip, "port": instance.Port}
+	if instance.ClusterName != "" {
+		target["clusterName"] = instance.ClusterName
+	}
+	consistencyType := "persistent"
+	if instance.Ephemeral {
+		consistencyType = "ephemeral"
+	}
+	params := map[string]string{}
+	params["namespaceId"] = proxy.clientConfig.NamespaceId
+	params["serviceName"] = serviceName
+	params["consistencyType"] = consistencyType
+	params["instances"] = util.ToJsonString([]map[string]interface{}{target})
+	params["metadata"] = util.ToJsonString(instance.Metadata)
+	_, err := proxy.nacosServer.ReqApi(constant.SERVICE_METADATA_PATH, params, http.MethodPut, proxy.clientConfig)
+	if err != nil {
+		return false, err
+	}
+	if instance.Ephemeral {
+		proxy.beatReactor.UpdateBeatMetadata(serviceName, instance.Ip, instance.Port, instance.Metadata)
+	}
+	return true, nil
 }
 
 // DeregisterInstance ...
@@ -191,6 +249,9 @@ func (proxy *NamingHttpProxy) QueryInstancesOfService(serviceName, groupName, cl
 	param["serviceName"] = util.GetGroupName(serviceName, groupName)
 	param["app"] = proxy.clientConfig.AppName
 	param["clusters"] = clusters
+	if udpPort == 0 {
+		udpPort = proxy.pushReceiver.Port()
+	}
 	param["udpPort"] = strconv.Itoa(udpPort)
 	param["healthyOnly"] = strconv.FormatBool(healthyOnly)
 	param["clientIP"] = util.LocalIP()
@@ -203,16 +264,121 @@ func (proxy *NamingHttpProxy) QueryInstancesOfService(serviceName, groupName, cl
 
 }
 
-// Subscribe ...
+// Subscribe query the instances of the service, the 1.x servers push its changes to the udp port of the
+// push receiver until the client stops querying it.
 func (proxy *NamingHttpProxy) Subscribe(serviceName, groupName, clusters string) (model.Service, error) {
-	return model.Service{}, nil
+	if _, loaded := proxy.subscribed.LoadOrStore(util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters), struct{}{}); !loaded {
+		monitor.GetNamingMetrics().AddSubscriptions(1)
+	}
+	if proxy.pushReceiver.Port() == 0 {
+		return model.Service{}, nil
+	}
+	service, err := proxy.QueryInstancesOfService(serviceName, groupName, clusters, 0, false)
+	if err != nil {
+		return model.Service{}, err
+	}
+	return *service, nil
 }
 
 // Unsubscribe ...
 func (proxy *NamingHttpProxy) Unsubscribe(serviceName, groupName, clusters string) error {
+	if _, loaded := proxy.subscribed.LoadAndDelete(util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)); loaded {
+		monitor.GetNamingMetrics().AddSubscriptions(-1)
+	}
 	return nil
 }
 
+// CreateService create the service with the service level settings.
+func (proxy *NamingHttpProxy) CreateService(service model.ServiceDefinition) (bool, error) {
+	logger.Infof("create service namespaceId:<%s>,serviceName:<%s>,groupName:<%s> with definition:<%s>",
+		proxy.clientConfig.NamespaceId, service.Name, service.GroupName, util.ToJsonString(service))
+	_, err := proxy.nacosServer.ReqApi(constant.SERVICE_INFO_PATH, proxy.serviceParams(service), http.MethodPost, proxy.clientConfig)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateService update the service level settings of the service, the metadata is replaced.
+func (proxy *NamingHttpProxy) UpdateService(service model.ServiceDefinition) (bool, error) {
+	logger.Infof("update service namespaceId:<%s>,serviceName:<%s>,groupName:<%s> with definition:<%s>",
+		proxy.clientConfig.NamespaceId, service.Name, service.GroupName, util.ToJsonString(service))
+	_, err := proxy.nacosServer.ReqApi(constant.SERVICE_INFO_PATH, proxy.serviceParams(service), http.MethodPut, proxy.clientConfig)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (proxy *NamingHttpProxy) serviceParams(service model.ServiceDefinition) map[string]string {
+	selector := service.Selector
+	if len(selector.Type) == 0 {
+		selector.Type = "none"
+	}
+	metadata := service.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	params := map[string]string{}
+	params["namespaceId"] = proxy.clientConfig.NamespaceId
+	params["serviceName"] = service.Name
+	params["groupName"] = service.GroupName
+	params["protectThreshold"] = strconv.FormatFloat(service.ProtectThreshold, 'f', -1, 64)
+	params["metadata"] = util.ToJsonString(metadata)
+	params["selector"] = util.ToJsonString(selector)
+	return params
+}
+
+// DeleteService delete the service, the server rejects it when the service has instances.
+func (proxy *NamingHttpProxy) DeleteService(serviceName, groupName string) (bool, error) {
+	logger.Infof("delete service namespaceId:<%s>,serviceName:<%s>,groupName:<%s>",
+		proxy.clientConfig.NamespaceId, serviceName, groupName)
+	params := map[string]string{}
+	params["namespaceId"] = proxy.clientConfig.NamespaceId
+	params["serviceName"] = serviceName
+	params["groupName"] = groupName
+	_, err := proxy.nacosServer.ReqApi(constant.SERVICE_INFO_PATH, params, http.MethodDelete, proxy.clientConfig)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetServiceDetail return the service level settings and the clusters of the service.
+func (proxy *NamingHttpProxy) GetServiceDetail(serviceName, groupName string) (model.ServiceDefinition, error) {
+	params := map[string]string{}
+	params["namespaceId"] = proxy.clientConfig.NamespaceId
+	params["serviceName"] = serviceName
+	params["groupName"] = groupName
+	var service model.ServiceDefinition
+	result, err := proxy.nacosServer.ReqApi(constant.SERVICE_INFO_PATH, params, http.MethodGet, proxy.clientConfig)
+	if err != nil {
+		return service, err
+	}
+	if err = json.Unmarshal([]byte(result), &service); err != nil {
+		return service, errors.Wrapf(err, "get service detail of service:<%s> from <%s> error", serviceName, result)
+	}
+	return service, nil
+}
+
+// WatchConnection is not supported by http, there's no long-lived connection to the server.
+func (proxy *NamingHttpProxy) WatchConnection(callback *func(event model.ConnectionEvent)) error {
+	return errors.New("connection events are not supported by http")
+}
+
+func (proxy *NamingHttpProxy) UnwatchConnection(callback *func(event model.ConnectionEvent)) {
+}
+
+// AddInterceptor is not supported by http, the interceptors are called around the grpc requests.
+func (proxy *NamingHttpProxy) AddInterceptor(interceptor rpc.Interceptor) error {
+	return errors.New("interceptors are not supported by http")
+}
+
+// RegisterServerRequestHandler is not supported by http, the pushes are received by udp.
+func (proxy *NamingHttpProxy) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	return errors.New("server request handlers are not supported by http")
+}
+
 func (proxy *NamingHttpProxy) CloseClient() {
 
 }
@@ -0,0 +1,51 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+type subscriptionNamingMetrics struct {
+	monitor.NamingMetrics
+	subscriptions int
+}
+
+func (m *subscriptionNamingMetrics) AddSubscriptions(delta int) {
+	m.subscriptions += delta
+}
+
+func TestNamingHttpProxy_SubscriptionsGauge(t *testing.T) {
+	metrics := &subscriptionNamingMetrics{NamingMetrics: monitor.GetNamingMetrics()}
+	monitor.SetNamingMetrics(metrics)
+	defer monitor.SetNamingMetrics(nil)
+
+	proxy := &NamingHttpProxy{pushReceiver: NewPushReceiver(context.Background(), nil)}
+	_, err := proxy.Subscribe("demo", "DEFAULT_GROUP", "")
+	assert.Nil(t, err)
+	_, err = proxy.Subscribe("demo", "DEFAULT_GROUP", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, metrics.subscriptions)
+
+	assert.Nil(t, proxy.Unsubscribe("demo", "DEFAULT_GROUP", ""))
+	assert.Nil(t, proxy.Unsubscribe("demo", "DEFAULT_GROUP", ""))
+	assert.Equal(t, 0, metrics.subscriptions)
+}
@@ -25,6 +25,7 @@ import (
 	"math/rand"
 	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
@@ -34,6 +35,8 @@ import (
 
 type PushReceiver struct {
 	ctx               context.Context
+	mutex             sync.Mutex
+	started           bool
 	port              int
 	host              string
 	serviceInfoHolder *naming_cache.ServiceInfoHolder
@@ -73,7 +76,22 @@ func (us *PushReceiver) tryListen() (*net.UDPConn, bool) {
 	return conn, true
 }
 
+// Port return the udp port listened, 0 if the server is not started.
+func (us *PushReceiver) Port() int {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	if !us.started {
+		return 0
+	}
+	return us.port
+}
+
 func (us *PushReceiver) startServer() {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+	if us.started {
+		return
+	}
 	var (
 		conn *net.UDPConn
 		ok   bool
@@ -98,6 +116,7 @@ func (us *PushReceiver) startServer() {
 	if conn == nil {
 		return
 	}
+	us.started = true
 
 	go func() {
 		defer conn.Close()
@@ -132,7 +151,7 @@ func (us *PushReceiver) handleClient(conn *net.UDPConn) {
 	ack := make(map[string]string)
 
 	if pushData.PushType == "dom" || pushData.PushType == "service" {
-		us.serviceInfoHolder.ProcessServiceJson(pushData.Data)
+		us.serviceInfoHolder.ProcessPushService(util.JsonToService(pushData.Data))
 
 		ack["type"] = "push-ack"
 		ack["lastRefTime"] = strconv.FormatInt(pushData.LastRefTime, 10)
@@ -141,7 +160,12 @@ func (us *PushReceiver) handleClient(conn *net.UDPConn) {
 	} else if pushData.PushType == "dump" {
 		ack["type"] = "dump-ack"
 		ack["lastRefTime"] = strconv.FormatInt(pushData.LastRefTime, 10)
-		ack["data"] = util.ToJsonString(us.serviceInfoHolder.ServiceInfoMap)
+		services := make(map[string]interface{})
+		us.serviceInfoHolder.ServiceInfoMap.Range(func(key, value interface{}) bool {
+			services[key.(string)] = value
+			return true
+		})
+		ack["data"] = util.ToJsonString(services)
 	} else {
 		ack["type"] = "unknow-ack"
 		ack["lastRefTime"] = strconv.FormatInt(pushData.LastRefTime, 10)
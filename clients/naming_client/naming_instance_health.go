@@ -0,0 +1,175 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+type instanceHealthState struct {
+	healthy bool
+	missing bool
+}
+
+// WatchInstanceHealth check the instances registered by the client on the server every interval and call the
+// callback when one of them turns healthy, unhealthy or missing. The first check records the states.
+func (sc *NamingClient) WatchInstanceHealth(param *vo.WatchInstanceHealthParam) error {
+	if param.Callback == nil {
+		return errors.New("callback cannot be nil!")
+	}
+	interval := time.Duration(param.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		clientConfig, _ := sc.GetClientConfig()
+		interval = time.Duration(clientConfig.BeatInterval) * time.Millisecond
+	}
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	sc.healthWatchMutex.Lock()
+	defer sc.healthWatchMutex.Unlock()
+	if _, ok := sc.healthWatchers[param]; ok {
+		return errors.New("the param is watching already!")
+	}
+	if sc.healthWatchers == nil {
+		sc.healthWatchers = make(map[*vo.WatchInstanceHealthParam]context.CancelFunc)
+	}
+	ctx, cancel := context.WithCancel(sc.ctx)
+	sc.healthWatchers[param] = cancel
+	go sc.watchInstanceHealth(ctx, interval, param.Callback)
+	return nil
+}
+
+// UnwatchInstanceHealth stop the notifications of the param.
+func (sc *NamingClient) UnwatchInstanceHealth(param *vo.WatchInstanceHealthParam) error {
+	sc.healthWatchMutex.Lock()
+	defer sc.healthWatchMutex.Unlock()
+	cancel, ok := sc.healthWatchers[param]
+	if !ok {
+		return errors.New("the param is not watching!")
+	}
+	cancel()
+	delete(sc.healthWatchers, param)
+	return nil
+}
+
+func (sc *NamingClient) watchInstanceHealth(ctx context.Context, interval time.Duration, callback func(event model.InstanceHealthEvent)) {
+	states := make(map[string]instanceHealthState)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, event := range sc.checkInstanceHealth(states) {
+			callback(event)
+		}
+	}
+}
+
+// checkInstanceHealth update the states of the registered instances and return the events of the changed ones.
+// The states of the instances of a service failed to query are kept until the next check.
+func (sc *NamingClient) checkInstanceHealth(states map[string]instanceHealthState) []model.InstanceHealthEvent {
+	var events []model.InstanceHealthEvent
+	hosts := make(map[string][]model.Instance)
+	failed := make(map[string]struct{})
+	checked := make(map[string]struct{})
+	for _, registered := range sc.ListRegisteredInstances() {
+		service := util.GetGroupName(registered.ServiceName, registered.GroupName)
+		key := service + "#" + registeredInstanceKey(registered.Instance)
+		checked[key] = struct{}{}
+		if _, ok := failed[service]; ok {
+			continue
+		}
+		serviceHosts, ok := hosts[service]
+		if !ok {
+			var err error
+			if serviceHosts, err = sc.serverInstances(registered.ServiceName, registered.GroupName); err != nil {
+				logger.Warnf("check the health of the instances of service %s failed: %v", service, err)
+				failed[service] = struct{}{}
+				continue
+			}
+			hosts[service] = serviceHosts
+		}
+		state := instanceHealthState{missing: true}
+		for _, host := range serviceHosts {
+			if sameInstance(host, registered.Instance) {
+				state = instanceHealthState{healthy: host.Healthy}
+				break
+			}
+		}
+		previous, ok := states[key]
+		states[key] = state
+		if ok && previous != state {
+			logger.Warnf("instance %s:%d of service %s turned healthy:%t, missing:%t", registered.Instance.Ip,
+				registered.Instance.Port, service, state.healthy, state.missing)
+			events = append(events, model.InstanceHealthEvent{
+				ServiceName: registered.ServiceName,
+				GroupName:   registered.GroupName,
+				Instance:    registered.Instance,
+				Healthy:     state.healthy,
+				Missing:     state.missing,
+			})
+		}
+	}
+	for key := range states {
+		if _, ok := checked[key]; !ok {
+			delete(states, key)
+		}
+	}
+	return events
+}
+
+// serverInstances return the instances of the service on the server, the cache is used when the service is
+// subscribed since it's updated by the pushes.
+func (sc *NamingClient) serverInstances(serviceName, groupName string) ([]model.Instance, error) {
+	if sc.serviceInfoHolder.IsSubscribed(util.GetGroupName(serviceName, groupName), "") {
+		if service, ok := sc.serviceInfoHolder.GetServiceInfo(serviceName, groupName, ""); ok {
+			return append([]model.Instance{}, service.Hosts...), nil
+		}
+	}
+	service, err := sc.serviceProxy.QueryInstancesOfService(serviceName, groupName, "", 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return []model.Instance{}, nil
+	}
+	return append([]model.Instance{}, service.Hosts...), nil
+}
+
+func sameInstance(host, registered model.Instance) bool {
+	return host.Ip == registered.Ip && host.Port == registered.Port &&
+		clusterName(host.ClusterName) == clusterName(registered.ClusterName)
+}
+
+func clusterName(cluster string) string {
+	if len(cluster) == 0 {
+		return constant.DEFAULT_CLUSTER
+	}
+	return cluster
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// namespaceClient return the client of another namespace, it's created on the first use and shares the
+// connection and the config of sc, but has its own cache, registrations and subscriptions.
+func (sc *NamingClient) namespaceClient(namespaceId string) (*NamingClient, error) {
+	sc.namespaceMutex.Lock()
+	defer sc.namespaceMutex.Unlock()
+	if client, ok := sc.namespaces[namespaceId]; ok {
+		return client, nil
+	}
+	delegate, ok := sc.serviceProxy.(*NamingProxyDelegate)
+	if !ok {
+		return nil, errors.Errorf("namespace %s is not supported by the naming proxy", namespaceId)
+	}
+	clientConfig, err := sc.GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig.NamespaceId = namespaceId
+	ctx, cancel := context.WithCancel(sc.ctx)
+	client := &NamingClient{INacosClient: sc.INacosClient, ctx: ctx, cancel: cancel}
+	client.init(clientConfig)
+	proxy, err := delegate.forNamespace(ctx, namespaceId, client.serviceInfoHolder)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	client.serviceProxy = proxy
+	client.startTasks(clientConfig)
+	if sc.namespaces == nil {
+		sc.namespaces = make(map[string]*NamingClient)
+	}
+	sc.namespaces[namespaceId] = client
+	return client, nil
+}
+
+// isOtherNamespace report whether the requests of the namespace go to the client of another namespace.
+func (sc *NamingClient) isOtherNamespace(namespaceId string) bool {
+	return len(namespaceId) > 0 && namespaceId != sc.namespaceId
+}
+
+// namespaceClients return the clients of the other namespaces sorted by the namespace.
+func (sc *NamingClient) namespaceClients() []*NamingClient {
+	sc.namespaceMutex.Lock()
+	defer sc.namespaceMutex.Unlock()
+	clients := make([]*NamingClient, 0, len(sc.namespaces))
+	for _, client := range sc.namespaces {
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].namespaceId < clients[j].namespaceId
+	})
+	return clients
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// FeedbackBalancer is a balancer learning from the outcomes of the requests sent to the instances it picks.
+type FeedbackBalancer interface {
+	Balancer
+	// Feedback report the outcome of a request to the instance, err is nil when it succeeds.
+	Feedback(instance model.Instance, err error, latency time.Duration)
+}
+
+// OutlierEjectionConfig configure the passive health checking of OutlierEjectionBalancer, the zero values
+// are replaced by the defaults.
+type OutlierEjectionConfig struct {
+	ErrorRateThreshold float64       // eject the instance when its error rate in an interval reaches it, default is 0.5
+	MinRequests        int           // the min number of requests in an interval to eject the instance, default is 5
+	Interval           time.Duration // the interval to count the requests, default is 10s
+	BaseEjectionTime   time.Duration // the first ejection time, it doubles for each consecutive ejection, default is 30s
+	MaxEjectionTime    time.Duration // the max ejection time, default is 300s
+	MaxEjectionPercent float64       // the max ratio of the instances ejected at the same time, at least one is ejected, default is 0.1
+	SlowLatency        time.Duration // the requests slower than it are counted as errors, default is 0, means disabled
+}
+
+type outlierStats struct {
+	windowStart  time.Time
+	total        int
+	failures     int
+	ejections    int
+	ejectedAt    time.Time
+	ejectedUntil time.Time
+}
+
+// OutlierEjectionBalancer wrap a balancer to skip the instances whose error rate exceeds the threshold, an
+// instance is ejected for exponentially longer windows while it keeps failing, Envoy style.
+type OutlierEjectionBalancer struct {
+	base   Balancer
+	config OutlierEjectionConfig
+	mutex  sync.Mutex
+	stats  map[string]*outlierStats
+	now    func() time.Time
+}
+
+func NewOutlierEjectionBalancer(base Balancer, config OutlierEjectionConfig) *OutlierEjectionBalancer {
+	if config.ErrorRateThreshold <= 0 {
+		config.ErrorRateThreshold = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 5
+	}
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.BaseEjectionTime <= 0 {
+		config.BaseEjectionTime = 30 * time.Second
+	}
+	if config.MaxEjectionTime <= 0 {
+		config.MaxEjectionTime = 300 * time.Second
+	}
+	if config.MaxEjectionPercent <= 0 {
+		config.MaxEjectionPercent = 0.1
+	}
+	return &OutlierEjectionBalancer{
+		base:   base,
+		config: config,
+		stats:  make(map[string]*outlierStats),
+		now:    time.Now,
+	}
+}
+
+// Pick choose one of the instances not ejected by the base balancer, the instances ejected beyond
+// MaxEjectionPercent are picked again starting from the ones ejected earliest.
+func (b *OutlierEjectionBalancer) Pick(param vo.SelectOneHealthInstanceParam, instances []model.Instance) model.Instance {
+	now := b.now()
+	type ejected struct {
+		index int
+		since time.Time
+	}
+	var ejectedInstances []ejected
+	b.mutex.Lock()
+	for i, instance := range instances {
+		if st, ok := b.stats[outlierKey(instance)]; ok && now.Before(st.ejectedUntil) {
+			ejectedInstances = append(ejectedInstances, ejected{index: i, since: st.ejectedAt})
+		}
+	}
+	b.mutex.Unlock()
+	if len(ejectedInstances) == 0 {
+		return b.base.Pick(param, instances)
+	}
+	maxEjected := int(math.Max(1, math.Floor(b.config.MaxEjectionPercent*float64(len(instances)))))
+	if maxEjected >= len(instances) {
+		maxEjected = len(instances) - 1
+	}
+	// keep the latest ejections
+	sort.Slice(ejectedInstances, func(i, j int) bool {
+		return ejectedInstances[i].since.After(ejectedInstances[j].since)
+	})
+	if len(ejectedInstances) > maxEjected {
+		ejectedInstances = ejectedInstances[:maxEjected]
+	}
+	skipped := make(map[int]struct{}, len(ejectedInstances))
+	for _, e := range ejectedInstances {
+		skipped[e.index] = struct{}{}
+	}
+	available := make([]model.Instance, 0, len(instances)-len(skipped))
+	for i, instance := range instances {
+		if _, ok := skipped[i]; !ok {
+			available = append(available, instance)
+		}
+	}
+	return b.base.Pick(param, available)
+}
+
+// Feedback count the outcome of a request, the instance is ejected when its error rate reaches the threshold.
+func (b *OutlierEjectionBalancer) Feedback(instance model.Instance, err error, latency time.Duration) {
+	now := b.now()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	key := outlierKey(instance)
+	st, ok := b.stats[key]
+	if !ok {
+		st = &outlierStats{windowStart: now}
+		b.stats[key] = st
+	}
+	if now.Before(st.ejectedUntil) {
+		return
+	}
+	if now.Sub(st.windowStart) >= b.config.Interval {
+		// an interval without ejection after the last ejection expired brings the ejection time back
+		if st.ejections > 0 && now.Sub(st.ejectedUntil) >= b.config.Interval {
+			st.ejections--
+		}
+		st.windowStart, st.total, st.failures = now, 0, 0
+	}
+	st.total++
+	if err != nil || (b.config.SlowLatency > 0 && latency >= b.config.SlowLatency) {
+		st.failures++
+	}
+	if st.total < b.config.MinRequests || float64(st.failures)/float64(st.total) < b.config.ErrorRateThreshold {
+		return
+	}
+	ejection := b.config.BaseEjectionTime << uint(st.ejections)
+	if ejection >= b.config.MaxEjectionTime || ejection <= 0 {
+		ejection = b.config.MaxEjectionTime
+	} else {
+		st.ejections++
+	}
+	st.ejectedAt, st.ejectedUntil = now, now.Add(ejection)
+	logger.Warnf("instance %s of service %s is ejected for %v, %d of %d requests failed", instanceAddress(instance),
+		instance.ServiceName, ejection, st.failures, st.total)
+	st.windowStart, st.total, st.failures = now, 0, 0
+}
+
+// Done release the instance if the base balancer needs it.
+func (b *OutlierEjectionBalancer) Done(instance model.Instance) {
+	if d, ok := b.base.(interface{ Done(instance model.Instance) }); ok {
+		d.Done(instance)
+	}
+}
+
+func outlierKey(instance model.Instance) string {
+	return instance.ServiceName + "#" + instanceAddress(instance)
+}
@@ -17,6 +17,8 @@
 package naming_proxy
 
 import (
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 )
 
@@ -28,6 +30,10 @@ type INamingProxy interface {
 
 	DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error)
 
+	BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error)
+
+	UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error)
+
 	GetServiceList(pageNo uint32, pageSize uint32, groupName, namespaceId string, selector *model.ExpressionSelector) (model.ServiceList, error)
 
 	ServerHealthy() bool
@@ -38,5 +44,21 @@ type INamingProxy interface {
 
 	Unsubscribe(serviceName, groupName, clusters string) error
 
+	CreateService(service model.ServiceDefinition) (bool, error)
+
+	UpdateService(service model.ServiceDefinition) (bool, error)
+
+	DeleteService(serviceName, groupName string) (bool, error)
+
+	GetServiceDetail(serviceName, groupName string) (model.ServiceDefinition, error)
+
+	WatchConnection(callback *func(event model.ConnectionEvent)) error
+
+	UnwatchConnection(callback *func(event model.ConnectionEvent))
+
+	AddInterceptor(interceptor rpc.Interceptor) error
+
+	RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error
+
 	CloseClient()
 }
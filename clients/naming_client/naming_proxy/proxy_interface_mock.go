@@ -8,6 +8,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	rpc "github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	rpc_request "github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	model "github.com/jun3372/nacos-sdk-go/model"
 )
 
@@ -163,3 +165,147 @@ func (mr *MockINamingProxyMockRecorder) Unsubscribe(serviceName, groupName, clus
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockINamingProxy)(nil).Unsubscribe), serviceName, groupName, clusters)
 }
+
+// BatchDeregisterInstance mocks base method
+func (m *MockINamingProxy) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDeregisterInstance", serviceName, groupName, instances)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDeregisterInstance indicates an expected call of BatchDeregisterInstance
+func (mr *MockINamingProxyMockRecorder) BatchDeregisterInstance(serviceName, groupName, instances interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDeregisterInstance", reflect.TypeOf((*MockINamingProxy)(nil).BatchDeregisterInstance), serviceName, groupName, instances)
+}
+
+// UpdateInstanceMetadata mocks base method
+func (m *MockINamingProxy) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstanceMetadata", serviceName, groupName, instance)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInstanceMetadata indicates an expected call of UpdateInstanceMetadata
+func (mr *MockINamingProxyMockRecorder) UpdateInstanceMetadata(serviceName, groupName, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstanceMetadata", reflect.TypeOf((*MockINamingProxy)(nil).UpdateInstanceMetadata), serviceName, groupName, instance)
+}
+
+// CreateService mocks base method
+func (m *MockINamingProxy) CreateService(service model.ServiceDefinition) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateService", service)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateService indicates an expected call of CreateService
+func (mr *MockINamingProxyMockRecorder) CreateService(service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockINamingProxy)(nil).CreateService), service)
+}
+
+// UpdateService mocks base method
+func (m *MockINamingProxy) UpdateService(service model.ServiceDefinition) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateService", service)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateService indicates an expected call of UpdateService
+func (mr *MockINamingProxyMockRecorder) UpdateService(service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockINamingProxy)(nil).UpdateService), service)
+}
+
+// DeleteService mocks base method
+func (m *MockINamingProxy) DeleteService(serviceName string, groupName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteService", serviceName, groupName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteService indicates an expected call of DeleteService
+func (mr *MockINamingProxyMockRecorder) DeleteService(serviceName, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteService", reflect.TypeOf((*MockINamingProxy)(nil).DeleteService), serviceName, groupName)
+}
+
+// GetServiceDetail mocks base method
+func (m *MockINamingProxy) GetServiceDetail(serviceName string, groupName string) (model.ServiceDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceDetail", serviceName, groupName)
+	ret0, _ := ret[0].(model.ServiceDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceDetail indicates an expected call of GetServiceDetail
+func (mr *MockINamingProxyMockRecorder) GetServiceDetail(serviceName, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceDetail", reflect.TypeOf((*MockINamingProxy)(nil).GetServiceDetail), serviceName, groupName)
+}
+
+// WatchConnection mocks base method
+func (m *MockINamingProxy) WatchConnection(callback *func(event model.ConnectionEvent)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchConnection", callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchConnection indicates an expected call of WatchConnection
+func (mr *MockINamingProxyMockRecorder) WatchConnection(callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchConnection", reflect.TypeOf((*MockINamingProxy)(nil).WatchConnection), callback)
+}
+
+// UnwatchConnection mocks base method
+func (m *MockINamingProxy) UnwatchConnection(callback *func(event model.ConnectionEvent)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnwatchConnection", callback)
+}
+
+// UnwatchConnection indicates an expected call of UnwatchConnection
+func (mr *MockINamingProxyMockRecorder) UnwatchConnection(callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnwatchConnection", reflect.TypeOf((*MockINamingProxy)(nil).UnwatchConnection), callback)
+}
+
+// AddInterceptor mocks base method
+func (m *MockINamingProxy) AddInterceptor(interceptor rpc.Interceptor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddInterceptor", interceptor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddInterceptor indicates an expected call of AddInterceptor
+func (mr *MockINamingProxyMockRecorder) AddInterceptor(interceptor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddInterceptor", reflect.TypeOf((*MockINamingProxy)(nil).AddInterceptor), interceptor)
+}
+
+// RegisterServerRequestHandler mocks base method
+func (m *MockINamingProxy) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterServerRequestHandler", request, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterServerRequestHandler indicates an expected call of RegisterServerRequestHandler
+func (mr *MockINamingProxyMockRecorder) RegisterServerRequestHandler(request, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterServerRequestHandler", reflect.TypeOf((*MockINamingProxy)(nil).RegisterServerRequestHandler), request, handler)
+}
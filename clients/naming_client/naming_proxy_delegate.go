@@ -27,16 +27,24 @@ import (
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_proxy"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_server"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/model"
 	"github.com/jun3372/nacos-sdk-go/util"
 )
 
 // NamingProxyDelegate ...
 type NamingProxyDelegate struct {
+	clientConfig      constant.ClientConfig
+	nacosServer       *nacos_server.NacosServer
 	httpClientProxy   *naming_http.NamingHttpProxy
 	grpcClientProxy   *naming_grpc.NamingGrpcProxy
 	serviceInfoHolder *naming_cache.ServiceInfoHolder
+	persistentByGrpc  bool
+	// legacy is true when all the requests are sent by http and the pushes are received by udp for the 1.x servers
+	legacy bool
 }
 
 func NewNamingProxyDelegate(ctx context.Context, clientCfg constant.ClientConfig, serverCfgs []constant.ServerConfig,
@@ -62,20 +70,82 @@ func NewNamingProxyDelegate(ctx context.Context, clientCfg constant.ClientConfig
 		return nil, err
 	}
 
+	if useLegacyServer(clientCfg.NamingCompatMode, httpClientProxy) {
+		logger.Infof("naming requests are sent by http and the pushes are received by udp")
+		httpClientProxy.StartPushReceiver()
+		return &NamingProxyDelegate{
+			clientConfig:      clientCfg,
+			nacosServer:       nacosServer,
+			httpClientProxy:   httpClientProxy,
+			serviceInfoHolder: serviceInfoHolder,
+			legacy:            true,
+		}, nil
+	}
+
 	grpcClientProxy, err := naming_grpc.NewNamingGrpcProxy(ctx, clientCfg, nacosServer, serviceInfoHolder)
 	if err != nil {
 		return nil, err
 	}
 
 	return &NamingProxyDelegate{
+		clientConfig:      clientCfg,
+		nacosServer:       nacosServer,
 		httpClientProxy:   httpClientProxy,
 		grpcClientProxy:   grpcClientProxy,
 		serviceInfoHolder: serviceInfoHolder,
+		persistentByGrpc:  clientCfg.PersistentByGrpc,
 	}, nil
 }
 
+// forNamespace return the delegate of another namespace, it shares the grpc connection with proxy.
+func (proxy *NamingProxyDelegate) forNamespace(ctx context.Context, namespaceId string,
+	serviceInfoHolder *naming_cache.ServiceInfoHolder) (*NamingProxyDelegate, error) {
+	clientCfg := proxy.clientConfig
+	clientCfg.NamespaceId = namespaceId
+	httpClientProxy, err := naming_http.NewNamingHttpProxy(ctx, clientCfg, proxy.nacosServer, serviceInfoHolder)
+	if err != nil {
+		return nil, err
+	}
+	delegate := &NamingProxyDelegate{
+		clientConfig:      clientCfg,
+		nacosServer:       proxy.nacosServer,
+		httpClientProxy:   httpClientProxy,
+		serviceInfoHolder: serviceInfoHolder,
+		persistentByGrpc:  proxy.persistentByGrpc,
+		legacy:            proxy.legacy,
+	}
+	if proxy.legacy {
+		httpClientProxy.StartPushReceiver()
+	} else {
+		delegate.grpcClientProxy = proxy.grpcClientProxy.ForNamespace(namespaceId, serviceInfoHolder)
+	}
+	return delegate, nil
+}
+
+// useLegacyServer return whether to use the http protocol of the 1.x servers, the version of the server is
+// detected in the auto mode, and grpc is used if it's unknown.
+func useLegacyServer(mode string, httpClientProxy *naming_http.NamingHttpProxy) bool {
+	switch mode {
+	case constant.NAMING_COMPAT_MODE_HTTP:
+		return true
+	case constant.NAMING_COMPAT_MODE_GRPC:
+		return false
+	case "", constant.NAMING_COMPAT_MODE_AUTO:
+	default:
+		logger.Warnf("unknown naming compat mode:%s, the server version is detected", mode)
+	}
+	version, err := httpClientProxy.ServerVersion()
+	if err != nil {
+		logger.Warnf("failed to detect the server version, grpc is used, err:%+v", err)
+		return false
+	}
+	return util.IsLegacyServerVersion(version)
+}
+
 func (proxy *NamingProxyDelegate) getExecuteClientProxy(instance model.Instance) (namingProxy naming_proxy.INamingProxy) {
-	if instance.Ephemeral {
+	if proxy.legacy {
+		namingProxy = proxy.httpClientProxy
+	} else if instance.Ephemeral || (proxy.persistentByGrpc && proxy.grpcClientProxy.SupportPersistentInstance()) {
 		namingProxy = proxy.grpcClientProxy
 	} else {
 		namingProxy = proxy.httpClientProxy
@@ -88,26 +158,66 @@ func (proxy *NamingProxyDelegate) RegisterInstance(serviceName string, groupName
 }
 
 func (proxy *NamingProxyDelegate) BatchRegisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	if proxy.legacy {
+		return proxy.httpClientProxy.BatchRegisterInstance(serviceName, groupName, instances)
+	}
 	return proxy.grpcClientProxy.BatchRegisterInstance(serviceName, groupName, instances)
 }
 
 func (proxy *NamingProxyDelegate) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	if !instance.Ephemeral && !proxy.legacy && proxy.grpcClientProxy.IsInstanceCachedForRedo(serviceName, groupName, instance) {
+		// the instance is registered as ephemeral, otherwise it is registered again on reconnect.
+		instance.Ephemeral = true
+	}
 	return proxy.getExecuteClientProxy(instance).DeregisterInstance(serviceName, groupName, instance)
 }
 
+func (proxy *NamingProxyDelegate) BatchDeregisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
+	if proxy.legacy {
+		return proxy.httpClientProxy.BatchDeregisterInstance(serviceName, groupName, instances)
+	}
+	return proxy.grpcClientProxy.BatchDeregisterInstance(serviceName, groupName, instances)
+}
+
+func (proxy *NamingProxyDelegate) UpdateInstanceMetadata(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	success, err := proxy.httpClientProxy.UpdateInstanceMetadata(serviceName, groupName, instance)
+	if err != nil || proxy.legacy {
+		return success, err
+	}
+	_, _ = proxy.grpcClientProxy.UpdateInstanceMetadata(serviceName, groupName, instance)
+	return success, nil
+}
+
 func (proxy *NamingProxyDelegate) GetServiceList(pageNo uint32, pageSize uint32, groupName, namespaceId string, selector *model.ExpressionSelector) (model.ServiceList, error) {
+	if proxy.legacy {
+		return proxy.httpClientProxy.GetServiceList(pageNo, pageSize, groupName, namespaceId, selector)
+	}
 	return proxy.grpcClientProxy.GetServiceList(pageNo, pageSize, groupName, namespaceId, selector)
 }
 
 func (proxy *NamingProxyDelegate) ServerHealthy() bool {
+	if proxy.legacy {
+		return proxy.httpClientProxy.ServerHealthy()
+	}
 	return proxy.grpcClientProxy.ServerHealthy() || proxy.httpClientProxy.ServerHealthy()
 }
 
 func (proxy *NamingProxyDelegate) QueryInstancesOfService(serviceName, groupName, clusters string, udpPort int, healthyOnly bool) (*model.Service, error) {
+	if proxy.legacy {
+		return proxy.httpClientProxy.QueryInstancesOfService(serviceName, groupName, clusters, udpPort, healthyOnly)
+	}
 	return proxy.grpcClientProxy.QueryInstancesOfService(serviceName, groupName, clusters, udpPort, healthyOnly)
 }
 
 func (proxy *NamingProxyDelegate) Subscribe(serviceName, groupName string, clusters string) (model.Service, error) {
+	if proxy.legacy {
+		service, err := proxy.httpClientProxy.Subscribe(serviceName, groupName, clusters)
+		if err != nil {
+			return model.Service{}, err
+		}
+		proxy.serviceInfoHolder.ProcessService(&service)
+		return service, nil
+	}
 	var err error
 	isSubscribed := proxy.grpcClientProxy.IsSubscribed(serviceName, groupName, clusters)
 	serviceNameWithGroup := util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters)
@@ -126,9 +236,61 @@ func (proxy *NamingProxyDelegate) Subscribe(serviceName, groupName string, clust
 
 func (proxy *NamingProxyDelegate) Unsubscribe(serviceName, groupName, clusters string) error {
 	proxy.serviceInfoHolder.StopUpdateIfContain(util.GetGroupName(serviceName, groupName), clusters)
+	if proxy.legacy {
+		return proxy.httpClientProxy.Unsubscribe(serviceName, groupName, clusters)
+	}
 	return proxy.grpcClientProxy.Unsubscribe(serviceName, groupName, clusters)
 }
 
+func (proxy *NamingProxyDelegate) CreateService(service model.ServiceDefinition) (bool, error) {
+	return proxy.httpClientProxy.CreateService(service)
+}
+
+func (proxy *NamingProxyDelegate) UpdateService(service model.ServiceDefinition) (bool, error) {
+	return proxy.httpClientProxy.UpdateService(service)
+}
+
+func (proxy *NamingProxyDelegate) DeleteService(serviceName, groupName string) (bool, error) {
+	return proxy.httpClientProxy.DeleteService(serviceName, groupName)
+}
+
+func (proxy *NamingProxyDelegate) GetServiceDetail(serviceName, groupName string) (model.ServiceDefinition, error) {
+	return proxy.httpClientProxy.GetServiceDetail(serviceName, groupName)
+}
+
+func (proxy *NamingProxyDelegate) WatchConnection(callback *func(event model.ConnectionEvent)) error {
+	if proxy.legacy {
+		return proxy.httpClientProxy.WatchConnection(callback)
+	}
+	return proxy.grpcClientProxy.WatchConnection(callback)
+}
+
+func (proxy *NamingProxyDelegate) UnwatchConnection(callback *func(event model.ConnectionEvent)) {
+	if proxy.legacy {
+		proxy.httpClientProxy.UnwatchConnection(callback)
+		return
+	}
+	proxy.grpcClientProxy.UnwatchConnection(callback)
+}
+
+func (proxy *NamingProxyDelegate) AddInterceptor(interceptor rpc.Interceptor) error {
+	if proxy.legacy {
+		return proxy.httpClientProxy.AddInterceptor(interceptor)
+	}
+	return proxy.grpcClientProxy.AddInterceptor(interceptor)
+}
+
+func (proxy *NamingProxyDelegate) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	if proxy.legacy {
+		return proxy.httpClientProxy.RegisterServerRequestHandler(request, handler)
+	}
+	return proxy.grpcClientProxy.RegisterServerRequestHandler(request, handler)
+}
+
 func (proxy *NamingProxyDelegate) CloseClient() {
+	if proxy.legacy {
+		proxy.httpClientProxy.CloseClient()
+		return
+	}
 	proxy.grpcClientProxy.CloseClient()
 }
@@ -0,0 +1,184 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+// runReconciler repair the drift between the client and the server every interval, it catches the
+// registrations failed silently and the pushes lost, which the redo on reconnect doesn't.
+func (sc *NamingClient) runReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sc.reconcile()
+	}
+}
+
+// reconcile register the instances of the client missing on the server again and update the subscribed
+// services whose cached instances differ from the server, it returns the number of repairs.
+func (sc *NamingClient) reconcile() int {
+	return sc.reconcileRegistered() + sc.reconcileSubscribed()
+}
+
+func (sc *NamingClient) reconcileRegistered() int {
+	sc.registeredMutex.Lock()
+	services := make([]registeredService, 0, len(sc.registeredServices))
+	for _, service := range sc.registeredServices {
+		instances := make(map[string]model.Instance, len(service.instances))
+		for key, instance := range service.instances {
+			instances[key] = instance
+		}
+		services = append(services, registeredService{serviceName: service.serviceName, groupName: service.groupName,
+			batch: service.batch, instances: instances})
+	}
+	sc.registeredMutex.Unlock()
+
+	var repairs int
+	for _, service := range services {
+		serviceFullName := util.GetGroupName(service.serviceName, service.groupName)
+		result, err := sc.serviceProxy.QueryInstancesOfService(service.serviceName, service.groupName, "", 0, false)
+		if err != nil {
+			logger.Warnf("reconcile the instances of service %s failed: %v", serviceFullName, err)
+			continue
+		}
+		var hosts []model.Instance
+		if result != nil {
+			hosts = result.Hosts
+		}
+		var missing, batchInstances []model.Instance
+		var batchMissing bool
+		for _, instance := range service.instances {
+			if reported, ok := sc.updateHealthChecker(service.serviceName, service.groupName, instance, func(*model.Instance) {}); ok {
+				instance = reported
+			}
+			found := false
+			for _, host := range hosts {
+				if sameInstance(host, instance) {
+					found = true
+					break
+				}
+			}
+			if service.batch && instance.Ephemeral {
+				// the server replaces the batch of a connection as a whole
+				batchInstances = append(batchInstances, instance)
+				batchMissing = batchMissing || !found
+			} else if !found {
+				missing = append(missing, instance)
+			}
+		}
+		for _, instance := range missing {
+			if sc.reconcileInstance(service, instance) {
+				repairs++
+			}
+		}
+		if batchMissing && sc.reconcileBatch(service, batchInstances) {
+			repairs++
+		}
+	}
+	return repairs
+}
+
+// reconcileInstance register the missing instance again, it's skipped if the instance is deregistered since
+// the snapshot, the registeredMutex is held so a deregistration waits for it.
+func (sc *NamingClient) reconcileInstance(service registeredService, instance model.Instance) bool {
+	serviceFullName := util.GetGroupName(service.serviceName, service.groupName)
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	if !sc.isRegisteredLocked(service.serviceName, service.groupName, instance) {
+		return false
+	}
+	logger.Warnf("reconcile: instance %s:%d of service %s is missing on the server, register it again",
+		instance.Ip, instance.Port, serviceFullName)
+	if _, err := sc.serviceProxy.RegisterInstance(service.serviceName, service.groupName, instance); err != nil {
+		logger.Errorf("reconcile: register instance %s:%d of service %s failed: %v", instance.Ip, instance.Port,
+			serviceFullName, err)
+		return false
+	}
+	return true
+}
+
+// reconcileBatch register the batch of instances again with the ones deregistered since the snapshot left out.
+func (sc *NamingClient) reconcileBatch(service registeredService, instances []model.Instance) bool {
+	serviceFullName := util.GetGroupName(service.serviceName, service.groupName)
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	registered := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if sc.isRegisteredLocked(service.serviceName, service.groupName, instance) {
+			registered = append(registered, instance)
+		}
+	}
+	if len(registered) == 0 {
+		return false
+	}
+	logger.Warnf("reconcile: instances of service %s are missing on the server, batch register them again", serviceFullName)
+	if _, err := sc.serviceProxy.BatchRegisterInstance(service.serviceName, service.groupName, registered); err != nil {
+		logger.Errorf("reconcile: batch register instances of service %s failed: %v", serviceFullName, err)
+		return false
+	}
+	return true
+}
+
+func (sc *NamingClient) reconcileSubscribed() int {
+	subscribed := make(map[string]watchedSubscription)
+	sc.subscribeMutex.Lock()
+	for key, sub := range sc.subscriptions {
+		subscribed[util.GetServiceCacheKey(key.service, sub.clusters)] = watchedSubscription{
+			serviceName: key.param.ServiceName,
+			groupName:   key.param.GroupName,
+			clusters:    sub.clusters,
+		}
+	}
+	sc.subscribeMutex.Unlock()
+
+	var repairs int
+	for cacheKey, subscription := range subscribed {
+		result, err := sc.serviceProxy.QueryInstancesOfService(subscription.serviceName, subscription.groupName,
+			subscription.clusters, 0, false)
+		if err != nil || result == nil {
+			logger.Warnf("reconcile service key:%s failed: %v", cacheKey, err)
+			continue
+		}
+		var cached []model.Instance
+		if service, ok := sc.serviceInfoHolder.GetServiceInfo(subscription.serviceName, subscription.groupName,
+			subscription.clusters); ok {
+			cached = service.Hosts
+		}
+		diff := naming_cache.DiffInstances(cached, result.Hosts)
+		if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0 {
+			continue
+		}
+		logger.Warnf("reconcile: cached instances of service key:%s differ from the server, added:%d, removed:%d, "+
+			"modified:%d, update them", cacheKey, len(diff.Added), len(diff.Removed), len(diff.Modified))
+		sc.serviceInfoHolder.ProcessService(result)
+		repairs++
+	}
+	return repairs
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// registeredService is the instances registered by the client, they are disabled and deregistered on Shutdown.
+type registeredService struct {
+	serviceName string
+	groupName   string
+	batch       bool
+	instances   map[string]model.Instance
+}
+
+func registeredInstanceKey(instance model.Instance) string {
+	return fmt.Sprintf("%s:%d#%s", instance.Ip, instance.Port, instance.ClusterName)
+}
+
+func (sc *NamingClient) recordRegistered(serviceName, groupName string, batch bool, instances ...model.Instance) {
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	sc.recordRegisteredLocked(serviceName, groupName, batch, instances...)
+}
+
+// recordRegisteredLocked is recordRegistered with the registeredMutex held.
+func (sc *NamingClient) recordRegisteredLocked(serviceName, groupName string, batch bool, instances ...model.Instance) {
+	key := util.GetGroupName(serviceName, groupName)
+	if sc.registeredServices == nil {
+		sc.registeredServices = make(map[string]*registeredService)
+	}
+	service, ok := sc.registeredServices[key]
+	if !ok || batch {
+		service = &registeredService{serviceName: serviceName, groupName: groupName, instances: make(map[string]model.Instance)}
+		sc.registeredServices[key] = service
+	}
+	service.batch = service.batch || batch
+	for _, instance := range instances {
+		service.instances[registeredInstanceKey(instance)] = instance
+	}
+}
+
+// removeRegistered stop the health checkers of the instances and remove them from the registered instances
+// together, so neither the health checker nor the reconciler registers them again once it returns. The removed
+// instances are returned to be recorded again if the deregistration fails.
+func (sc *NamingClient) removeRegistered(serviceName, groupName string, instances ...model.Instance) (removed []model.Instance, batch bool) {
+	key := util.GetGroupName(serviceName, groupName)
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	for _, instance := range instances {
+		sc.stopHealthCheck(serviceName, groupName, instance.Ip, instance.Port, instance.ClusterName)
+	}
+	service, ok := sc.registeredServices[key]
+	if !ok {
+		return nil, false
+	}
+	for _, instance := range instances {
+		instanceKey := registeredInstanceKey(instance)
+		if registered, ok := service.instances[instanceKey]; ok {
+			removed = append(removed, registered)
+			delete(service.instances, instanceKey)
+		}
+	}
+	if len(service.instances) == 0 {
+		delete(sc.registeredServices, key)
+	}
+	return removed, service.batch
+}
+
+// restoreRegistered record the instances removed by removeRegistered again when their deregistration fails,
+// so they are deregistered on Shutdown. Their health checkers aren't started again.
+func (sc *NamingClient) restoreRegistered(serviceName, groupName string, batch bool, instances ...model.Instance) {
+	if len(instances) == 0 {
+		return
+	}
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	if _, ok := sc.registeredServices[util.GetGroupName(serviceName, groupName)]; ok {
+		// the registering as a batch again would replace the other instances of the service
+		batch = false
+	}
+	sc.recordRegisteredLocked(serviceName, groupName, batch, instances...)
+}
+
+// isRegisteredLocked report whether the instance is registered by the client, the registeredMutex must be held.
+func (sc *NamingClient) isRegisteredLocked(serviceName, groupName string, instance model.Instance) bool {
+	service, ok := sc.registeredServices[util.GetGroupName(serviceName, groupName)]
+	if !ok {
+		return false
+	}
+	_, ok = service.instances[registeredInstanceKey(instance)]
+	return ok
+}
+
+// updateRegistered apply the update to the instance registered by the client, false if it's not registered.
+// The instance before the update is returned too.
+func (sc *NamingClient) updateRegistered(serviceName, groupName string, target model.Instance,
+	update func(instance *model.Instance)) (previous model.Instance, updated model.Instance, ok bool) {
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	service, ok := sc.registeredServices[util.GetGroupName(serviceName, groupName)]
+	if !ok {
+		return model.Instance{}, model.Instance{}, false
+	}
+	key := registeredInstanceKey(target)
+	previous, ok = service.instances[key]
+	if !ok {
+		return model.Instance{}, model.Instance{}, false
+	}
+	updated = previous
+	updated.Metadata = util.DeepCopyMap(previous.Metadata)
+	update(&updated)
+	service.instances[key] = updated
+	return previous, updated, true
+}
+
+// updateRegisteredInstance apply the update to the registered instance and register it again, the health
+// reported by its health supplier is kept. The previous instance is restored if the server rejects the update.
+func (sc *NamingClient) updateRegisteredInstance(serviceName, groupName string, target model.Instance,
+	update func(instance *model.Instance)) (bool, error) {
+	if serviceName == "" {
+		return false, errors.New("serviceName cannot be empty!")
+	}
+	if len(groupName) == 0 {
+		groupName = constant.DEFAULT_GROUP
+	}
+	previous, instance, ok := sc.updateRegistered(serviceName, groupName, target, update)
+	if !ok {
+		return false, errors.Errorf("instance %s:%d of service %s is not registered by the client",
+			target.Ip, target.Port, util.GetGroupName(serviceName, groupName))
+	}
+	if reported, ok := sc.updateHealthChecker(serviceName, groupName, target, update); ok {
+		instance = reported
+	}
+	success, err := sc.serviceProxy.RegisterInstance(serviceName, groupName, instance)
+	if err != nil {
+		restore := func(instance *model.Instance) {
+			*instance = previous
+		}
+		sc.updateRegistered(serviceName, groupName, target, restore)
+		sc.updateHealthChecker(serviceName, groupName, target, restore)
+	}
+	return success, err
+}
+
+// SetInstanceWeight change the weight of a registered instance
+func (sc *NamingClient) SetInstanceWeight(param vo.SetInstanceWeightParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.SetInstanceWeight(param)
+	}
+	if param.Weight < 0 {
+		return false, errors.New("weight cannot be negative!")
+	}
+	target := model.Instance{Ip: param.Ip, Port: param.Port, ClusterName: param.ClusterName}
+	return sc.updateRegisteredInstance(param.ServiceName, param.GroupName, target, func(instance *model.Instance) {
+		instance.Weight = param.Weight
+	})
+}
+
+// EnableInstance enable a registered instance to receive traffic again
+func (sc *NamingClient) EnableInstance(param vo.ToggleInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.EnableInstance(param)
+	}
+	target := model.Instance{Ip: param.Ip, Port: param.Port, ClusterName: param.ClusterName}
+	return sc.updateRegisteredInstance(param.ServiceName, param.GroupName, target, func(instance *model.Instance) {
+		instance.Enable = true
+	})
+}
+
+// DisableInstance disable a registered instance to shift the traffic off it, it keeps registered
+func (sc *NamingClient) DisableInstance(param vo.ToggleInstanceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.DisableInstance(param)
+	}
+	target := model.Instance{Ip: param.Ip, Port: param.Port, ClusterName: param.ClusterName}
+	return sc.updateRegisteredInstance(param.ServiceName, param.GroupName, target, func(instance *model.Instance) {
+		instance.Enable = false
+	})
+}
+
+// RegisteredInstance is an instance registered by the client, it can be deregistered on its own.
+type RegisteredInstance struct {
+	ServiceName string
+	GroupName   string
+	Instance    model.Instance
+	client      *NamingClient
+}
+
+// Deregister deregister the instance, the other instances registered by the client are kept.
+func (r *RegisteredInstance) Deregister() (bool, error) {
+	return r.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          r.Instance.Ip,
+		Port:        r.Instance.Port,
+		Cluster:     r.Instance.ClusterName,
+		ServiceName: r.ServiceName,
+		GroupName:   r.GroupName,
+		Ephemeral:   r.Instance.Ephemeral,
+	})
+}
+
+// ListRegisteredInstances return the instances registered by the client which are not deregistered yet,
+// sorted by service and address.
+func (sc *NamingClient) ListRegisteredInstances() []*RegisteredInstance {
+	sc.registeredMutex.Lock()
+	defer sc.registeredMutex.Unlock()
+	var result []*RegisteredInstance
+	for _, service := range sc.registeredServices {
+		for _, instance := range service.instances {
+			result = append(result, &RegisteredInstance{
+				ServiceName: service.serviceName,
+				GroupName:   service.groupName,
+				Instance:    instance,
+				client:      sc,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ki := util.GetGroupName(result[i].ServiceName, result[i].GroupName) + "#" + registeredInstanceKey(result[i].Instance)
+		kj := util.GetGroupName(result[j].ServiceName, result[j].GroupName) + "#" + registeredInstanceKey(result[j].Instance)
+		return ki < kj
+	})
+	return result
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+type selectCacheKey struct {
+	cacheKey string
+	healthy  bool
+}
+
+// selectCacheEntry is the selected instances of a cached service, the service is identified by its refresh
+// time, checksum and number of hosts, the holder only replaces a service by a newer one.
+type selectCacheEntry struct {
+	lastRefTime uint64
+	checksum    string
+	hostCount   int
+	expireAt    time.Time
+	instances   []model.Instance
+}
+
+func (e *selectCacheEntry) matches(service model.Service) bool {
+	return e.lastRefTime == service.LastRefTime && e.checksum == service.Checksum && e.hostCount == len(service.Hosts)
+}
+
+// cachedSelectInstances return a copy of the instances selected from the cached service, the selection is
+// reused until the service is updated or the ttl expires, so the hot callers don't filter the hosts each time.
+func (sc *NamingClient) cachedSelectInstances(serviceName, groupName, clusters string, service model.Service,
+	healthy bool) ([]model.Instance, error) {
+	if sc.selectCacheTtl <= 0 || len(service.Hosts) == 0 {
+		return sc.selectInstances(service, healthy)
+	}
+	key := selectCacheKey{cacheKey: util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters), healthy: healthy}
+	now := time.Now()
+	if value, ok := sc.selectCache.Load(key); ok {
+		entry := value.(*selectCacheEntry)
+		if entry.matches(service) && now.Before(entry.expireAt) {
+			return append([]model.Instance(nil), entry.instances...), nil
+		}
+	}
+	instances, err := sc.selectInstances(service, healthy)
+	if err != nil {
+		return instances, err
+	}
+	sc.selectCache.Store(key, &selectCacheEntry{
+		lastRefTime: service.LastRefTime,
+		checksum:    service.Checksum,
+		hostCount:   len(service.Hosts),
+		expireAt:    now.Add(sc.selectCacheTtl),
+		instances:   append([]model.Instance(nil), instances...),
+	})
+	return instances, nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/model"
+)
+
+const (
+	selectorTypeLabel     = "label"
+	providerLabelPrefix   = "PROVIDER.label."
+	consumerLabelPrefix   = "CONSUMER.label."
+	labelClauseSeparator  = "&"
+	labelNotEqualOperator = "!="
+	labelEqualOperator    = "="
+)
+
+type labelClause struct {
+	key      string
+	value    string
+	equal    bool
+	consumer bool // the value is the consumer label of the key
+}
+
+// labelFilter return the filter of the instances matching the label selector, nil for the nil selector.
+// The nacos server doesn't take a selector on subscribing or querying the instances, so it's applied on
+// the client. The expression is the clauses joined by "&", the syntax of the server
+// "CONSUMER.label.zone = PROVIDER.label.zone" matches the instances whose metadata have the value of
+// ClientConfig.ConsumerLabels for the key, the absent labels are empty. The clauses comparing a provider
+// label to a value like "PROVIDER.label.zone = a" or "env != test" are accepted by the client too.
+func (sc *NamingClient) labelFilter(selector *model.ExpressionSelector) (func(instance model.Instance) bool, error) {
+	if selector == nil || (len(selector.Type) == 0 && len(selector.Expression) == 0) {
+		return nil, nil
+	}
+	if selector.Type != selectorTypeLabel {
+		return nil, errors.Errorf("unsupported selector type:%s", selector.Type)
+	}
+	var clauses []labelClause
+	for _, expression := range strings.Split(selector.Expression, labelClauseSeparator) {
+		if len(strings.TrimSpace(expression)) == 0 {
+			continue
+		}
+		clause, err := parseLabelClause(expression)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+	var consumerLabels map[string]string
+	if clientConfig, err := sc.GetClientConfig(); err == nil {
+		consumerLabels = clientConfig.ConsumerLabels
+	}
+	return func(instance model.Instance) bool {
+		for _, clause := range clauses {
+			value := clause.value
+			if clause.consumer {
+				value = consumerLabels[clause.key]
+			}
+			if (instance.Metadata[clause.key] == value) != clause.equal {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseLabelClause(expression string) (labelClause, error) {
+	clause := labelClause{equal: true}
+	operator := labelEqualOperator
+	if strings.Contains(expression, labelNotEqualOperator) {
+		clause.equal, operator = false, labelNotEqualOperator
+	}
+	parts := strings.SplitN(expression, operator, 2)
+	if len(parts) != 2 {
+		return clause, errors.Errorf("invalid label selector clause:%s", expression)
+	}
+	left, right := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if strings.HasPrefix(right, providerLabelPrefix) && strings.HasPrefix(left, consumerLabelPrefix) {
+		left, right = right, left
+	}
+	if strings.HasPrefix(left, consumerLabelPrefix) {
+		return clause, errors.Errorf("the consumer labels can only be compared to the provider ones, clause:%s", expression)
+	}
+	clause.key, clause.value = strings.TrimPrefix(left, providerLabelPrefix), right
+	if strings.HasPrefix(right, consumerLabelPrefix) {
+		// the server compares the labels of the same key
+		if !strings.HasPrefix(left, providerLabelPrefix) || strings.TrimPrefix(right, consumerLabelPrefix) != clause.key {
+			return clause, errors.Errorf("the consumer and provider labels of the clause must be the same, clause:%s", expression)
+		}
+		clause.value, clause.consumer = "", true
+	}
+	if len(clause.key) == 0 {
+		return clause, errors.Errorf("invalid label selector clause:%s", expression)
+	}
+	return clause, nil
+}
+
+func filterInstances(instances []model.Instance, filter func(instance model.Instance) bool) []model.Instance {
+	if filter == nil {
+		return instances
+	}
+	filtered := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if filter(instance) {
+			filtered = append(filtered, instance)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+func newServiceDefinition(serviceName, groupName string, protectThreshold float64, metadata map[string]string,
+	selector *model.ExpressionSelector) (model.ServiceDefinition, error) {
+	if serviceName == "" {
+		return model.ServiceDefinition{}, errors.New("serviceName cannot be empty!")
+	}
+	if protectThreshold < 0 || protectThreshold > 1 {
+		return model.ServiceDefinition{}, errors.New("protectThreshold must be between 0 and 1!")
+	}
+	if len(groupName) == 0 {
+		groupName = constant.DEFAULT_GROUP
+	}
+	service := model.ServiceDefinition{
+		Name:             serviceName,
+		GroupName:        groupName,
+		ProtectThreshold: protectThreshold,
+		Metadata:         metadata,
+	}
+	if selector != nil {
+		service.Selector = *selector
+	}
+	return service, nil
+}
+
+// CreateService create a service with the service level settings, the instances are registered separately
+func (sc *NamingClient) CreateService(param vo.CreateServiceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.CreateService(param)
+	}
+	service, err := newServiceDefinition(param.ServiceName, param.GroupName, param.ProtectThreshold, param.Metadata, param.Selector)
+	if err != nil {
+		return false, err
+	}
+	return sc.serviceProxy.CreateService(service)
+}
+
+// UpdateService update the service level settings of a service
+func (sc *NamingClient) UpdateService(param vo.UpdateServiceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.UpdateService(param)
+	}
+	service, err := newServiceDefinition(param.ServiceName, param.GroupName, param.ProtectThreshold, param.Metadata, param.Selector)
+	if err != nil {
+		return false, err
+	}
+	return sc.serviceProxy.UpdateService(service)
+}
+
+// DeleteService delete a service without instances
+func (sc *NamingClient) DeleteService(param vo.DeleteServiceParam) (bool, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return false, err
+		}
+		return client.DeleteService(param)
+	}
+	if param.ServiceName == "" {
+		return false, errors.New("serviceName cannot be empty!")
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	return sc.serviceProxy.DeleteService(param.ServiceName, param.GroupName)
+}
+
+// GetServiceDetail return the service level settings and the clusters of a service
+func (sc *NamingClient) GetServiceDetail(param vo.GetServiceDetailParam) (model.ServiceDefinition, error) {
+	if sc.isOtherNamespace(param.NamespaceId) {
+		client, err := sc.namespaceClient(param.NamespaceId)
+		if err != nil {
+			return model.ServiceDefinition{}, err
+		}
+		return client.GetServiceDetail(param)
+	}
+	if param.ServiceName == "" {
+		return model.ServiceDefinition{}, errors.New("serviceName cannot be empty!")
+	}
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	return sc.serviceProxy.GetServiceDetail(param.ServiceName, param.GroupName)
+}
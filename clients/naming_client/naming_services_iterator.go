@@ -0,0 +1,183 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+type servicesPage struct {
+	names []string
+	err   error
+}
+
+// ServicesIterator page through all the services of a group, it's not safe for concurrent use.
+type ServicesIterator struct {
+	client      *NamingClient
+	namespace   string
+	groupName   string
+	pageSize    uint32
+	parallelism int
+	pageNo      uint32
+	fetched     int64
+	prefetched  []chan servicesPage
+	names       []string
+	current     string
+	done        bool
+	err         error
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// IterateServices return an iterator over all the services of the group, the pages after the first one are
+// fetched by Parallelism goroutines when it's greater than 1.
+func (sc *NamingClient) IterateServices(param vo.ServicesIteratorParam) *ServicesIterator {
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	if len(param.NameSpace) == 0 {
+		clientConfig, _ := sc.GetClientConfig()
+		param.NameSpace = clientConfig.NamespaceId
+		if len(param.NameSpace) == 0 {
+			param.NameSpace = constant.DEFAULT_NAMESPACE_ID
+		}
+	}
+	if param.PageSize == 0 {
+		param.PageSize = servicesPageSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ServicesIterator{
+		client:      sc,
+		namespace:   param.NameSpace,
+		groupName:   param.GroupName,
+		pageSize:    param.PageSize,
+		parallelism: param.Parallelism,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// GetAllServiceNames return the names of all the services of the group
+func (sc *NamingClient) GetAllServiceNames(param vo.ServicesIteratorParam) ([]string, error) {
+	it := sc.IterateServices(param)
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		names = append(names, it.Service())
+	}
+	return names, it.Err()
+}
+
+// Next advance to the next service, false when all the services are iterated or an error occurs.
+func (it *ServicesIterator) Next() bool {
+	for len(it.names) == 0 {
+		if it.done {
+			return false
+		}
+		page := it.nextPage()
+		if page.err != nil {
+			it.err, it.done = page.err, true
+			it.Close()
+			return false
+		}
+		it.names = page.names
+	}
+	it.current, it.names = it.names[0], it.names[1:]
+	return true
+}
+
+// Service return the current service name
+func (it *ServicesIterator) Service() string {
+	return it.current
+}
+
+// Err return the error stopping the iteration
+func (it *ServicesIterator) Err() error {
+	return it.err
+}
+
+// Close stop fetching the remaining pages
+func (it *ServicesIterator) Close() {
+	it.done, it.names = true, nil
+	it.cancel()
+}
+
+func (it *ServicesIterator) nextPage() servicesPage {
+	if len(it.prefetched) > 0 {
+		ch := it.prefetched[0]
+		it.prefetched = it.prefetched[1:]
+		it.done = len(it.prefetched) == 0
+		select {
+		case page := <-ch:
+			return page
+		case <-it.ctx.Done():
+			return servicesPage{err: it.ctx.Err()}
+		}
+	}
+	it.pageNo++
+	page, received, count := it.fetch(it.pageNo)
+	if page.err != nil {
+		return page
+	}
+	it.fetched += int64(received)
+	if received < int(it.pageSize) || it.fetched >= count {
+		it.done = true
+	} else if it.pageNo == 1 && it.parallelism > 1 {
+		it.prefetch(uint32((count + int64(it.pageSize) - 1) / int64(it.pageSize)))
+	}
+	return page
+}
+
+// prefetch start fetching the pages from 2 to total, the pages are consumed in order.
+func (it *ServicesIterator) prefetch(total uint32) {
+	pages := make(chan uint32, total-1)
+	for pageNo := uint32(2); pageNo <= total; pageNo++ {
+		pages <- pageNo
+		it.prefetched = append(it.prefetched, make(chan servicesPage, 1))
+	}
+	close(pages)
+	prefetched := it.prefetched
+	for i := 0; i < it.parallelism && i < len(prefetched); i++ {
+		go func() {
+			for pageNo := range pages {
+				if it.ctx.Err() != nil {
+					return
+				}
+				page, _, _ := it.fetch(pageNo)
+				prefetched[pageNo-2] <- page
+			}
+		}()
+	}
+}
+
+func (it *ServicesIterator) fetch(pageNo uint32) (page servicesPage, received int, count int64) {
+	serviceList, err := it.client.serviceProxy.GetServiceList(pageNo, it.pageSize, it.groupName, it.namespace,
+		&model.ExpressionSelector{})
+	if err != nil {
+		return servicesPage{err: err}, 0, 0
+	}
+	for _, name := range serviceList.Doms {
+		if len(name) > 0 {
+			page.names = append(page.names, name)
+		}
+	}
+	return page, len(serviceList.Doms), serviceList.Count
+}
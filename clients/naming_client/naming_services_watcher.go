@@ -0,0 +1,189 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+const servicesPageSize = 500
+
+// servicesWatcher poll the service list of a group, the nacos server doesn't push the changes of it.
+type servicesWatcher struct {
+	namespace string
+	groupName string
+	callbacks []*func(added, removed []string, err error)
+	services  map[string]struct{}
+	synced    bool
+	cancel    context.CancelFunc
+}
+
+// WatchServices notify the callback when the services of the group are created or removed, the first
+// notification contains all the existing services as added.
+func (sc *NamingClient) WatchServices(param *vo.WatchServicesParam) error {
+	if param.Callback == nil {
+		return errors.New("callback cannot be nil!")
+	}
+	key := sc.servicesWatchKey(param)
+	clientConfig, _ := sc.GetClientConfig()
+
+	sc.watchMutex.Lock()
+	if sc.servicesWatchers == nil {
+		sc.servicesWatchers = make(map[string]*servicesWatcher)
+	}
+	watcher, ok := sc.servicesWatchers[key]
+	if ok {
+		watcher.callbacks = append(watcher.callbacks, &param.Callback)
+		var current []string
+		if watcher.synced {
+			current = sortedServices(watcher.services)
+		}
+		sc.watchMutex.Unlock()
+		if current != nil {
+			param.Callback(current, nil, nil)
+		}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(sc.ctx)
+	watcher = &servicesWatcher{
+		namespace: param.NameSpace,
+		groupName: param.GroupName,
+		callbacks: []*func(added, removed []string, err error){&param.Callback},
+		services:  make(map[string]struct{}),
+		cancel:    cancel,
+	}
+	sc.servicesWatchers[key] = watcher
+	sc.watchMutex.Unlock()
+
+	interval := time.Duration(clientConfig.ServiceListPollMs) * time.Millisecond
+	if interval <= 0 {
+		interval = constant.DEFAULT_SERVICE_LIST_POLL_MILLS * time.Millisecond
+	}
+	go sc.pollServices(ctx, watcher, interval)
+	return nil
+}
+
+// UnwatchServices remove the callback added by WatchServices, the polling stops with the last callback.
+func (sc *NamingClient) UnwatchServices(param *vo.WatchServicesParam) error {
+	key := sc.servicesWatchKey(param)
+
+	sc.watchMutex.Lock()
+	defer sc.watchMutex.Unlock()
+	watcher, ok := sc.servicesWatchers[key]
+	if !ok {
+		return nil
+	}
+	var callbacks []*func(added, removed []string, err error)
+	for _, callback := range watcher.callbacks {
+		if callback != &param.Callback {
+			callbacks = append(callbacks, callback)
+		}
+	}
+	watcher.callbacks = callbacks
+	if len(callbacks) == 0 {
+		watcher.cancel()
+		delete(sc.servicesWatchers, key)
+	}
+	return nil
+}
+
+// servicesWatchKey fill the default group and namespace of the param and return the key of its watcher.
+func (sc *NamingClient) servicesWatchKey(param *vo.WatchServicesParam) string {
+	if len(param.GroupName) == 0 {
+		param.GroupName = constant.DEFAULT_GROUP
+	}
+	if len(param.NameSpace) == 0 {
+		clientConfig, _ := sc.GetClientConfig()
+		param.NameSpace = clientConfig.NamespaceId
+		if len(param.NameSpace) == 0 {
+			param.NameSpace = constant.DEFAULT_NAMESPACE_ID
+		}
+	}
+	return param.NameSpace + constant.SERVICE_INFO_SPLITER + param.GroupName
+}
+
+func (sc *NamingClient) pollServices(ctx context.Context, watcher *servicesWatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sc.syncServices(watcher)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (sc *NamingClient) syncServices(watcher *servicesWatcher) {
+	names, err := sc.listAllServices(watcher.namespace, watcher.groupName)
+	sc.watchMutex.Lock()
+	callbacks := watcher.callbacks
+	if err != nil {
+		sc.watchMutex.Unlock()
+		logger.Warnf("poll the services of group %s failed, err:%v", watcher.groupName, err)
+		for _, callback := range callbacks {
+			(*callback)(nil, nil, err)
+		}
+		return
+	}
+	services := make(map[string]struct{}, len(names))
+	var added, removed []string
+	for _, name := range names {
+		services[name] = struct{}{}
+		if _, ok := watcher.services[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for name := range watcher.services {
+		if _, ok := services[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	firstSync := !watcher.synced
+	watcher.services, watcher.synced = services, true
+	sc.watchMutex.Unlock()
+	if !firstSync && len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	for _, callback := range callbacks {
+		(*callback)(added, removed, nil)
+	}
+}
+
+func (sc *NamingClient) listAllServices(namespace, groupName string) ([]string, error) {
+	return sc.GetAllServiceNames(vo.ServicesIteratorParam{NameSpace: namespace, GroupName: groupName})
+}
+
+func sortedServices(services map[string]struct{}) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
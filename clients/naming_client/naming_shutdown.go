@@ -0,0 +1,190 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// Shutdown disable the registered instances, wait the drain window for the callers to move away, then
+// deregister them and close the client. The drain is cut short when ctx is done. The clients of the other
+// namespaces are shut down together.
+func (sc *NamingClient) Shutdown(ctx context.Context, drain time.Duration) error {
+	namespaceClients := sc.namespaceClients()
+	namespaceErrs := make([]error, len(namespaceClients))
+	var wg sync.WaitGroup
+	for i, client := range namespaceClients {
+		wg.Add(1)
+		go func(i int, client *NamingClient) {
+			defer wg.Done()
+			namespaceErrs[i] = client.Shutdown(ctx, drain)
+		}(i, client)
+	}
+
+	sc.registeredMutex.Lock()
+	sc.healthMutex.Lock()
+	for key, checker := range sc.healthCheckers {
+		checker.cancel()
+		delete(sc.healthCheckers, key)
+	}
+	sc.healthMutex.Unlock()
+	services := make([]*registeredService, 0, len(sc.registeredServices))
+	for _, service := range sc.registeredServices {
+		services = append(services, service)
+	}
+	sc.registeredServices = nil
+	sc.registeredMutex.Unlock()
+
+	var errs []string
+	for _, service := range services {
+		if err := sc.disableRegistered(service); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(services) > 0 && drain > 0 {
+		timer := time.NewTimer(drain)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Warnf("drain of the registered instances is cut short: %v", ctx.Err())
+		}
+	}
+	for _, service := range services {
+		if err := sc.deregisterRegistered(service); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	wg.Wait()
+	for i, err := range namespaceErrs {
+		if err != nil {
+			errs = append(errs, "namespace "+namespaceClients[i].namespaceId+": "+err.Error())
+		}
+	}
+	sc.CloseClient()
+	if len(errs) > 0 {
+		return errors.Errorf("shutdown naming client failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (sc *NamingClient) disableRegistered(service *registeredService) error {
+	var batchInstances []model.Instance
+	for _, instance := range service.instances {
+		instance.Enable = false
+		if service.batch && instance.Ephemeral {
+			batchInstances = append(batchInstances, instance)
+			continue
+		}
+		if _, err := sc.serviceProxy.RegisterInstance(service.serviceName, service.groupName, instance); err != nil {
+			return errors.Wrapf(err, "disable instance %s:%d of service %s", instance.Ip, instance.Port, service.serviceName)
+		}
+	}
+	if len(batchInstances) > 0 {
+		if _, err := sc.serviceProxy.BatchRegisterInstance(service.serviceName, service.groupName, batchInstances); err != nil {
+			return errors.Wrapf(err, "disable instances of service %s", service.serviceName)
+		}
+	}
+	return nil
+}
+
+func (sc *NamingClient) deregisterRegistered(service *registeredService) error {
+	var batchInstances []model.Instance
+	for _, instance := range service.instances {
+		if service.batch && instance.Ephemeral {
+			batchInstances = append(batchInstances, instance)
+			continue
+		}
+		if _, err := sc.serviceProxy.DeregisterInstance(service.serviceName, service.groupName, instance); err != nil {
+			return errors.Wrapf(err, "deregister instance %s:%d of service %s", instance.Ip, instance.Port, service.serviceName)
+		}
+	}
+	if len(batchInstances) > 0 {
+		if _, err := sc.serviceProxy.BatchDeregisterInstance(service.serviceName, service.groupName, batchInstances); err != nil {
+			return errors.Wrapf(err, "deregister instances of service %s", service.serviceName)
+		}
+	}
+	return nil
+}
+
+// ShutdownOnSignal run the BeforeShutdown hooks, Shutdown and the AfterShutdown hooks when the process receives
+// one of the signals, then exit. The returned func stops handling the signals.
+func (sc *NamingClient) ShutdownOnSignal(param vo.ShutdownOnSignalParam) (stop func()) {
+	signals := param.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	done := make(chan struct{})
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case sig := <-ch:
+			logger.Infof("receive signal %v, shutdown the naming client", sig)
+			sc.shutdownOnSignal(param)
+		case <-done:
+		case <-sc.ctx.Done():
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (sc *NamingClient) shutdownOnSignal(param vo.ShutdownOnSignalParam) {
+	ctx := context.Background()
+	if param.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(param.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+	runShutdownHooks(ctx, "before", param.BeforeShutdown)
+	code := 0
+	if err := sc.Shutdown(ctx, time.Duration(param.DrainMs)*time.Millisecond); err != nil {
+		logger.Errorf("shutdown on signal failed, err:%+v", err)
+		code = 1
+	}
+	runShutdownHooks(ctx, "after", param.AfterShutdown)
+	exit := param.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
+
+// runShutdownHooks run the hooks in order, a failed hook doesn't stop the others.
+func runShutdownHooks(ctx context.Context, stage string, hooks []func(ctx context.Context) error) {
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			logger.Warnf("shutdown hook %d %s shutdown failed, err:%+v", i, stage, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/util"
+)
+
+const defaultSubscribeMissedChecks = 3
+
+type watchedSubscription struct {
+	serviceName string
+	groupName   string
+	clusters    string
+}
+
+// runSubscribeWatchdog re-subscribe the services without data within missedChecks intervals, the server
+// loses the subscriptions when it's restarted or evicts them.
+func (sc *NamingClient) runSubscribeWatchdog(ctx context.Context, interval time.Duration, missedChecks int) {
+	if missedChecks <= 0 {
+		missedChecks = defaultSubscribeMissedChecks
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sc.checkSubscriptions(time.Duration(missedChecks) * interval)
+	}
+}
+
+func (sc *NamingClient) checkSubscriptions(timeout time.Duration) {
+	watched := make(map[string]watchedSubscription)
+	sc.subscribeMutex.Lock()
+	for key, sub := range sc.subscriptions {
+		watched[util.GetServiceCacheKey(key.service, sub.clusters)] = watchedSubscription{
+			serviceName: key.param.ServiceName,
+			groupName:   key.param.GroupName,
+			clusters:    sub.clusters,
+		}
+	}
+	sc.subscribeMutex.Unlock()
+
+	now := uint64(util.CurrentMillis())
+	for cacheKey, subscription := range watched {
+		if updateTime, ok := sc.serviceInfoHolder.UpdateTimeMap.Load(cacheKey); ok &&
+			now-updateTime.(uint64) < uint64(timeout.Milliseconds()) {
+			continue
+		}
+		logger.Warnf("no data of service key:%s received in %v, subscribe it again", cacheKey, timeout)
+		service, err := sc.serviceProxy.Subscribe(subscription.serviceName, subscription.groupName, subscription.clusters)
+		if err != nil {
+			logger.Errorf("re-subscribe service key:%s failed, err:%v", cacheKey, err)
+			continue
+		}
+		sc.serviceInfoHolder.ProcessService(&service)
+		// the service is not updated when it's not changed, the subscription is alive anyway
+		sc.serviceInfoHolder.UpdateTimeMap.Store(cacheKey, uint64(util.CurrentMillis()))
+	}
+}
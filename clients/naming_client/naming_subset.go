@@ -0,0 +1,123 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// newSubscription return the callbacks to register for the param, they receive the instances accepted by the
+// filter and the subset of the healthy ones when param.SubsetSize is set.
+func (sc *NamingClient) newSubscription(param *vo.SubscribeParam, filter func(instance model.Instance) bool) subscription {
+	var sub subscription
+	if param.SubsetSize <= 0 && filter == nil {
+		if param.SubscribeCallback != nil {
+			sub.callback = &param.SubscribeCallback
+		}
+		if param.SubscribeDiffCallback != nil {
+			sub.diffCallback = &param.SubscribeDiffCallback
+		}
+		return sub
+	}
+	size, key := param.SubsetSize, sc.subsetKey(param.SubsetKey)
+	if callback := param.SubscribeCallback; callback != nil {
+		subsetCallback := func(services []model.Instance, err error) {
+			callback(healthySubset(filterInstances(services, filter), size, key), err)
+		}
+		sub.callback = &subsetCallback
+	}
+	if callback := param.SubscribeDiffCallback; callback != nil {
+		var (
+			mutex    sync.Mutex
+			previous []model.Instance
+		)
+		// the diff is computed between the subsets, the instances out of them are neither added nor removed
+		subsetCallback := func(services []model.Instance, diff model.InstancesDiff, err error) {
+			subset := healthySubset(filterInstances(services, filter), size, key)
+			mutex.Lock()
+			diff, previous = naming_cache.DiffInstances(previous, subset), subset
+			mutex.Unlock()
+			callback(subset, diff, err)
+		}
+		sub.diffCallback = &subsetCallback
+	}
+	return sub
+}
+
+// subsetKey return the key of the subsets, the key of the param comes first.
+func (sc *NamingClient) subsetKey(key string) string {
+	if len(key) > 0 {
+		return key
+	}
+	if clientConfig, err := sc.GetClientConfig(); err == nil && len(clientConfig.NamingSubsetKey) > 0 {
+		return clientConfig.NamingSubsetKey
+	}
+	return util.LocalIP()
+}
+
+// healthySubset pick the subset from the healthy instances, so the subset isn't wasted on the unhealthy ones.
+func healthySubset(instances []model.Instance, size int, key string) []model.Instance {
+	if size <= 0 {
+		return instances
+	}
+	healthy := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Healthy && instance.Enable && instance.Weight > 0 {
+			healthy = append(healthy, instance)
+		}
+	}
+	return subsetInstances(healthy, size, key)
+}
+
+// subsetInstances pick size of the instances with the highest rendezvous hash of the key, so each client
+// keeps a stable subset and only the clients of a removed instance pick another one. The instances keep
+// their order.
+func subsetInstances(instances []model.Instance, size int, key string) []model.Instance {
+	if size <= 0 || len(instances) <= size {
+		return instances
+	}
+	type scored struct {
+		index int
+		score uint64
+	}
+	scores := make([]scored, len(instances))
+	for i, instance := range instances {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key + "#" + instanceAddress(instance)))
+		scores[i] = scored{index: i, score: h.Sum64()}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].score > scores[j].score
+	})
+	picked := make([]int, size)
+	for i := range picked {
+		picked[i] = scores[i].index
+	}
+	sort.Ints(picked)
+	subset := make([]model.Instance, size)
+	for i, index := range picked {
+		subset[i] = instances[index]
+	}
+	return subset
+}
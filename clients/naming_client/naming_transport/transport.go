@@ -0,0 +1,251 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_transport
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+// Transport is a http.RoundTripper sending the requests of http://serviceName/... to a healthy instance of
+// the service selected by the balancer, e.g.
+//
+//	httpClient := &http.Client{Transport: naming_transport.NewTransport(namingClient)}
+//	resp, err := httpClient.Get("http://demo-service/hello")
+type Transport struct {
+	client     naming_client.INamingClient
+	base       http.RoundTripper
+	groupName  string
+	clusters   []string
+	strategy   string
+	zone       string
+	hashKey    func(req *http.Request) string
+	tryTimeout time.Duration
+	maxRetries int
+}
+
+// Option ...
+type Option func(*Transport)
+
+// WithBase set the transport sending the rewritten requests, default is http.DefaultTransport
+func WithBase(base http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.base = base
+	}
+}
+
+// WithGroupName set the group of the services, default is DEFAULT_GROUP
+func WithGroupName(groupName string) Option {
+	return func(t *Transport) {
+		t.groupName = groupName
+	}
+}
+
+// WithClusters set the clusters to select the instances from
+func WithClusters(clusters ...string) Option {
+	return func(t *Transport) {
+		t.clusters = clusters
+	}
+}
+
+// WithStrategy set the balancer strategy, default is the weighted random one
+func WithStrategy(strategy string) Option {
+	return func(t *Transport) {
+		t.strategy = strategy
+	}
+}
+
+// WithZone set the zone preferred by the zoneAffinity strategy
+func WithZone(zone string) Option {
+	return func(t *Transport) {
+		t.zone = zone
+	}
+}
+
+// WithHashKey set the function returning the key of a request for the consistentHash strategy
+func WithHashKey(hashKey func(req *http.Request) string) Option {
+	return func(t *Transport) {
+		t.hashKey = hashKey
+	}
+}
+
+// WithTryTimeout set the timeout of each try including reading the body, default is 0, means no timeout
+func WithTryTimeout(tryTimeout time.Duration) Option {
+	return func(t *Transport) {
+		t.tryTimeout = tryTimeout
+	}
+}
+
+// WithMaxRetries set the max times to retry another instance on connection errors, default is 2
+func WithMaxRetries(maxRetries int) Option {
+	return func(t *Transport) {
+		t.maxRetries = maxRetries
+	}
+}
+
+// NewTransport create a transport selecting the instances by the naming client.
+func NewTransport(client naming_client.INamingClient, opts ...Option) *Transport {
+	t := &Transport{
+		client:     client,
+		base:       http.DefaultTransport,
+		groupName:  constant.DEFAULT_GROUP,
+		maxRetries: 2,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RoundTrip select an instance of the service named by the request host and send the request to it, the
+// request is retried on another instance when it fails to connect and its body can be sent again. The instances
+// failed are not selected again by the retries.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	param := vo.SelectOneHealthInstanceParam{
+		ServiceName: req.URL.Hostname(),
+		GroupName:   t.groupName,
+		Clusters:    t.clusters,
+		Strategy:    t.strategy,
+		Zone:        t.zone,
+	}
+	if t.hashKey != nil {
+		param.HashKey = t.hashKey(req)
+	}
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	var lastErr error
+	for try := 0; try <= t.maxRetries; try++ {
+		if try > 0 && !replayable {
+			break
+		}
+		instance, err := t.client.SelectOneHealthyInstance(param)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, errors.Wrapf(err, "select instance of service %s", param.ServiceName)
+		}
+		resp, err := t.try(req, param, *instance, try > 0)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		param.Excluded = append(param.Excluded, net.JoinHostPort(instance.Ip, strconv.FormatUint(instance.Port, 10)))
+		if req.Context().Err() != nil || !isRetryable(req, err) {
+			return nil, err
+		}
+		logger.Warnf("request service %s by instance %s:%d failed: %v", param.ServiceName, instance.Ip, instance.Port, err)
+	}
+	return nil, lastErr
+}
+
+func (t *Transport) try(req *http.Request, param vo.SelectOneHealthInstanceParam, instance model.Instance, retry bool) (*http.Response, error) {
+	done, feedback := t.done(param, instance)
+	start := time.Now()
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.tryTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.tryTimeout)
+	}
+	outReq := req.Clone(ctx)
+	outReq.URL.Host = net.JoinHostPort(instance.Ip, strconv.FormatUint(instance.Port, 10))
+	if retry && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			done()
+			return nil, err
+		}
+		outReq.Body = body
+	}
+	resp, err := t.base.RoundTrip(outReq)
+	if err != nil {
+		feedback(err, time.Since(start))
+		cancel()
+		done()
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		feedback(errors.Errorf("status code %d", resp.StatusCode), time.Since(start))
+	} else {
+		feedback(nil, time.Since(start))
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() {
+		cancel()
+		done()
+	}}
+	return resp, nil
+}
+
+// done return the functions to release the instance picked by the balancer and to report the outcome of
+// the request to it if it needs, the 5xx responses are reported as errors.
+func (t *Transport) done(param vo.SelectOneHealthInstanceParam, instance model.Instance) (func(), func(err error, latency time.Duration)) {
+	done, feedback := func() {}, func(err error, latency time.Duration) {}
+	balancer, err := naming_client.GetBalancer(param.Strategy)
+	if err != nil {
+		return done, feedback
+	}
+	if b, ok := balancer.(interface{ Done(instance model.Instance) }); ok {
+		done = func() { b.Done(instance) }
+	}
+	if b, ok := balancer.(naming_client.FeedbackBalancer); ok {
+		feedback = func(err error, latency time.Duration) { b.Feedback(instance, err, latency) }
+	}
+	return done, feedback
+}
+
+// isRetryable report whether the request can be sent to another instance, the dial errors are always
+// retryable while the try timeouts are retryable for the idempotent methods.
+func isRetryable(req *http.Request, err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	released bool
+	release  func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	if !b.released {
+		b.released = true
+		b.release()
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_transport
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jun3372/nacos-sdk-go/clients/naming_client"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/jun3372/nacos-sdk-go/vo"
+)
+
+type mockNamingClient struct {
+	naming_client.INamingClient
+	instances []model.Instance
+	params    []vo.SelectOneHealthInstanceParam
+}
+
+func (m *mockNamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
+	instance := m.instances[len(m.params)%len(m.instances)]
+	m.params = append(m.params, param)
+	return &instance, nil
+}
+
+func instanceOf(t *testing.T, rawUrl string) model.Instance {
+	u, err := url.Parse(rawUrl)
+	assert.Nil(t, err)
+	port, err := strconv.ParseUint(u.Port(), 10, 64)
+	assert.Nil(t, err)
+	return model.Instance{Ip: u.Hostname(), Port: port, Weight: 1, Enable: true, Healthy: true}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Host + r.URL.Path + ":" + string(body)))
+	}))
+	defer server.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	dead := instanceOf(t, "http://"+listener.Addr().String())
+	_ = listener.Close()
+
+	client := &mockNamingClient{instances: []model.Instance{dead, instanceOf(t, server.URL)}}
+	httpClient := &http.Client{Transport: NewTransport(client, WithGroupName("group-a"),
+		WithHashKey(func(req *http.Request) string { return req.Header.Get("X-User") }))}
+	req, err := http.NewRequest(http.MethodPost, "http://demo-service/hello", strings.NewReader("world"))
+	assert.Nil(t, err)
+	req.Header.Set("X-User", "user-a")
+	resp, err := httpClient.Do(req)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "demo-service/hello:world", string(body))
+	assert.Equal(t, 2, len(client.params))
+	assert.Equal(t, "demo-service", client.params[0].ServiceName)
+	assert.Equal(t, "group-a", client.params[0].GroupName)
+	assert.Equal(t, "user-a", client.params[0].HashKey)
+	assert.Nil(t, client.params[0].Excluded)
+	assert.Equal(t, []string{listener.Addr().String()}, client.params[1].Excluded)
+
+	client = &mockNamingClient{instances: []model.Instance{dead}}
+	httpClient = &http.Client{Transport: NewTransport(client, WithMaxRetries(1))}
+	_, err = httpClient.Get("http://demo-service/hello")
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(client.params))
+}
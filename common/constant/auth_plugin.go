@@ -0,0 +1,41 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package constant
+
+import "time"
+
+// AuthPlugin logs in the nacos servers and decorates the requests with the token, it replaces the username and
+// password login to integrate the sso or the other token services.
+type AuthPlugin interface {
+	// GetLoginToken logs in the server and returns the token and its ttl in seconds, the token is refreshed
+	// ahead of the ttl, or never if the ttl isn't positive.
+	GetLoginToken(server ServerConfig) (token string, ttl int64, err error)
+	// DecorateRequest puts the token into the headers or the params of a request, both are the metadata
+	// headers of the grpc requests.
+	DecorateRequest(token string, headers map[string]string, params map[string]string)
+}
+
+// TokenRefreshFailure is the event of the token refresh failing repeatedly, ExpireAt is the expiration of the
+// current token, zero if there's no token.
+type TokenRefreshFailure struct {
+	Failures int
+	ExpireAt time.Time
+	Err      error
+}
+
+// TokenRefreshListener is notified on each failed refresh once the consecutive failures reach 3.
+type TokenRefreshListener func(failure TokenRefreshFailure)
@@ -20,7 +20,9 @@ import (
 	"os"
 	"time"
 
+	"github.com/jun3372/nacos-sdk-go/common/credentials"
 	"github.com/jun3372/nacos-sdk-go/common/file"
+	"google.golang.org/grpc"
 )
 
 func NewClientConfig(opts ...ClientOption) *ClientConfig {
@@ -137,6 +139,55 @@ func WithKMSVersion(kmsVersion KMSVersion) ClientOption {
 	}
 }
 
+// WithAesEncryptionKey ...
+func WithAesEncryptionKey(aesEncryptionKey string) ClientOption {
+	return func(config *ClientConfig) {
+		config.AesEncryptionKey = aesEncryptionKey
+	}
+}
+
+// WithMd5RetryTimes ...
+func WithMd5RetryTimes(md5RetryTimes int) ClientOption {
+	return func(config *ClientConfig) {
+		config.Md5RetryTimes = md5RetryTimes
+	}
+}
+
+// WithMd5RetryIntervalMs ...
+func WithMd5RetryIntervalMs(md5RetryIntervalMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.Md5RetryIntervalMs = md5RetryIntervalMs
+	}
+}
+
+// WithCallbackQueueSize ...
+func WithCallbackQueueSize(callbackQueueSize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.CallbackQueueSize = callbackQueueSize
+	}
+}
+
+// WithLocalConfigDir ...
+func WithLocalConfigDir(localConfigDir string) ClientOption {
+	return func(config *ClientConfig) {
+		config.LocalConfigDir = localConfigDir
+	}
+}
+
+// WithCacheEncryptionKey ...
+func WithCacheEncryptionKey(cacheEncryptionKey string) ClientOption {
+	return func(config *ClientConfig) {
+		config.CacheEncryptionKey = cacheEncryptionKey
+	}
+}
+
+// WithCompressThreshold ...
+func WithCompressThreshold(compressThreshold int) ClientOption {
+	return func(config *ClientConfig) {
+		config.CompressThreshold = compressThreshold
+	}
+}
+
 func WithKMSv3Config(kmsv3Config *KMSv3Config) ClientOption {
 	return func(config *ClientConfig) {
 		config.KMSv3Config = kmsv3Config
@@ -225,3 +276,433 @@ func WithTLS(tlsCfg TLSConfig) ClientOption {
 		config.TLSCfg = tlsCfg
 	}
 }
+
+// WithRequestQps ...
+func WithRequestQps(requestType string, qps float64) ClientOption {
+	return func(config *ClientConfig) {
+		if config.RequestQps == nil {
+			config.RequestQps = make(map[string]float64)
+		}
+		config.RequestQps[requestType] = qps
+	}
+}
+
+// WithInferConfigType ...
+func WithInferConfigType(inferConfigType bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.InferConfigType = inferConfigType
+	}
+}
+
+// WithPublishRetryCount ...
+func WithPublishRetryCount(publishRetryCount int) ClientOption {
+	return func(config *ClientConfig) {
+		config.PublishRetryCount = publishRetryCount
+	}
+}
+
+// WithPublishBackoffMs ...
+func WithPublishBackoffMs(publishBackoffMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.PublishBackoffMs = publishBackoffMs
+	}
+}
+
+// WithReadOnly ...
+func WithReadOnly(readOnly bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.ReadOnly = readOnly
+	}
+}
+
+// WithConfigSourcePriority ...
+func WithConfigSourcePriority(sources ...string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigSourcePriority = sources
+	}
+}
+
+// WithWatchDebounceMs ...
+func WithWatchDebounceMs(watchDebounceMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.WatchDebounceMs = watchDebounceMs
+	}
+}
+
+// WithGrpcMaxCallRecvMsgSize ...
+func WithGrpcMaxCallRecvMsgSize(grpcMaxCallRecvMsgSize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcMaxCallRecvMsgSize = grpcMaxCallRecvMsgSize
+	}
+}
+
+// WithGrpcMaxCallSendMsgSize ...
+func WithGrpcMaxCallSendMsgSize(grpcMaxCallSendMsgSize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcMaxCallSendMsgSize = grpcMaxCallSendMsgSize
+	}
+}
+
+// WithSnapshotTtlMs ...
+func WithSnapshotTtlMs(snapshotTtlMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.SnapshotTtlMs = snapshotTtlMs
+	}
+}
+
+// WithPersistentByGrpc ...
+func WithPersistentByGrpc(persistentByGrpc bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.PersistentByGrpc = persistentByGrpc
+	}
+}
+
+// WithServiceListPollMs ...
+func WithServiceListPollMs(serviceListPollMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServiceListPollMs = serviceListPollMs
+	}
+}
+
+// WithPushProtectThreshold ...
+func WithPushProtectThreshold(pushProtectThreshold float64) ClientOption {
+	return func(config *ClientConfig) {
+		config.PushProtectThreshold = pushProtectThreshold
+	}
+}
+
+// WithPushProtectGraceMs ...
+func WithPushProtectGraceMs(pushProtectGraceMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.PushProtectGraceMs = pushProtectGraceMs
+	}
+}
+
+// WithSubscribeWatchdogMs ...
+func WithSubscribeWatchdogMs(subscribeWatchdogMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.SubscribeWatchdogMs = subscribeWatchdogMs
+	}
+}
+
+// WithSubscribeMissedChecks ...
+func WithSubscribeMissedChecks(subscribeMissedChecks int) ClientOption {
+	return func(config *ClientConfig) {
+		config.SubscribeMissedChecks = subscribeMissedChecks
+	}
+}
+
+// WithNamingCompatMode ...
+func WithNamingCompatMode(namingCompatMode string) ClientOption {
+	return func(config *ClientConfig) {
+		config.NamingCompatMode = namingCompatMode
+	}
+}
+
+// WithReconcileIntervalMs ...
+func WithReconcileIntervalMs(reconcileIntervalMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.ReconcileIntervalMs = reconcileIntervalMs
+	}
+}
+
+// WithSelectCacheTtlMs ...
+func WithSelectCacheTtlMs(selectCacheTtlMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.SelectCacheTtlMs = selectCacheTtlMs
+	}
+}
+
+// WithGrpcDialer ...
+func WithGrpcDialer(grpcDialer GrpcDialer) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcDialer = grpcDialer
+	}
+}
+
+// WithGrpcDialOptions ...
+func WithGrpcDialOptions(grpcDialOptions ...grpc.DialOption) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcDialOptions = grpcDialOptions
+	}
+}
+
+// WithGrpcKeepAliveTimeMs ...
+func WithGrpcKeepAliveTimeMs(grpcKeepAliveTimeMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcKeepAliveTimeMs = grpcKeepAliveTimeMs
+	}
+}
+
+// WithGrpcKeepAliveTimeoutMs ...
+func WithGrpcKeepAliveTimeoutMs(grpcKeepAliveTimeoutMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcKeepAliveTimeoutMs = grpcKeepAliveTimeoutMs
+	}
+}
+
+// WithGrpcInitialWindowSize ...
+func WithGrpcInitialWindowSize(grpcInitialWindowSize int32) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcInitialWindowSize = grpcInitialWindowSize
+	}
+}
+
+// WithGrpcConnWindowSize ...
+func WithGrpcConnWindowSize(grpcConnWindowSize int32) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcConnWindowSize = grpcConnWindowSize
+	}
+}
+
+// WithGrpcMaxBackoffMs ...
+func WithGrpcMaxBackoffMs(grpcMaxBackoffMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcMaxBackoffMs = grpcMaxBackoffMs
+	}
+}
+
+// WithGrpcIdleTimeoutMs ...
+func WithGrpcIdleTimeoutMs(grpcIdleTimeoutMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcIdleTimeoutMs = grpcIdleTimeoutMs
+	}
+}
+
+// WithServerListProvider ...
+func WithServerListProvider(serverListProvider ServerListProvider) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServerListProvider = serverListProvider
+	}
+}
+
+// WithServerListRefreshMs ...
+func WithServerListRefreshMs(serverListRefreshMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServerListRefreshMs = serverListRefreshMs
+	}
+}
+
+// WithRequestMaxAttempts ...
+func WithRequestMaxAttempts(requestMaxAttempts int) ClientOption {
+	return func(config *ClientConfig) {
+		config.RequestMaxAttempts = requestMaxAttempts
+	}
+}
+
+// WithRequestRetryCodes ...
+func WithRequestRetryCodes(requestRetryCodes ...int) ClientOption {
+	return func(config *ClientConfig) {
+		config.RequestRetryCodes = requestRetryCodes
+	}
+}
+
+// WithRequestBackoffMs ...
+func WithRequestBackoffMs(requestBackoffMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.RequestBackoffMs = requestBackoffMs
+	}
+}
+
+// WithRequestMaxBackoffMs ...
+func WithRequestMaxBackoffMs(requestMaxBackoffMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.RequestMaxBackoffMs = requestMaxBackoffMs
+	}
+}
+
+// WithEnableGrpcCompression ...
+func WithEnableGrpcCompression(enableGrpcCompression bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.EnableGrpcCompression = enableGrpcCompression
+	}
+}
+
+// WithProxyURL ...
+func WithProxyURL(proxyURL string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ProxyURL = proxyURL
+	}
+}
+
+// WithConnectionLabels ...
+func WithConnectionLabels(connectionLabels map[string]string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConnectionLabels = connectionLabels
+	}
+}
+
+// WithGrpcHealthCheckMs ...
+func WithGrpcHealthCheckMs(grpcHealthCheckMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcHealthCheckMs = grpcHealthCheckMs
+	}
+}
+
+// WithGrpcHealthTimeoutMs ...
+func WithGrpcHealthTimeoutMs(grpcHealthTimeoutMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcHealthTimeoutMs = grpcHealthTimeoutMs
+	}
+}
+
+// WithGrpcHealthFailures ...
+func WithGrpcHealthFailures(grpcHealthFailures int) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcHealthFailures = grpcHealthFailures
+	}
+}
+
+// WithGrpcReconnectJitterMs ...
+func WithGrpcReconnectJitterMs(grpcReconnectJitterMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcReconnectJitterMs = grpcReconnectJitterMs
+	}
+}
+
+// WithGrpcProbeIdleMs ...
+func WithGrpcProbeIdleMs(grpcProbeIdleMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcProbeIdleMs = grpcProbeIdleMs
+	}
+}
+
+// WithWireLog ...
+func WithWireLog(wireLog bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.WireLog = wireLog
+	}
+}
+
+// WithHttpMaxIdlePerHost ...
+func WithHttpMaxIdlePerHost(httpMaxIdlePerHost int) ClientOption {
+	return func(config *ClientConfig) {
+		config.HttpMaxIdlePerHost = httpMaxIdlePerHost
+	}
+}
+
+// WithHttpTLSHandshakeMs ...
+func WithHttpTLSHandshakeMs(httpTLSHandshakeMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.HttpTLSHandshakeMs = httpTLSHandshakeMs
+	}
+}
+
+// WithHttpKeepAliveMs ...
+func WithHttpKeepAliveMs(httpKeepAliveMs int64) ClientOption {
+	return func(config *ClientConfig) {
+		config.HttpKeepAliveMs = httpKeepAliveMs
+	}
+}
+
+// WithHttpIdleTimeoutMs ...
+func WithHttpIdleTimeoutMs(httpIdleTimeoutMs uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.HttpIdleTimeoutMs = httpIdleTimeoutMs
+	}
+}
+
+// WithConfigCompatMode ...
+func WithConfigCompatMode(configCompatMode string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigCompatMode = configCompatMode
+	}
+}
+
+// WithSecurityToken ...
+func WithSecurityToken(securityToken string) ClientOption {
+	return func(config *ClientConfig) {
+		config.SecurityToken = securityToken
+	}
+}
+
+// WithCredentialsProvider ...
+func WithCredentialsProvider(credentialsProvider credentials.Provider) ClientOption {
+	return func(config *ClientConfig) {
+		config.CredentialsProvider = credentialsProvider
+	}
+}
+
+// WithRamRoleName ...
+func WithRamRoleName(ramRoleName string) ClientOption {
+	return func(config *ClientConfig) {
+		config.RamRoleName = ramRoleName
+	}
+}
+
+// WithRamRoleArn ...
+func WithRamRoleArn(ramRoleArn string) ClientOption {
+	return func(config *ClientConfig) {
+		config.RamRoleArn = ramRoleArn
+	}
+}
+
+// WithRamRoleSessionName ...
+func WithRamRoleSessionName(ramRoleSessionName string) ClientOption {
+	return func(config *ClientConfig) {
+		config.RamRoleSessionName = ramRoleSessionName
+	}
+}
+
+// WithCredentialsFile ...
+func WithCredentialsFile(credentialsFile string) ClientOption {
+	return func(config *ClientConfig) {
+		config.CredentialsFile = credentialsFile
+	}
+}
+
+// WithAuthPlugin ...
+func WithAuthPlugin(authPlugin AuthPlugin) ClientOption {
+	return func(config *ClientConfig) {
+		config.AuthPlugin = authPlugin
+	}
+}
+
+// WithTokenRefreshListener ...
+func WithTokenRefreshListener(tokenRefreshListener TokenRefreshListener) ClientOption {
+	return func(config *ClientConfig) {
+		config.TokenRefreshListener = tokenRefreshListener
+	}
+}
+
+// WithIdentityKey ...
+func WithIdentityKey(identityKey string) ClientOption {
+	return func(config *ClientConfig) {
+		config.IdentityKey = identityKey
+	}
+}
+
+// WithIdentityValue ...
+func WithIdentityValue(identityValue string) ClientOption {
+	return func(config *ClientConfig) {
+		config.IdentityValue = identityValue
+	}
+}
+
+// WithCallbackWorkerNum ...
+func WithCallbackWorkerNum(callbackWorkerNum int) ClientOption {
+	return func(config *ClientConfig) {
+		config.CallbackWorkerNum = callbackWorkerNum
+	}
+}
+
+// WithNamingSubsetKey ...
+func WithNamingSubsetKey(namingSubsetKey string) ClientOption {
+	return func(config *ClientConfig) {
+		config.NamingSubsetKey = namingSubsetKey
+	}
+}
+
+// WithConsumerLabels ...
+func WithConsumerLabels(consumerLabels map[string]string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConsumerLabels = consumerLabels
+	}
+}
+
+// WithCredentialsFromEnv ...
+func WithCredentialsFromEnv(credentialsFromEnv bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.CredentialsFromEnv = credentialsFromEnv
+	}
+}
@@ -27,84 +27,126 @@ const (
 	UNKNOWN_KMS_VERSION KMSVersion = "UNKNOWN_KMS_VERSION"
 )
 const (
-	KEY_USERNAME                = "username"
-	KEY_PASSWORD                = "password"
-	KEY_ENDPOINT                = "endpoint"
-	KEY_NAME_SPACE              = "namespace"
-	KEY_ACCESS_KEY              = "accessKey"
-	KEY_SECRET_KEY              = "secretKey"
-	KEY_SERVER_ADDR             = "serverAddr"
-	KEY_CONTEXT_PATH            = "contextPath"
-	KEY_ENCODE                  = "encode"
-	KEY_DATA_ID                 = "dataId"
-	KEY_GROUP                   = "group"
-	KEY_TENANT                  = "tenant"
-	KEY_DESC                    = "desc"
-	KEY_APP_NAME                = "appName"
-	KEY_CONTENT                 = "content"
-	KEY_TIMEOUT_MS              = "timeoutMs"
-	KEY_LISTEN_INTERVAL         = "listenInterval"
-	KEY_SERVER_CONFIGS          = "serverConfigs"
-	KEY_CLIENT_CONFIG           = "clientConfig"
-	KEY_TOKEN                   = "token"
-	KEY_ACCESS_TOKEN            = "accessToken"
-	KEY_TOKEN_TTL               = "tokenTtl"
-	KEY_GLOBAL_ADMIN            = "globalAdmin"
-	KEY_TOKEN_REFRESH_WINDOW    = "tokenRefreshWindow"
-	WEB_CONTEXT                 = "/nacos"
-	CONFIG_BASE_PATH            = "/v1/cs"
-	CONFIG_PATH                 = CONFIG_BASE_PATH + "/configs"
-	CONFIG_AGG_PATH             = "/datum.do"
-	CONFIG_LISTEN_PATH          = CONFIG_BASE_PATH + "/configs/listener"
-	SERVICE_BASE_PATH           = "/v1/ns"
-	SERVICE_PATH                = SERVICE_BASE_PATH + "/instance"
-	SERVICE_INFO_PATH           = SERVICE_BASE_PATH + "/service"
-	SERVICE_SUBSCRIBE_PATH      = SERVICE_PATH + "/list"
-	NAMESPACE_PATH              = "/v1/console/namespaces"
-	SPLIT_CONFIG                = string(rune(1))
-	SPLIT_CONFIG_INNER          = string(rune(2))
-	KEY_LISTEN_CONFIGS          = "Listening-Configs"
-	KEY_SERVICE_NAME            = "serviceName"
-	KEY_IP                      = "ip"
-	KEY_PORT                    = "port"
-	KEY_WEIGHT                  = "weight"
-	KEY_ENABLE                  = "enable"
-	KEY_HEALTHY                 = "healthy"
-	KEY_METADATA                = "metadata"
-	KEY_CLUSTER_NAME            = "clusterName"
-	KEY_CLUSTER                 = "cluster"
-	KEY_BEAT                    = "beat"
-	KEY_DOM                     = "dom"
-	DEFAULT_CONTEXT_PATH        = "/nacos"
-	CLIENT_VERSION              = "Nacos-Go-Client:v2.2.4"
-	REQUEST_DOMAIN_RETRY_TIME   = 3
-	SERVICE_INFO_SPLITER        = "@@"
-	CONFIG_INFO_SPLITER         = "@@"
-	DEFAULT_NAMESPACE_ID        = "public"
-	DEFAULT_GROUP               = "DEFAULT_GROUP"
-	NAMING_INSTANCE_ID_SPLITTER = "#"
-	DefaultClientErrorCode      = "SDK.NacosError"
-	DEFAULT_SERVER_SCHEME       = "http"
-	HTTPS_SERVER_SCHEME         = "https"
-	LABEL_SOURCE                = "source"
-	LABEL_SOURCE_SDK            = "sdk"
-	LABEL_MODULE                = "module"
-	LABEL_MODULE_CONFIG         = "config"
-	LABEL_MODULE_NAMING         = "naming"
-	RESPONSE_CODE_SUCCESS       = 200
-	UN_REGISTER                 = 301
-	KEEP_ALIVE_TIME             = 5
-	DEFAULT_TIMEOUT_MILLS       = 3000
-	ALL_SYNC_INTERNAL           = 5 * time.Minute
-	CLIENT_APPNAME_HEADER       = "Client-AppName"
-	APPNAME_HEADER              = "AppName"
-	CLIENT_REQUEST_TS_HEADER    = "Client-RequestTS"
-	CLIENT_REQUEST_TOKEN_HEADER = "Client-RequestToken"
-	EX_CONFIG_INFO              = "exConfigInfo"
-	CHARSET_KEY                 = "charset"
-	LOG_FILE_NAME               = "nacos-sdk.log"
-	HTTPS_SERVER_PORT           = 443
-	GRPC                        = "grpc"
-	RpcPortOffset               = 1000
-	MSE_KMSv1_DEFAULT_KEY_ID    = "alias/acs/mse"
+	KEY_USERNAME                     = "username"
+	KEY_PASSWORD                     = "password"
+	KEY_ENDPOINT                     = "endpoint"
+	KEY_NAME_SPACE                   = "namespace"
+	KEY_ACCESS_KEY                   = "accessKey"
+	KEY_SECRET_KEY                   = "secretKey"
+	KEY_SECURITY_TOKEN               = "securityToken"
+	KEY_SERVER_ADDR                  = "serverAddr"
+	KEY_CONTEXT_PATH                 = "contextPath"
+	KEY_ENCODE                       = "encode"
+	KEY_DATA_ID                      = "dataId"
+	KEY_GROUP                        = "group"
+	KEY_TENANT                       = "tenant"
+	KEY_DESC                         = "desc"
+	KEY_APP_NAME                     = "appName"
+	KEY_CONTENT                      = "content"
+	KEY_TIMEOUT_MS                   = "timeoutMs"
+	KEY_LISTEN_INTERVAL              = "listenInterval"
+	KEY_SERVER_CONFIGS               = "serverConfigs"
+	KEY_CLIENT_CONFIG                = "clientConfig"
+	KEY_TOKEN                        = "token"
+	KEY_ACCESS_TOKEN                 = "accessToken"
+	KEY_TOKEN_TTL                    = "tokenTtl"
+	KEY_GLOBAL_ADMIN                 = "globalAdmin"
+	KEY_TOKEN_REFRESH_WINDOW         = "tokenRefreshWindow"
+	WEB_CONTEXT                      = "/nacos"
+	CONFIG_BASE_PATH                 = "/v1/cs"
+	CONFIG_PATH                      = CONFIG_BASE_PATH + "/configs"
+	CONFIG_AGG_PATH                  = "/datum.do"
+	CONFIG_LISTEN_PATH               = CONFIG_BASE_PATH + "/configs/listener"
+	CONFIG_HISTORY_PATH              = CONFIG_BASE_PATH + "/history"
+	SERVICE_BASE_PATH                = "/v1/ns"
+	SERVICE_PATH                     = SERVICE_BASE_PATH + "/instance"
+	SERVICE_INFO_PATH                = SERVICE_BASE_PATH + "/service"
+	SERVICE_SUBSCRIBE_PATH           = SERVICE_PATH + "/list"
+	SERVICE_METADATA_PATH            = SERVICE_PATH + "/metadata/batch"
+	NAMESPACE_PATH                   = "/v1/console/namespaces"
+	SERVER_STATE_PATH                = "/v1/console/server/state"
+	SPLIT_CONFIG                     = string(rune(1))
+	SPLIT_CONFIG_INNER               = string(rune(2))
+	KEY_LISTEN_CONFIGS               = "Listening-Configs"
+	KEY_SERVICE_NAME                 = "serviceName"
+	KEY_IP                           = "ip"
+	KEY_PORT                         = "port"
+	KEY_WEIGHT                       = "weight"
+	KEY_ENABLE                       = "enable"
+	KEY_HEALTHY                      = "healthy"
+	KEY_METADATA                     = "metadata"
+	KEY_CLUSTER_NAME                 = "clusterName"
+	KEY_CLUSTER                      = "cluster"
+	KEY_BEAT                         = "beat"
+	KEY_DOM                          = "dom"
+	DEFAULT_CONTEXT_PATH             = "/nacos"
+	CLIENT_VERSION                   = "Nacos-Go-Client:v2.2.4"
+	REQUEST_DOMAIN_RETRY_TIME        = 3
+	SERVICE_INFO_SPLITER             = "@@"
+	CONFIG_INFO_SPLITER              = "@@"
+	DEFAULT_NAMESPACE_ID             = "public"
+	DEFAULT_GROUP                    = "DEFAULT_GROUP"
+	DEFAULT_CLUSTER                  = "DEFAULT"
+	NAMING_INSTANCE_ID_SPLITTER      = "#"
+	DefaultClientErrorCode           = "SDK.NacosError"
+	DEFAULT_SERVER_SCHEME            = "http"
+	HTTPS_SERVER_SCHEME              = "https"
+	LABEL_SOURCE                     = "source"
+	LABEL_SOURCE_SDK                 = "sdk"
+	LABEL_MODULE                     = "module"
+	LABEL_MODULE_CONFIG              = "config"
+	LABEL_MODULE_NAMING              = "naming"
+	LABEL_TASK_ID                    = "taskId"
+	RESPONSE_CODE_SUCCESS            = 200
+	RESPONSE_CODE_FAIL               = 500
+	UN_REGISTER                      = 301
+	NO_HANDLER                       = 302
+	KEEP_ALIVE_TIME                  = 5
+	DEFAULT_TIMEOUT_MILLS            = 3000
+	ALL_SYNC_INTERNAL                = 5 * time.Minute
+	CLIENT_APPNAME_HEADER            = "Client-AppName"
+	APPNAME_HEADER                   = "AppName"
+	CLIENT_REQUEST_TS_HEADER         = "Client-RequestTS"
+	CLIENT_REQUEST_TOKEN_HEADER      = "Client-RequestToken"
+	CONFIG_TYPE_HEADER               = "Config-Type"
+	ENCRYPTED_DATA_KEY_HEADER        = "Encrypted-Data-Key"
+	EX_CONFIG_INFO                   = "exConfigInfo"
+	CHARSET_KEY                      = "charset"
+	LOG_FILE_NAME                    = "nacos-sdk.log"
+	HTTPS_SERVER_PORT                = 443
+	GRPC                             = "grpc"
+	RpcPortOffset                    = 1000
+	DEFAULT_MD5_RETRY_TIMES          = 3
+	DEFAULT_MD5_RETRY_INTERVAL_MILLS = 500
+	DEFAULT_CALLBACK_WORKER_NUM      = 8
+	DEFAULT_CALLBACK_QUEUE_SIZE      = 1024
+	MAX_MD5_RETRY_INTERVAL           = 10 * time.Second
+	DEFAULT_PUBLISH_BACKOFF_MILLS    = 200
+	MAX_PUBLISH_BACKOFF              = 5 * time.Second
+	MSE_KMSv1_DEFAULT_KEY_ID         = "alias/acs/mse"
+	CONFIG_SOURCE_LOCAL              = "local"
+	CONFIG_SOURCE_FAILOVER           = "failover"
+	CONFIG_SOURCE_SERVER             = "server"
+	CONFIG_SOURCE_SNAPSHOT           = "snapshot"
+	DEFAULT_WATCH_DEBOUNCE_MILLS     = 100
+	BALANCER_WEIGHTED_RANDOM         = "weightedRandom"
+	BALANCER_ROUND_ROBIN             = "roundRobin"
+	BALANCER_LEAST_CONNECTIONS       = "leastConnections"
+	BALANCER_CONSISTENT_HASH         = "consistentHash"
+	BALANCER_ZONE_AFFINITY           = "zoneAffinity"
+	INSTANCE_ZONE_KEY                = "zone"
+	DEFAULT_SERVICE_LIST_POLL_MILLS  = 10000
+	NAMING_COMPAT_MODE_AUTO          = "auto"
+	NAMING_COMPAT_MODE_HTTP          = "http"
+	NAMING_COMPAT_MODE_GRPC          = "grpc"
+	CONFIG_COMPAT_MODE_AUTO          = "auto"
+	CONFIG_COMPAT_MODE_HTTP          = "http"
+	CONFIG_COMPAT_MODE_GRPC          = "grpc"
+	CONNECTION_EVENT_CONNECTED       = "CONNECTED"
+	CONNECTION_EVENT_DISCONNECTED    = "DISCONNECTED"
+	CONNECTION_EVENT_RECONNECTED     = "RECONNECTED"
+	CONNECTION_EVENT_SWITCHED        = "SWITCHED"
+	ABILITY_PERSISTENT_BY_GRPC       = "supportPersistentInstanceByGrpc"
+	ABILITY_FUZZY_WATCH              = "fuzzyWatch"
+	ABILITY_LOCK                     = "lock"
 )
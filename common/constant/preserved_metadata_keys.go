@@ -16,8 +16,25 @@
 
 package constant
 
+import "time"
+
+// the preserved metadata keys of the instances, the durations are in milliseconds.
+const (
+	REGISTER_SOURCE       = "preserved.register.source"
+	HEART_BEAT_TIMEOUT    = "preserved.heart.beat.timeout"
+	IP_DELETE_TIMEOUT     = "preserved.ip.delete.timeout"
+	HEART_BEAT_INTERVAL   = "preserved.heart.beat.interval"
+	INSTANCE_ID_GENERATOR = "preserved.instance.id.generator"
+)
+
+const (
+	INSTANCE_ID_GENERATOR_SIMPLE    = "simple"
+	INSTANCE_ID_GENERATOR_SNOWFLAKE = "snowflake"
+)
+
+// the defaults of the server when the preserved metadata are not set.
 const (
-	HEART_BEAT_TIMEOUT  = "preserved.heart.beat.timeout"
-	IP_DELETE_TIMEOUT   = "preserved.ip.delete.timeout"
-	HEART_BEAT_INTERVAL = "preserved.heart.beat.interval"
+	DEFAULT_HEART_BEAT_TIMEOUT  = 15 * time.Second
+	DEFAULT_IP_DELETE_TIMEOUT   = 30 * time.Second
+	DEFAULT_HEART_BEAT_INTERVAL = 5 * time.Second
 )
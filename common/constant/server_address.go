@@ -0,0 +1,83 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package constant
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SplitServerAddress parse the address of host, host:port, ipv6, [ipv6] or [ipv6]:port, the port is 0 when it's absent.
+func SplitServerAddress(address string) (string, uint64, error) {
+	address = strings.TrimSpace(address)
+	if len(address) == 0 {
+		return "", 0, errors.New("empty server address")
+	}
+	if net.ParseIP(address) != nil {
+		return address, 0, nil
+	}
+	if strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]") {
+		host := address[1 : len(address)-1]
+		if net.ParseIP(host) == nil {
+			return "", 0, errors.Errorf("invalid ipv6 server address %s", address)
+		}
+		return host, 0, nil
+	}
+	if !strings.Contains(address, ":") {
+		return address, 0, nil
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "invalid server address %s", address)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "invalid port of server address %s", address)
+	}
+	return host, port, nil
+}
+
+// Address return the host:port of the server, the ipv6 host is bracketed.
+func (s ServerConfig) Address() string {
+	return net.JoinHostPort(s.IpAddr, strconv.FormatUint(s.Port, 10))
+}
+
+// normalizeAddress strip the brackets of the ipv6 IpAddr, and take the port of an IpAddr like [::1]:8848
+// when the Port is not set. The IpAddr with a scheme is left as is.
+func (s *ServerConfig) normalizeAddress() {
+	if strings.Contains(s.IpAddr, "://") {
+		return
+	}
+	host, port, err := SplitServerAddress(s.IpAddr)
+	if err != nil {
+		return
+	}
+	s.IpAddr = host
+	if s.Port == 0 {
+		s.Port = port
+	}
+}
+
+// NormalizeServerConfigs normalize the addresses of the server configs in place.
+func NormalizeServerConfigs(configs []ServerConfig) {
+	for i := range configs {
+		configs[i].normalizeAddress()
+	}
+}
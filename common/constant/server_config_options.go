@@ -27,6 +27,7 @@ func NewServerConfig(ipAddr string, port uint64, opts ...ServerOption) *ServerCo
 	for _, opt := range opts {
 		opt(serverConfig)
 	}
+	serverConfig.normalizeAddress()
 
 	return serverConfig
 }
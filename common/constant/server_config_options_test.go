@@ -46,3 +46,35 @@ func TestNewServerConfigWithOptions(t *testing.T) {
 	assert.Equal(t, "https", config.Scheme)
 	assert.True(t, config.Port > 0 && config.Port < 65535)
 }
+
+func TestNewServerConfigWithIpv6(t *testing.T) {
+	config := NewServerConfig("[::1]:8848", 0)
+	assert.Equal(t, "::1", config.IpAddr)
+	assert.Equal(t, uint64(8848), config.Port)
+	assert.Equal(t, "[::1]:8848", config.Address())
+
+	config = NewServerConfig("fe80::1", 8848)
+	assert.Equal(t, "fe80::1", config.IpAddr)
+	assert.Equal(t, "[fe80::1]:8848", config.Address())
+}
+
+func TestSplitServerAddress(t *testing.T) {
+	for address, expected := range map[string]struct {
+		host string
+		port uint64
+	}{
+		"127.0.0.1":        {"127.0.0.1", 0},
+		"127.0.0.1:8848":   {"127.0.0.1", 8848},
+		"console.nacos.io": {"console.nacos.io", 0},
+		"::1":              {"::1", 0},
+		"[::1]":            {"::1", 0},
+		"[2001:db8::1]:80": {"2001:db8::1", 80},
+	} {
+		host, port, err := SplitServerAddress(address)
+		assert.Nil(t, err)
+		assert.Equal(t, expected.host, host, address)
+		assert.Equal(t, expected.port, port, address)
+	}
+	_, _, err := SplitServerAddress("127.0.0.1:port")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package constant
+
+import "context"
+
+// ServerListProvider provides the nacos servers dynamically, it's polled every ServerListRefreshMs of the client
+// config and the changes are applied to the live connections, an error or an empty list keeps the current servers.
+type ServerListProvider interface {
+	ServerList(ctx context.Context) ([]ServerConfig, error)
+}
+
+// ServerListFunc adapts a func to a ServerListProvider.
+type ServerListFunc func(ctx context.Context) ([]ServerConfig, error)
+
+func (f ServerListFunc) ServerList(ctx context.Context) ([]ServerConfig, error) {
+	return f(ctx)
+}
+
+// StaticServerList is a ServerListProvider of the fixed servers.
+type StaticServerList []ServerConfig
+
+func (l StaticServerList) ServerList(ctx context.Context) ([]ServerConfig, error) {
+	return l, nil
+}
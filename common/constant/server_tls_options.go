@@ -16,7 +16,7 @@
 
 package constant
 
-var SkipVerifyConfig = TLSConfig{Enable: true}
+var SkipVerifyConfig = TLSConfig{Enable: true, InsecureSkipVerify: true}
 
 func NewTLSConfig(opts ...TLSOption) *TLSConfig {
 	tlsConfig := TLSConfig{Enable: true}
@@ -41,3 +41,9 @@ func WithCertificate(certFile, keyFile string) TLSOption {
 		tc.KeyFile = keyFile
 	}
 }
+
+func WithInsecureSkipVerify(insecureSkipVerify bool) TLSOption {
+	return func(tc *TLSConfig) {
+		tc.InsecureSkipVerify = insecureSkipVerify
+	}
+}
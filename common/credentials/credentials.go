@@ -0,0 +1,177 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// refreshAhead is how long before the expiration the temporary credentials are refreshed.
+const refreshAhead = 3 * time.Minute
+
+// Credentials are the keys to sign the requests to the server, SecurityToken is only set for the temporary
+// credentials of STS and ECS RAM role.
+type Credentials struct {
+	AccessKey     string
+	SecretKey     string
+	SecurityToken string
+	Expiration    time.Time
+}
+
+// IsEmpty returns whether the credentials have no keys.
+func (c Credentials) IsEmpty() bool {
+	return len(c.AccessKey) == 0 || len(c.SecretKey) == 0
+}
+
+// Provider provides the credentials to sign the requests.
+type Provider interface {
+	Credentials() (Credentials, error)
+}
+
+// ProviderFunc adapts a func to the Provider.
+type ProviderFunc func() (Credentials, error)
+
+// Credentials calls f.
+func (f ProviderFunc) Credentials() (Credentials, error) {
+	return f()
+}
+
+// StaticProvider returns the fixed credentials.
+type StaticProvider Credentials
+
+// Credentials returns the static credentials.
+func (p StaticProvider) Credentials() (Credentials, error) {
+	return Credentials(p), nil
+}
+
+// Chain returns the credentials of the first provider that has any, the errors are returned only if no provider
+// has the credentials.
+type Chain []Provider
+
+// Credentials walks the chain in order.
+func (c Chain) Credentials() (Credentials, error) {
+	var errs []string
+	for _, provider := range c {
+		credentials, err := provider.Credentials()
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !credentials.IsEmpty() {
+			return credentials, nil
+		}
+	}
+	if len(errs) > 0 {
+		return Credentials{}, errors.Errorf("no credentials found: %s", strings.Join(errs, "; "))
+	}
+	return Credentials{}, nil
+}
+
+// cachedProvider caches the credentials of the provider, the credentials without expiration are kept for maxAge
+// and the temporary ones are refreshed ahead of the expiration.
+type cachedProvider struct {
+	provider  Provider
+	maxAge    time.Duration
+	mutex     sync.Mutex
+	cached    Credentials
+	refreshAt time.Time
+}
+
+// NewCachedProvider caches the credentials of provider, the credentials without expiration are fetched again
+// after maxAge.
+func NewCachedProvider(provider Provider, maxAge time.Duration) Provider {
+	return &cachedProvider{provider: provider, maxAge: maxAge}
+}
+
+func (p *cachedProvider) Credentials() (Credentials, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	if !p.refreshAt.IsZero() && now.Before(p.refreshAt) {
+		return p.cached, nil
+	}
+	credentials, err := p.provider.Credentials()
+	if err != nil {
+		// keep using the old temporary credentials until they really expire
+		if !p.cached.IsEmpty() && !p.cached.Expiration.IsZero() && now.Before(p.cached.Expiration) {
+			return p.cached, nil
+		}
+		return Credentials{}, err
+	}
+	p.cached = credentials
+	p.refreshAt = now.Add(p.maxAge)
+	if !credentials.Expiration.IsZero() {
+		if refreshAt := credentials.Expiration.Add(-refreshAhead); refreshAt.Before(p.refreshAt) {
+			p.refreshAt = refreshAt
+		}
+	}
+	return credentials, nil
+}
+
+// Options configures the default provider chain.
+type Options struct {
+	// AccessKey, SecretKey and SecurityToken are the static credentials.
+	AccessKey     string
+	SecretKey     string
+	SecurityToken string
+	// RamRoleName is the RAM role attached to the ECS instance.
+	RamRoleName string
+	// RoleArn is the RAM role to assume with STS, the other credentials of the chain are used to assume it.
+	RoleArn         string
+	RoleSessionName string
+	// CredentialsFile is the ini credentials file of the aliyun cli.
+	CredentialsFile string
+	// FromEnv enables the environment variables and the credentials file named by them.
+	FromEnv    bool
+	HttpClient *http.Client
+}
+
+// NewDefaultChain returns the provider chain of the static credentials, the ECS RAM role, the credentials file
+// and the environment variables. The environment is only read if FromEnv is set, so a client doesn't sign with
+// the keys of the process by surprise. The chain is used to assume the role with STS if RoleArn is set.
+func NewDefaultChain(options Options) Provider {
+	var chain Chain
+	if len(options.AccessKey) > 0 {
+		chain = append(chain, StaticProvider{
+			AccessKey:     options.AccessKey,
+			SecretKey:     options.SecretKey,
+			SecurityToken: options.SecurityToken,
+		})
+	}
+	if len(options.RamRoleName) > 0 {
+		chain = append(chain, NewEcsRamRoleProvider(options.RamRoleName, options.HttpClient))
+	}
+	if len(options.CredentialsFile) > 0 {
+		chain = append(chain, NewFileProvider(options.CredentialsFile, options.HttpClient))
+	}
+	if options.FromEnv {
+		chain = append(chain, EnvProvider{})
+		if len(options.CredentialsFile) == 0 {
+			chain = append(chain, NewFileProvider("", options.HttpClient))
+		}
+	}
+	var provider Provider = chain
+	if len(options.RoleArn) > 0 {
+		provider = NewStsProvider(chain, options.RoleArn, options.RoleSessionName, options.HttpClient)
+	}
+	return NewCachedProvider(provider, time.Minute)
+}
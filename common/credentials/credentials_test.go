@@ -0,0 +1,176 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	failed := ProviderFunc(func() (Credentials, error) {
+		return Credentials{}, errors.New("failed")
+	})
+	static := StaticProvider{AccessKey: "ak", SecretKey: "sk"}
+	c, err := Chain{failed, StaticProvider{}, static}.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak", c.AccessKey)
+
+	c, err = Chain{StaticProvider{}}.Credentials()
+	assert.Nil(t, err)
+	assert.True(t, c.IsEmpty())
+
+	_, err = Chain{failed}.Credentials()
+	assert.NotNil(t, err)
+}
+
+func TestCachedProvider(t *testing.T) {
+	calls := 0
+	var fail bool
+	provider := NewCachedProvider(ProviderFunc(func() (Credentials, error) {
+		calls++
+		if fail {
+			return Credentials{}, errors.New("failed")
+		}
+		return Credentials{AccessKey: "ak", SecretKey: "sk", Expiration: time.Now().Add(refreshAhead + time.Second)}, nil
+	}), time.Hour)
+	_, _ = provider.Credentials()
+	_, _ = provider.Credentials()
+	assert.Equal(t, 1, calls)
+
+	time.Sleep(time.Second)
+	fail = true
+	c, err := provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak", c.AccessKey)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naccess_key_id = ak\naccess_key_secret = sk\n\n[test]\ntype = sts\naccess_key_id = ak1\naccess_key_secret = sk1\nsecurity_token = token\n"
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	c, err := NewFileProvider(path, nil).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{AccessKey: "ak", SecretKey: "sk"}, c)
+
+	t.Setenv(EnvProfile, "test")
+	c, err = NewFileProvider(path, nil).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{AccessKey: "ak1", SecretKey: "sk1", SecurityToken: "token"}, c)
+
+	t.Setenv(EnvProfile, "none")
+	c, err = NewFileProvider(path, nil).Credentials()
+	assert.Nil(t, err)
+	assert.True(t, c.IsEmpty())
+}
+
+func TestDefaultChainEnv(t *testing.T) {
+	t.Setenv(EnvAccessKeyId, "env-ak")
+	t.Setenv(EnvAccessKeySecret, "env-sk")
+	c, err := NewDefaultChain(Options{}).Credentials()
+	assert.Nil(t, err)
+	assert.True(t, c.IsEmpty())
+
+	c, err = NewDefaultChain(Options{FromEnv: true}).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "env-ak", c.AccessKey)
+
+	c, err = NewDefaultChain(Options{AccessKey: "ak", SecretKey: "sk", FromEnv: true}).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "ak", c.AccessKey)
+}
+
+func TestEcsRamRoleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte("role"))
+		case "/role":
+			_, _ = w.Write([]byte(`{"Code":"Success","AccessKeyId":"STS.ak","AccessKeySecret":"sk",` +
+				`"SecurityToken":"token","Expiration":"2099-01-01T00:00:00Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	endpoint := ecsMetadataEndpoint
+	ecsMetadataEndpoint = server.URL + "/"
+	defer func() { ecsMetadataEndpoint = endpoint }()
+
+	c, err := NewEcsRamRoleProvider("", nil).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "STS.ak", c.AccessKey)
+	assert.Equal(t, "token", c.SecurityToken)
+	assert.Equal(t, 2099, c.Expiration.Year())
+
+	_, err = NewEcsRamRoleProvider("unknown", nil).Credentials()
+	assert.NotNil(t, err)
+}
+
+func TestStsProvider(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"Credentials":{"AccessKeyId":"STS.ak","AccessKeySecret":"sts-sk",` +
+			`"SecurityToken":"token","Expiration":"2099-01-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+	endpoint := stsEndpoint
+	stsEndpoint = server.URL + "/"
+	defer func() { stsEndpoint = endpoint }()
+
+	c, err := NewStsProvider(StaticProvider{AccessKey: "ak", SecretKey: "sk"}, "acs:ram::1:role/nacos", "", nil).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{AccessKey: "STS.ak", SecretKey: "sts-sk", SecurityToken: "token",
+		Expiration: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}, c)
+	assert.Equal(t, "AssumeRole", query.Get("Action"))
+	assert.Equal(t, "ak", query.Get("AccessKeyId"))
+	assert.Equal(t, defaultRoleSessionName, query.Get("RoleSessionName"))
+
+	params := map[string]string{}
+	for key := range query {
+		if key != "Signature" {
+			params[key] = query.Get(key)
+		}
+	}
+	assert.Equal(t, signQuery(http.MethodGet, canonicalizedQuery(params), "sk"), query.Get("Signature"))
+}
+
+func TestSignQuery(t *testing.T) {
+	// the example of the rpc signature in the aliyun docs
+	params := map[string]string{
+		"AccessKeyId":      "testid",
+		"Action":           "DescribeRegions",
+		"Format":           "XML",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf",
+		"SignatureVersion": "1.0",
+		"Timestamp":        "2016-02-23T12:46:24Z",
+		"Version":          "2014-05-26",
+	}
+	assert.Equal(t, "OLeaidS1JvxuMvnyHOwuJ+uX5qY=", signQuery(http.MethodGet, canonicalizedQuery(params), "testsecret"))
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ecsMetadataEndpoint = "http://100.100.100.200/latest/meta-data/ram/security-credentials/"
+
+const defaultHttpTimeout = 5 * time.Second
+
+type ecsRamRoleResponse struct {
+	Code            string `json:"Code"`
+	AccessKeyId     string `json:"AccessKeyId"`
+	AccessKeySecret string `json:"AccessKeySecret"`
+	SecurityToken   string `json:"SecurityToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// EcsRamRoleProvider reads the temporary credentials of the RAM role attached to the ECS instance from the
+// instance metadata, the credentials are refreshed ahead of the expiration.
+type EcsRamRoleProvider struct {
+	roleName   string
+	httpClient *http.Client
+}
+
+// NewEcsRamRoleProvider returns the cached provider of the role, the role attached to the instance is used if
+// roleName is empty.
+func NewEcsRamRoleProvider(roleName string, httpClient *http.Client) Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHttpTimeout}
+	}
+	return NewCachedProvider(&EcsRamRoleProvider{roleName: roleName, httpClient: httpClient}, time.Hour)
+}
+
+// Credentials fetches the credentials from the metadata.
+func (p *EcsRamRoleProvider) Credentials() (Credentials, error) {
+	roleName := p.roleName
+	if len(roleName) == 0 {
+		body, err := p.get(ecsMetadataEndpoint)
+		if err != nil {
+			return Credentials{}, errors.Wrap(err, "get the ecs ram role")
+		}
+		roleName = strings.TrimSpace(strings.SplitN(string(body), "\n", 2)[0])
+		if len(roleName) == 0 {
+			return Credentials{}, errors.New("no ram role is attached to the ecs instance")
+		}
+	}
+	body, err := p.get(ecsMetadataEndpoint + roleName)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "get the credentials of ecs ram role %s", roleName)
+	}
+	var response ecsRamRoleResponse
+	if err = json.Unmarshal(body, &response); err != nil {
+		return Credentials{}, errors.Wrapf(err, "parse the credentials of ecs ram role %s", roleName)
+	}
+	if response.Code != "Success" {
+		return Credentials{}, errors.Errorf("get the credentials of ecs ram role %s failed, code:%s", roleName, response.Code)
+	}
+	expiration, err := time.Parse(time.RFC3339, response.Expiration)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "parse the expiration of ecs ram role %s", roleName)
+	}
+	return Credentials{
+		AccessKey:     response.AccessKeyId,
+		SecretKey:     response.AccessKeySecret,
+		SecurityToken: response.SecurityToken,
+		Expiration:    expiration,
+	}, nil
+}
+
+func (p *EcsRamRoleProvider) get(url string) ([]byte, error) {
+	response, err := p.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("status code:%d, body:%s", response.StatusCode, string(body))
+	}
+	return body, nil
+}
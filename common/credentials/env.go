@@ -0,0 +1,40 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import "os"
+
+const (
+	EnvAccessKeyId     = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	EnvAccessKeySecret = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	EnvSecurityToken   = "ALIBABA_CLOUD_SECURITY_TOKEN"
+	EnvCredentialsFile = "ALIBABA_CLOUD_CREDENTIALS_FILE"
+	EnvProfile         = "ALIBABA_CLOUD_PROFILE"
+)
+
+// EnvProvider reads the credentials from ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET and
+// ALIBABA_CLOUD_SECURITY_TOKEN.
+type EnvProvider struct{}
+
+// Credentials returns the credentials of the environment variables.
+func (EnvProvider) Credentials() (Credentials, error) {
+	return Credentials{
+		AccessKey:     os.Getenv(EnvAccessKeyId),
+		SecretKey:     os.Getenv(EnvAccessKeySecret),
+		SecurityToken: os.Getenv(EnvSecurityToken),
+	}, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const defaultProfile = "default"
+
+// FileProvider reads the credentials of the profile from the ini credentials file of the aliyun cli, the
+// access_key, sts, ecs_ram_role and ram_role_arn types are supported.
+type FileProvider struct {
+	path       string
+	httpClient *http.Client
+	once       sync.Once
+	provider   Provider
+	err        error
+}
+
+// NewFileProvider reads the credentials file at path, ALIBABA_CLOUD_CREDENTIALS_FILE is used if it's empty. The
+// ~/.alibabacloud/credentials of the aliyun cli isn't read implicitly to not send its keys to the other servers.
+// The profile is read from ALIBABA_CLOUD_PROFILE.
+func NewFileProvider(path string, httpClient *http.Client) *FileProvider {
+	return &FileProvider{path: path, httpClient: httpClient}
+}
+
+// Credentials returns the credentials of the profile, nothing is returned if no file is set.
+func (p *FileProvider) Credentials() (Credentials, error) {
+	p.once.Do(func() {
+		p.provider, p.err = p.load()
+	})
+	if p.err != nil || p.provider == nil {
+		return Credentials{}, p.err
+	}
+	return p.provider.Credentials()
+}
+
+func (p *FileProvider) load() (Provider, error) {
+	path := p.path
+	if len(path) == 0 {
+		path = os.Getenv(EnvCredentialsFile)
+	}
+	if len(path) == 0 {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open credentials file %s", path)
+	}
+	defer file.Close()
+	profileName := os.Getenv(EnvProfile)
+	if len(profileName) == 0 {
+		profileName = defaultProfile
+	}
+	profile, err := readProfile(file, profileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read credentials file %s", path)
+	}
+	if profile == nil {
+		return nil, nil
+	}
+	return p.profileProvider(profileName, profile)
+}
+
+func (p *FileProvider) profileProvider(name string, profile map[string]string) (Provider, error) {
+	static := StaticProvider{
+		AccessKey: profile["access_key_id"],
+		SecretKey: profile["access_key_secret"],
+	}
+	switch profile["type"] {
+	case "", "access_key":
+		return static, nil
+	case "sts":
+		static.SecurityToken = profile["security_token"]
+		return static, nil
+	case "ecs_ram_role":
+		return NewEcsRamRoleProvider(profile["role_name"], p.httpClient), nil
+	case "ram_role_arn":
+		return NewStsProvider(static, profile["role_arn"], profile["role_session_name"], p.httpClient), nil
+	default:
+		return nil, errors.Errorf("unsupported credentials type %s of profile %s", profile["type"], name)
+	}
+}
+
+// readProfile returns the keys of the section, nil if the section doesn't exist.
+func readProfile(file *os.File, section string) (map[string]string, error) {
+	var profile map[string]string
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if current == section && profile == nil {
+				profile = map[string]string{}
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if i := strings.Index(line, "="); i > 0 {
+			profile[strings.TrimSpace(line[:i])] = strings.Trim(strings.TrimSpace(line[i+1:]), `"`)
+		}
+	}
+	return profile, scanner.Err()
+}
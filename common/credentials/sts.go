@@ -0,0 +1,167 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package credentials
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var stsEndpoint = "https://sts.aliyuncs.com/"
+
+const (
+	defaultRoleSessionName = "nacos-sdk-go"
+	stsDurationSeconds     = "3600"
+)
+
+type stsResponse struct {
+	Code        string `json:"Code"`
+	Message     string `json:"Message"`
+	Credentials struct {
+		AccessKeyId     string `json:"AccessKeyId"`
+		AccessKeySecret string `json:"AccessKeySecret"`
+		SecurityToken   string `json:"SecurityToken"`
+		Expiration      string `json:"Expiration"`
+	} `json:"Credentials"`
+}
+
+// StsProvider assumes the RAM role with the credentials of the source, the temporary credentials are refreshed
+// ahead of the expiration.
+type StsProvider struct {
+	source          Provider
+	roleArn         string
+	roleSessionName string
+	httpClient      *http.Client
+}
+
+// NewStsProvider returns the cached provider to assume roleArn with the credentials of source.
+func NewStsProvider(source Provider, roleArn string, roleSessionName string, httpClient *http.Client) Provider {
+	if len(roleSessionName) == 0 {
+		roleSessionName = defaultRoleSessionName
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHttpTimeout}
+	}
+	return NewCachedProvider(&StsProvider{
+		source:          source,
+		roleArn:         roleArn,
+		roleSessionName: roleSessionName,
+		httpClient:      httpClient,
+	}, time.Hour)
+}
+
+// Credentials calls the AssumeRole of STS.
+func (p *StsProvider) Credentials() (Credentials, error) {
+	source, err := p.source.Credentials()
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "get the source credentials to assume role %s", p.roleArn)
+	}
+	if source.IsEmpty() {
+		return Credentials{}, errors.Errorf("no source credentials to assume role %s", p.roleArn)
+	}
+	params := map[string]string{
+		"Action":           "AssumeRole",
+		"Format":           "JSON",
+		"Version":          "2015-04-01",
+		"AccessKeyId":      source.AccessKey,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   nonce(),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"RoleArn":          p.roleArn,
+		"RoleSessionName":  p.roleSessionName,
+		"DurationSeconds":  stsDurationSeconds,
+	}
+	if len(source.SecurityToken) > 0 {
+		params["SecurityToken"] = source.SecurityToken
+	}
+	query := canonicalizedQuery(params)
+	query += "&Signature=" + percentEncode(signQuery(http.MethodGet, query, source.SecretKey))
+	response, err := p.httpClient.Get(stsEndpoint + "?" + query)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "assume role %s", p.roleArn)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "assume role %s", p.roleArn)
+	}
+	var result stsResponse
+	if err = json.Unmarshal(body, &result); err != nil {
+		return Credentials{}, errors.Wrapf(err, "parse the response of assume role %s", p.roleArn)
+	}
+	if response.StatusCode != http.StatusOK {
+		return Credentials{}, errors.Errorf("assume role %s failed, code:%s, message:%s", p.roleArn, result.Code, result.Message)
+	}
+	expiration, err := time.Parse(time.RFC3339, result.Credentials.Expiration)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "parse the expiration of assume role %s", p.roleArn)
+	}
+	return Credentials{
+		AccessKey:     result.Credentials.AccessKeyId,
+		SecretKey:     result.Credentials.AccessKeySecret,
+		SecurityToken: result.Credentials.SecurityToken,
+		Expiration:    expiration,
+	}, nil
+}
+
+// canonicalizedQuery encodes the params sorted by the keys.
+func canonicalizedQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, percentEncode(key)+"="+percentEncode(params[key]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// signQuery signs the canonicalized query with the rpc signature of the aliyun pop api.
+func signQuery(method string, query string, secretKey string) string {
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(query)
+	mac := hmac.New(sha1.New, []byte(secretKey+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func percentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+func nonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
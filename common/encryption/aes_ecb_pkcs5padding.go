@@ -59,7 +59,13 @@ func PKCS5Padding(ciphertext []byte, blockSize int) []byte {
 
 func PKCS5UnPadding(origData []byte) []byte {
 	length := len(origData)
+	if length == 0 {
+		return origData
+	}
 	unpadding := int(origData[length-1])
+	if unpadding > length {
+		return origData
+	}
 	return origData[:(length - unpadding)]
 }
 
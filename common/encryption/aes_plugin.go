@@ -0,0 +1,150 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"crypto/rand"
+
+	inner_encoding "github.com/jun3372/nacos-sdk-go/common/encoding"
+	"github.com/pkg/errors"
+)
+
+// AesPlugin encrypt the content with a random data key per publish, the data key is encrypted
+// by the master key and stored as encryptedDataKey.
+type AesPlugin struct {
+	masterKey []byte
+}
+
+func NewAesPlugin(masterKey string) (*AesPlugin, error) {
+	switch len(masterKey) {
+	case 16, 24, 32:
+	default:
+		return nil, errors.Errorf("aes master key length should be 16, 24 or 32, but is %d", len(masterKey))
+	}
+	return &AesPlugin{masterKey: []byte(masterKey)}, nil
+}
+
+func (a *AesPlugin) Encrypt(param *HandlerParam) error {
+	if len(param.PlainDataKey) == 0 {
+		return EmptyPlainDataKeyError
+	}
+	encrypted, err := aesEncryptBase64(param.Content, param.PlainDataKey)
+	if err != nil {
+		return err
+	}
+	param.Content = encrypted
+	return nil
+}
+
+func (a *AesPlugin) Decrypt(param *HandlerParam) error {
+	if len(param.PlainDataKey) == 0 {
+		return EmptyPlainDataKeyError
+	}
+	decrypted, err := aesDecryptBase64(param.Content, param.PlainDataKey)
+	if err != nil {
+		return err
+	}
+	param.Content = decrypted
+	return nil
+}
+
+func (a *AesPlugin) AlgorithmName() string {
+	return AesAlgorithmName
+}
+
+func (a *AesPlugin) GenerateSecretKey(param *HandlerParam) (string, error) {
+	dataKey := make([]byte, aesDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	encoded, err := inner_encoding.EncodeBase64(dataKey)
+	if err != nil {
+		return "", err
+	}
+	param.PlainDataKey = inner_encoding.EncodeUtf8Bytes2String(encoded)
+	if _, err = a.EncryptSecretKey(param); err != nil {
+		return "", err
+	}
+	return param.PlainDataKey, nil
+}
+
+func (a *AesPlugin) EncryptSecretKey(param *HandlerParam) (string, error) {
+	if len(param.PlainDataKey) == 0 {
+		return "", EmptyPlainDataKeyError
+	}
+	encryptedDataKey, err := aesEncryptBase64Raw(param.PlainDataKey, a.masterKey)
+	if err != nil {
+		return "", err
+	}
+	param.EncryptedDataKey = encryptedDataKey
+	return encryptedDataKey, nil
+}
+
+func (a *AesPlugin) DecryptSecretKey(param *HandlerParam) (string, error) {
+	if len(param.EncryptedDataKey) == 0 {
+		return "", EmptyEncryptedDataKeyError
+	}
+	plainDataKey, err := aesDecryptBase64Raw(param.EncryptedDataKey, a.masterKey)
+	if err != nil {
+		return "", err
+	}
+	if len(plainDataKey) == 0 {
+		return "", EmptyPlainDataKeyError
+	}
+	param.PlainDataKey = plainDataKey
+	return plainDataKey, nil
+}
+
+func aesEncryptBase64(content, base64Key string) (string, error) {
+	key, err := inner_encoding.DecodeBase64(inner_encoding.DecodeString2Utf8Bytes(base64Key))
+	if err != nil {
+		return "", err
+	}
+	return aesEncryptBase64Raw(content, key)
+}
+
+func aesDecryptBase64(content, base64Key string) (string, error) {
+	key, err := inner_encoding.DecodeBase64(inner_encoding.DecodeString2Utf8Bytes(base64Key))
+	if err != nil {
+		return "", err
+	}
+	return aesDecryptBase64Raw(content, key)
+}
+
+func aesEncryptBase64Raw(content string, key []byte) (string, error) {
+	encrypted, err := AesEcbPkcs5PaddingEncrypt(inner_encoding.DecodeString2Utf8Bytes(content), key)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := inner_encoding.EncodeBase64(encrypted)
+	if err != nil {
+		return "", err
+	}
+	return inner_encoding.EncodeUtf8Bytes2String(encoded), nil
+}
+
+func aesDecryptBase64Raw(content string, key []byte) (string, error) {
+	decoded, err := inner_encoding.DecodeBase64(inner_encoding.DecodeString2Utf8Bytes(content))
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := AesEcbPkcs5PaddingDecrypt(decoded, key)
+	if err != nil {
+		return "", err
+	}
+	return inner_encoding.EncodeUtf8Bytes2String(decrypted), nil
+}
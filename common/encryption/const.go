@@ -24,6 +24,7 @@ const (
 	KmsAes128AlgorithmName = "cipher-kms-aes-128"
 	KmsAes256AlgorithmName = "cipher-kms-aes-256"
 	KmsAlgorithmName       = "cipher"
+	AesAlgorithmName       = "cipher-aes"
 
 	kmsAes128KeySpec = "AES_128"
 	kmsAes256KeySpec = "AES_256"
@@ -36,7 +37,10 @@ const (
 	maskUnit8Width  = 8
 	maskUnit32Width = 32
 
-	KmsHandlerName = "KmsHandler"
+	KmsHandlerName           = "KmsHandler"
+	PluginManagerHandlerName = "PluginManagerHandler"
+
+	aesDataKeySize = 16
 )
 
 var (
@@ -0,0 +1,160 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/pkg/errors"
+)
+
+var defaultPluginManager = NewEncryptionPluginManager()
+
+// GetDefaultEncryptionPluginManager return the global plugin manager, plugins joined to it are
+// available to every config client created afterwards.
+func GetDefaultEncryptionPluginManager() *EncryptionPluginManager {
+	return defaultPluginManager
+}
+
+// EncryptionPluginManager hold the encryption plugins keyed by algorithm name, a dataId is handled
+// by the plugin with the longest algorithm name it starts with.
+type EncryptionPluginManager struct {
+	mux     sync.RWMutex
+	plugins map[string]Plugin
+}
+
+func NewEncryptionPluginManager() *EncryptionPluginManager {
+	return &EncryptionPluginManager{plugins: make(map[string]Plugin, 4)}
+}
+
+func (m *EncryptionPluginManager) Join(plugin Plugin) error {
+	if plugin == nil {
+		return errors.New("encryption plugin can not be nil")
+	}
+	name := plugin.AlgorithmName()
+	if !strings.HasPrefix(name, CipherPrefix) && name != KmsAlgorithmName {
+		return errors.Errorf("encryption plugin algorithm name should start with: %s", CipherPrefix)
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.plugins[name]; ok {
+		logger.Warnf("encryption algorithm [%s] has already joined to plugin manager, will be update", name)
+	}
+	m.plugins[name] = plugin
+	return nil
+}
+
+func (m *EncryptionPluginManager) Remove(algorithmName string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.plugins, algorithmName)
+}
+
+func (m *EncryptionPluginManager) GetPlugin(dataId string) (Plugin, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	var matched Plugin
+	matchedLen := 0
+	for name, plugin := range m.plugins {
+		if strings.HasPrefix(dataId, name) && len(name) > matchedLen {
+			matched = plugin
+			matchedLen = len(name)
+		}
+	}
+	if matched == nil {
+		return nil, PluginNotFoundError
+	}
+	return matched, nil
+}
+
+func (m *EncryptionPluginManager) Size() int {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return len(m.plugins)
+}
+
+// Clone return a new manager with the same plugins.
+func (m *EncryptionPluginManager) Clone() *EncryptionPluginManager {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	clone := NewEncryptionPluginManager()
+	for name, plugin := range m.plugins {
+		clone.plugins[name] = plugin
+	}
+	return clone
+}
+
+// NewPluginManagerHandler return a Handler which encrypts and decrypts with the plugins of manager.
+func NewPluginManagerHandler(manager *EncryptionPluginManager) Handler {
+	return &pluginManagerHandler{manager: manager}
+}
+
+type pluginManagerHandler struct {
+	manager *EncryptionPluginManager
+}
+
+func (h *pluginManagerHandler) EncryptionHandler(param *HandlerParam) error {
+	if err := h.paramCheck(*param); err != nil {
+		return err
+	}
+	plugin, err := h.manager.GetPlugin(param.DataId)
+	if err != nil {
+		return err
+	}
+	plainSecretKey, err := plugin.GenerateSecretKey(param)
+	if err != nil {
+		return err
+	}
+	param.PlainDataKey = plainSecretKey
+	return plugin.Encrypt(param)
+}
+
+func (h *pluginManagerHandler) DecryptionHandler(param *HandlerParam) error {
+	if err := h.paramCheck(*param); err != nil {
+		return err
+	}
+	plugin, err := h.manager.GetPlugin(param.DataId)
+	if err != nil {
+		return err
+	}
+	plainSecretKey, err := plugin.DecryptSecretKey(param)
+	if err != nil {
+		return err
+	}
+	param.PlainDataKey = plainSecretKey
+	return plugin.Decrypt(param)
+}
+
+func (h *pluginManagerHandler) RegisterPlugin(plugin Plugin) error {
+	return h.manager.Join(plugin)
+}
+
+func (h *pluginManagerHandler) GetHandlerName() string {
+	return PluginManagerHandlerName
+}
+
+func (h *pluginManagerHandler) paramCheck(param HandlerParam) error {
+	if !strings.HasPrefix(param.DataId, CipherPrefix) {
+		return DataIdParamCheckError
+	}
+	if len(param.Content) == 0 {
+		return ContentParamCheckError
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginManagerHandler_Aes(t *testing.T) {
+	_, err := NewAesPlugin("short")
+	assert.NotNil(t, err)
+
+	aesPlugin, err := NewAesPlugin("0123456789abcdef")
+	assert.Nil(t, err)
+	manager := NewEncryptionPluginManager()
+	assert.Nil(t, manager.Join(aesPlugin))
+	handler := NewPluginManagerHandler(manager)
+
+	param := &HandlerParam{DataId: "cipher-aes-test", Content: "hello 加密"}
+	assert.Nil(t, handler.EncryptionHandler(param))
+	assert.NotEqual(t, "hello 加密", param.Content)
+	assert.NotEmpty(t, param.EncryptedDataKey)
+
+	decryptParam := &HandlerParam{DataId: param.DataId, Content: param.Content, EncryptedDataKey: param.EncryptedDataKey}
+	assert.Nil(t, handler.DecryptionHandler(decryptParam))
+	assert.Equal(t, "hello 加密", decryptParam.Content)
+
+	assert.Equal(t, PluginNotFoundError, handler.EncryptionHandler(&HandlerParam{DataId: "cipher-kms-aes-128-test", Content: "x"}))
+	assert.Equal(t, DataIdParamCheckError, handler.EncryptionHandler(&HandlerParam{DataId: "test", Content: "x"}))
+}
+
+func TestEncryptionPluginManager_GetPlugin(t *testing.T) {
+	manager := NewEncryptionPluginManager()
+	base := &KmsBasePlugin{}
+	aes128 := &KmsAes128Plugin{}
+	assert.Nil(t, manager.Join(base))
+	assert.Nil(t, manager.Join(aes128))
+	assert.NotNil(t, manager.Join(nil))
+
+	plugin, err := manager.GetPlugin("cipher-kms-aes-128-test")
+	assert.Nil(t, err)
+	assert.Equal(t, aes128, plugin)
+	plugin, err = manager.GetPlugin("cipher-test")
+	assert.Nil(t, err)
+	assert.Equal(t, base, plugin)
+
+	clone := manager.Clone()
+	manager.Remove(KmsAlgorithmName)
+	assert.Equal(t, 1, manager.Size())
+	assert.Equal(t, 2, clone.Size())
+}
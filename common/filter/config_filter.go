@@ -98,8 +98,10 @@ func (c *configFilterPriorityQueue) addFilter(filter IConfigFilter) error {
 	if pos == len(*c) {
 		*c = append((*c)[:], filter)
 	} else {
-		temp := append((*c)[:pos], filter)
-		*c = append(temp[:], (*c)[pos:]...)
+		temp := make([]IConfigFilter, 0, len(*c)+1)
+		temp = append(temp, (*c)[:pos]...)
+		temp = append(temp, filter)
+		*c = append(temp, (*c)[pos:]...)
 	}
 	return nil
 }
@@ -0,0 +1,72 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Flatten parse structured content into dotted keys, e.g. {"a":{"b":[1]}} becomes {"a.b[0]":"1"}.
+func Flatten(format, content string) (map[string]string, error) {
+	result := make(map[string]string)
+	var tree interface{}
+	switch normalize(format) {
+	case PROPERTIES:
+		return ParseProperties(content)
+	case YAML, JSON:
+		if err := yaml.Unmarshal([]byte(content), &tree); err != nil {
+			return nil, err
+		}
+	case TOML:
+		m, err := ParseToml(content)
+		if err != nil {
+			return nil, err
+		}
+		tree = m
+	default:
+		return nil, errors.Errorf("format %s can not be flattened", format)
+	}
+	flatten("", tree, result)
+	return result, nil
+}
+
+func flatten(prefix string, node interface{}, result map[string]string) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			flatten(joinPath(prefix, k), v, result)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range value {
+			flatten(joinPath(prefix, fmt.Sprint(k)), v, result)
+		}
+	case []interface{}:
+		for i, v := range value {
+			flatten(prefix+"["+strconv.Itoa(i)+"]", v, result)
+		}
+	case nil:
+		if prefix != "" {
+			result[prefix] = ""
+		}
+	default:
+		result[prefix] = fmt.Sprint(value)
+	}
+}
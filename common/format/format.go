@@ -0,0 +1,153 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	JSON       = "json"
+	YAML       = "yaml"
+	PROPERTIES = "properties"
+	TOML       = "toml"
+	TEXT       = "text"
+	XML        = "xml"
+	HTML       = "html"
+)
+
+// Decoder parse config content into v, v must be a pointer.
+type Decoder interface {
+	Decode(content []byte, v interface{}) error
+}
+
+// DecoderFunc is an adapter to use an ordinary function as a Decoder.
+type DecoderFunc func(content []byte, v interface{}) error
+
+func (f DecoderFunc) Decode(content []byte, v interface{}) error {
+	return f(content, v)
+}
+
+var (
+	decoderMux sync.RWMutex
+	decoders   = map[string]Decoder{
+		JSON:       DecoderFunc(json.Unmarshal),
+		YAML:       DecoderFunc(yaml.Unmarshal),
+		PROPERTIES: DecoderFunc(decodeProperties),
+		TOML:       DecoderFunc(decodeToml),
+	}
+	formatAlias = map[string]string{
+		"yml": YAML,
+	}
+)
+
+// RegisterDecoder register or replace the decoder of a format, e.g. "hcl".
+func RegisterDecoder(format string, decoder Decoder) {
+	decoderMux.Lock()
+	defer decoderMux.Unlock()
+	decoders[strings.ToLower(format)] = decoder
+}
+
+func GetDecoder(format string) (Decoder, bool) {
+	decoderMux.RLock()
+	defer decoderMux.RUnlock()
+	decoder, ok := decoders[normalize(format)]
+	return decoder, ok
+}
+
+// Decode parse content into v with the decoder of format.
+func Decode(format string, content []byte, v interface{}) error {
+	decoder, ok := GetDecoder(format)
+	if !ok {
+		return errors.Errorf("no decoder registered for format: %s", format)
+	}
+	return decoder.Decode(content, v)
+}
+
+// DetectFormat return the declared format if it has a decoder, otherwise the format of the dataId
+// extension, otherwise the format sniffed from content.
+func DetectFormat(declared, dataId, content string) string {
+	if _, ok := GetDecoder(declared); ok {
+		return normalize(declared)
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(dataId), "."); ext != "" {
+		if _, ok := GetDecoder(ext); ok {
+			return normalize(ext)
+		}
+	}
+	return sniff(content)
+}
+
+func normalize(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if alias, ok := formatAlias[format]; ok {
+		return alias
+	}
+	return format
+}
+
+func sniff(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return TEXT
+	}
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return JSON
+	}
+	var hasColon, hasEquals, hasTable bool
+	for _, line := range strings.Split(trimmed, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			hasTable = true
+			continue
+		}
+		colon, equals := strings.Index(line, ":"), strings.Index(line, "=")
+		switch {
+		case equals > 0 && (colon < 0 || equals < colon):
+			hasEquals = true
+		case colon > 0 || strings.HasPrefix(line, "- "):
+			hasColon = true
+		}
+	}
+	switch {
+	case hasTable:
+		return TOML
+	case hasEquals && !hasColon:
+		if looksLikeToml(trimmed) {
+			return TOML
+		}
+		return PROPERTIES
+	case hasColon:
+		return YAML
+	}
+	return TEXT
+}
+
+// looksLikeToml report whether every assignment value is a valid toml value.
+func looksLikeToml(content string) bool {
+	var v map[string]interface{}
+	return decodeToml([]byte(content), &v) == nil && !strings.Contains(content, "\\\n")
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testServer struct {
+	Host    string        `json:"host" yaml:"host"`
+	Port    int           `json:"port" yaml:"port"`
+	Debug   bool          `json:"debug" yaml:"debug"`
+	Tags    []string      `json:"tags" yaml:"tags"`
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+type testConfig struct {
+	Name   string     `json:"name" yaml:"name"`
+	Server testServer `json:"server" yaml:"server"`
+}
+
+func TestDecode(t *testing.T) {
+	cases := map[string]string{
+		JSON:       `{"name":"app","server":{"host":"127.0.0.1","port":8848,"debug":true,"tags":["a","b"]}}`,
+		YAML:       "name: app\nserver:\n  host: 127.0.0.1\n  port: 8848\n  debug: true\n  tags: [a, b]\n",
+		PROPERTIES: "# comment\nname=app\nserver.host=127.0.0.1\nserver.port = 8848\nserver.debug:true\nserver.tags=a,b\n",
+		TOML:       "name = \"app\" # comment\n[server]\nhost = \"127.0.0.1\"\nport = 8_848\ndebug = true\ntags = [\n  \"a\",\n  \"b\",\n]\n",
+	}
+	for f, content := range cases {
+		t.Run(f, func(t *testing.T) {
+			var config testConfig
+			assert.Nil(t, Decode(f, []byte(content), &config))
+			assert.Equal(t, "app", config.Name)
+			assert.Equal(t, "127.0.0.1", config.Server.Host)
+			assert.Equal(t, 8848, config.Server.Port)
+			assert.True(t, config.Server.Debug)
+			assert.Equal(t, []string{"a", "b"}, config.Server.Tags)
+		})
+	}
+}
+
+func TestDecodeProperties(t *testing.T) {
+	var config testConfig
+	content := "server.timeout=3s\nname=ap\\\n  p\nserver.host=\\u0031.0.0.1"
+	assert.Nil(t, Decode(PROPERTIES, []byte(content), &config))
+	assert.Equal(t, 3*time.Second, config.Server.Timeout)
+	assert.Equal(t, "app", config.Name)
+	assert.Equal(t, "1.0.0.1", config.Server.Host)
+
+	var flat map[string]string
+	assert.Nil(t, Decode(PROPERTIES, []byte("a.b=1\na=2"), &flat))
+	assert.Equal(t, map[string]string{"a.b": "1", "a": "2"}, flat)
+
+	assert.NotNil(t, Decode(PROPERTIES, []byte("server.port=abc"), &config))
+}
+
+func TestParseToml(t *testing.T) {
+	m, err := ParseToml("a.b = 'x'\n[[items]]\nid = 1\n[[items]]\nid = 2\ninline = { k = \"v\", n = 1.5 }\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "x", m["a"].(map[string]interface{})["b"])
+	items := m["items"].([]interface{})
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, int64(2), items[1].(map[string]interface{})["id"])
+	assert.Equal(t, 1.5, items[1].(map[string]interface{})["inline"].(map[string]interface{})["n"])
+
+	m, err = ParseToml("\"a=b\" = 1\n'c=d'.e = 2\nf = { \"g=h\" = 3 }")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), m["a=b"])
+	assert.Equal(t, int64(2), m["c=d"].(map[string]interface{})["e"])
+	assert.Equal(t, int64(3), m["f"].(map[string]interface{})["g=h"])
+
+	_, err = ParseToml("a = unquoted")
+	assert.NotNil(t, err)
+
+	// the features out of the supported subset are rejected rather than misread
+	for _, content := range []string{"a = 1\na = 2", "a = { b = 1, b = 2 }", "a = \"\"\"x\"\"\"", "a = inf", "a = 1\n[a]"} {
+		_, err = ParseToml(content)
+		assert.NotNil(t, err, content)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, YAML, DetectFormat("yml", "app", ""))
+	assert.Equal(t, JSON, DetectFormat("", "app.json", ""))
+	assert.Equal(t, JSON, DetectFormat("", "app", `{"a":1}`))
+	assert.Equal(t, YAML, DetectFormat("", "app", "a:\n  b: 1"))
+	assert.Equal(t, PROPERTIES, DetectFormat("", "app", "a.b=hello"))
+	assert.Equal(t, TOML, DetectFormat("text", "app", "[a]\nb = 1"))
+	assert.Equal(t, TEXT, DetectFormat("", "app", "hello"))
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder("upper", DecoderFunc(func(content []byte, v interface{}) error {
+		*(v.(*string)) = strings.ToUpper(string(content))
+		return nil
+	}))
+	var s string
+	assert.Nil(t, Decode("UPPER", []byte("abc"), &s))
+	assert.Equal(t, "ABC", s)
+	assert.NotNil(t, Decode("hcl", []byte("abc"), &s))
+}
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(JSON, `{"a":1}`))
+	assert.Nil(t, Validate("yml", "a: 1\n---\nb: 2"))
+	assert.Nil(t, Validate(XML, "<a><b/></a>"))
+	assert.Nil(t, Validate(PROPERTIES, "a"))
+	assert.Nil(t, Validate("", "{"))
+
+	err := Validate(JSON, "{\n \"a\": 1,\n \"b\" 2}")
+	assert.Equal(t, &SyntaxError{Format: JSON, Line: 3, Column: 7, Msg: "invalid character '2' after object key"}, err)
+	err = Validate(YAML, "a: 1\nb: c: d")
+	syntaxErr, ok := err.(*SyntaxError)
+	assert.True(t, ok)
+	assert.Equal(t, 2, syntaxErr.Line)
+	err = Validate(XML, "<a>\n<b></a>")
+	assert.Equal(t, "invalid xml content at line 2, column 8: element <b> closed by </a>", err.Error())
+	err = Validate(TOML, "a = 1\nb")
+	assert.Equal(t, &SyntaxError{Format: TOML, Line: 2, Msg: "expected key = value"}, err)
+}
+
+func TestInferType(t *testing.T) {
+	assert.Equal(t, YAML, InferType("app.yml", ""))
+	assert.Equal(t, XML, InferType("app", "<a><b/></a>"))
+	assert.Equal(t, HTML, InferType("app", "<!DOCTYPE html><html></html>"))
+	assert.Equal(t, PROPERTIES, InferType("app", "a.b=hello"))
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"bufio"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ParseProperties parse java properties content into a flat key/value map.
+func ParseProperties(content string) (map[string]string, error) {
+	result := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	var logical strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical.Len() == 0 && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		if continued := strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\"); continued {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+		key, value := splitProperty(logical.String())
+		result[key] = value
+		logical.Reset()
+	}
+	if logical.Len() > 0 {
+		key, value := splitProperty(logical.String())
+		result[key] = value
+	}
+	return result, scanner.Err()
+}
+
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t':
+			key := unescapeProperty(strings.TrimSpace(line[:i]))
+			value := strings.TrimLeft(line[i+1:], " \t")
+			if line[i] == ' ' || line[i] == '\t' {
+				if len(value) > 0 && (value[0] == '=' || value[0] == ':') {
+					value = strings.TrimLeft(value[1:], " \t")
+				}
+			}
+			return key, unescapeProperty(value)
+		}
+	}
+	return unescapeProperty(line), ""
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// nestProperties turn dotted keys into nested maps, so "a.b=1" becomes {"a":{"b":"1"}}.
+// A key which is both a value and a parent, like "a=1" and "a.b=2", keeps the value under "".
+func nestProperties(flat map[string]string) map[string]interface{} {
+	root := make(map[string]interface{})
+	for key, value := range flat {
+		parts := strings.Split(key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				if existing, isValue := node[part].(string); isValue {
+					child[""] = existing
+				}
+				node[part] = child
+			}
+			node = child
+		}
+		last := parts[len(parts)-1]
+		if child, ok := node[last].(map[string]interface{}); ok {
+			child[""] = value
+			continue
+		}
+		node[last] = value
+	}
+	return root
+}
+
+func decodeProperties(content []byte, v interface{}) error {
+	flat, err := ParseProperties(string(content))
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("properties decode target must be a non-nil pointer")
+	}
+	if m, ok := v.(*map[string]string); ok {
+		*m = flat
+		return nil
+	}
+	return weakDecode(nestProperties(flat), rv.Elem(), "")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// weakDecode assign src, which is built of strings and nested maps, to dst converting strings
+// to the kind of dst.
+func weakDecode(src interface{}, dst reflect.Value, path string) error {
+	if src == nil {
+		return nil
+	}
+	if m, ok := src.(map[string]interface{}); ok && dst.Kind() != reflect.Struct && dst.Kind() != reflect.Map &&
+		dst.Kind() != reflect.Ptr && dst.Kind() != reflect.Interface {
+		if value, ok := m[""]; ok {
+			src = value
+		}
+	}
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return weakDecode(src, dst.Elem(), path)
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	case reflect.Struct:
+		m, ok := src.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("properties key %s can not be decoded into struct %s", path, dst.Type())
+		}
+		return weakDecodeStruct(m, dst, path)
+	case reflect.Map:
+		m, ok := src.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return errors.Errorf("properties key %s can not be decoded into %s", path, dst.Type())
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for k, value := range m {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := weakDecode(value, elem, joinPath(path, k)); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+	}
+
+	s, ok := src.(string)
+	if !ok {
+		return errors.Errorf("properties key %s can not be decoded into %s", path, dst.Type())
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return errors.Wrapf(err, "properties key %s", path)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if dst.Type() == durationType {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return errors.Wrapf(err, "properties key %s", path)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "properties key %s", path)
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(s), 10, dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "properties key %s", path)
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "properties key %s", path)
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		slice := reflect.MakeSlice(dst.Type(), 0, len(items))
+		for i, item := range items {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := weakDecode(strings.TrimSpace(item), elem, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		dst.Set(slice)
+	default:
+		return errors.Errorf("properties key %s can not be decoded into %s", path, dst.Type())
+	}
+	return nil
+}
+
+func weakDecodeStruct(m map[string]interface{}, dst reflect.Value, path string) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := weakDecodeStruct(m, dst.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		value, ok := m[name]
+		if !ok {
+			for k, v := range m {
+				if strings.EqualFold(k, name) {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := weakDecode(value, dst.Field(i), joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"properties", "json", "yaml"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
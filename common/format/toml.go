@@ -0,0 +1,399 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseToml parse the commonly used subset of toml: tables, arrays of tables, dotted keys, basic and literal
+// strings, numbers, booleans, arrays and inline tables. Date-times are kept as strings. The multi-line strings,
+// inf and nan are rejected, and a key defined twice is an error, but a table header repeated is merged rather
+// than rejected. Register a decoder of a full toml library with RegisterDecoder for the other features.
+func ParseToml(content string) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	lines := strings.Split(content, "\n")
+	for lineNo := 0; lineNo < len(lines); lineNo++ {
+		line := strings.TrimSpace(stripTomlComment(lines[lineNo]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") && strings.HasSuffix(line, "]]") {
+			keys, err := parseTomlKey(line[2 : len(line)-2])
+			if err != nil {
+				return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+			}
+			parent, err := tomlTable(root, keys[:len(keys)-1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+			}
+			last := keys[len(keys)-1]
+			array, _ := parent[last].([]interface{})
+			current = make(map[string]interface{})
+			parent[last] = append(array, current)
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			keys, err := parseTomlKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+			}
+			if current, err = tomlTable(root, keys); err != nil {
+				return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+			}
+			continue
+		}
+		eq := tomlKeyEnd(line)
+		if eq <= 0 {
+			return nil, errors.Errorf("toml line %d: expected key = value", lineNo+1)
+		}
+		keys, err := parseTomlKey(line[:eq])
+		if err != nil {
+			return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+		}
+		raw := strings.TrimSpace(line[eq+1:])
+		// arrays may span several lines
+		for strings.HasPrefix(raw, "[") && !tomlBalanced(raw) && lineNo+1 < len(lines) {
+			lineNo++
+			raw += " " + strings.TrimSpace(stripTomlComment(lines[lineNo]))
+		}
+		parser := &tomlValueParser{s: raw}
+		value, err := parser.parseValue()
+		if err != nil {
+			return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+		}
+		if parser.skipSpace(); parser.pos != len(parser.s) {
+			return nil, errors.Errorf("toml line %d: unexpected %q", lineNo+1, parser.s[parser.pos:])
+		}
+		table, err := tomlTable(current, keys[:len(keys)-1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+		}
+		if err = setTomlValue(table, keys[len(keys)-1], value); err != nil {
+			return nil, errors.Wrapf(err, "toml line %d", lineNo+1)
+		}
+	}
+	return root, nil
+}
+
+// decodeToml decode into v through json, so fields are matched by their json tag or name.
+func decodeToml(content []byte, v interface{}) error {
+	m, err := ParseToml(string(content))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func tomlTable(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	table := root
+	for _, key := range keys {
+		switch child := table[key].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[key] = next
+			table = next
+		case map[string]interface{}:
+			table = child
+		case []interface{}:
+			if len(child) == 0 {
+				return nil, errors.Errorf("key %s is not a table", key)
+			}
+			last, ok := child[len(child)-1].(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("key %s is not a table", key)
+			}
+			table = last
+		default:
+			return nil, errors.Errorf("key %s is already defined as a value", key)
+		}
+	}
+	return table, nil
+}
+
+func setTomlValue(table map[string]interface{}, key string, value interface{}) error {
+	if _, ok := table[key]; ok {
+		return errors.Errorf("key %s is already defined", key)
+	}
+	table[key] = value
+	return nil
+}
+
+func parseTomlKey(s string) ([]string, error) {
+	var keys []string
+	parser := &tomlValueParser{s: strings.TrimSpace(s)}
+	for {
+		parser.skipSpace()
+		if parser.pos >= len(parser.s) {
+			return nil, errors.New("empty toml key")
+		}
+		var key string
+		switch parser.s[parser.pos] {
+		case '"', '\'':
+			value, err := parser.parseString()
+			if err != nil {
+				return nil, err
+			}
+			key = value
+		default:
+			start := parser.pos
+			for parser.pos < len(parser.s) && isTomlBareKeyChar(parser.s[parser.pos]) {
+				parser.pos++
+			}
+			key = parser.s[start:parser.pos]
+			if key == "" {
+				return nil, errors.Errorf("invalid toml key %q", s)
+			}
+		}
+		keys = append(keys, key)
+		parser.skipSpace()
+		if parser.pos == len(parser.s) {
+			return keys, nil
+		}
+		if parser.s[parser.pos] != '.' {
+			return nil, errors.Errorf("invalid toml key %q", s)
+		}
+		parser.pos++
+	}
+}
+
+func isTomlBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// tomlKeyEnd return the index of the first '=' out of the quoted key segments, or -1.
+func tomlKeyEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+func stripTomlComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func tomlBalanced(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+type tomlValueParser struct {
+	s   string
+	pos int
+}
+
+func (p *tomlValueParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *tomlValueParser) parseValue() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, errors.New("missing toml value")
+	}
+	switch c := p.s[p.pos]; c {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(",]} \t", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	// a date-time may contain a space between date and time
+	if p.pos+1 < len(p.s) && p.s[p.pos] == ' ' && isDate(p.s[start:p.pos]) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+		p.pos++
+		for p.pos < len(p.s) && !strings.ContainsRune(",]} \t", rune(p.s[p.pos])) {
+			p.pos++
+		}
+	}
+	return parseTomlScalar(p.s[start:p.pos])
+}
+
+func isDate(s string) bool {
+	return len(s) == 10 && s[4] == '-' && s[7] == '-'
+}
+
+func parseTomlScalar(raw string) (interface{}, error) {
+	switch raw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return nil, errors.Errorf("unsupported toml value %s", raw)
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if i, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		return f, nil
+	}
+	if len(raw) >= 8 && raw[0] >= '0' && raw[0] <= '9' && (strings.Contains(raw, "-") || strings.Contains(raw, ":")) {
+		return raw, nil
+	}
+	return nil, errors.Errorf("invalid toml value %q", raw)
+}
+
+func (p *tomlValueParser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	if strings.HasPrefix(p.s[p.pos:], strings.Repeat(string(quote), 3)) {
+		return "", errors.New("multi-line toml strings are not supported")
+	}
+	end := p.pos + 1
+	for end < len(p.s) && p.s[end] != quote {
+		if quote == '"' && p.s[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(p.s) {
+		return "", errors.New("unterminated toml string")
+	}
+	raw := p.s[p.pos : end+1]
+	p.pos = end + 1
+	if quote == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	return strconv.Unquote(raw)
+}
+
+func (p *tomlValueParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	array := make([]interface{}, 0)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, errors.New("unterminated toml array")
+		}
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return array, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlValueParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, errors.New("unterminated toml inline table")
+		}
+		if p.s[p.pos] == '}' {
+			p.pos++
+			return table, nil
+		}
+		eq := tomlKeyEnd(p.s[p.pos:])
+		if eq < 0 {
+			return nil, errors.New("expected key = value in toml inline table")
+		}
+		keys, err := parseTomlKey(p.s[p.pos : p.pos+eq])
+		if err != nil {
+			return nil, err
+		}
+		p.pos += eq + 1
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		target, err := tomlTable(table, keys[:len(keys)-1])
+		if err != nil {
+			return nil, err
+		}
+		if err = setTomlValue(target, keys[len(keys)-1], value); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
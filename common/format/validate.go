@@ -0,0 +1,166 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SyntaxError describe where the content fails to parse as its format, Column is 0 when it's unknown.
+type SyntaxError struct {
+	Format string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("invalid %s content at line %d, column %d: %s", e.Format, e.Line, e.Column, e.Msg)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid %s content at line %d: %s", e.Format, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("invalid %s content: %s", e.Format, e.Msg)
+}
+
+var lineNumberPattern = regexp.MustCompile(`(?:toml )?line (\d+):?\s*`)
+
+// Validate check the content parses as the format, it returns a *SyntaxError when it doesn't. The formats
+// without a syntax to check, such as text and properties, are always valid.
+func Validate(format, content string) error {
+	switch normalize(format) {
+	case JSON:
+		return validateJson(content)
+	case YAML:
+		return validateYaml(content)
+	case XML:
+		return validateXml(content)
+	case TOML:
+		if _, err := ParseToml(content); err != nil {
+			return newSyntaxError(TOML, err.Error())
+		}
+	}
+	return nil
+}
+
+// InferType return the nacos config type of the content, from the dataId extension first and then the content.
+func InferType(dataId, content string) string {
+	ext := normalize(strings.TrimPrefix(filepath.Ext(dataId), "."))
+	switch ext {
+	case JSON, YAML, PROPERTIES, TOML, XML, HTML, TEXT:
+		return ext
+	}
+	if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "<") {
+		if strings.HasPrefix(strings.ToLower(trimmed), "<!doctype html") || strings.HasPrefix(strings.ToLower(trimmed), "<html") {
+			return HTML
+		}
+		if validateXml(trimmed) == nil {
+			return XML
+		}
+	}
+	return sniff(content)
+}
+
+func validateJson(content string) error {
+	var v interface{}
+	err := json.Unmarshal([]byte(content), &v)
+	if err == nil {
+		return nil
+	}
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		line, column := position(content, syntaxErr.Offset)
+		return &SyntaxError{Format: JSON, Line: line, Column: column, Msg: syntaxErr.Error()}
+	}
+	return &SyntaxError{Format: JSON, Msg: err.Error()}
+}
+
+func validateYaml(content string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var v interface{}
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newSyntaxError(YAML, strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+	}
+}
+
+func validateXml(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	hasRoot := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, column := position(content, decoder.InputOffset())
+			msg := err.Error()
+			if syntaxErr, ok := err.(*xml.SyntaxError); ok {
+				msg = syntaxErr.Msg
+			}
+			return &SyntaxError{Format: XML, Line: line, Column: column, Msg: msg}
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			hasRoot = true
+		}
+	}
+	if !hasRoot {
+		return &SyntaxError{Format: XML, Msg: "no root element"}
+	}
+	return nil
+}
+
+// newSyntaxError take the line number out of the message of the parser.
+func newSyntaxError(format, msg string) *SyntaxError {
+	syntaxErr := &SyntaxError{Format: format, Msg: msg}
+	if match := lineNumberPattern.FindStringSubmatchIndex(msg); match != nil {
+		syntaxErr.Line, _ = strconv.Atoi(msg[match[2]:match[3]])
+		syntaxErr.Msg = strings.TrimSpace(msg[:match[0]] + msg[match[1]:])
+	}
+	return syntaxErr
+}
+
+// position convert the byte offset of content to the 1-based line and column.
+func position(content string, offset int64) (int, int) {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	line, column := 1, 1
+	for _, c := range content[:offset] {
+		if c == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return line, column
+}
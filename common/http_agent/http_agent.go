@@ -17,10 +17,17 @@
 package http_agent
 
 import (
+	"context"
+	crypto_tls "crypto/tls"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/proxy"
 	"github.com/jun3372/nacos-sdk-go/common/tls"
 
 	"github.com/jun3372/nacos-sdk-go/common/logger"
@@ -28,8 +35,24 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	defaultMaxIdleConnsPerHost = 16
+	// defaultDialTimeout caps the dials of the connections, they are bounded by the timeout of the request too,
+	// since the request is canceled with its dial when the timeout of the client runs out.
+	defaultDialTimeout = 30 * time.Second
+	defaultKeepAlive   = 30 * time.Second
+)
+
 type HttpAgent struct {
-	TlsConfig constant.TLSConfig
+	TlsConfig           constant.TLSConfig
+	ProxyURL            string        // the proxy of the requests, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are honored if it's empty
+	MaxIdleConnsPerHost int           // the idle connections kept for each server, default is 16
+	TLSHandshakeTimeout time.Duration // the timeout of the tls handshakes, default is 10s
+	KeepAlive           time.Duration // the interval of the tcp keep-alive probes, default is 30s, negative disables them
+	IdleConnTimeout     time.Duration // close the idle connections after it, default is 90s
+	tlsMutex            sync.Mutex
+	tlsTransport        *http.Transport
+	transport           *http.Transport
 }
 
 func (agent *HttpAgent) Get(path string, header http.Header, timeoutMs uint64,
@@ -98,6 +121,16 @@ func (agent *HttpAgent) Request(method string, path string, header http.Header,
 	}
 	return
 }
+
+// RequestWithBody send the body as is, the query string should be already in path.
+func (agent *HttpAgent) RequestWithBody(method string, path string, header http.Header, timeoutMs uint64, body []byte) (response *http.Response, err error) {
+	client, err := agent.createClient()
+	if err != nil {
+		return nil, err
+	}
+	return requestWithBody(client, method, path, header, timeoutMs, body)
+}
+
 func (agent *HttpAgent) Post(path string, header http.Header, timeoutMs uint64,
 	params map[string]string) (response *http.Response, err error) {
 	client, err := agent.createClient()
@@ -123,14 +156,119 @@ func (agent *HttpAgent) Put(path string, header http.Header, timeoutMs uint64,
 	return put(client, path, header, timeoutMs, params)
 }
 
+// createClient return a client of the shared transport, so the connections are pooled across the requests.
 func (agent *HttpAgent) createClient() (*http.Client, error) {
 	if !agent.TlsConfig.Enable {
-		return &http.Client{}, nil
+		transport, err := agent.getTransport()
+		if err != nil {
+			return nil, err
+		}
+		return &http.Client{Transport: transport}, nil
 	}
-	cfg, err := tls.NewTLS(agent.TlsConfig)
+	transport, err := agent.getTlsTransport()
 	if err != nil {
 		return nil, err
 	}
-	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}, nil
+	return &http.Client{Transport: transport}, nil
+}
 
+// getTlsTransport returns the shared tls transport, whose connections are dialed with the latest tls config
+// of the reloader, so the rotated certificates are used without recreating the agent
+func (agent *HttpAgent) getTlsTransport() (*http.Transport, error) {
+	agent.tlsMutex.Lock()
+	defer agent.tlsMutex.Unlock()
+	if agent.tlsTransport != nil {
+		return agent.tlsTransport, nil
+	}
+	reloader, err := tls.NewReloader(agent.TlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	transport := agent.newTransport()
+	transport.Proxy = agent.proxy
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		cfg := reloader.Config().Clone()
+		if len(cfg.ServerName) == 0 {
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		// the tls of the proxied requests is handled here too, so the reloaded config is used.
+		proxyURL, err := proxy.Resolve(agent.ProxyURL, "https", addr)
+		if err != nil {
+			return nil, err
+		}
+		if proxyURL == nil {
+			dialer := &crypto_tls.Dialer{NetDialer: agent.netDialer(), Config: cfg}
+			return dialer.DialContext(ctx, network, addr)
+		}
+		conn, err := proxy.Dial(ctx, proxyURL, addr, agent.netDialer().DialContext)
+		if err != nil {
+			return nil, err
+		}
+		// the transport doesn't apply the handshake timeout to DialTLSContext
+		handshakeCtx, cancel := context.WithTimeout(ctx, transport.TLSHandshakeTimeout)
+		defer cancel()
+		tlsConn := crypto_tls.Client(conn, cfg)
+		if err = tlsConn.HandshakeContext(handshakeCtx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	agent.tlsTransport = transport
+	return agent.tlsTransport, nil
+}
+
+// proxy return the proxy of the http requests, the https ones are tunneled by DialTLSContext of the tls transport.
+func (agent *HttpAgent) proxy(request *http.Request) (*url.URL, error) {
+	if request.URL.Scheme == "https" {
+		return nil, nil
+	}
+	return proxy.Resolve(agent.ProxyURL, request.URL.Scheme, request.URL.Host)
+}
+
+// getTransport returns the shared transport of the plain http requests.
+func (agent *HttpAgent) getTransport() (*http.Transport, error) {
+	agent.tlsMutex.Lock()
+	defer agent.tlsMutex.Unlock()
+	if agent.transport != nil {
+		return agent.transport, nil
+	}
+	transport := agent.newTransport()
+	if len(agent.ProxyURL) > 0 {
+		proxyURL, err := proxy.Parse(agent.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	agent.transport = transport
+	return agent.transport, nil
+}
+
+// newTransport clone the default transport with the pool and keep-alive settings of the agent.
+func (agent *HttpAgent) newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = agent.netDialer().DialContext
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if agent.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = agent.MaxIdleConnsPerHost
+	}
+	if transport.MaxIdleConns < transport.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = transport.MaxIdleConnsPerHost
+	}
+	if agent.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = agent.TLSHandshakeTimeout
+	}
+	if agent.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = agent.IdleConnTimeout
+	}
+	return transport
+}
+
+func (agent *HttpAgent) netDialer() *net.Dialer {
+	keepAlive := defaultKeepAlive
+	if agent.KeepAlive != 0 {
+		keepAlive = agent.KeepAlive
+	}
+	return &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: keepAlive}
 }
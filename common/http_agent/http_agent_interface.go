@@ -27,4 +27,5 @@ type IHttpAgent interface {
 	Put(path string, header http.Header, timeoutMs uint64, params map[string]string) (response *http.Response, err error)
 	RequestOnlyResult(method string, path string, header http.Header, timeoutMs uint64, params map[string]string) string
 	Request(method string, path string, header http.Header, timeoutMs uint64, params map[string]string) (response *http.Response, err error)
+	RequestWithBody(method string, path string, header http.Header, timeoutMs uint64, body []byte) (response *http.Response, err error)
 }
@@ -0,0 +1,79 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport(t *testing.T) {
+	agent := &HttpAgent{MaxIdleConnsPerHost: 200, TLSHandshakeTimeout: 3 * time.Second,
+		IdleConnTimeout: 20 * time.Second, KeepAlive: 10 * time.Second}
+	transport := agent.newTransport()
+	assert.Equal(t, 200, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 200, transport.MaxIdleConns)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 20*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 10*time.Second, agent.netDialer().KeepAlive)
+	assert.Equal(t, defaultDialTimeout, agent.netDialer().Timeout)
+
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	defaults := (&HttpAgent{}).newTransport()
+	assert.Equal(t, defaultMaxIdleConnsPerHost, defaults.MaxIdleConnsPerHost)
+	assert.Equal(t, defaultTransport.TLSHandshakeTimeout, defaults.TLSHandshakeTimeout)
+	assert.Equal(t, defaultTransport.IdleConnTimeout, defaults.IdleConnTimeout)
+	assert.Equal(t, defaultKeepAlive, (&HttpAgent{}).netDialer().KeepAlive)
+
+	// a negative keep-alive disables the tcp keep-alive probes of the dialer
+	assert.True(t, (&HttpAgent{KeepAlive: -1}).netDialer().KeepAlive < 0)
+}
+
+func TestSharedTransport(t *testing.T) {
+	agent := &HttpAgent{}
+	first, err := agent.createClient()
+	assert.Nil(t, err)
+	second, err := agent.createClient()
+	assert.Nil(t, err)
+	assert.Same(t, first.Transport, second.Transport)
+
+	tlsAgent := &HttpAgent{TlsConfig: constant.TLSConfig{Enable: true}}
+	first, err = tlsAgent.createClient()
+	assert.Nil(t, err)
+	second, err = tlsAgent.createClient()
+	assert.Nil(t, err)
+	assert.Same(t, first.Transport, second.Transport)
+
+	// the connection is reused by the requests
+	var remotes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remotes = append(remotes, r.RemoteAddr)
+	}))
+	defer server.Close()
+	for i := 0; i < 3; i++ {
+		response, err := agent.Get(server.URL, http.Header{}, 1000, nil)
+		assert.Nil(t, err)
+		_ = response.Body.Close()
+	}
+	assert.Equal(t, 3, len(remotes))
+	assert.Equal(t, remotes[0], remotes[2])
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
@@ -32,6 +33,10 @@ import (
 var (
 	logger  Logger
 	logLock sync.RWMutex
+	// level is the level of the default logger, which can be changed at runtime by SetLevel
+	level = zap.NewAtomicLevel()
+	// levelSet is 1 after SetLevel, then InitNacosLogger keeps the level instead of the one of the config
+	levelSet int32
 )
 
 var levelMap = map[string]zapcore.Level{
@@ -60,6 +65,12 @@ type NacosLogger struct {
 	Logger
 }
 
+// DebugEnabler can be implemented by the loggers passed to SetLogger, so the sdk skips building the debug logs
+// when they are not logged.
+type DebugEnabler interface {
+	IsDebugEnabled() bool
+}
+
 // Logger is the interface for Logger types
 type Logger interface {
 	Info(args ...interface{})
@@ -137,7 +148,9 @@ func InitLogger(config Config) (err error) {
 
 // InitNacosLogger is init nacos default logger
 func InitNacosLogger(config Config) (Logger, error) {
-	logLevel := getLogLevel(config.Level)
+	if atomic.LoadInt32(&levelSet) == 0 {
+		level.SetLevel(getLogLevel(config.Level))
+	}
 	encoder := getEncoder()
 	writer := zapcore.AddSync(io.Discard)
 	if !config.IsDevNull {
@@ -153,7 +166,7 @@ func InitNacosLogger(config Config) (Logger, error) {
 		encoderFn = zapcore.NewJSONEncoder
 	}
 
-	core := zapcore.NewCore(encoderFn(encoder), writer, logLevel)
+	core := zapcore.NewCore(encoderFn(encoder), writer, level)
 	zaplogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 	return &NacosLogger{zaplogger.Sugar()}, nil
 }
@@ -180,6 +193,37 @@ func getEncoder() zapcore.EncoderConfig {
 	}
 }
 
+// SetLevel change the level of the default logger at runtime, the level is one of debug, info, warn and error.
+// It's kept when the logger is initialized by the config later.
+func SetLevel(logLevel string) {
+	atomic.StoreInt32(&levelSet, 1)
+	level.SetLevel(getLogLevel(logLevel))
+}
+
+// IsDebugEnabled return true if the active logger logs at the debug level, the loggers set by SetLogger are
+// asked by DebugEnabler, or they are assumed to log at the debug level.
+func IsDebugEnabled() bool {
+	log := GetLogger()
+	if log == nil {
+		return false
+	}
+	if enabler, ok := log.(DebugEnabler); ok {
+		return enabler.IsDebugEnabled()
+	}
+	return true
+}
+
+// IsDebugEnabled return true if the zap logger is enabled at the debug level.
+func (l *NacosLogger) IsDebugEnabled() bool {
+	switch log := l.Logger.(type) {
+	case *zap.SugaredLogger:
+		return log.Desugar().Core().Enabled(zapcore.DebugLevel)
+	case DebugEnabler:
+		return log.IsDebugEnabled()
+	}
+	return true
+}
+
 // SetLogger sets logger for sdk
 func SetLogger(log Logger) {
 	logLock.Lock()
@@ -18,6 +18,7 @@ package logger
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,6 +101,42 @@ func TestRaceLogger(t *testing.T) {
 	reset()
 }
 
+func TestSetLevel(t *testing.T) {
+	defer func() {
+		atomic.StoreInt32(&levelSet, 0)
+		reset()
+	}()
+	log, err := InitNacosLogger(Config{Level: "info", IsDevNull: true})
+	assert.NoError(t, err)
+	SetLogger(log)
+	assert.False(t, IsDebugEnabled())
+	SetLevel("debug")
+	assert.True(t, IsDebugEnabled())
+
+	// the explicit level is kept by the loggers initialized later
+	log, err = InitNacosLogger(Config{Level: "info", IsDevNull: true})
+	assert.NoError(t, err)
+	SetLogger(log)
+	assert.True(t, IsDebugEnabled())
+	SetLevel("warn")
+	assert.False(t, IsDebugEnabled())
+
+	SetLogger(&mockLogger{})
+	assert.True(t, IsDebugEnabled())
+	SetLogger(&debugDisabledLogger{})
+	assert.False(t, IsDebugEnabled())
+	SetLogger(&NacosLogger{&debugDisabledLogger{}})
+	assert.False(t, IsDebugEnabled())
+}
+
+type debugDisabledLogger struct {
+	mockLogger
+}
+
+func (m debugDisabledLogger) IsDebugEnabled() bool {
+	return false
+}
+
 type mockLogger struct {
 }
 
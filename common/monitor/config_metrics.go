@@ -0,0 +1,70 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ConfigMetrics receives the metrics of the config client, the implementation must be safe for concurrent use.
+type ConfigMetrics interface {
+	// ObservePublish is called after a config is published, err is nil when it succeeds
+	ObservePublish(dataId, group string, cost time.Duration, err error)
+	// ObserveQuery is called after a config is queried from the server
+	ObserveQuery(dataId, group string, cost time.Duration, err error)
+	// ObserveNotify is called after the listeners of a changed config return, cost starts from the change is detected
+	ObserveNotify(dataId, group string, cost time.Duration)
+	// IncFailoverRead is called when the config is read from the failover directory instead of the server
+	IncFailoverRead(dataId, group string)
+	// IncCacheHit is called when the config is served from the snapshot, on listening or when the server is unavailable
+	IncCacheHit(dataId, group string)
+	// IncMd5Mismatch is called when the queried content doesn't match the md5 of the change notify
+	IncMd5Mismatch(dataId, group string)
+}
+
+type noopConfigMetrics struct{}
+
+func (noopConfigMetrics) ObservePublish(dataId, group string, cost time.Duration, err error) {}
+func (noopConfigMetrics) ObserveQuery(dataId, group string, cost time.Duration, err error)   {}
+func (noopConfigMetrics) ObserveNotify(dataId, group string, cost time.Duration)             {}
+func (noopConfigMetrics) IncFailoverRead(dataId, group string)                               {}
+func (noopConfigMetrics) IncCacheHit(dataId, group string)                                   {}
+func (noopConfigMetrics) IncMd5Mismatch(dataId, group string)                                {}
+
+type configMetricsHolder struct {
+	metrics ConfigMetrics
+}
+
+var configMetrics atomic.Value
+
+func init() {
+	configMetrics.Store(configMetricsHolder{metrics: noopConfigMetrics{}})
+}
+
+// SetConfigMetrics replace the metrics of all the config clients, nil restores the default no-op implementation.
+func SetConfigMetrics(metrics ConfigMetrics) {
+	if metrics == nil {
+		metrics = noopConfigMetrics{}
+	}
+	configMetrics.Store(configMetricsHolder{metrics: metrics})
+}
+
+// GetConfigMetrics return the metrics set by SetConfigMetrics.
+func GetConfigMetrics() ConfigMetrics {
+	return configMetrics.Load().(configMetricsHolder).metrics
+}
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -37,3 +38,43 @@ func TestHistorgam(t *testing.T) {
 		assert.NotNil(t, monitor)
 	})
 }
+
+type countConfigMetrics struct {
+	noopConfigMetrics
+	publish int
+}
+
+func (m *countConfigMetrics) ObservePublish(dataId, group string, cost time.Duration, err error) {
+	m.publish++
+}
+
+func TestConfigMetrics(t *testing.T) {
+	assert.Equal(t, noopConfigMetrics{}, GetConfigMetrics())
+	metrics := &countConfigMetrics{}
+	SetConfigMetrics(metrics)
+	GetConfigMetrics().ObservePublish("dataId", "group", time.Second, nil)
+	assert.Equal(t, 1, metrics.publish)
+
+	SetConfigMetrics(nil)
+	assert.Equal(t, noopConfigMetrics{}, GetConfigMetrics())
+}
+
+type lagNamingMetrics struct {
+	noopNamingMetrics
+	lags []time.Duration
+}
+
+func (m *lagNamingMetrics) ObservePushLag(serviceName, groupName string, lag time.Duration) {
+	m.lags = append(m.lags, lag)
+}
+
+func TestNamingMetrics(t *testing.T) {
+	assert.Equal(t, noopNamingMetrics{}, GetNamingMetrics())
+	metrics := &lagNamingMetrics{}
+	SetNamingMetrics(metrics)
+	GetNamingMetrics().ObservePushLag("service", "group", time.Second)
+	assert.Equal(t, []time.Duration{time.Second}, metrics.lags)
+
+	SetNamingMetrics(nil)
+	assert.Equal(t, noopNamingMetrics{}, GetNamingMetrics())
+}
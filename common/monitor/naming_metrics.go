@@ -0,0 +1,65 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// NamingMetrics receives the metrics of the naming client, the implementation must be safe for concurrent use.
+type NamingMetrics interface {
+	// ObservePushLag is called after a pushed service is applied to the cache, lag starts from the LastRefTime of the service
+	ObservePushLag(serviceName, groupName string, lag time.Duration)
+	// IncPush is called when a service is pushed by the server, empty is true when it has no instance
+	IncPush(serviceName, groupName string, empty bool)
+	// AddSubscriptions is called with 1 when a service is subscribed from the server and -1 when it's unsubscribed
+	AddSubscriptions(delta int)
+	// ObserveSelect is called after the instances of a service are selected, err is nil when it succeeds
+	ObserveSelect(serviceName, groupName string, cost time.Duration, err error)
+}
+
+type noopNamingMetrics struct{}
+
+func (noopNamingMetrics) ObservePushLag(serviceName, groupName string, lag time.Duration) {}
+func (noopNamingMetrics) IncPush(serviceName, groupName string, empty bool)               {}
+func (noopNamingMetrics) AddSubscriptions(delta int)                                      {}
+func (noopNamingMetrics) ObserveSelect(serviceName, groupName string, cost time.Duration, err error) {
+}
+
+type namingMetricsHolder struct {
+	metrics NamingMetrics
+}
+
+var namingMetrics atomic.Value
+
+func init() {
+	namingMetrics.Store(namingMetricsHolder{metrics: noopNamingMetrics{}})
+}
+
+// SetNamingMetrics replace the metrics of all the naming clients, nil restores the default no-op implementation.
+func SetNamingMetrics(metrics NamingMetrics) {
+	if metrics == nil {
+		metrics = noopNamingMetrics{}
+	}
+	namingMetrics.Store(namingMetricsHolder{metrics: metrics})
+}
+
+// GetNamingMetrics return the metrics set by SetNamingMetrics.
+func GetNamingMetrics() NamingMetrics {
+	return namingMetrics.Load().(namingMetricsHolder).metrics
+}
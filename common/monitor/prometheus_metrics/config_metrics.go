@@ -0,0 +1,106 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus_metrics
+
+import (
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConfigMetrics is the prometheus adapter of monitor.ConfigMetrics, the dataId is not used as a label
+// to keep the cardinality low.
+type ConfigMetrics struct {
+	publishLatency *prometheus.HistogramVec
+	queryLatency   *prometheus.HistogramVec
+	notifyLatency  *prometheus.HistogramVec
+	counter        *prometheus.CounterVec
+}
+
+// NewConfigMetrics create the collectors and register them to registerer, use prometheus.DefaultRegisterer
+// when registerer is nil.
+func NewConfigMetrics(registerer prometheus.Registerer) (*ConfigMetrics, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	m := &ConfigMetrics{
+		publishLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "nacos_config_publish_seconds",
+			Help: "the latency of publishing config",
+		}, []string{"group", "result"}),
+		queryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "nacos_config_query_seconds",
+			Help: "the latency of querying config from server",
+		}, []string{"group", "result"}),
+		notifyLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "nacos_config_notify_seconds",
+			Help: "the latency from a config change is detected to the listeners return",
+		}, []string{"group"}),
+		counter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nacos_config_events_total",
+			Help: "the count of config failover reads, cache hits and md5 mismatches",
+		}, []string{"group", "event"}),
+	}
+	for _, collector := range []prometheus.Collector{m.publishLatency, m.queryLatency, m.notifyLatency, m.counter} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Install create the metrics with the default registerer and set it to all the config clients.
+func Install() error {
+	m, err := NewConfigMetrics(nil)
+	if err != nil {
+		return err
+	}
+	monitor.SetConfigMetrics(m)
+	return nil
+}
+
+func result(err error) string {
+	if err != nil {
+		return "fail"
+	}
+	return "success"
+}
+
+func (m *ConfigMetrics) ObservePublish(dataId, group string, cost time.Duration, err error) {
+	m.publishLatency.WithLabelValues(group, result(err)).Observe(cost.Seconds())
+}
+
+func (m *ConfigMetrics) ObserveQuery(dataId, group string, cost time.Duration, err error) {
+	m.queryLatency.WithLabelValues(group, result(err)).Observe(cost.Seconds())
+}
+
+func (m *ConfigMetrics) ObserveNotify(dataId, group string, cost time.Duration) {
+	m.notifyLatency.WithLabelValues(group).Observe(cost.Seconds())
+}
+
+func (m *ConfigMetrics) IncFailoverRead(dataId, group string) {
+	m.counter.WithLabelValues(group, "failover_read").Inc()
+}
+
+func (m *ConfigMetrics) IncCacheHit(dataId, group string) {
+	m.counter.WithLabelValues(group, "cache_hit").Inc()
+}
+
+func (m *ConfigMetrics) IncMd5Mismatch(dataId, group string) {
+	m.counter.WithLabelValues(group, "md5_mismatch").Inc()
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus_metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/monitor"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NamingMetrics is the prometheus adapter of monitor.NamingMetrics, the service name is not used as a label
+// to keep the cardinality low.
+type NamingMetrics struct {
+	pushLag       *prometheus.HistogramVec
+	pushes        *prometheus.CounterVec
+	subscriptions prometheus.Gauge
+	selectLatency *prometheus.HistogramVec
+}
+
+// NewNamingMetrics create the collectors and register them to registerer, use prometheus.DefaultRegisterer
+// when registerer is nil.
+func NewNamingMetrics(registerer prometheus.Registerer) (*NamingMetrics, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	m := &NamingMetrics{
+		pushLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "nacos_naming_push_lag_seconds",
+			Help: "the lag from the server refreshes a service to the pushed service is applied to the cache",
+		}, []string{"group"}),
+		pushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "nacos_naming_pushes_total",
+			Help: "the count of the services pushed by the server, empty is true for the pushes without instance",
+		}, []string{"group", "empty"}),
+		subscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "nacos_naming_subscriptions",
+			Help: "the number of the services subscribed from the server",
+		}),
+		selectLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "nacos_naming_select_seconds",
+			Help: "the latency of selecting the instances of a service",
+			// the selections are mostly served from the cache
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"group", "result"}),
+	}
+	for _, collector := range []prometheus.Collector{m.pushLag, m.pushes, m.subscriptions, m.selectLatency} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// InstallNaming create the metrics with the default registerer and set it to all the naming clients.
+func InstallNaming() error {
+	m, err := NewNamingMetrics(nil)
+	if err != nil {
+		return err
+	}
+	monitor.SetNamingMetrics(m)
+	return nil
+}
+
+func (m *NamingMetrics) ObservePushLag(serviceName, groupName string, lag time.Duration) {
+	m.pushLag.WithLabelValues(groupName).Observe(lag.Seconds())
+}
+
+func (m *NamingMetrics) IncPush(serviceName, groupName string, empty bool) {
+	m.pushes.WithLabelValues(groupName, strconv.FormatBool(empty)).Inc()
+}
+
+func (m *NamingMetrics) AddSubscriptions(delta int) {
+	m.subscriptions.Add(float64(delta))
+}
+
+func (m *NamingMetrics) ObserveSelect(serviceName, groupName string, cost time.Duration, err error) {
+	m.selectLatency.WithLabelValues(groupName, result(err)).Observe(cost.Seconds())
+}
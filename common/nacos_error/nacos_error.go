@@ -18,6 +18,7 @@ package nacos_error
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 )
@@ -52,3 +53,38 @@ func (err *NacosError) ErrorCode() string {
 		return err.errorCode
 	}
 }
+
+// TimeoutError is returned when a request runs out of its timeout, which is shared by all the attempts
+// and the servers tried.
+type TimeoutError struct {
+	Elapsed    time.Duration
+	Attempts   int
+	LastServer string
+	Err        error // the error of the last attempt
+}
+
+func NewTimeoutError(elapsed time.Duration, attempts int, lastServer string, err error) *TimeoutError {
+	return &TimeoutError{
+		Elapsed:    elapsed,
+		Attempts:   attempts,
+		LastServer: lastServer,
+		Err:        err,
+	}
+}
+
+func (err *TimeoutError) Error() string {
+	msg := fmt.Sprintf("request timeout after %v, attempts:%d, last server:%s", err.Elapsed, err.Attempts, err.LastServer)
+	if err.Err != nil {
+		return msg + ", caused by: " + err.Err.Error()
+	}
+	return msg
+}
+
+func (err *TimeoutError) Unwrap() error {
+	return err.Err
+}
+
+// Cause is for errors.Cause of github.com/pkg/errors.
+func (err *TimeoutError) Cause() error {
+	return err.Err
+}
@@ -38,35 +38,42 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/monitor"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/credentials"
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
 	"github.com/jun3372/nacos-sdk-go/common/security"
 	"github.com/jun3372/nacos-sdk-go/inner/uuid"
 	"github.com/jun3372/nacos-sdk-go/util"
 )
 
+// securityTokenHeader carries the sts token of the temporary credentials.
+const securityTokenHeader = "Spas-SecurityToken"
+
 type NacosServer struct {
 	sync.RWMutex
 	securityLogin         security.AuthClient
+	credentials           credentials.Provider
+	identityKey           string
+	identityValue         string
 	serverList            []constant.ServerConfig
 	httpAgent             http_agent.IHttpAgent
 	timeoutMs             uint64
-	endpoint              string
-	lastSrvRefTime        int64
-	vipSrvRefInterMills   int64
 	contextPath           string
-	endpointContextPath   string
-	endpointQueryParams   string
-	endpointQueryHeader   map[string][]string
-	clusterName           string
+	provider              constant.ServerListProvider
+	refreshInterval       time.Duration
+	preferredZone         string
+	failbackWindow        time.Duration
+	serverFailures        sync.Map
+	retryPolicy           retry.Policy
 	currentIndex          int32
 	ServerSrcChangeSignal chan struct{}
 }
 
 func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, clientCfg constant.ClientConfig, httpAgent http_agent.IHttpAgent, timeoutMs uint64, endpoint string, endpointQueryHeader map[string][]string) (*NacosServer, error) {
 	severLen := len(serverList)
-	if severLen == 0 && endpoint == "" {
+	if severLen == 0 && endpoint == "" && clientCfg.ServerListProvider == nil {
 		return &NacosServer{}, errors.New("both serverlist  and  endpoint are empty")
 	}
 
@@ -75,22 +82,33 @@ func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, cli
 	ns := NacosServer{
 		serverList:            serverList,
 		securityLogin:         securityLogin,
+		credentials:           newCredentialsProvider(clientCfg),
+		identityKey:           clientCfg.IdentityKey,
+		identityValue:         clientCfg.IdentityValue,
 		httpAgent:             httpAgent,
 		timeoutMs:             timeoutMs,
-		endpoint:              endpoint,
-		vipSrvRefInterMills:   10000,
-		endpointContextPath:   clientCfg.EndpointContextPath,
-		endpointQueryParams:   clientCfg.EndpointQueryParams,
-		endpointQueryHeader:   endpointQueryHeader,
-		clusterName:           clientCfg.ClusterName,
 		contextPath:           clientCfg.ContextPath,
+		refreshInterval:       10 * time.Second,
+		preferredZone:         clientCfg.PreferredZone,
+		failbackWindow:        defaultFailbackWindow,
+		retryPolicy:           retry.NewPolicy(clientCfg),
 		ServerSrcChangeSignal: make(chan struct{}, 1),
 	}
+	if clientCfg.ServerListRefreshMs > 0 {
+		ns.refreshInterval = time.Duration(clientCfg.ServerListRefreshMs) * time.Millisecond
+	}
+	if clientCfg.ServerFailbackMs > 0 {
+		ns.failbackWindow = time.Duration(clientCfg.ServerFailbackMs) * time.Millisecond
+	}
+	if clientCfg.ServerListProvider != nil {
+		ns.provider = clientCfg.ServerListProvider
+	} else if severLen == 0 {
+		ns.provider = newEndpointServerList(endpoint, clientCfg, httpAgent, timeoutMs, endpointQueryHeader)
+	}
 	if severLen > 0 {
 		ns.currentIndex = rand.Int31n(int32(severLen))
-	} else {
-		ns.initRefreshSrvIfNeed(ctx)
 	}
+	ns.initRefreshSrvIfNeed(ctx)
 
 	_, err := securityLogin.Login()
 
@@ -104,18 +122,40 @@ func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, cli
 
 func (server *NacosServer) callConfigServer(api string, params map[string]string, newHeaders map[string]string,
 	method string, curServer string, contextPath string, timeoutMS uint64) (result string, err error) {
+	result, _, err = server.callConfigServerWithBody(api, params, newHeaders, method, curServer, contextPath, timeoutMS, nil, "")
+	return result, err
+}
+
+// callConfigServerWithBody send params as the form when body is nil, otherwise send params as the
+// query string and body with the contentType. The request is replayed once after a re-login if the token
+// is rejected, within the timeout left by the first attempt and the re-login.
+func (server *NacosServer) callConfigServerWithBody(api string, params map[string]string, newHeaders map[string]string,
+	method string, curServer string, contextPath string, timeoutMS uint64, body []byte, contentType string) (string, http.Header, error) {
+	_, deadline := requestDeadline(timeoutMS)
+	token := server.AccessToken()
+	result, header, code, err := server.requestConfigServer(api, params, newHeaders, method, curServer, contextPath, timeoutMS, body, contentType)
+	if err != nil && server.ReAuth(token, code, result) {
+		if remaining := remainingMs(deadline); remaining > 0 {
+			result, header, _, err = server.requestConfigServer(api, params, newHeaders, method, curServer, contextPath, remaining, body, contentType)
+		}
+	}
+	return result, header, err
+}
+
+func (server *NacosServer) requestConfigServer(api string, params map[string]string, newHeaders map[string]string, method string,
+	curServer string, contextPath string, timeoutMS uint64, body []byte, contentType string) (result string, header http.Header, code int, err error) {
 	start := time.Now()
 	if contextPath == "" {
 		contextPath = constant.WEB_CONTEXT
 	}
 
-	signHeaders := GetSignHeaders(params, newHeaders["secretKey"])
+	signHeaders := GetSignHeaders(params, newHeaders[constant.KEY_SECRET_KEY])
 
 	url := curServer + contextPath + api
 
 	headers := map[string][]string{}
 	for k, v := range newHeaders {
-		if k != "accessKey" && k != "secretKey" {
+		if k != constant.KEY_ACCESS_KEY && k != constant.KEY_SECRET_KEY && k != constant.KEY_SECURITY_TOKEN {
 			headers[k] = []string{v}
 		}
 	}
@@ -130,17 +170,29 @@ func (server *NacosServer) callConfigServer(api string, params map[string]string
 	}
 	headers["RequestId"] = []string{uid.String()}
 	headers["Content-Type"] = []string{"application/x-www-form-urlencoded;charset=utf-8"}
-	headers["Spas-AccessKey"] = []string{newHeaders["accessKey"]}
+	if body != nil {
+		headers["Content-Type"] = []string{contentType}
+	}
+	headers["Spas-AccessKey"] = []string{newHeaders[constant.KEY_ACCESS_KEY]}
+	if token := newHeaders[constant.KEY_SECURITY_TOKEN]; len(token) > 0 {
+		headers[securityTokenHeader] = []string{token}
+	}
 	headers["Timestamp"] = []string{signHeaders["Timestamp"]}
 	headers["Spas-Signature"] = []string{signHeaders["Spas-Signature"]}
-	server.InjectSecurityInfo(params)
+	server.injectHttpSecurityInfo(headers, params)
 
 	var response *http.Response
-	response, err = server.httpAgent.Request(method, url, headers, timeoutMS, params)
+	if body != nil {
+		response, err = server.httpAgent.RequestWithBody(method, url+"?"+util.GetUrlFormedMap(params), headers, timeoutMS, body)
+	} else {
+		response, err = server.httpAgent.Request(method, url, headers, timeoutMS, params)
+	}
 	monitor.GetConfigRequestMonitor(method, url, util.GetStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
 	if err != nil {
 		return
 	}
+	code = response.StatusCode
+	header = response.Header
 	var bytes []byte
 	bytes, err = io.ReadAll(response.Body)
 	defer response.Body.Close()
@@ -156,7 +208,23 @@ func (server *NacosServer) callConfigServer(api string, params map[string]string
 	}
 }
 
-func (server *NacosServer) callServer(api string, params map[string]string, method string, curServer string, contextPath string) (result string, err error) {
+// callServer requests the naming api, the request is replayed once after a re-login if the token is rejected,
+// within the timeout left by the first attempt and the re-login.
+func (server *NacosServer) callServer(api string, params map[string]string, newHeaders map[string]string, method string,
+	curServer string, contextPath string, timeoutMS uint64) (string, error) {
+	_, deadline := requestDeadline(timeoutMS)
+	token := server.AccessToken()
+	result, code, err := server.requestNamingServer(api, params, newHeaders, method, curServer, contextPath, timeoutMS)
+	if err != nil && server.ReAuth(token, code, result) {
+		if remaining := remainingMs(deadline); remaining > 0 {
+			result, _, err = server.requestNamingServer(api, params, newHeaders, method, curServer, contextPath, remaining)
+		}
+	}
+	return result, err
+}
+
+func (server *NacosServer) requestNamingServer(api string, params map[string]string, newHeaders map[string]string, method string,
+	curServer string, contextPath string, timeoutMS uint64) (result string, code int, err error) {
 	start := time.Now()
 	if contextPath == "" {
 		contextPath = constant.WEB_CONTEXT
@@ -165,6 +233,9 @@ func (server *NacosServer) callServer(api string, params map[string]string, meth
 	url := curServer + contextPath + api
 
 	headers := map[string][]string{}
+	for k, v := range newHeaders {
+		headers[k] = []string{v}
+	}
 	headers["Client-Version"] = []string{constant.CLIENT_VERSION}
 	headers["User-Agent"] = []string{constant.CLIENT_VERSION}
 	//headers["Accept-Encoding"] = []string{"gzip,deflate,sdch"}
@@ -177,13 +248,14 @@ func (server *NacosServer) callServer(api string, params map[string]string, meth
 	headers["Request-Module"] = []string{"Naming"}
 	headers["Content-Type"] = []string{"application/x-www-form-urlencoded;charset=utf-8"}
 
-	server.InjectSecurityInfo(params)
+	server.injectHttpSecurityInfo(headers, params)
 
 	var response *http.Response
-	response, err = server.httpAgent.Request(method, url, headers, server.timeoutMs, params)
+	response, err = server.httpAgent.Request(method, url, headers, timeoutMS, params)
 	if err != nil {
 		return
 	}
+	code = response.StatusCode
 	var bytes []byte
 	bytes, err = io.ReadAll(response.Body)
 	defer response.Body.Close()
@@ -200,170 +272,323 @@ func (server *NacosServer) callServer(api string, params map[string]string, meth
 	}
 }
 
+// requestDeadline return the start and the deadline shared by the attempts of a request, the default timeout
+// is used if it's not set.
+func requestDeadline(timeoutMS uint64) (time.Time, time.Time) {
+	if timeoutMS == 0 {
+		timeoutMS = constant.DEFAULT_TIMEOUT_MILLS
+	}
+	start := time.Now()
+	return start, start.Add(time.Duration(timeoutMS) * time.Millisecond)
+}
+
+// remainingMs return the milliseconds left before the deadline, 0 if it has passed.
+func remainingMs(deadline time.Time) uint64 {
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return 0
+	}
+	return uint64(remaining)
+}
+
 func (server *NacosServer) ReqConfigApi(api string, params map[string]string, headers map[string]string, method string, timeoutMS uint64) (string, error) {
-	srvs := server.serverList
+	return server.ReqConfigApiWithBody(api, params, headers, method, timeoutMS, nil, "")
+}
+
+// ReqConfigApiWithHeader request the config api like ReqConfigApi, and return the headers of the response too,
+// e.g. the Config-Type and the Encrypted-Data-Key of a queried config.
+func (server *NacosServer) ReqConfigApiWithHeader(api string, params map[string]string, headers map[string]string, method string,
+	timeoutMS uint64) (string, http.Header, error) {
+	return server.reqConfigApi(api, params, headers, method, timeoutMS, nil, "")
+}
+
+// ReqConfigApiWithBody request the config api with a raw body, such as a multipart form, params are sent
+// as the query string.
+func (server *NacosServer) ReqConfigApiWithBody(api string, params map[string]string, headers map[string]string, method string,
+	timeoutMS uint64, body []byte, contentType string) (string, error) {
+	result, _, err := server.reqConfigApi(api, params, headers, method, timeoutMS, body, contentType)
+	return result, err
+}
+
+func (server *NacosServer) reqConfigApi(api string, params map[string]string, headers map[string]string, method string,
+	timeoutMS uint64, body []byte, contentType string) (string, http.Header, error) {
+	srvs := server.GetServerList()
 	if srvs == nil || len(srvs) == 0 {
-		return "", errors.New("server list is empty")
+		return "", nil, errors.New("server list is empty")
 	}
 
-	server.InjectSecurityInfo(params)
-
 	//only one server,retry request when error
 	var err error
 	var result string
+	var header http.Header
+	var lastServer string
+	attempts := 0
+	start, deadline := requestDeadline(timeoutMS)
 	if len(srvs) == 1 {
-		for i := 0; i < constant.REQUEST_DOMAIN_RETRY_TIME; i++ {
-			result, err = server.callConfigServer(api, params, headers, method, getAddress(srvs[0]), srvs[0].ContextPath, timeoutMS)
+		for attempts < server.retryPolicy.Attempts() {
+			remaining := remainingMs(deadline)
+			if remaining == 0 {
+				break
+			}
+			attempts++
+			lastServer = getAddress(srvs[0])
+			result, header, err = server.callConfigServerWithBody(api, params, headers, method, lastServer, srvs[0].ContextPath, remaining, body, contentType)
 			if err == nil {
-				return result, nil
+				return result, header, nil
 			}
 			logger.Errorf("api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s>", api, method, util.ToJsonString(params), err, result)
+			if !server.retryable(err) || !server.retryPolicy.Wait(attempts, deadline) {
+				break
+			}
 		}
 	} else {
-		index := rand.Intn(len(srvs))
-		for i := 1; i <= len(srvs); i++ {
-			curServer := srvs[index]
-			result, err = server.callConfigServer(api, params, headers, method, getAddress(curServer), curServer.ContextPath, timeoutMS)
+		for _, curServer := range server.orderedServers(srvs) {
+			remaining := remainingMs(deadline)
+			if remaining == 0 {
+				break
+			}
+			attempts++
+			lastServer = getAddress(curServer)
+			result, header, err = server.callConfigServerWithBody(api, params, headers, method, lastServer, curServer.ContextPath, remaining, body, contentType)
 			if err == nil {
-				return result, nil
+				return result, header, nil
 			}
+			server.reportIfUnreachable(curServer, err)
 			logger.Errorf("[ERROR] api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s> \n", api, method, util.ToJsonString(params), err, result)
-			index = (index + i) % len(srvs)
 		}
 	}
-	return "", errors.Wrapf(err, "retry %d times request failed!", constant.REQUEST_DOMAIN_RETRY_TIME)
+	if remainingMs(deadline) == 0 {
+		return "", nil, nacos_error.NewTimeoutError(time.Since(start), attempts, lastServer, err)
+	}
+	return "", nil, errors.Wrapf(err, "request failed after %d attempts!", attempts)
 }
 
 func (server *NacosServer) ReqApi(api string, params map[string]string, method string, config constant.ClientConfig) (string, error) {
-	srvs := server.serverList
+	srvs := server.GetServerList()
 	if srvs == nil || len(srvs) == 0 {
 		return "", errors.New("server list is empty")
 	}
 
-	server.InjectSecurityInfo(params)
-	server.InjectSignForNamingHttp(params, config)
+	cred := server.Credentials()
+	server.injectSignForNamingHttp(params, cred)
+	headers := map[string]string{}
+	if len(cred.SecurityToken) > 0 {
+		headers[securityTokenHeader] = cred.SecurityToken
+	}
 
 	//only one server,retry request when error
 	var err error
 	var result string
+	var lastServer string
+	attempts := 0
+	start, deadline := requestDeadline(server.timeoutMs)
 	if len(srvs) == 1 {
-		for i := 0; i < constant.REQUEST_DOMAIN_RETRY_TIME; i++ {
-			result, err = server.callServer(api, params, method, getAddress(srvs[0]), srvs[0].ContextPath)
+		for attempts < server.retryPolicy.Attempts() {
+			remaining := remainingMs(deadline)
+			if remaining == 0 {
+				break
+			}
+			attempts++
+			lastServer = getAddress(srvs[0])
+			result, err = server.callServer(api, params, headers, method, lastServer, srvs[0].ContextPath, remaining)
 			if err == nil {
 				return result, nil
 			}
 			logger.Errorf("api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s>", api, method, util.ToJsonString(params), err, result)
+			if !server.retryable(err) || !server.retryPolicy.Wait(attempts, deadline) {
+				break
+			}
 		}
 	} else {
-		index := rand.Intn(len(srvs))
-		for i := 1; i <= len(srvs); i++ {
-			curServer := srvs[index]
-			result, err = server.callServer(api, params, method, getAddress(curServer), curServer.ContextPath)
+		for _, curServer := range server.orderedServers(srvs) {
+			remaining := remainingMs(deadline)
+			if remaining == 0 {
+				break
+			}
+			attempts++
+			lastServer = getAddress(curServer)
+			result, err = server.callServer(api, params, headers, method, lastServer, curServer.ContextPath, remaining)
 			if err == nil {
 				return result, nil
 			}
+			server.reportIfUnreachable(curServer, err)
 			logger.Errorf("api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s>", api, method, util.ToJsonString(params), err, result)
-			index = (index + i) % len(srvs)
 		}
 	}
-	return "", errors.Wrapf(err, "retry %d times request failed!", constant.REQUEST_DOMAIN_RETRY_TIME)
+	if remainingMs(deadline) == 0 {
+		return "", nacos_error.NewTimeoutError(time.Since(start), attempts, lastServer, err)
+	}
+	return "", errors.Wrapf(err, "request failed after %d attempts!", attempts)
 }
 
 func (server *NacosServer) initRefreshSrvIfNeed(ctx context.Context) {
-	if server.endpoint == "" {
+	if server.provider == nil {
 		return
 	}
-
-	if len(strings.TrimSpace(server.endpointContextPath)) == 0 {
-		server.endpointContextPath = "nacos"
-	}
-
-	if len(strings.TrimSpace(server.clusterName)) == 0 {
-		server.clusterName = "serverlist"
-	}
-	urlString := "http://" + server.endpoint + "/" + strings.TrimSpace(server.endpointContextPath) + "/" + strings.TrimSpace(server.clusterName)
-	if len(strings.TrimSpace(server.endpointQueryParams)) != 0 {
-		urlString += "?" + server.endpointQueryParams
-	}
-	logger.Infof("nacos address server url: <%s>", urlString)
-
-	server.refreshServerSrvIfNeed(urlString, server.endpointQueryHeader)
+	server.refreshServerList(ctx)
 	go func() {
+		ticker := time.NewTicker(server.refreshInterval)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				time.Sleep(time.Duration(10) * time.Second)
-				server.refreshServerSrvIfNeed(urlString, server.endpointQueryHeader)
+			case <-ticker.C:
+				server.refreshServerList(ctx)
 			}
 		}
 	}()
-
 }
 
-func (server *NacosServer) refreshServerSrvIfNeed(urlString string, header map[string][]string) {
-	if util.CurrentMillis()-server.lastSrvRefTime < server.vipSrvRefInterMills && len(server.serverList) > 0 {
+// refreshServerList replace the server list with the one of the provider, and signal the rpc clients to check
+// their connected servers.
+func (server *NacosServer) refreshServerList(ctx context.Context) {
+	servers, err := server.provider.ServerList(ctx)
+	if err != nil {
+		logger.Errorf("refresh server list failed, the current one is kept, err:%+v", err)
+		return
+	}
+	if len(servers) == 0 {
+		logger.Warnf("the server list provider returns no server, the current one is kept")
 		return
 	}
+	servers = server.withDefaults(servers)
 
-	var list []string
+	server.Lock()
+	serverPrev := server.serverList
+	if reflect.DeepEqual(serverPrev, servers) {
+		server.Unlock()
+		return
+	}
+	server.serverList = servers
+	server.Unlock()
+	logger.Infof("server list is updated, old: <%v>,new:<%v>", serverPrev, servers)
 
-	result := server.httpAgent.RequestOnlyResult(http.MethodGet, urlString, header, server.timeoutMs, nil)
-	list = strings.Split(result, "\n")
+	server.securityLogin.UpdateServerList(servers)
+	if serverPrev != nil {
+		select {
+		case server.ServerSrcChangeSignal <- struct{}{}:
+		default:
+		}
+	}
+}
 
-	var servers []constant.ServerConfig
+// withDefaults fill the scheme, port and context path of the provided servers.
+func (server *NacosServer) withDefaults(servers []constant.ServerConfig) []constant.ServerConfig {
 	contextPath := server.contextPath
 	if len(contextPath) == 0 {
 		contextPath = constant.WEB_CONTEXT
 	}
-	for _, line := range list {
-		if line != "" {
-			splitLine := strings.Split(strings.TrimSpace(line), ":")
-			port := 8848
-			var err error
-			if len(splitLine) == 2 {
-				port, err = strconv.Atoi(splitLine[1])
-				if err != nil {
-					logger.Errorf("get port from server:<%s>  error: <%+v>", line, err)
-					continue
-				}
-			}
-
-			servers = append(servers, constant.ServerConfig{Scheme: constant.DEFAULT_SERVER_SCHEME, IpAddr: splitLine[0], Port: uint64(port), ContextPath: contextPath})
+	result := make([]constant.ServerConfig, 0, len(servers))
+	for _, srv := range servers {
+		if len(srv.Scheme) == 0 {
+			srv.Scheme = constant.DEFAULT_SERVER_SCHEME
 		}
-	}
-	if len(servers) > 0 {
-		if !reflect.DeepEqual(server.serverList, servers) {
-			server.Lock()
-			var serverPrev = server.serverList
-			logger.Infof("server list is updated, old: <%v>,new:<%v>", serverPrev, servers)
-
-			server.serverList = servers
-			if serverPrev != nil {
-				server.ServerSrcChangeSignal <- struct{}{}
-			}
-			server.lastSrvRefTime = util.CurrentMillis()
-			server.Unlock()
+		if srv.Port == 0 {
+			srv.Port = 8848
 		}
-
+		if len(srv.ContextPath) == 0 {
+			srv.ContextPath = contextPath
+		}
+		result = append(result, srv)
 	}
-	return
+	return result
 }
 
 func (server *NacosServer) GetServerList() []constant.ServerConfig {
+	server.RLock()
+	defer server.RUnlock()
 	return server.serverList
 }
 
+// injectIdentity puts the identity header whitelisted by the nacos.core.auth.server.identity of the server.
+func (server *NacosServer) injectIdentity(headers map[string]string) {
+	if server.identityKey != "" {
+		headers[server.identityKey] = server.identityValue
+	}
+}
+
+// AccessToken returns the current token of the auth plugin, it's passed to ReAuth when the request is rejected.
+func (server *NacosServer) AccessToken() string {
+	return server.securityLogin.GetAccessToken()
+}
+
+// ReAuth logs in again if a request with the token was rejected for the expired or invalid token, it returns
+// whether the request can be replayed with the new token. The login is skipped if the token has been refreshed.
+func (server *NacosServer) ReAuth(token string, code int, message string) bool {
+	if !security.IsTokenRejected(code, message) {
+		return false
+	}
+	relogged, err := server.securityLogin.Relogin(token)
+	if err != nil {
+		logger.Errorf("re-login after the token is rejected err:%v", err)
+	}
+	return relogged
+}
+
+// InjectSecurityInfo decorates the grpc request with the token of the auth plugin and the server identity, the
+// param is the headers.
 func (server *NacosServer) InjectSecurityInfo(param map[string]string) {
-	accessToken := server.securityLogin.GetAccessToken()
-	if accessToken != "" {
-		param[constant.KEY_ACCESS_TOKEN] = accessToken
+	server.injectIdentity(param)
+	server.securityLogin.DecorateRequest(param, param)
+}
+
+// injectHttpSecurityInfo decorates the headers and the params of the http request with the token and the
+// server identity.
+func (server *NacosServer) injectHttpSecurityInfo(headers map[string][]string, params map[string]string) {
+	decorated := map[string]string{}
+	server.injectIdentity(decorated)
+	server.securityLogin.DecorateRequest(decorated, params)
+	for k, v := range decorated {
+		headers[k] = []string{v}
+	}
+}
+
+// Credentials returns the credentials to sign the requests, nothing is returned if the provider fails.
+func (server *NacosServer) Credentials() credentials.Credentials {
+	if server.credentials == nil {
+		return credentials.Credentials{}
+	}
+	c, err := server.credentials.Credentials()
+	if err != nil {
+		logger.Errorf("get the credentials err:%v", err)
+		return credentials.Credentials{}
+	}
+	return c
+}
+
+func newCredentialsProvider(clientCfg constant.ClientConfig) credentials.Provider {
+	if clientCfg.CredentialsProvider != nil {
+		return clientCfg.CredentialsProvider
+	}
+	return credentials.NewDefaultChain(credentials.Options{
+		AccessKey:       clientCfg.AccessKey,
+		SecretKey:       clientCfg.SecretKey,
+		SecurityToken:   clientCfg.SecurityToken,
+		RamRoleName:     clientCfg.RamRoleName,
+		RoleArn:         clientCfg.RamRoleArn,
+		RoleSessionName: clientCfg.RamRoleSessionName,
+		CredentialsFile: clientCfg.CredentialsFile,
+		FromEnv:         clientCfg.CredentialsFromEnv,
+	})
+}
+
+// staticCredentials returns the keys configured in the clientConfig.
+func staticCredentials(clientConfig constant.ClientConfig) credentials.Credentials {
+	return credentials.Credentials{
+		AccessKey:     clientConfig.AccessKey,
+		SecretKey:     clientConfig.SecretKey,
+		SecurityToken: clientConfig.SecurityToken,
 	}
 }
 
+// InjectSignForNamingHttp signs the naming http params with the keys of the clientConfig.
 func (server *NacosServer) InjectSignForNamingHttp(param map[string]string, clientConfig constant.ClientConfig) {
-	if clientConfig.AccessKey == "" || clientConfig.SecretKey == "" {
+	server.injectSignForNamingHttp(param, staticCredentials(clientConfig))
+}
+
+func (server *NacosServer) injectSignForNamingHttp(param map[string]string, cred credentials.Credentials) {
+	if cred.IsEmpty() {
 		return
 	}
 	var signData string
@@ -377,30 +602,39 @@ func (server *NacosServer) InjectSignForNamingHttp(param map[string]string, clie
 	} else {
 		signData = timeStamp
 	}
-	param["signature"] = signWithhmacSHA1Encrypt(signData, clientConfig.SecretKey)
-	param["ak"] = clientConfig.AccessKey
+	param["signature"] = signWithhmacSHA1Encrypt(signData, cred.SecretKey)
+	param["ak"] = cred.AccessKey
 	param["data"] = signData
 }
 
+// InjectSign signs the grpc request with the keys of the clientConfig.
 func (server *NacosServer) InjectSign(request rpc_request.IRequest, param map[string]string, clientConfig constant.ClientConfig) {
-	if clientConfig.AccessKey == "" || clientConfig.SecretKey == "" {
+	server.InjectSignWithCredentials(request, param, staticCredentials(clientConfig))
+}
+
+// InjectSignWithCredentials signs the grpc request with the cred, usually the Credentials of the server.
+func (server *NacosServer) InjectSignWithCredentials(request rpc_request.IRequest, param map[string]string, cred credentials.Credentials) {
+	if cred.IsEmpty() {
 		return
 	}
 	sts := request.GetStringToSign()
 	if sts == "" {
 		return
 	}
-	signature := signWithhmacSHA1Encrypt(sts, clientConfig.SecretKey)
+	signature := signWithhmacSHA1Encrypt(sts, cred.SecretKey)
 	param["data"] = sts
 	param["signature"] = signature
-	param["ak"] = clientConfig.AccessKey
+	param["ak"] = cred.AccessKey
+	if len(cred.SecurityToken) > 0 {
+		param[securityTokenHeader] = cred.SecurityToken
+	}
 }
 
 func getAddress(cfg constant.ServerConfig) string {
 	if strings.Index(cfg.IpAddr, "http://") >= 0 || strings.Index(cfg.IpAddr, "https://") >= 0 {
 		return cfg.IpAddr + ":" + strconv.Itoa(int(cfg.Port))
 	}
-	return cfg.Scheme + "://" + cfg.IpAddr + ":" + strconv.Itoa(int(cfg.Port))
+	return cfg.Scheme + "://" + cfg.Address()
 }
 
 func GetSignHeadersFromRequest(cr rpc_request.IConfigRequest, secretKey string) map[string]string {
@@ -467,16 +701,35 @@ func signWithhmacSHA1Encrypt(encryptText, encryptKey string) string {
 }
 
 func (server *NacosServer) GetNextServer() (constant.ServerConfig, error) {
-	serverLen := len(server.GetServerList())
+	servers := server.preferredServers(server.GetServerList())
+	if len(servers) == 0 && len(server.preferredZone) > 0 {
+		servers = server.stableServers(server.GetServerList())
+	}
+	if len(servers) == 0 {
+		servers = server.GetServerList()
+	}
+	serverLen := len(servers)
 	if serverLen == 0 {
 		return constant.ServerConfig{}, errors.New("server is empty")
 	}
 	index := atomic.AddInt32(&server.currentIndex, 1) % int32(serverLen)
-	return server.GetServerList()[index], nil
+	if index < 0 {
+		index += int32(serverLen)
+	}
+	return servers[index], nil
 }
 
+// InjectSkAk puts the access key of the clientConfig into the params.
 func (server *NacosServer) InjectSkAk(params map[string]string, clientConfig constant.ClientConfig) {
-	if clientConfig.AccessKey != "" {
-		params["Spas-AccessKey"] = clientConfig.AccessKey
+	server.InjectSkAkWithCredentials(params, staticCredentials(clientConfig))
+}
+
+// InjectSkAkWithCredentials puts the access key and the security token of the cred into the params.
+func (server *NacosServer) InjectSkAkWithCredentials(params map[string]string, cred credentials.Credentials) {
+	if cred.AccessKey != "" {
+		params["Spas-AccessKey"] = cred.AccessKey
+	}
+	if cred.SecurityToken != "" {
+		params[securityTokenHeader] = cred.SecurityToken
 	}
 }
@@ -18,11 +18,19 @@ package nacos_server
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/credentials"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -79,6 +87,31 @@ func TestNacosServer_InjectSignForNamingHttp_NoAk(t *testing.T) {
 	assert.Empty(t, param["signature"])
 }
 
+func TestNacosServer_InjectSignForNamingHttp_Provider(t *testing.T) {
+	t.Setenv(credentials.EnvAccessKeyId, "env-ak")
+	t.Setenv(credentials.EnvAccessKeySecret, "env-sk")
+	server, err := buildNacosServer(constant.ClientConfig{})
+	if err != nil {
+		t.FailNow()
+	}
+	param := map[string]string{"serviceName": "s-0"}
+	server.injectSignForNamingHttp(param, server.Credentials())
+	assert.Empty(t, param["ak"])
+
+	server, err = buildNacosServer(constant.ClientConfig{
+		CredentialsProvider: credentials.StaticProvider{AccessKey: "123", SecretKey: "321", SecurityToken: "token"},
+	})
+	if err != nil {
+		t.FailNow()
+	}
+	server.injectSignForNamingHttp(param, server.Credentials())
+	assert.Equal(t, "123", param["ak"])
+	headers := map[string]string{}
+	server.InjectSkAkWithCredentials(headers, server.Credentials())
+	assert.Equal(t, "123", headers["Spas-AccessKey"])
+	assert.Equal(t, "token", headers[securityTokenHeader])
+}
+
 func TestNacosServer_InjectSignForNamingHttp_WithGroup(t *testing.T) {
 	clientConfig := constant.ClientConfig{
 		AccessKey: "123",
@@ -157,3 +190,176 @@ func TestNacosServer_InjectSignForNamingHttp_WithoutServiceNameAndGroup(t *testi
 	_, has := param["signature"]
 	assert.True(t, has)
 }
+
+func TestNacosServer_ServerListProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	servers := []constant.ServerConfig{{IpAddr: "127.0.0.1"}}
+	var providerErr error
+	provider := constant.ServerListFunc(func(ctx context.Context) ([]constant.ServerConfig, error) {
+		return servers, providerErr
+	})
+	server, err := NewNacosServer(ctx, nil, constant.ClientConfig{ServerListProvider: provider, ServerListRefreshMs: 3600 * 1000},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []constant.ServerConfig{{Scheme: constant.DEFAULT_SERVER_SCHEME, IpAddr: "127.0.0.1", Port: 8848,
+		ContextPath: constant.WEB_CONTEXT}}, server.GetServerList())
+
+	servers = []constant.ServerConfig{{IpAddr: "127.0.0.2", Port: 8849, ContextPath: "/nacos"}}
+	server.refreshServerList(ctx)
+	assert.Equal(t, "127.0.0.2", server.GetServerList()[0].IpAddr)
+	select {
+	case <-server.ServerSrcChangeSignal:
+	default:
+		t.Fatal("the rpc clients are not signaled")
+	}
+
+	providerErr = errors.New("provider failed")
+	servers = nil
+	server.refreshServerList(ctx)
+	assert.Equal(t, uint64(8849), server.GetServerList()[0].Port)
+}
+
+func TestNacosServer_PreferredZone(t *testing.T) {
+	servers := []constant.ServerConfig{{IpAddr: "10.0.0.1", Port: 8848, Zone: "a"}, {IpAddr: "10.0.1.1", Port: 8848, Zone: "b"},
+		{IpAddr: "10.0.1.2", Port: 8848, Zone: "b"}}
+	server, err := NewNacosServer(context.Background(), servers, constant.ClientConfig{PreferredZone: "a", ServerFailbackMs: 50},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		next, err := server.GetNextServer()
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.1", next.IpAddr)
+	}
+	assert.Equal(t, "10.0.0.1", server.orderedServers(servers)[0].IpAddr)
+	_, ok := server.FailbackServer("10.0.0.1", 8848)
+	assert.False(t, ok)
+
+	// fail over to the other zone until the failback window passes
+	server.ReportServerError("10.0.0.1", 8848)
+	next, _ := server.GetNextServer()
+	assert.Equal(t, "b", next.Zone)
+	assert.Equal(t, 0, len(server.preferredServers(servers)))
+	assert.Equal(t, 3, len(server.orderedServers(servers)))
+	_, ok = server.FailbackServer("10.0.1.1", 8848)
+	assert.False(t, ok)
+
+	time.Sleep(60 * time.Millisecond)
+	preferred, ok := server.FailbackServer("10.0.1.1", 8848)
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1", preferred.IpAddr)
+}
+
+type headerAuthPlugin struct {
+	logins int
+}
+
+func (p *headerAuthPlugin) GetLoginToken(server constant.ServerConfig) (string, int64, error) {
+	p.logins++
+	return "sso-token", 0, nil
+}
+
+func (p *headerAuthPlugin) DecorateRequest(token string, headers map[string]string, params map[string]string) {
+	headers["Authorization"] = "Bearer " + token
+}
+
+func TestNacosServer_AuthPlugin(t *testing.T) {
+	plugin := &headerAuthPlugin{}
+	server, err := buildNacosServer(constant.ClientConfig{AuthPlugin: plugin, Username: "nacos"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, plugin.logins)
+
+	param := map[string]string{}
+	server.InjectSecurityInfo(param)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer sso-token"}, param)
+
+	headers := map[string][]string{}
+	params := map[string]string{}
+	server.injectHttpSecurityInfo(headers, params)
+	assert.Equal(t, []string{"Bearer sso-token"}, headers["Authorization"])
+	assert.Empty(t, params)
+}
+
+func TestNacosServer_ReplayAfterTokenExpired(t *testing.T) {
+	var logins, requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/nacos/v1/auth/users/login" {
+			n := atomic.AddInt32(&logins, 1)
+			_, _ = w.Write([]byte(`{"accessToken":"token-` + strconv.Itoa(int(n)) + `","tokenTtl":18000}`))
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get(constant.KEY_ACCESS_TOKEN) != "token-2" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("token expired!"))
+			return
+		}
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	nacosServer, err := NewNacosServer(context.Background(),
+		[]constant.ServerConfig{{Scheme: "http", IpAddr: u.Hostname(), Port: uint64(port), ContextPath: "/nacos"}},
+		constant.ClientConfig{Username: "replay", Password: "nacos", TimeoutMs: 1000, RequestMaxAttempts: 1},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+
+	result, err := nacosServer.ReqConfigApi("/v1/cs/configs", map[string]string{"dataId": "d"}, map[string]string{},
+		http.MethodGet, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", result)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&logins))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// a denied request isn't replayed
+	assert.False(t, nacosServer.ReAuth("token-2", http.StatusForbidden, "authorization failed!"))
+}
+
+func TestNacosServer_ReplayWithinTimeout(t *testing.T) {
+	var logins, requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/nacos/v1/auth/users/login" {
+			if n := atomic.AddInt32(&logins, 1); n > 1 {
+				// the re-login uses up the budget of the request
+				time.Sleep(300 * time.Millisecond)
+			}
+			_, _ = w.Write([]byte(`{"accessToken":"token-` + strconv.Itoa(int(atomic.LoadInt32(&logins))) + `","tokenTtl":18000}`))
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("token expired!"))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	nacosServer, err := NewNacosServer(context.Background(),
+		[]constant.ServerConfig{{Scheme: "http", IpAddr: u.Hostname(), Port: uint64(port), ContextPath: "/nacos"}},
+		constant.ClientConfig{Username: "replay-timeout", Password: "nacos", TimeoutMs: 1000, RequestMaxAttempts: 1},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+
+	start := time.Now()
+	_, err = nacosServer.ReqConfigApi("/v1/cs/configs", map[string]string{"dataId": "d"}, map[string]string{},
+		http.MethodGet, 200)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&logins))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.True(t, time.Since(start) < time.Second, time.Since(start))
+}
+
+func TestNacosServer_InjectIdentity(t *testing.T) {
+	server, err := buildNacosServer(constant.ClientConfig{IdentityKey: "serverIdentity", IdentityValue: "security"})
+	assert.Nil(t, err)
+
+	param := map[string]string{}
+	server.InjectSecurityInfo(param)
+	assert.Equal(t, map[string]string{"serverIdentity": "security"}, param)
+
+	headers := map[string][]string{}
+	params := map[string]string{}
+	server.injectHttpSecurityInfo(headers, params)
+	assert.Equal(t, []string{"security"}, headers["serverIdentity"])
+	assert.Empty(t, params)
+}
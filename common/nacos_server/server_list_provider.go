@@ -0,0 +1,112 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/http_agent"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+)
+
+// endpointServerList queries the servers from the address server, one host[:port] per line.
+type endpointServerList struct {
+	url       string
+	header    map[string][]string
+	httpAgent http_agent.IHttpAgent
+	timeoutMs uint64
+}
+
+func newEndpointServerList(endpoint string, clientCfg constant.ClientConfig, httpAgent http_agent.IHttpAgent,
+	timeoutMs uint64, header map[string][]string) *endpointServerList {
+	contextPath := strings.TrimSpace(clientCfg.EndpointContextPath)
+	if len(contextPath) == 0 {
+		contextPath = "nacos"
+	}
+	clusterName := strings.TrimSpace(clientCfg.ClusterName)
+	if len(clusterName) == 0 {
+		clusterName = "serverlist"
+	}
+	urlString := "http://" + endpoint + "/" + contextPath + "/" + clusterName
+	if len(strings.TrimSpace(clientCfg.EndpointQueryParams)) != 0 {
+		urlString += "?" + clientCfg.EndpointQueryParams
+	}
+	logger.Infof("nacos address server url: <%s>", urlString)
+	return &endpointServerList{url: urlString, header: header, httpAgent: httpAgent, timeoutMs: timeoutMs}
+}
+
+func (e *endpointServerList) ServerList(ctx context.Context) ([]constant.ServerConfig, error) {
+	result := e.httpAgent.RequestOnlyResult(http.MethodGet, e.url, e.header, e.timeoutMs, nil)
+	var servers []constant.ServerConfig
+	for _, line := range strings.Split(result, "\n") {
+		if line == "" {
+			continue
+		}
+		host, port, err := constant.SplitServerAddress(line)
+		if err != nil {
+			logger.Errorf("get port from server:<%s>  error: <%+v>", line, err)
+			continue
+		}
+		if port == 0 {
+			port = 8848
+		}
+		servers = append(servers, constant.ServerConfig{IpAddr: host, Port: port})
+	}
+	return servers, nil
+}
+
+// DnsSrvServerList is a ServerListProvider of the dns srv records of _Service._Proto.Name, e.g. _nacos._tcp.example.com,
+// the target and the port of each record is the address of a nacos server.
+type DnsSrvServerList struct {
+	Service     string
+	Proto       string
+	Name        string
+	Scheme      string        // optional,default:http
+	ContextPath string        // optional,default:the context path of the client config or /nacos
+	Resolver    *net.Resolver // optional,default:net.DefaultResolver
+}
+
+func (d DnsSrvServerList) ServerList(ctx context.Context) ([]constant.ServerConfig, error) {
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, d.Service, d.Proto, d.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "lookup the srv records of service:%s proto:%s name:%s failed", d.Service, d.Proto, d.Name)
+	}
+	servers := make([]constant.ServerConfig, 0, len(records))
+	for _, record := range records {
+		servers = append(servers, constant.ServerConfig{Scheme: d.Scheme, ContextPath: d.ContextPath,
+			IpAddr: strings.TrimSuffix(record.Target, "."), Port: uint64(record.Port)})
+	}
+	// the records are shuffled by weight, sort them to not take a lookup as a change of the servers
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].IpAddr != servers[j].IpAddr {
+			return servers[i].IpAddr < servers[j].IpAddr
+		}
+		return servers[i].Port < servers[j].Port
+	})
+	return servers, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
+)
+
+// the default time a failed server of the preferred zone must stay without errors before it's preferred again
+const defaultFailbackWindow = 30 * time.Second
+
+func serverKey(ip string, port uint64) string {
+	return net.JoinHostPort(ip, strconv.FormatUint(port, 10))
+}
+
+// ReportServerError mark the server failed, it's not preferred until the failback window passes without errors.
+func (server *NacosServer) ReportServerError(ip string, port uint64) {
+	server.serverFailures.Store(serverKey(ip, port), time.Now())
+}
+
+// reportIfUnreachable report the server failed unless it responds an error code, which tells it's reachable.
+func (server *NacosServer) reportIfUnreachable(srv constant.ServerConfig, err error) {
+	if _, ok := err.(*nacos_error.NacosError); ok {
+		return
+	}
+	server.ReportServerError(srv.IpAddr, srv.Port)
+}
+
+// retryable reports whether the failed request to a single server is retried, the error codes responded by
+// the server are retried only if they are in the retry codes of the policy.
+func (server *NacosServer) retryable(err error) bool {
+	nacosErr, ok := err.(*nacos_error.NacosError)
+	if !ok {
+		return true
+	}
+	code, convErr := strconv.Atoi(nacosErr.ErrorCode())
+	if convErr != nil {
+		return true
+	}
+	return server.retryPolicy.RetryableCode(code)
+}
+
+func (server *NacosServer) isStable(srv constant.ServerConfig) bool {
+	failedAt, ok := server.serverFailures.Load(serverKey(srv.IpAddr, srv.Port))
+	return !ok || time.Since(failedAt.(time.Time)) >= server.failbackWindow
+}
+
+func (server *NacosServer) isPreferred(srv constant.ServerConfig) bool {
+	return len(server.preferredZone) > 0 && srv.Zone == server.preferredZone
+}
+
+// preferredServers return the stable servers of the preferred zone.
+func (server *NacosServer) preferredServers(srvs []constant.ServerConfig) []constant.ServerConfig {
+	if len(server.preferredZone) == 0 {
+		return nil
+	}
+	var preferred []constant.ServerConfig
+	for _, srv := range srvs {
+		if server.isPreferred(srv) && server.isStable(srv) {
+			preferred = append(preferred, srv)
+		}
+	}
+	return preferred
+}
+
+// stableServers return the servers without errors within the failback window.
+func (server *NacosServer) stableServers(srvs []constant.ServerConfig) []constant.ServerConfig {
+	var stable []constant.ServerConfig
+	for _, srv := range srvs {
+		if server.isStable(srv) {
+			stable = append(stable, srv)
+		}
+	}
+	return stable
+}
+
+// orderedServers return the servers in the order to try, the stable ones of the preferred zone first,
+// each part starts from a random server to spread the requests.
+func (server *NacosServer) orderedServers(srvs []constant.ServerConfig) []constant.ServerConfig {
+	preferred := server.preferredServers(srvs)
+	others := make([]constant.ServerConfig, 0, len(srvs)-len(preferred))
+	for _, srv := range srvs {
+		if !server.isPreferred(srv) || !server.isStable(srv) {
+			others = append(others, srv)
+		}
+	}
+	return append(rotate(preferred), rotate(others)...)
+}
+
+func rotate(srvs []constant.ServerConfig) []constant.ServerConfig {
+	if len(srvs) < 2 {
+		return srvs
+	}
+	index := rand.Intn(len(srvs))
+	return append(append([]constant.ServerConfig{}, srvs[index:]...), srvs[:index]...)
+}
+
+// FailbackServer return a stable server of the preferred zone if the connected server is not in it.
+func (server *NacosServer) FailbackServer(ip string, port uint64) (constant.ServerConfig, bool) {
+	srvs := server.GetServerList()
+	for _, srv := range srvs {
+		if srv.IpAddr == ip && srv.Port == port && server.isPreferred(srv) {
+			return constant.ServerConfig{}, false
+		}
+	}
+	preferred := server.preferredServers(srvs)
+	if len(preferred) == 0 {
+		return constant.ServerConfig{}, false
+	}
+	return preferred[rand.Intn(len(preferred))], true
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	crypto_tls "crypto/tls"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ContextDialer dials the address, net.Dialer is used if it's nil.
+type ContextDialer func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Resolve return the proxy to reach the address by the scheme, proxyURL is used if it's set, otherwise the
+// proxy is read from HTTPS_PROXY, HTTP_PROXY and NO_PROXY. A nil url means no proxy.
+func Resolve(proxyURL string, scheme string, address string) (*url.URL, error) {
+	if len(proxyURL) > 0 {
+		return Parse(proxyURL)
+	}
+	return http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: scheme, Host: address}})
+}
+
+// Parse parse the proxy url, a host:port without the scheme is taken as a http proxy.
+func Parse(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if u, err = url.Parse("http://" + proxyURL); err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy url %s", proxyURL)
+		}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("unsupported proxy scheme %s, only http and https are supported", u.Scheme)
+	}
+	return u, nil
+}
+
+// Dial open a tunnel to the address by the CONNECT method of the http proxy.
+func Dial(ctx context.Context, proxy *url.URL, address string, dialer ContextDialer) (net.Conn, error) {
+	if dialer == nil {
+		dialer = (&net.Dialer{}).DialContext
+	}
+	proxyAddress := proxy.Host
+	if proxy.Port() == "" {
+		port := "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(proxy.Hostname(), port)
+	}
+	conn, err := dialer(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dial proxy %s failed", proxyAddress)
+	}
+	if proxy.Scheme == "https" {
+		tlsConn := crypto_tls.Client(conn, &crypto_tls.Config{ServerName: proxy.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, errors.Wrapf(err, "tls handshake with proxy %s failed", proxyAddress)
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credential := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credential)
+	}
+	if err = request.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "send CONNECT to proxy %s failed", proxyAddress)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "read CONNECT response of proxy %s failed", proxyAddress)
+	}
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		_ = conn.Close()
+		return nil, errors.Errorf("proxy %s refused to connect to %s: %s", proxyAddress, address, response.Status)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn read the bytes buffered after the CONNECT response first.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+)
+
+// AbilityStatus is whether the server of the current connection supports an ability.
+type AbilityStatus int
+
+const (
+	// ABILITY_UNKNOWN means the server doesn't support the ability negotiation, the servers before 2.2.
+	ABILITY_UNKNOWN AbilityStatus = iota
+	ABILITY_SUPPORTED
+	ABILITY_NOT_SUPPORTED
+)
+
+// clientAbilityTable is reported to the servers supporting the ability negotiation.
+var clientAbilityTable = map[string]bool{
+	constant.ABILITY_FUZZY_WATCH: true,
+	constant.ABILITY_LOCK:        false,
+}
+
+// ServerAbility return whether the server of the current connection supports the ability.
+func (r *RpcClient) ServerAbility(key string) AbilityStatus {
+	connection := r.currentConnection
+	if connection == nil {
+		return ABILITY_UNKNOWN
+	}
+	return connection.getAbility(key)
+}
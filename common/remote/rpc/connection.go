@@ -17,6 +17,10 @@
 package rpc
 
 import (
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
 	"google.golang.org/grpc"
@@ -29,6 +33,9 @@ type IConnection interface {
 	getServerInfo() ServerInfo
 	setAbandon(flag bool)
 	getAbandon() bool
+	getAbility(key string) AbilityStatus
+	getLastReceived() time.Time
+	probeStream() error
 }
 
 type Connection struct {
@@ -36,6 +43,9 @@ type Connection struct {
 	connectionId string
 	abandon      bool
 	serverInfo   ServerInfo
+	abilityMutex sync.RWMutex
+	abilityTable map[string]bool
+	lastReceived int64 // the unix nanoseconds when the last push is received or the connection is created
 }
 
 func (c *Connection) getConnectionId() string {
@@ -57,3 +67,33 @@ func (c *Connection) getAbandon() bool {
 func (c *Connection) close() {
 	_ = c.conn.Close()
 }
+
+func (c *Connection) setAbilityTable(table map[string]bool) {
+	if table == nil {
+		table = map[string]bool{}
+	}
+	c.abilityMutex.Lock()
+	c.abilityTable = table
+	c.abilityMutex.Unlock()
+}
+
+// getAbility return ABILITY_UNKNOWN until the server reported its abilities.
+func (c *Connection) getAbility(key string) AbilityStatus {
+	c.abilityMutex.RLock()
+	defer c.abilityMutex.RUnlock()
+	if c.abilityTable == nil {
+		return ABILITY_UNKNOWN
+	}
+	if c.abilityTable[key] {
+		return ABILITY_SUPPORTED
+	}
+	return ABILITY_NOT_SUPPORTED
+}
+
+func (c *Connection) setLastReceived(t time.Time) {
+	atomic.StoreInt64(&c.lastReceived, t.UnixNano())
+}
+
+func (c *Connection) getLastReceived() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.lastReceived))
+}
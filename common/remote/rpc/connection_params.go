@@ -0,0 +1,165 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+// the max delay between the reconnections if ConnectionParams.MaxBackoff is not set
+const defaultMaxReconnectBackoff = 5 * time.Second
+
+// ConnectionParams tunes the grpc connections of a client, the zero values mean the env or the defaults
+type ConnectionParams struct {
+	KeepAliveTime         time.Duration
+	KeepAliveTimeout      time.Duration
+	InitialWindowSize     int32
+	InitialConnWindowSize int32
+	MaxBackoff            time.Duration
+	IdleTimeout           time.Duration
+	HealthCheckInterval   time.Duration
+	HealthCheckTimeout    time.Duration
+	HealthCheckFailures   int           // the consecutive failed health checks to reconnect
+	ReconnectJitter       time.Duration // the max random delay added to every reconnection
+	ProbeIdle             time.Duration // check the health even if the requests succeed when there's no push within it
+}
+
+// NewConnectionParams returns the connection params of the client config
+func NewConnectionParams(clientCfg constant.ClientConfig) ConnectionParams {
+	return ConnectionParams{
+		KeepAliveTime:         time.Duration(clientCfg.GrpcKeepAliveTimeMs) * time.Millisecond,
+		KeepAliveTimeout:      time.Duration(clientCfg.GrpcKeepAliveTimeoutMs) * time.Millisecond,
+		InitialWindowSize:     clientCfg.GrpcInitialWindowSize,
+		InitialConnWindowSize: clientCfg.GrpcConnWindowSize,
+		MaxBackoff:            time.Duration(clientCfg.GrpcMaxBackoffMs) * time.Millisecond,
+		IdleTimeout:           time.Duration(clientCfg.GrpcIdleTimeoutMs) * time.Millisecond,
+		HealthCheckInterval:   time.Duration(clientCfg.GrpcHealthCheckMs) * time.Millisecond,
+		HealthCheckTimeout:    time.Duration(clientCfg.GrpcHealthTimeoutMs) * time.Millisecond,
+		HealthCheckFailures:   clientCfg.GrpcHealthFailures,
+		ReconnectJitter:       time.Duration(clientCfg.GrpcReconnectJitterMs) * time.Millisecond,
+		ProbeIdle:             time.Duration(clientCfg.GrpcProbeIdleMs) * time.Millisecond,
+	}
+}
+
+// NewConnectionLabels returns the labels of the connection setup of the module, the ConnectionLabels of the
+// client config are sent without the empty keys and values and the reserved keys, which are source, module,
+// AppName and taskId. AppName is sent if it's set.
+func NewConnectionLabels(clientCfg constant.ClientConfig, module string) map[string]string {
+	labels := make(map[string]string, len(clientCfg.ConnectionLabels)+3)
+	for k, v := range clientCfg.ConnectionLabels {
+		if k == "" || v == "" || reservedConnectionLabels[k] {
+			continue
+		}
+		labels[k] = v
+	}
+	labels[constant.LABEL_SOURCE] = constant.LABEL_SOURCE_SDK
+	labels[constant.LABEL_MODULE] = module
+	if clientCfg.AppName != "" {
+		labels[constant.APPNAME_HEADER] = clientCfg.AppName
+	}
+	return labels
+}
+
+var reservedConnectionLabels = map[string]bool{
+	constant.LABEL_SOURCE:   true,
+	constant.LABEL_MODULE:   true,
+	constant.APPNAME_HEADER: true,
+	constant.LABEL_TASK_ID:  true,
+}
+
+// GetCompressThreshold returns the compress threshold of the rpc clients of the client config,
+// EnableGrpcCompression compresses all the non-empty requests unless CompressThreshold is set
+func GetCompressThreshold(clientCfg constant.ClientConfig) int {
+	if clientCfg.CompressThreshold <= 0 && clientCfg.EnableGrpcCompression {
+		return 1
+	}
+	return clientCfg.CompressThreshold
+}
+
+func (p ConnectionParams) dialOptions() []grpc.DialOption {
+	keepAlive := getKeepAliveTimeMillis()
+	if p.KeepAliveTime > 0 {
+		keepAlive.Time = p.KeepAliveTime
+	}
+	if p.KeepAliveTimeout > 0 {
+		keepAlive.Timeout = p.KeepAliveTimeout
+	}
+	initialWindowSize := p.InitialWindowSize
+	if initialWindowSize <= 0 {
+		initialWindowSize = getInitialWindowSize()
+	}
+	initialConnWindowSize := p.InitialConnWindowSize
+	if initialConnWindowSize <= 0 {
+		initialConnWindowSize = getInitialConnWindowSize()
+	}
+	opts := []grpc.DialOption{
+		grpc.WithKeepaliveParams(keepAlive),
+		grpc.WithInitialWindowSize(initialWindowSize),
+		grpc.WithInitialConnWindowSize(initialConnWindowSize),
+	}
+	if p.MaxBackoff > 0 {
+		backoffConfig := backoff.DefaultConfig
+		backoffConfig.MaxDelay = p.MaxBackoff
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoffConfig, MinConnectTimeout: 20 * time.Second}))
+	}
+	if p.IdleTimeout > 0 {
+		opts = append(opts, grpc.WithIdleTimeout(p.IdleTimeout))
+	}
+	return opts
+}
+
+// reconnectDelay returns the delay before the next reconnection, it grows by 100ms every turn up to the max backoff
+func (p ConnectionParams) reconnectDelay(retryTurns int) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxReconnectBackoff
+	}
+	delay := time.Duration(retryTurns) * 100 * time.Millisecond
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	if p.ReconnectJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.ReconnectJitter)))
+	}
+	return delay
+}
+
+func (p ConnectionParams) healthCheckInterval() time.Duration {
+	if p.HealthCheckInterval > 0 {
+		return p.HealthCheckInterval
+	}
+	return constant.KEEP_ALIVE_TIME * time.Second
+}
+
+func (p ConnectionParams) healthCheckTimeoutMills() int64 {
+	if p.HealthCheckTimeout > 0 {
+		return p.HealthCheckTimeout.Milliseconds()
+	}
+	return constant.DEFAULT_TIMEOUT_MILLS
+}
+
+func (p ConnectionParams) healthCheckFailures() int {
+	if p.HealthCheckFailures > 0 {
+		return p.HealthCheckFailures
+	}
+	return 1
+}
@@ -1,6 +1,8 @@
 package rpc
 
 import (
+	"time"
+
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
 )
@@ -23,3 +25,12 @@ func (m *MockConnection) getServerInfo() ServerInfo {
 func (m *MockConnection) setAbandon(flag bool) {
 
 }
+func (m *MockConnection) getAbility(key string) AbilityStatus {
+	return ABILITY_UNKNOWN
+}
+func (m *MockConnection) getLastReceived() time.Time {
+	return time.Now()
+}
+func (m *MockConnection) probeStream() error {
+	return nil
+}
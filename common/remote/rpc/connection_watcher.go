@@ -0,0 +1,81 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/model"
+)
+
+// WatchConnectionEvents add a callback of the connection events, it's called in the event goroutine of the client
+// and must not block.
+func (r *RpcClient) WatchConnectionEvents(callback *func(event model.ConnectionEvent)) {
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+	r.connectionWatchers = append(r.connectionWatchers, callback)
+}
+
+// UnwatchConnectionEvents remove the callback added by WatchConnectionEvents.
+func (r *RpcClient) UnwatchConnectionEvents(callback *func(event model.ConnectionEvent)) {
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+	var watchers []*func(event model.ConnectionEvent)
+	for _, watcher := range r.connectionWatchers {
+		if watcher != callback {
+			watchers = append(watchers, watcher)
+		}
+	}
+	r.connectionWatchers = watchers
+}
+
+// notifyConnectionWatchers tell a connection from a reconnection to the same server or a switch to another one,
+// the last connected server is only accessed by the event goroutine.
+func (r *RpcClient) notifyConnectionWatchers(event ConnectionEvent) {
+	server := serverAddress(event.serverInfo.serverIp, event.serverInfo.serverPort)
+	connectionEvent := model.ConnectionEvent{Server: server, ConnectionId: event.connectionId, Time: time.Now()}
+	switch {
+	case event.isDisConnected():
+		connectionEvent.Type = constant.CONNECTION_EVENT_DISCONNECTED
+	case len(r.lastConnectedServer) == 0:
+		connectionEvent.Type = constant.CONNECTION_EVENT_CONNECTED
+	case r.lastConnectedServer == server:
+		connectionEvent.Type = constant.CONNECTION_EVENT_RECONNECTED
+	default:
+		connectionEvent.Type = constant.CONNECTION_EVENT_SWITCHED
+		connectionEvent.PreviousServer = r.lastConnectedServer
+	}
+	if event.isConnected() {
+		r.lastConnectedServer = server
+	}
+
+	r.watchMutex.Lock()
+	watchers := r.connectionWatchers
+	r.watchMutex.Unlock()
+	for _, watcher := range watchers {
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					logger.Errorf("%s connection event callback panic, event:%+v, err:%+v", r.name, connectionEvent, err)
+				}
+			}()
+			(*watcher)(connectionEvent)
+		}()
+	}
+}
@@ -18,8 +18,8 @@ package rpc
 
 import (
 	"context"
-	"encoding/json"
 	"io"
+	"net"
 	"os"
 	"strconv"
 	"sync"
@@ -30,23 +30,30 @@ import (
 
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_serializer"
 
 	nacos_grpc_service "github.com/jun3372/nacos-sdk-go/api/grpc"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_server"
+	"github.com/jun3372/nacos-sdk-go/common/proxy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
 
+// setupAckTimeout is how long to wait for the abilities of the servers supporting the negotiation.
+const setupAckTimeout = 3 * time.Second
+
 type GrpcClient struct {
 	*RpcClient
 }
 
 func NewGrpcClient(ctx context.Context, clientName string, nacosServer *nacos_server.NacosServer) *GrpcClient {
+	ctx, cancel := context.WithCancel(ctx)
 	rpcClient := &GrpcClient{
 		&RpcClient{
 			ctx:              ctx,
+			cancel:           cancel,
 			name:             clientName,
 			labels:           make(map[string]string, 8),
 			rpcClientStatus:  INITIALIZED,
@@ -112,16 +119,48 @@ func getKeepAliveTimeMillis() keepalive.ClientParameters {
 
 func (c *GrpcClient) createNewConnection(serverInfo ServerInfo) (*grpc.ClientConn, error) {
 	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(getMaxCallRecvMsgSize())))
-	opts = append(opts, grpc.WithKeepaliveParams(getKeepAliveTimeMillis()))
-	opts = append(opts, grpc.WithInsecure())
-	opts = append(opts, grpc.WithInitialWindowSize(getInitialWindowSize()))
-	opts = append(opts, grpc.WithInitialConnWindowSize(getInitialConnWindowSize()))
+	maxCallRecvMsgSize := c.MaxCallRecvMsgSize
+	if maxCallRecvMsgSize <= 0 {
+		maxCallRecvMsgSize = getMaxCallRecvMsgSize()
+	}
+	callOptions := []grpc.CallOption{grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize)}
+	if c.MaxCallSendMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(c.MaxCallSendMsgSize))
+	}
+	opts = append(opts, grpc.WithDefaultCallOptions(callOptions...))
+	if c.TLSConfig.Enable {
+		creds, err := c.transportCredentials()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	opts = append(opts, c.ConnectionParams.dialOptions()...)
+	if len(c.ProxyURL) > 0 {
+		proxyURL, err := proxy.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		var dialer proxy.ContextDialer
+		if c.Dialer != nil {
+			dialer = func(ctx context.Context, _, address string) (net.Conn, error) {
+				return c.Dialer(ctx, address)
+			}
+		}
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return proxy.Dial(ctx, proxyURL, address, dialer)
+		}))
+	} else if c.Dialer != nil {
+		opts = append(opts, grpc.WithContextDialer(c.Dialer))
+	}
+	opts = append(opts, c.DialOptions...)
 	rpcPort := serverInfo.serverGrpcPort
 	if rpcPort == 0 {
 		rpcPort = serverInfo.serverPort + c.rpcPortOffset()
 	}
-	return grpc.Dial(serverInfo.serverIp+":"+strconv.FormatUint(rpcPort, 10), opts...)
+	return grpc.Dial(net.JoinHostPort(serverInfo.serverIp, strconv.FormatUint(rpcPort, 10)), opts...)
 
 }
 
@@ -148,6 +187,9 @@ func (c *GrpcClient) connectToServer(serverInfo ServerInfo) (IConnection, error)
 		return nil, errors.Errorf("create biStreamRequestClient failed , err:%v", err)
 	}
 	grpcConn := NewGrpcConnection(serverInfo, serverCheckResponse.ConnectionId, conn, client, biStreamRequestClient)
+	if serverCheckResponse.SupportAbilityNegotiation {
+		grpcConn.setupAck = make(chan struct{})
+	}
 	c.bindBiRequestStream(biStreamRequestClient, grpcConn)
 	err = c.sendConnectionSetupRequest(grpcConn)
 	return grpcConn, err
@@ -159,12 +201,44 @@ func (c *GrpcClient) sendConnectionSetupRequest(grpcConn *GrpcConnection) error
 	csr.Tenant = c.Tenant
 	csr.Labels = c.labels
 	csr.ClientAbilities = c.clientAbilities
+	csr.AbilityTable = clientAbilityTable
 	err := grpcConn.biStreamSend(convertRequest(csr))
 	if err != nil {
 		logger.Warnf("send connectionSetupRequest error:%v", err)
+		return err
+	}
+	if grpcConn.setupAck == nil {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	select {
+	case <-grpcConn.setupAck:
+	case <-time.After(setupAckTimeout):
+		logger.Warnf("connectionId %s wait setupAckRequest timeout, the server abilities are unknown",
+			grpcConn.getConnectionId())
+	}
+	return nil
+}
+
+// handleSetupAck save the abilities of the server on the connection, it isn't dispatched to the registered
+// handlers since the connection isn't the current one yet.
+func (c *GrpcClient) handleSetupAck(p *nacos_grpc_service.Payload, grpcConn *GrpcConnection) {
+	request := &rpc_request.SetupAckRequest{InternalRequest: rpc_request.NewInternalRequest()}
+	if err := rpc_serializer.Unmarshal(p.GetBody().Value, request); err != nil {
+		logger.Errorf("%s Fail to json Unmarshal for request:%s", grpcConn.getConnectionId(), request.GetRequestType())
+		return
+	}
+	grpcConn.setAbilityTable(request.AbilityTable)
+	grpcConn.ackOnce.Do(func() {
+		if grpcConn.setupAck != nil {
+			close(grpcConn.setupAck)
+		}
+	})
+	response := &rpc_response.SetupAckResponse{Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true}}
+	response.SetRequestId(request.GetRequestId())
+	if err := grpcConn.biStreamSend(convertResponse(response)); err != nil && err != io.EOF {
+		logger.Warnf("%s Fail to send response:%s", grpcConn.getConnectionId(), response.GetResponseType())
 	}
-	time.Sleep(100 * time.Millisecond)
-	return err
 }
 
 func (c *GrpcClient) getConnectionType() ConnectionType {
@@ -201,6 +275,7 @@ func (c *GrpcClient) bindBiRequestStream(streamClient nacos_grpc_service.BiReque
 						return
 					}
 				} else {
+					grpcConn.setLastReceived(time.Now())
 					c.handleServerRequest(payload, grpcConn)
 				}
 			}
@@ -217,7 +292,7 @@ func serverCheck(client nacos_grpc_service.RequestClient) (rpc_response.IRespons
 		if err != nil {
 			return nil, err
 		}
-		err = json.Unmarshal(payload.GetBody().Value, &response)
+		err = rpc_serializer.Unmarshal(payload.GetBody().Value, &response)
 		if err != nil {
 			return nil, err
 		}
@@ -238,6 +313,10 @@ func serverCheck(client nacos_grpc_service.RequestClient) (rpc_response.IRespons
 func (c *GrpcClient) handleServerRequest(p *nacos_grpc_service.Payload, grpcConn *GrpcConnection) {
 	client := c.GetRpcClient()
 	payLoadType := p.GetMetadata().GetType()
+	if payLoadType == "SetupAckRequest" {
+		c.handleSetupAck(p, grpcConn)
+		return
+	}
 
 	handlerMapping, ok := client.serverRequestHandlerMapping.Load(payLoadType)
 	if !ok {
@@ -248,7 +327,7 @@ func (c *GrpcClient) handleServerRequest(p *nacos_grpc_service.Payload, grpcConn
 	mapping := handlerMapping.(ServerRequestHandlerMapping)
 
 	serverRequest := mapping.serverRequest()
-	err := json.Unmarshal(p.GetBody().Value, serverRequest)
+	err := rpc_serializer.Unmarshal(p.GetBody().Value, serverRequest)
 	if err != nil {
 		logger.Errorf("%s Fail to json Unmarshal for request:%s, ackId->%s", grpcConn.getConnectionId(),
 			serverRequest.GetRequestType(), serverRequest.GetRequestId())
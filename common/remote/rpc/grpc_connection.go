@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/any"
@@ -28,12 +29,18 @@ import (
 	"github.com/pkg/errors"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/encoding/gzip"
 )
 
 type GrpcConnection struct {
 	*Connection
 	client         nacos_grpc_service.RequestClient
 	biStreamClient nacos_grpc_service.BiRequestStream_RequestBiStreamClient
+	// setupAck is closed when the abilities are received, nil if the server doesn't support the negotiation
+	setupAck chan struct{}
+	ackOnce  sync.Once
+	sendLock sync.Mutex // the stream doesn't support the concurrent sends
 }
 
 func NewGrpcConnection(serverInfo ServerInfo, connectionId string, conn *grpc.ClientConn,
@@ -44,6 +51,7 @@ func NewGrpcConnection(serverInfo ServerInfo, connectionId string, conn *grpc.Cl
 			connectionId: connectionId,
 			abandon:      false,
 			conn:         conn,
+			lastReceived: time.Now().UnixNano(),
 		},
 		client:         client,
 		biStreamClient: biStreamClient,
@@ -53,7 +61,11 @@ func (g *GrpcConnection) request(request rpc_request.IRequest, timeoutMills int6
 	p := convertRequest(request)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMills)*time.Millisecond)
 	defer cancel()
-	responsePayload, err := g.client.Request(ctx, p)
+	var callOptions []grpc.CallOption
+	if client != nil && client.CompressThreshold > 0 && len(p.GetBody().GetValue()) > client.CompressThreshold {
+		callOptions = append(callOptions, grpc.UseCompressor(gzip.Name))
+	}
+	responsePayload, err := g.client.Request(ctx, p, callOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,9 +84,29 @@ func (g *GrpcConnection) close() {
 }
 
 func (g *GrpcConnection) biStreamSend(payload *nacos_grpc_service.Payload) error {
+	g.sendLock.Lock()
+	defer g.sendLock.Unlock()
 	return g.biStreamClient.Send(payload)
 }
 
+// probeStream check the idle stream by what the server has to return, a successful send proves nothing on a
+// half-open connection. The keepalive pings must be acknowledged by the server, the transport leaves the ready
+// state and the stream is closed otherwise. The last received time is only set by the pushes.
+func (g *GrpcConnection) probeStream() error {
+	if g.biStreamClient == nil {
+		return errors.New("the stream is not created")
+	}
+	if err := g.biStreamClient.Context().Err(); err != nil {
+		return errors.Wrap(err, "the stream is closed")
+	}
+	if g.conn != nil {
+		if state := g.conn.GetState(); state != connectivity.Ready {
+			return errors.Errorf("the transport is %s, the keepalive pings are not acknowledged", state)
+		}
+	}
+	return nil
+}
+
 func convertRequest(r rpc_request.IRequest) *nacos_grpc_service.Payload {
 	Metadata := nacos_grpc_service.Metadata{
 		Type:     r.GetRequestType(),
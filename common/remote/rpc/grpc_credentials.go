@@ -0,0 +1,66 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"net"
+
+	nacos_tls "github.com/jun3372/nacos-sdk-go/common/tls"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadableCredentials handshakes with the latest tls config of the reloader,
+// so the reconnections pick up the rotated certificates
+type reloadableCredentials struct {
+	reloader *nacos_tls.Reloader
+}
+
+func (c *reloadableCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return credentials.NewTLS(c.reloader.Config()).ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *reloadableCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("server handshake is not supported by the client credentials")
+}
+
+func (c *reloadableCredentials) Info() credentials.ProtocolInfo {
+	return credentials.NewTLS(c.reloader.Config()).Info()
+}
+
+func (c *reloadableCredentials) Clone() credentials.TransportCredentials {
+	return &reloadableCredentials{reloader: c.reloader}
+}
+
+func (c *reloadableCredentials) OverrideServerName(string) error {
+	return errors.New("override server name is not supported, use the ServerNameOverride of the tls config")
+}
+
+// transportCredentials returns the tls credentials of the client, the reloader is shared by the reconnections
+func (r *RpcClient) transportCredentials() (credentials.TransportCredentials, error) {
+	r.tlsMutex.Lock()
+	defer r.tlsMutex.Unlock()
+	if r.tlsReloader == nil {
+		reloader, err := nacos_tls.NewReloader(r.TLSConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "create grpc tls config failed")
+		}
+		r.tlsReloader = reloader
+	}
+	return &reloadableCredentials{reloader: r.tlsReloader}, nil
+}
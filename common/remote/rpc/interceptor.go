@@ -0,0 +1,67 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+)
+
+// Invoker send the request to the next interceptor of the chain, the last one sends it to the server.
+type Invoker func(ctx context.Context, request rpc_request.IRequest) (rpc_response.IResponse, error)
+
+// Interceptor is called around every request of the RpcClient, e.g. for metrics, tracing or audit logging.
+// It must call next to send the request, ctx carries the deadline of the request and a shorter one is honored,
+// the timeout of the request is used if next is called with a ctx without a deadline.
+type Interceptor func(ctx context.Context, request rpc_request.IRequest, next Invoker) (rpc_response.IResponse, error)
+
+// AddInterceptor append the interceptor to the chain, the interceptors are called in the order they are added.
+func (r *RpcClient) AddInterceptor(interceptor Interceptor) {
+	r.interceptorMutex.Lock()
+	defer r.interceptorMutex.Unlock()
+	interceptors, _ := r.interceptors.Load().([]Interceptor)
+	chain := make([]Interceptor, 0, len(interceptors)+1)
+	chain = append(chain, interceptors...)
+	r.interceptors.Store(append(chain, interceptor))
+}
+
+// invoke pass the request through the interceptors, then send it by the retry policy of the client before the
+// deadline of ctx, or the deadline of the request if the interceptors drop it.
+func (r *RpcClient) invoke(ctx context.Context, request rpc_request.IRequest) (rpc_response.IResponse, error) {
+	interceptors, _ := r.interceptors.Load().([]Interceptor)
+	requestDeadline, _ := ctx.Deadline()
+	var next Invoker
+	next = func(ctx context.Context, request rpc_request.IRequest) (rpc_response.IResponse, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = requestDeadline
+		}
+		if r.WireLog {
+			return r.wireLogRequest(request, deadline)
+		}
+		return r.request(request, deadline)
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, invoker := interceptors[i], next
+		next = func(ctx context.Context, request rpc_request.IRequest) (rpc_response.IResponse, error) {
+			return interceptor(ctx, request, invoker)
+		}
+	}
+	return next(ctx, request)
+}
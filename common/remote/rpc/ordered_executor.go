@@ -0,0 +1,76 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"sync"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+)
+
+// OrderedExecutor runs the tasks of the same key one by one in the order they are submitted, while the tasks of
+// different keys run concurrently, e.g. to keep the listen and the remove-listen requests of a config in order.
+type OrderedExecutor struct {
+	mutex sync.Mutex
+	// queues are the pending tasks of the keys, a key is present while its tasks are running
+	queues map[string][]func()
+}
+
+func NewOrderedExecutor() *OrderedExecutor {
+	return &OrderedExecutor{queues: make(map[string][]func())}
+}
+
+// Submit queue the task of the key, the returned channel is closed when the task is done.
+func (e *OrderedExecutor) Submit(key string, task func()) <-chan struct{} {
+	done := make(chan struct{})
+	e.mutex.Lock()
+	queue, running := e.queues[key]
+	e.queues[key] = append(queue, func() {
+		defer close(done)
+		task()
+	})
+	e.mutex.Unlock()
+	if !running {
+		go e.run(key)
+	}
+	return done
+}
+
+func (e *OrderedExecutor) run(key string) {
+	for {
+		e.mutex.Lock()
+		queue := e.queues[key]
+		if len(queue) == 0 {
+			delete(e.queues, key)
+			e.mutex.Unlock()
+			return
+		}
+		task := queue[0]
+		e.queues[key] = queue[1:]
+		e.mutex.Unlock()
+		runOrderedTask(key, task)
+	}
+}
+
+func runOrderedTask(key string, task func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Errorf("the ordered task of key %s panics: %v", key, err)
+		}
+	}()
+	task()
+}
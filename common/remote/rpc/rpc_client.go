@@ -18,7 +18,6 @@ package rpc
 
 import (
 	"context"
-	"math"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -28,10 +27,16 @@ import (
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
 	"github.com/jun3372/nacos-sdk-go/common/nacos_server"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
 	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
-	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
+	nacos_tls "github.com/jun3372/nacos-sdk-go/common/tls"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ConnectionType uint32
@@ -95,6 +100,9 @@ type ServerInfo struct {
 
 type RpcClient struct {
 	ctx                         context.Context
+	cancel                      context.CancelFunc
+	pendingRequests             int32
+	draining                    int32
 	name                        string
 	labels                      map[string]string
 	currentConnection           IConnection
@@ -102,6 +110,7 @@ type RpcClient struct {
 	eventChan                   chan ConnectionEvent
 	reconnectionChan            chan ReconnectContext
 	connectionEventListeners    atomic.Value
+	listenerMutex               sync.Mutex
 	lastActiveTimestamp         atomic.Value
 	executeClient               IRpcClient
 	nacosServer                 *nacos_server.NacosServer
@@ -109,6 +118,25 @@ type RpcClient struct {
 	mux                         *sync.Mutex
 	clientAbilities             rpc_request.ClientAbilities
 	Tenant                      string
+	CompressThreshold           int // the requests whose body is larger than it are sent with gzip, 0 means never
+	serverErrors                sync.Map
+	watchMutex                  sync.Mutex
+	connectionWatchers          []*func(event model.ConnectionEvent)
+	lastConnectedServer         string
+	MaxCallRecvMsgSize          int                 // the max size of the grpc messages to receive, 0 means the env or the default 10MB
+	MaxCallSendMsgSize          int                 // the max size of the grpc messages to send, 0 means the grpc default
+	TLSConfig                   constant.TLSConfig  // the connections are plaintext unless it's enabled
+	Dialer                      constant.GrpcDialer // dial the connections with it instead of tcp if it's set
+	ProxyURL                    string              // tunnel the connections through the http proxy, Dialer reaches the proxy if it's set
+	DialOptions                 []grpc.DialOption   // the extra options applied after the default ones
+	RetryPolicy                 retry.Policy        // retries the failed requests, the zero value means the default policy
+	ConnectionParams            ConnectionParams    // tunes the keepalive, window sizes, backoff and idle timeout of the connections
+	WireLog                     bool                // log the requests and responses with the secrets redacted when the logger is at the debug level
+	tlsMutex                    sync.Mutex
+	tlsReloader                 *nacos_tls.Reloader
+	interceptorMutex            sync.Mutex
+	interceptors                atomic.Value
+	healthCheckFailures         int // the consecutive failed health checks, only accessed by the health check goroutine
 }
 
 type ServerRequestHandlerMapping struct {
@@ -122,7 +150,9 @@ type ReconnectContext struct {
 }
 
 type ConnectionEvent struct {
-	eventType ConnectionStatus
+	eventType    ConnectionStatus
+	serverInfo   ServerInfo
+	connectionId string
 }
 
 func (r *RpcClient) putAllLabels(labels map[string]string) {
@@ -182,7 +212,7 @@ func (r *RpcClient) Start() {
 	}()
 
 	go func() {
-		timer := time.NewTimer(5 * time.Second)
+		timer := time.NewTimer(r.ConnectionParams.healthCheckInterval())
 		for {
 			select {
 			case rc := <-r.reconnectionChan:
@@ -207,6 +237,7 @@ func (r *RpcClient) Start() {
 			case <-r.nacosServer.ServerSrcChangeSignal:
 				r.notifyServerSrvChange()
 			case <-r.ctx.Done():
+				timer.Stop()
 				return
 			}
 		}
@@ -223,6 +254,7 @@ func (r *RpcClient) Start() {
 		}
 		logger.Infof("[RpcClient.Start] %s try to connect to server on start up, server: %+v", r.name, serverInfo)
 		if connection, err := r.executeClient.connectToServer(serverInfo); err != nil {
+			r.recordServerError(serverInfo, err)
 			logger.Warnf("[RpcClient.Start] %s fail to connect to server on start up, error message=%v, "+
 				"start up retry times left=%d", r.name, err.Error(), startUpRetryTimes)
 		} else {
@@ -235,14 +267,18 @@ func (r *RpcClient) Start() {
 			currentConnection.getServerInfo(), currentConnection.getConnectionId())
 		r.currentConnection = currentConnection
 		atomic.StoreInt32((*int32)(&r.rpcClientStatus), (int32)(RUNNING))
-		r.notifyConnectionChange(CONNECTED)
+		r.notifyConnectionChange(CONNECTED, currentConnection)
 	} else {
 		r.switchServerAsync(ServerInfo{}, false)
 	}
 }
 
-func (r *RpcClient) notifyConnectionChange(eventType ConnectionStatus) {
-	r.eventChan <- ConnectionEvent{eventType: eventType}
+func (r *RpcClient) notifyConnectionChange(eventType ConnectionStatus, connection IConnection) {
+	select {
+	case r.eventChan <- ConnectionEvent{eventType: eventType, serverInfo: connection.getServerInfo(),
+		connectionId: connection.getConnectionId()}:
+	case <-r.ctx.Done():
+	}
 }
 
 func (r *RpcClient) notifyServerSrvChange() {
@@ -263,21 +299,55 @@ func (r *RpcClient) notifyServerSrvChange() {
 	}
 }
 
+// registerServerRequestHandlers register the internal handlers, unless the types are registered before Start.
 func (r *RpcClient) registerServerRequestHandlers() {
 	// register ConnectResetRequestHandler.
-	r.RegisterServerRequestHandler(func() rpc_request.IRequest {
+	r.registerDefaultHandler(func() rpc_request.IRequest {
 		return &rpc_request.ConnectResetRequest{InternalRequest: rpc_request.NewInternalRequest()}
 	}, &ConnectResetRequestHandler{})
 
 	// register client detection request.
-	r.RegisterServerRequestHandler(func() rpc_request.IRequest {
+	r.registerDefaultHandler(func() rpc_request.IRequest {
 		return &rpc_request.ClientDetectionRequest{InternalRequest: rpc_request.NewInternalRequest()}
 	}, &ClientDetectionRequestHandler{})
 }
 
-func (r *RpcClient) Shutdown() {
+func (r *RpcClient) registerDefaultHandler(request func() rpc_request.IRequest, handler IServerRequestHandler) {
+	if _, ok := r.serverRequestHandlerMapping.Load(request().GetRequestType()); ok {
+		return
+	}
+	r.RegisterServerRequestHandler(request, handler)
+}
+
+// Shutdown reject the new requests, wait the pending requests to finish until ctx is done, then stop the
+// reconnection and the health check and close the connection. The connection keeps running while the pending
+// requests drain. The client is removed, so CreateClient creates a new one for the name.
+func (r *RpcClient) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&r.draining, 0, 1) {
+		return nil
+	}
+	cMux.Lock()
+	if client, ok := clientMap[r.name]; ok && client.GetRpcClient() == r {
+		delete(clientMap, r.name)
+	}
+	cMux.Unlock()
+
+	var err error
+	ticker := time.NewTicker(10 * time.Millisecond)
+	for atomic.LoadInt32(&r.pendingRequests) > 0 && err == nil {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			err = errors.Wrapf(ctx.Err(), "%s shutdown with %d pending requests", r.name, atomic.LoadInt32(&r.pendingRequests))
+		}
+	}
+	ticker.Stop()
 	atomic.StoreInt32((*int32)(&r.rpcClientStatus), (int32)(SHUTDOWN))
 	r.closeConnection()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
 }
 
 func (r *RpcClient) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler IServerRequestHandler) {
@@ -296,12 +366,27 @@ func (r *RpcClient) RegisterServerRequestHandler(request func() rpc_request.IReq
 
 func (r *RpcClient) RegisterConnectionListener(listener IConnectionEventListener) {
 	logger.Debugf("%s register connection listener [%+v] to current client", r.name, reflect.TypeOf(listener))
+	r.listenerMutex.Lock()
+	defer r.listenerMutex.Unlock()
 	listeners := r.connectionEventListeners.Load()
 	connectionEventListeners := listeners.([]IConnectionEventListener)
 	connectionEventListeners = append(connectionEventListeners, listener)
 	r.connectionEventListeners.Store(connectionEventListeners)
 }
 
+// RemoveConnectionListener remove the listener added by RegisterConnectionListener.
+func (r *RpcClient) RemoveConnectionListener(listener IConnectionEventListener) {
+	r.listenerMutex.Lock()
+	defer r.listenerMutex.Unlock()
+	var connectionEventListeners []IConnectionEventListener
+	for _, l := range r.connectionEventListeners.Load().([]IConnectionEventListener) {
+		if l != listener {
+			connectionEventListeners = append(connectionEventListeners, l)
+		}
+	}
+	r.connectionEventListeners.Store(connectionEventListeners)
+}
+
 func (r *RpcClient) switchServerAsync(recommendServerInfo ServerInfo, onRequestFail bool) {
 	r.reconnectionChan <- ReconnectContext{serverInfo: recommendServerInfo, onRequestFail: onRequestFail}
 }
@@ -331,6 +416,7 @@ func (r *RpcClient) reconnect(serverInfo ServerInfo, onRequestFail bool) {
 			}
 		}
 		connectionNew, err := r.executeClient.connectToServer(serverInfo)
+		r.recordServerError(serverInfo, err)
 		if connectionNew != nil && err == nil {
 			logger.Infof("%s success to connect a server %+v, connectionId=%s", r.name, serverInfo,
 				connectionNew.getConnectionId())
@@ -343,7 +429,7 @@ func (r *RpcClient) reconnect(serverInfo ServerInfo, onRequestFail bool) {
 			}
 			r.currentConnection = connectionNew
 			atomic.StoreInt32((*int32)(&r.rpcClientStatus), (int32)(RUNNING))
-			r.notifyConnectionChange(CONNECTED)
+			r.notifyConnectionChange(CONNECTED, connectionNew)
 			return
 		}
 		if r.isShutdown() {
@@ -352,13 +438,14 @@ func (r *RpcClient) reconnect(serverInfo ServerInfo, onRequestFail bool) {
 		if reConnectTimes > 0 && reConnectTimes%len(r.nacosServer.GetServerList()) == 0 {
 			logger.Warnf("%s fail to connect server, after trying %d times, last try server is %+v, error=%v", r.name,
 				reConnectTimes, serverInfo, err)
-			if retryTurns < 50 {
-				retryTurns++
-			}
+			retryTurns++
 		}
 		reConnectTimes++
 		if !r.IsRunning() {
-			time.Sleep(time.Duration((math.Min(float64(retryTurns), 50))*100) * time.Millisecond)
+			select {
+			case <-time.After(r.ConnectionParams.reconnectDelay(retryTurns)):
+			case <-r.ctx.Done():
+			}
 		}
 	}
 	if r.isShutdown() {
@@ -369,17 +456,18 @@ func (r *RpcClient) reconnect(serverInfo ServerInfo, onRequestFail bool) {
 func (r *RpcClient) closeConnection() {
 	if r.currentConnection != nil {
 		r.currentConnection.close()
-		r.notifyConnectionChange(DISCONNECTED)
+		r.notifyConnectionChange(DISCONNECTED, r.currentConnection)
 	}
 }
 
 // Notify when client new connected.
 func (r *RpcClient) notifyConnectionEvent(event ConnectionEvent) {
+	r.notifyConnectionWatchers(event)
 	listeners := r.connectionEventListeners.Load().([]IConnectionEventListener)
 	if len(listeners) == 0 {
 		return
 	}
-	logger.Infof("%s notify %s event to listeners , connectionId=%s", r.name, event.toString(), r.currentConnection.getConnectionId())
+	logger.Infof("%s notify %s event to listeners , connectionId=%s", r.name, event.toString(), event.connectionId)
 	for _, v := range listeners {
 		if event.isConnected() {
 			v.OnConnected()
@@ -391,19 +479,27 @@ func (r *RpcClient) notifyConnectionEvent(event ConnectionEvent) {
 }
 
 func (r *RpcClient) healthCheck(timer *time.Timer) {
-	defer timer.Reset(constant.KEEP_ALIVE_TIME * time.Second)
+	defer timer.Reset(r.ConnectionParams.healthCheckInterval())
+	r.failbackIfNeed()
 	var reconnectContext ReconnectContext
-	lastActiveTimeStamp := r.lastActiveTimestamp.Load().(time.Time)
-	if time.Now().Sub(lastActiveTimeStamp) < constant.KEEP_ALIVE_TIME*time.Second {
+	if !r.needHealthCheck() {
 		return
 	}
-	if r.sendHealthCheck() {
+	if r.sendHealthCheck() && (!r.streamIdle() || r.probeStream()) {
 		r.lastActiveTimestamp.Store(time.Now())
+		r.healthCheckFailures = 0
 		return
 	} else {
 		if r.currentConnection == nil || r.isShutdown() {
 			return
 		}
+		r.healthCheckFailures++
+		if r.healthCheckFailures < r.ConnectionParams.healthCheckFailures() {
+			logger.Warnf("%s server healthy check fail %d times, currentConnection=%s", r.name, r.healthCheckFailures,
+				r.currentConnection.getConnectionId())
+			return
+		}
+		r.healthCheckFailures = 0
 		logger.Infof("%s server healthy check fail, currentConnection=%s", r.name, r.currentConnection.getConnectionId())
 		atomic.StoreInt32((*int32)(&r.rpcClientStatus), (int32)(UNHEALTHY))
 		reconnectContext = ReconnectContext{onRequestFail: false}
@@ -411,13 +507,47 @@ func (r *RpcClient) healthCheck(timer *time.Timer) {
 	r.reconnect(reconnectContext.serverInfo, reconnectContext.onRequestFail)
 }
 
+// needHealthCheck return true if no request succeeded within the interval, or no push is received within
+// ConnectionParams.ProbeIdle, since the requests may succeed on a connection whose stream is half-open.
+func (r *RpcClient) needHealthCheck() bool {
+	if r.healthCheckFailures > 0 {
+		return true
+	}
+	lastActiveTimeStamp := r.lastActiveTimestamp.Load().(time.Time)
+	if time.Since(lastActiveTimeStamp) >= r.ConnectionParams.healthCheckInterval() {
+		return true
+	}
+	return r.streamIdle()
+}
+
+// streamIdle return true if no push is received within ConnectionParams.ProbeIdle, the stream is probed besides
+// the health check request then.
+func (r *RpcClient) streamIdle() bool {
+	connection := r.currentConnection
+	return r.ConnectionParams.ProbeIdle > 0 && connection != nil &&
+		time.Since(connection.getLastReceived()) >= r.ConnectionParams.ProbeIdle
+}
+
+func (r *RpcClient) probeStream() bool {
+	connection := r.currentConnection
+	if connection == nil {
+		return false
+	}
+	if err := connection.probeStream(); err != nil {
+		logger.Errorf("%s probe the stream failed, connectionId=%s, err=%v", r.name, connection.getConnectionId(), err)
+		return false
+	}
+	return true
+}
+
 func (r *RpcClient) sendHealthCheck() bool {
 	if r.currentConnection == nil {
 		return false
 	}
 	response, err := r.currentConnection.request(rpc_request.NewHealthCheckRequest(),
-		constant.DEFAULT_TIMEOUT_MILLS, r)
+		r.ConnectionParams.healthCheckTimeoutMills(), r)
 	if err != nil {
+		r.recordServerError(r.currentConnection.getServerInfo(), err)
 		logger.Errorf("client sendHealthCheck failed,err=%v", err)
 		return false
 	}
@@ -477,55 +607,108 @@ func (c *ConnectionEvent) toString() string {
 	return ""
 }
 
+// Request send the request within the timeout, it's retried by the RetryPolicy of the client until the
+// attempts or the timeout run out, the error tells the number of the attempts. The timeout is shared by
+// all the attempts and the servers switched to, a *nacos_error.TimeoutError is returned when it runs out.
+// The request passes through the interceptors before it's sent.
 func (r *RpcClient) Request(request rpc_request.IRequest, timeoutMills int64) (rpc_response.IResponse, error) {
-	retryTimes := 0
-	start := util.CurrentMillis()
-	var currentErr error
-	for retryTimes < constant.REQUEST_DOMAIN_RETRY_TIME && util.CurrentMillis() < start+timeoutMills {
-		if r.currentConnection == nil || !r.IsRunning() {
-			currentErr = waitReconnect(timeoutMills, &retryTimes, request,
-				errors.Errorf("client not connected, current status:%s", r.rpcClientStatus.getDesc()))
-			continue
+	atomic.AddInt32(&r.pendingRequests, 1)
+	defer atomic.AddInt32(&r.pendingRequests, -1)
+	if atomic.LoadInt32(&r.draining) == 1 {
+		return nil, errors.Errorf("%s request %s rejected, the client is shutting down", r.name, request.GetRequestType())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMills)*time.Millisecond)
+	defer cancel()
+	return r.invoke(ctx, request)
+}
+
+func (r *RpcClient) request(request rpc_request.IRequest, deadline time.Time) (rpc_response.IResponse, error) {
+	start := time.Now()
+	var (
+		attempts   int
+		retryable  bool
+		timedOut   bool
+		lastServer string
+		err        error
+		response   rpc_response.IResponse
+	)
+	for {
+		attempts++
+		if connection := r.currentConnection; connection != nil {
+			serverInfo := connection.getServerInfo()
+			lastServer = serverAddress(serverInfo.serverIp, serverInfo.serverPort)
 		}
-		response, err := r.currentConnection.request(request, timeoutMills, r)
-		if err != nil {
-			currentErr = waitReconnect(timeoutMills, &retryTimes, request, err)
-			continue
+		response, retryable, err = r.requestOnce(request, deadline)
+		if err == nil {
+			return response, nil
 		}
-		if resp, ok := response.(*rpc_response.ErrorResponse); ok {
-			if resp.GetErrorCode() == constant.UN_REGISTER {
-				r.mux.Lock()
-				if atomic.CompareAndSwapInt32((*int32)(&r.rpcClientStatus), (int32)(RUNNING), (int32)(UNHEALTHY)) {
-					logger.Infof("Connection is unregistered, switch server, connectionId=%s, request=%s",
-						r.currentConnection.getConnectionId(), request.GetRequestType())
-					r.switchServerAsync(ServerInfo{}, false)
-				}
-				r.mux.Unlock()
-			}
-			currentErr = waitReconnect(timeoutMills, &retryTimes, request, errors.New(response.GetMessage()))
-			continue
+		logger.Errorf("Send request fail, request=%s, body=%s, attempts=%v, error=%+v", request.GetRequestType(), redactBody(request.GetBody(request)), attempts, err)
+		if !retryable || attempts >= r.RetryPolicy.Attempts() {
+			break
 		}
-		if response != nil && !response.IsSuccess() {
-			logger.Warnf("%s request received fail response, error code: %d, result code: %d, message: [%s]", request.GetRequestType(), response.GetErrorCode(), response.GetResultCode(), response.GetMessage())
+		if !r.RetryPolicy.Wait(attempts, deadline) {
+			timedOut = true
+			break
 		}
-		r.lastActiveTimestamp.Store(time.Now())
-		return response, nil
 	}
-
-	if atomic.CompareAndSwapInt32((*int32)(&r.rpcClientStatus), int32(RUNNING), int32(UNHEALTHY)) {
+	if retryable && atomic.CompareAndSwapInt32((*int32)(&r.rpcClientStatus), int32(RUNNING), int32(UNHEALTHY)) {
 		r.switchServerAsync(ServerInfo{}, true)
 	}
-	if currentErr != nil {
-		return nil, currentErr
+	if timedOut || !time.Now().Before(deadline) || status.Code(err) == codes.DeadlineExceeded {
+		return nil, nacos_error.NewTimeoutError(time.Since(start), attempts, lastServer,
+			errors.Wrapf(err, "%s request", request.GetRequestType()))
 	}
-	return nil, errors.New("request fail, unknown error")
+	return nil, errors.Wrapf(err, "%s request failed after %d attempts", request.GetRequestType(), attempts)
 }
 
-func waitReconnect(timeoutMills int64, retryTimes *int, request rpc_request.IRequest, err error) error {
-	logger.Errorf("Send request fail, request=%s, body=%s, retryTimes=%v, error=%+v", request.GetRequestType(), request.GetBody(request), *retryTimes, err)
-	time.Sleep(time.Duration(math.Min(100, float64(timeoutMills/3))) * time.Millisecond)
-	*retryTimes++
-	return err
+// ErrRequestNotSupported is the cause of the error when the server has no handler of the request.
+var ErrRequestNotSupported = errors.New("the request is not supported by the server")
+
+// IsRequestNotSupported reports whether the request failed for the server doesn't support it, the caller can
+// fall back to the other apis immediately.
+func IsRequestNotSupported(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrRequestNotSupported || status.Code(cause) == codes.Unimplemented
+}
+
+// requestOnce send the request on the current connection with the time left, the failure is retryable unless
+// the message is too large, the request is not supported, or the server rejects it with a code the retry policy
+// doesn't retry.
+func (r *RpcClient) requestOnce(request rpc_request.IRequest, deadline time.Time) (rpc_response.IResponse, bool, error) {
+	if r.currentConnection == nil || !r.IsRunning() {
+		return nil, true, errors.Errorf("client not connected, current status:%s", r.rpcClientStatus.getDesc())
+	}
+	timeoutMills := time.Until(deadline).Milliseconds()
+	if timeoutMills <= 0 {
+		return nil, false, errors.New("request timeout")
+	}
+	response, err := r.currentConnection.request(request, timeoutMills, r)
+	if err != nil {
+		r.recordServerError(r.currentConnection.getServerInfo(), err)
+		// the message exceeds the size limit, retrying or switching server doesn't help.
+		code := status.Code(err)
+		return nil, code != codes.ResourceExhausted && code != codes.Unimplemented, err
+	}
+	if resp, ok := response.(*rpc_response.ErrorResponse); ok {
+		if resp.GetErrorCode() == constant.UN_REGISTER {
+			r.mux.Lock()
+			if atomic.CompareAndSwapInt32((*int32)(&r.rpcClientStatus), (int32)(RUNNING), (int32)(UNHEALTHY)) {
+				logger.Infof("Connection is unregistered, switch server, connectionId=%s, request=%s",
+					r.currentConnection.getConnectionId(), request.GetRequestType())
+				r.switchServerAsync(ServerInfo{}, false)
+			}
+			r.mux.Unlock()
+		}
+		if resp.GetErrorCode() == constant.NO_HANDLER {
+			return nil, false, errors.Wrap(ErrRequestNotSupported, response.GetMessage())
+		}
+		return nil, r.RetryPolicy.RetryableCode(resp.GetErrorCode()), errors.New(response.GetMessage())
+	}
+	if response != nil && !response.IsSuccess() {
+		logger.Warnf("%s request received fail response, error code: %d, result code: %d, message: [%s]", request.GetRequestType(), response.GetErrorCode(), response.GetResultCode(), response.GetMessage())
+	}
+	r.lastActiveTimestamp.Store(time.Now())
+	return response, false, nil
 }
 
 func (r *RpcClient) Name() string {
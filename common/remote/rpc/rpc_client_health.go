@@ -0,0 +1,135 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/model"
+)
+
+type serverError struct {
+	err  string
+	time time.Time
+}
+
+func (status RpcClientStatus) String() string {
+	return status.getDesc()
+}
+
+func serverAddress(ip string, port uint64) string {
+	return net.JoinHostPort(ip, strconv.FormatUint(port, 10))
+}
+
+// recordServerError keep the last error of the server for ServerHealth.
+func (r *RpcClient) recordServerError(serverInfo ServerInfo, err error) {
+	if err == nil {
+		return
+	}
+	r.serverErrors.Store(serverAddress(serverInfo.serverIp, serverInfo.serverPort), serverError{err: err.Error(), time: time.Now()})
+	if r.nacosServer != nil {
+		r.nacosServer.ReportServerError(serverInfo.serverIp, serverInfo.serverPort)
+	}
+}
+
+// failbackIfNeed move the connection back to a server of the preferred zone once it has been stable for the
+// failback window, the current connection is kept if the preferred server is still unreachable or unhealthy.
+func (r *RpcClient) failbackIfNeed() {
+	current := r.currentConnection
+	if r.nacosServer == nil || current == nil || !r.IsRunning() {
+		return
+	}
+	currentInfo := current.getServerInfo()
+	preferred, ok := r.nacosServer.FailbackServer(currentInfo.serverIp, currentInfo.serverPort)
+	if !ok {
+		return
+	}
+	serverInfo := ServerInfo{serverIp: preferred.IpAddr, serverPort: preferred.Port, serverGrpcPort: preferred.GrpcPort}
+	connection, err := r.executeClient.connectToServer(serverInfo)
+	if err == nil {
+		err = r.checkConnectionHealth(connection)
+		if err != nil {
+			connection.close()
+		}
+	}
+	if err != nil {
+		r.recordServerError(serverInfo, err)
+		logger.Warnf("%s fail back to the preferred server %+v failed, keep the current server %+v, error=%v",
+			r.name, serverInfo, currentInfo, err)
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	// the connection is reset or switched while the preferred server is checked
+	if r.currentConnection != current || !r.IsRunning() {
+		logger.Infof("%s the connection is changed, give up failing back to the preferred server %+v", r.name, serverInfo)
+		connection.close()
+		return
+	}
+	logger.Infof("%s fail back to the preferred server %+v, connectionId=%s", r.name, serverInfo, connection.getConnectionId())
+	r.currentConnection.setAbandon(true)
+	r.closeConnection()
+	r.currentConnection = connection
+	r.notifyConnectionChange(CONNECTED, connection)
+}
+
+// checkConnectionHealth send a health check request on the connection, the server must answer it successfully.
+func (r *RpcClient) checkConnectionHealth(connection IConnection) error {
+	response, err := connection.request(rpc_request.NewHealthCheckRequest(), r.ConnectionParams.healthCheckTimeoutMills(), r)
+	if err != nil {
+		return err
+	}
+	if response == nil || !response.IsSuccess() {
+		return errors.New("the server failed the health check")
+	}
+	return nil
+}
+
+// Status return the current status of the rpc client.
+func (r *RpcClient) Status() RpcClientStatus {
+	return RpcClientStatus(atomic.LoadInt32((*int32)(&r.rpcClientStatus)))
+}
+
+// ServerHealth return the connectivity to each server in the server list, and the last error seen on it.
+func (r *RpcClient) ServerHealth() model.ServerHealth {
+	health := model.ServerHealth{Healthy: r.IsRunning(), ConnectionState: r.Status().String()}
+	var current string
+	if connection := r.currentConnection; connection != nil && health.Healthy {
+		current = serverAddress(connection.getServerInfo().serverIp, connection.getServerInfo().serverPort)
+	}
+	if r.nacosServer == nil {
+		return health
+	}
+	for _, server := range r.nacosServer.GetServerList() {
+		status := model.ServerStatus{Address: serverAddress(server.IpAddr, server.Port)}
+		status.Connected = status.Address == current
+		if v, ok := r.serverErrors.Load(status.Address); ok {
+			status.LastError = v.(serverError).err
+			status.LastErrorTime = v.(serverError).time
+		}
+		health.Servers = append(health.Servers, status)
+	}
+	return health
+}
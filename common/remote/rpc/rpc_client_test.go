@@ -1,7 +1,725 @@
 package rpc
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nacos_grpc_service "github.com/jun3372/nacos-sdk-go/api/grpc"
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/http_agent"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/nacos_error"
+	"github.com/jun3372/nacos-sdk-go/common/nacos_server"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
 
 func TestHealthCheck(t *testing.T) {
 
 }
+
+type serverInfoConnection struct {
+	MockConnection
+	serverInfo ServerInfo
+}
+
+func (c *serverInfoConnection) getServerInfo() ServerInfo {
+	return c.serverInfo
+}
+
+func (c *serverInfoConnection) getAbandon() bool {
+	return false
+}
+
+func TestServerHealth(t *testing.T) {
+	serverList := []constant.ServerConfig{{IpAddr: "127.0.0.1", Port: 8848}, {IpAddr: "127.0.0.2", Port: 8848}}
+	nacosServer, err := nacos_server.NewNacosServer(context.Background(), serverList, constant.ClientConfig{},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+	client := &RpcClient{nacosServer: nacosServer, rpcClientStatus: RUNNING,
+		currentConnection: &serverInfoConnection{serverInfo: ServerInfo{serverIp: "127.0.0.1", serverPort: 8848}}}
+	client.recordServerError(ServerInfo{serverIp: "127.0.0.2", serverPort: 8848}, errors.New("connection refused"))
+
+	health := client.ServerHealth()
+	assert.True(t, health.Healthy)
+	assert.Equal(t, "RUNNING", health.ConnectionState)
+	assert.Equal(t, 2, len(health.Servers))
+	assert.True(t, health.Servers[0].Connected)
+	assert.Equal(t, "", health.Servers[0].LastError)
+	assert.False(t, health.Servers[1].Connected)
+	assert.Equal(t, "connection refused", health.Servers[1].LastError)
+}
+
+type exhaustedConnection struct {
+	serverInfoConnection
+	requests int
+}
+
+func (c *exhaustedConnection) request(request rpc_request.IRequest, timeoutMills int64, client *RpcClient) (rpc_response.IResponse, error) {
+	c.requests++
+	return nil, status.Error(codes.ResourceExhausted, "grpc: received message larger than max")
+}
+
+func TestRequestResourceExhausted(t *testing.T) {
+	conn := &exhaustedConnection{}
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: conn}
+	_, err := client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, 1, conn.requests)
+	assert.True(t, client.IsRunning())
+}
+
+type recordingLogger struct {
+	logger.Logger
+	mutex  sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRequestFailureRedacted(t *testing.T) {
+	origin := logger.GetLogger()
+	defer logger.SetLogger(origin)
+	recorder := &recordingLogger{Logger: origin}
+	logger.SetLogger(recorder)
+
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: &exhaustedConnection{}}
+	_, err := client.Request(rpc_request.NewConfigPublishRequest("group", "dataId", "", "password=123", ""), 3000)
+	assert.NotNil(t, err)
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	assert.NotEmpty(t, recorder.errors)
+	for _, message := range recorder.errors {
+		assert.NotContains(t, message, "password=123")
+	}
+}
+
+func TestCustomDialer(t *testing.T) {
+	dialed := make(chan string, 1)
+	client := NewGrpcClient(context.Background(), "dialer-test", nil)
+	client.Dialer = func(ctx context.Context, address string) (net.Conn, error) {
+		select {
+		case dialed <- address:
+		default:
+		}
+		return nil, errors.New("dial refused")
+	}
+	conn, err := client.createNewConnection(ServerInfo{serverIp: "127.0.0.1", serverPort: 8848})
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.Connect()
+
+	select {
+	case address := <-dialed:
+		assert.Equal(t, "127.0.0.1:9848", address)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the custom dialer is not used")
+	}
+}
+
+func TestConnectionParams(t *testing.T) {
+	params := NewConnectionParams(constant.ClientConfig{GrpcKeepAliveTimeMs: 10000, GrpcMaxBackoffMs: 1000, GrpcIdleTimeoutMs: 30000})
+	assert.Equal(t, 10*time.Second, params.KeepAliveTime)
+	assert.Equal(t, 5, len(params.dialOptions()))
+	assert.Equal(t, 300*time.Millisecond, params.reconnectDelay(3))
+	assert.Equal(t, time.Second, params.reconnectDelay(50))
+
+	defaults := NewConnectionParams(constant.ClientConfig{})
+	assert.Equal(t, 3, len(defaults.dialOptions()))
+	assert.Equal(t, defaultMaxReconnectBackoff, defaults.reconnectDelay(100))
+}
+
+type healthCheckedConnection struct {
+	serverInfoConnection
+	healthy bool
+	closed  bool
+}
+
+func (c *healthCheckedConnection) request(request rpc_request.IRequest, timeoutMills int64, client *RpcClient) (rpc_response.IResponse, error) {
+	return &rpc_response.HealthCheckResponse{Response: &rpc_response.Response{Success: c.healthy}}, nil
+}
+
+func (c *healthCheckedConnection) close() {
+	c.closed = true
+}
+
+type failbackRpcClient struct {
+	*GrpcClient
+	connection *healthCheckedConnection
+}
+
+func (c *failbackRpcClient) connectToServer(serverInfo ServerInfo) (IConnection, error) {
+	c.connection.serverInfo = serverInfo
+	return c.connection, nil
+}
+
+func TestFailback(t *testing.T) {
+	servers := []constant.ServerConfig{{IpAddr: "10.0.0.1", Port: 8848, Zone: "a"}, {IpAddr: "10.0.1.1", Port: 8848, Zone: "b"}}
+	nacosServer, err := nacos_server.NewNacosServer(context.Background(), servers, constant.ClientConfig{PreferredZone: "a", ServerFailbackMs: 50},
+		&http_agent.HttpAgent{}, 1000, "", nil)
+	assert.Nil(t, err)
+	current := &healthCheckedConnection{serverInfoConnection: serverInfoConnection{serverInfo: ServerInfo{serverIp: "10.0.1.1", serverPort: 8848}}}
+	executeClient := &failbackRpcClient{connection: &healthCheckedConnection{}}
+	client := &RpcClient{ctx: context.Background(), name: "failback-test", nacosServer: nacosServer, rpcClientStatus: RUNNING, currentConnection: current,
+		executeClient: executeClient, mux: &sync.Mutex{}, eventChan: make(chan ConnectionEvent, 2)}
+
+	// the preferred server failing the health check is not switched to
+	client.failbackIfNeed()
+	assert.Same(t, current, client.currentConnection)
+	assert.True(t, executeClient.connection.closed)
+	assert.False(t, current.closed)
+
+	// the preferred server is tried again once it's stable for the failback window
+	preferred := &healthCheckedConnection{healthy: true}
+	executeClient.connection = preferred
+	client.failbackIfNeed()
+	assert.Same(t, current, client.currentConnection)
+	time.Sleep(60 * time.Millisecond)
+	client.failbackIfNeed()
+	assert.Same(t, preferred, client.currentConnection)
+	assert.True(t, current.closed)
+	assert.False(t, preferred.closed)
+	assert.Equal(t, "10.0.0.1", client.currentConnection.getServerInfo().serverIp)
+}
+
+type idleConnection struct {
+	serverInfoConnection
+}
+
+func (c *idleConnection) getLastReceived() time.Time {
+	return time.Now().Add(-time.Minute)
+}
+
+func TestHealthCheckParams(t *testing.T) {
+	params := NewConnectionParams(constant.ClientConfig{GrpcHealthCheckMs: 2000, GrpcHealthTimeoutMs: 500,
+		GrpcHealthFailures: 3, GrpcProbeIdleMs: 10000})
+	assert.Equal(t, 2*time.Second, params.healthCheckInterval())
+	assert.Equal(t, int64(500), params.healthCheckTimeoutMills())
+	assert.Equal(t, 3, params.healthCheckFailures())
+
+	defaults := NewConnectionParams(constant.ClientConfig{})
+	assert.Equal(t, constant.KEEP_ALIVE_TIME*time.Second, defaults.healthCheckInterval())
+	assert.Equal(t, 1, defaults.healthCheckFailures())
+
+	client := &RpcClient{ConnectionParams: params, currentConnection: &serverInfoConnection{}}
+	client.lastActiveTimestamp.Store(time.Now())
+	assert.False(t, client.needHealthCheck())
+	client.currentConnection = &idleConnection{}
+	assert.True(t, client.needHealthCheck())
+	client.healthCheckFailures = 1
+	client.currentConnection = &serverInfoConnection{}
+	assert.True(t, client.needHealthCheck())
+}
+
+type brokenStreamConnection struct {
+	healthCheckedConnection
+}
+
+func (c *brokenStreamConnection) getLastReceived() time.Time {
+	return time.Now().Add(-time.Minute)
+}
+
+func (c *brokenStreamConnection) probeStream() error {
+	return errors.New("stream broken")
+}
+
+type probeStream struct {
+	setupRecordingStream
+	ctx context.Context
+}
+
+func (s *probeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestProbeStream(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	grpcClient := &GrpcClient{RpcClient: &RpcClient{Dialer: func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}}}
+	conn, err := grpcClient.createNewConnection(ServerInfo{serverIp: "127.0.0.1", serverPort: 8848})
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.Connect()
+	waitState := func(ready bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for state := conn.GetState(); (state == connectivity.Ready) != ready; state = conn.GetState() {
+			if !conn.WaitForStateChange(ctx, state) {
+				t.Fatalf("the transport stays %s", state)
+			}
+		}
+	}
+	waitState(true)
+
+	// the stream accepts the sends but never delivers anything
+	stream := &probeStream{ctx: context.Background()}
+	grpcConn := NewGrpcConnection(ServerInfo{}, "", conn, nil, stream)
+	lastReceived := time.Now().Add(-time.Minute)
+	grpcConn.setLastReceived(lastReceived)
+	assert.Nil(t, grpcConn.probeStream())
+	assert.Equal(t, lastReceived.UnixNano(), grpcConn.getLastReceived().UnixNano())
+	assert.Empty(t, stream.payloads)
+
+	// the transport isn't ready once the server stops answering
+	server.Stop()
+	waitState(false)
+	assert.NotNil(t, grpcConn.probeStream())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NotNil(t, NewGrpcConnection(ServerInfo{}, "", nil, nil, &probeStream{ctx: ctx}).probeStream())
+
+	params := NewConnectionParams(constant.ClientConfig{GrpcHealthFailures: 3, GrpcProbeIdleMs: 10000})
+	client := &RpcClient{ConnectionParams: params, rpcClientStatus: RUNNING,
+		currentConnection: &brokenStreamConnection{healthCheckedConnection{healthy: true}}}
+	client.lastActiveTimestamp.Store(time.Now())
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	client.healthCheck(timer)
+	assert.Equal(t, 1, client.healthCheckFailures)
+
+	client = &RpcClient{ConnectionParams: params, rpcClientStatus: RUNNING,
+		currentConnection: &healthCheckedConnection{healthy: true}}
+	client.lastActiveTimestamp.Store(time.Now().Add(-time.Hour))
+	client.healthCheck(timer)
+	assert.Equal(t, 0, client.healthCheckFailures)
+}
+
+func TestConnectionWatchers(t *testing.T) {
+	client := &RpcClient{name: "watcher-test"}
+	var events []model.ConnectionEvent
+	callback := func(event model.ConnectionEvent) {
+		events = append(events, event)
+	}
+	client.WatchConnectionEvents(&callback)
+
+	first, second := ServerInfo{serverIp: "127.0.0.1", serverPort: 8848}, ServerInfo{serverIp: "127.0.0.2", serverPort: 8848}
+	client.notifyConnectionWatchers(ConnectionEvent{eventType: CONNECTED, serverInfo: first, connectionId: "1"})
+	client.notifyConnectionWatchers(ConnectionEvent{eventType: DISCONNECTED, serverInfo: first, connectionId: "1"})
+	client.notifyConnectionWatchers(ConnectionEvent{eventType: CONNECTED, serverInfo: first, connectionId: "2"})
+	client.notifyConnectionWatchers(ConnectionEvent{eventType: CONNECTED, serverInfo: second, connectionId: "3"})
+	client.UnwatchConnectionEvents(&callback)
+	client.notifyConnectionWatchers(ConnectionEvent{eventType: DISCONNECTED, serverInfo: second, connectionId: "3"})
+
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, constant.CONNECTION_EVENT_CONNECTED, events[0].Type)
+	assert.Equal(t, constant.CONNECTION_EVENT_DISCONNECTED, events[1].Type)
+	assert.Equal(t, constant.CONNECTION_EVENT_RECONNECTED, events[2].Type)
+	assert.Equal(t, "2", events[2].ConnectionId)
+	assert.Equal(t, constant.CONNECTION_EVENT_SWITCHED, events[3].Type)
+	assert.Equal(t, "127.0.0.2:8848", events[3].Server)
+	assert.Equal(t, "127.0.0.1:8848", events[3].PreviousServer)
+}
+
+type countingConnectionListener struct {
+	connected int
+}
+
+func (l *countingConnectionListener) OnConnected() {
+	l.connected++
+}
+
+func (l *countingConnectionListener) OnDisConnect() {}
+
+func TestRemoveConnectionListener(t *testing.T) {
+	client := &RpcClient{name: "listener-test"}
+	client.connectionEventListeners.Store([]IConnectionEventListener{})
+	first, second := &countingConnectionListener{}, &countingConnectionListener{}
+	client.RegisterConnectionListener(first)
+	client.RegisterConnectionListener(second)
+	client.RemoveConnectionListener(first)
+	listeners := client.connectionEventListeners.Load().([]IConnectionEventListener)
+	assert.Equal(t, []IConnectionEventListener{second}, listeners)
+}
+
+type errorResponseConnection struct {
+	serverInfoConnection
+	requests int
+	code     int
+}
+
+func (c *errorResponseConnection) request(request rpc_request.IRequest, timeoutMills int64, client *RpcClient) (rpc_response.IResponse, error) {
+	c.requests++
+	code := c.code
+	if code == 0 {
+		code = 500
+	}
+	return &rpc_response.ErrorResponse{Response: &rpc_response.Response{ErrorCode: code, Message: "server error"}}, nil
+}
+
+func TestRequestRetryPolicy(t *testing.T) {
+	conn := &errorResponseConnection{}
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: conn, mux: new(sync.Mutex),
+		reconnectionChan: make(chan ReconnectContext, 1), RetryPolicy: retry.Policy{MaxAttempts: 2, Backoff: time.Millisecond}}
+	_, err := client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+	assert.Equal(t, 2, conn.requests)
+
+	conn.requests = 0
+	client.rpcClientStatus = RUNNING
+	client.RetryPolicy.RetryCodes = []int{503}
+	_, err = client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Contains(t, err.Error(), "after 1 attempts")
+	assert.Equal(t, 1, conn.requests)
+	assert.True(t, client.IsRunning())
+}
+
+func TestRequestNotSupported(t *testing.T) {
+	conn := &errorResponseConnection{code: constant.NO_HANDLER}
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: conn, mux: new(sync.Mutex),
+		reconnectionChan: make(chan ReconnectContext, 1), RetryPolicy: retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond}}
+	_, err := client.Request(rpc_request.NewConfigSearchRequest("group", "dataId", ""), 3000)
+	assert.True(t, IsRequestNotSupported(err))
+	assert.Equal(t, 1, conn.requests)
+	assert.True(t, client.IsRunning())
+	assert.True(t, IsRequestNotSupported(status.Error(codes.Unimplemented, "unknown service")))
+	assert.False(t, IsRequestNotSupported(errors.New("server error")))
+}
+
+func TestRequestTimeoutBudget(t *testing.T) {
+	conn := &errorResponseConnection{serverInfoConnection: serverInfoConnection{serverInfo: ServerInfo{serverIp: "127.0.0.1", serverPort: 8848}}}
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: conn, mux: new(sync.Mutex),
+		reconnectionChan: make(chan ReconnectContext, 1),
+		RetryPolicy:      retry.Policy{MaxAttempts: 10, Backoff: 40 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}}
+	_, err := client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 100)
+	var timeoutErr *nacos_error.TimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.True(t, timeoutErr.Attempts < 10)
+	assert.Equal(t, conn.requests, timeoutErr.Attempts)
+	assert.Equal(t, "127.0.0.1:8848", timeoutErr.LastServer)
+	assert.True(t, timeoutErr.Elapsed < time.Second)
+	assert.Contains(t, err.Error(), "server error")
+}
+
+func TestServerAbility(t *testing.T) {
+	conn := NewGrpcConnection(ServerInfo{serverIp: "127.0.0.1", serverPort: 8848}, "1", nil, nil, nil)
+	client := &RpcClient{currentConnection: conn}
+	assert.Equal(t, ABILITY_UNKNOWN, client.ServerAbility(constant.ABILITY_FUZZY_WATCH))
+
+	conn.setAbilityTable(map[string]bool{constant.ABILITY_FUZZY_WATCH: true, constant.ABILITY_LOCK: false})
+	assert.Equal(t, ABILITY_SUPPORTED, client.ServerAbility(constant.ABILITY_FUZZY_WATCH))
+	assert.Equal(t, ABILITY_NOT_SUPPORTED, client.ServerAbility(constant.ABILITY_LOCK))
+	assert.Equal(t, ABILITY_NOT_SUPPORTED, client.ServerAbility(constant.ABILITY_PERSISTENT_BY_GRPC))
+}
+
+func TestShutdown(t *testing.T) {
+	iRpcClient, err := CreateClient(context.Background(), "shutdown-test", GRPC, nil, nil)
+	assert.Nil(t, err)
+	client := iRpcClient.GetRpcClient()
+	client.currentConnection = &serverInfoConnection{}
+	client.rpcClientStatus = RUNNING
+	atomic.AddInt32(&client.pendingRequests, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&client.pendingRequests, -1)
+	}()
+	assert.Nil(t, client.Shutdown(context.Background()))
+	assert.NotNil(t, client.ctx.Err())
+	assert.Nil(t, getClient("shutdown-test"))
+	assert.Nil(t, client.Shutdown(context.Background()))
+
+	iRpcClient, err = CreateClient(context.Background(), "shutdown-test", GRPC, nil, nil)
+	assert.Nil(t, err)
+	client = iRpcClient.GetRpcClient()
+	atomic.AddInt32(&client.pendingRequests, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, client.Shutdown(ctx))
+	assert.True(t, client.isShutdown())
+}
+
+type blockingConnection struct {
+	serverInfoConnection
+	release chan struct{}
+}
+
+func (c *blockingConnection) request(request rpc_request.IRequest, timeoutMills int64, client *RpcClient) (rpc_response.IResponse, error) {
+	<-c.release
+	return &rpc_response.HealthCheckResponse{Response: &rpc_response.Response{ResultCode: 200, Success: true}}, nil
+}
+
+func TestShutdown_Drain(t *testing.T) {
+	iRpcClient, err := CreateClient(context.Background(), "shutdown-drain-test", GRPC, nil, nil)
+	assert.Nil(t, err)
+	client := iRpcClient.GetRpcClient()
+	connection := &blockingConnection{release: make(chan struct{})}
+	client.currentConnection = connection
+	client.rpcClientStatus = RUNNING
+
+	pending := make(chan error, 1)
+	go func() {
+		_, err := client.Request(rpc_request.NewHealthCheckRequest(), 3000)
+		pending <- err
+	}()
+	for atomic.LoadInt32(&client.pendingRequests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- client.Shutdown(context.Background())
+	}()
+	for atomic.LoadInt32(&client.draining) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = client.Request(rpc_request.NewHealthCheckRequest(), 3000)
+	assert.NotNil(t, err)
+	assert.True(t, client.IsRunning())
+	close(connection.release)
+	assert.Nil(t, <-pending)
+	assert.Nil(t, <-shutdown)
+	assert.True(t, client.isShutdown())
+}
+
+func TestInterceptors(t *testing.T) {
+	client := &RpcClient{rpcClientStatus: RUNNING, currentConnection: &serverInfoConnection{}, mux: new(sync.Mutex)}
+	var calls []string
+	client.AddInterceptor(func(ctx context.Context, request rpc_request.IRequest, next Invoker) (rpc_response.IResponse, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		calls = append(calls, "first")
+		return next(ctx, request)
+	})
+	client.AddInterceptor(func(ctx context.Context, request rpc_request.IRequest, next Invoker) (rpc_response.IResponse, error) {
+		calls = append(calls, "second:"+request.GetRequestType())
+		return next(ctx, request)
+	})
+	_, err := client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second:ConfigQueryRequest"}, calls)
+
+	detached := &RpcClient{rpcClientStatus: RUNNING, currentConnection: &serverInfoConnection{}, mux: new(sync.Mutex)}
+	detached.AddInterceptor(func(ctx context.Context, request rpc_request.IRequest, next Invoker) (rpc_response.IResponse, error) {
+		return next(context.Background(), request)
+	})
+	_, err = detached.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Nil(t, err)
+
+	client.AddInterceptor(func(ctx context.Context, request rpc_request.IRequest, next Invoker) (rpc_response.IResponse, error) {
+		return nil, errors.New("injected")
+	})
+	_, err = client.Request(rpc_request.NewConfigQueryRequest("group", "dataId", ""), 3000)
+	assert.Equal(t, "injected", err.Error())
+}
+
+type customDetectionHandler struct {
+	ClientDetectionRequestHandler
+}
+
+func TestRegisterServerRequestHandler(t *testing.T) {
+	client := NewGrpcClient(context.Background(), "handler-test", nil).RpcClient
+	handler := &customDetectionHandler{}
+	client.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return &rpc_request.ClientDetectionRequest{InternalRequest: rpc_request.NewInternalRequest()}
+	}, handler)
+	client.registerServerRequestHandlers()
+
+	mapping, ok := client.serverRequestHandlerMapping.Load("ClientDetectionRequest")
+	assert.True(t, ok)
+	assert.Equal(t, handler, mapping.(ServerRequestHandlerMapping).handler)
+	_, ok = client.serverRequestHandlerMapping.Load("ConnectResetRequest")
+	assert.True(t, ok)
+}
+
+func TestRedactBody(t *testing.T) {
+	body := `{"dataId":"app","content":"password=123","instance":{"ip":"127.0.0.1","metadata":{"dbPassword":"123"}},"accessToken":""}`
+	redacted := redactBody(body)
+	assert.Contains(t, redacted, `"dataId":"app"`)
+	assert.Contains(t, redacted, `"ip":"127.0.0.1"`)
+	assert.Contains(t, redacted, `"content":"******"`)
+	assert.Contains(t, redacted, `"dbPassword":"******"`)
+	assert.Contains(t, redacted, `"accessToken":""`)
+	assert.NotContains(t, redacted, "123")
+
+	assert.Equal(t, maxWireLogBody+len("...(truncated)"), len(redactBody(strings.Repeat("a", 2*maxWireLogBody))))
+}
+
+func TestOrderedExecutor(t *testing.T) {
+	executor := NewOrderedExecutor()
+	var mutex sync.Mutex
+	var order []int
+	var done []<-chan struct{}
+	for i := 0; i < 100; i++ {
+		i := i
+		done = append(done, executor.Submit("key", func() {
+			if i == 10 {
+				panic("task panics")
+			}
+			mutex.Lock()
+			order = append(order, i)
+			mutex.Unlock()
+		}))
+	}
+	for _, d := range done {
+		<-d
+	}
+	assert.Equal(t, 99, len(order))
+	for i := 1; i < len(order); i++ {
+		assert.True(t, order[i-1] < order[i])
+	}
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	assert.Equal(t, 0, len(executor.queues))
+}
+
+type compressorRecordingClient struct {
+	compressors []string
+}
+
+func (c *compressorRecordingClient) Request(ctx context.Context, in *nacos_grpc_service.Payload, opts ...grpc.CallOption) (*nacos_grpc_service.Payload, error) {
+	compressor := ""
+	for _, opt := range opts {
+		if compressorOption, ok := opt.(grpc.CompressorCallOption); ok {
+			compressor = compressorOption.CompressorType
+		}
+	}
+	c.compressors = append(c.compressors, compressor)
+	return convertResponse(&rpc_response.ConfigPublishResponse{Response: &rpc_response.Response{ResultCode: 200, Success: true}}), nil
+}
+
+func TestCompressThreshold(t *testing.T) {
+	recorder := &compressorRecordingClient{}
+	conn := &GrpcConnection{Connection: &Connection{}, client: recorder}
+	client := &RpcClient{CompressThreshold: 1024}
+	small := rpc_request.NewConfigPublishRequest("group", "dataId", "", "small", "")
+	large := rpc_request.NewConfigPublishRequest("group", "dataId", "", strings.Repeat("large", 1024), "")
+
+	for _, request := range []rpc_request.IRequest{small, large} {
+		_, err := conn.request(request, 1000, client)
+		assert.Nil(t, err)
+	}
+	_, err := conn.request(large, 1000, &RpcClient{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", gzip.Name, ""}, recorder.compressors)
+}
+
+type compressionServer struct {
+	nacos_grpc_service.UnimplementedRequestServer
+}
+
+func (s *compressionServer) Request(ctx context.Context, in *nacos_grpc_service.Payload) (*nacos_grpc_service.Payload, error) {
+	return convertResponse(&rpc_response.ConfigPublishResponse{Response: &rpc_response.Response{ResultCode: 200, Success: true}}), nil
+}
+
+type compressionRecordingHandler struct {
+	mutex        sync.Mutex
+	compressions []string
+}
+
+func (h *compressionRecordingHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *compressionRecordingHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		h.mutex.Lock()
+		h.compressions = append(h.compressions, header.Compression)
+		h.mutex.Unlock()
+	}
+}
+
+func (h *compressionRecordingHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *compressionRecordingHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestCompressThreshold_Wire(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	handler := &compressionRecordingHandler{}
+	server := grpc.NewServer(grpc.StatsHandler(handler))
+	nacos_grpc_service.RegisterRequestServer(server, &compressionServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	small := rpc_request.NewConfigPublishRequest("group", "dataId", "", "small", "")
+	large := rpc_request.NewConfigPublishRequest("group", "dataId", "", strings.Repeat("large", 1024), "")
+	for _, clientConfig := range []constant.ClientConfig{
+		{CompressThreshold: 1024},
+		{EnableGrpcCompression: true},
+		{},
+	} {
+		client := &GrpcClient{RpcClient: &RpcClient{
+			CompressThreshold: GetCompressThreshold(clientConfig),
+			Dialer: func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			},
+		}}
+		conn, err := client.createNewConnection(ServerInfo{serverIp: "127.0.0.1", serverPort: 8848})
+		assert.Nil(t, err)
+		grpcConn := NewGrpcConnection(ServerInfo{}, "", conn, nacos_grpc_service.NewRequestClient(conn), nil)
+		for _, request := range []rpc_request.IRequest{small, large} {
+			response, err := grpcConn.request(request, 3000, client.RpcClient)
+			assert.Nil(t, err)
+			assert.True(t, response.IsSuccess())
+		}
+		_ = conn.Close()
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	assert.Equal(t, []string{"", gzip.Name, gzip.Name, gzip.Name, "", ""}, handler.compressions)
+}
+
+type setupRecordingStream struct {
+	nacos_grpc_service.BiRequestStream_RequestBiStreamClient
+	payloads []*nacos_grpc_service.Payload
+}
+
+func (s *setupRecordingStream) Send(payload *nacos_grpc_service.Payload) error {
+	s.payloads = append(s.payloads, payload)
+	return nil
+}
+
+func TestConnectionSetupLabels(t *testing.T) {
+	clientConfig := constant.ClientConfig{AppName: "app", ConnectionLabels: map[string]string{
+		"env": "prod", "empty": "", "": "value", constant.LABEL_MODULE: "custom", constant.LABEL_TASK_ID: "1",
+	}}
+	labels := NewConnectionLabels(clientConfig, constant.LABEL_MODULE_NAMING)
+	assert.Equal(t, map[string]string{"env": "prod", constant.LABEL_SOURCE: constant.LABEL_SOURCE_SDK,
+		constant.LABEL_MODULE: constant.LABEL_MODULE_NAMING, constant.APPNAME_HEADER: "app"}, labels)
+	_, ok := NewConnectionLabels(constant.ClientConfig{}, constant.LABEL_MODULE_NAMING)[constant.APPNAME_HEADER]
+	assert.False(t, ok)
+
+	iRpcClient, err := CreateClient(context.Background(), "setup-labels-test", GRPC, labels, nil)
+	assert.Nil(t, err)
+	defer iRpcClient.GetRpcClient().Shutdown(context.Background())
+	stream := &setupRecordingStream{}
+	grpcConn := NewGrpcConnection(ServerInfo{}, "", nil, nil, stream)
+	assert.Nil(t, iRpcClient.(*GrpcClient).sendConnectionSetupRequest(grpcConn))
+	assert.Equal(t, 1, len(stream.payloads))
+	assert.Equal(t, "ConnectionSetupRequest", stream.payloads[0].GetMetadata().GetType())
+	setup := &rpc_request.ConnectionSetupRequest{}
+	assert.Nil(t, json.Unmarshal(stream.payloads[0].GetBody().GetValue(), setup))
+	assert.Equal(t, labels, setup.Labels)
+}
@@ -111,8 +111,27 @@ func (r *ConfigPublishRequest) GetRequestType() string {
 	return "ConfigPublishRequest"
 }
 
+// request of searching configs by page, the same as the search api of the console.
+type ConfigSearchRequest struct {
+	*ConfigRequest
+	Search   string `json:"search"`
+	Tag      string `json:"tag"`
+	AppName  string `json:"appName"`
+	PageNo   int    `json:"pageNo"`
+	PageSize int    `json:"pageSize"`
+}
+
+func NewConfigSearchRequest(group, dataId, tenant string) *ConfigSearchRequest {
+	return &ConfigSearchRequest{ConfigRequest: NewConfigRequest(group, dataId, tenant)}
+}
+
+func (r *ConfigSearchRequest) GetRequestType() string {
+	return "ConfigSearchRequest"
+}
+
 type ConfigRemoveRequest struct {
 	*ConfigRequest
+	Tag string `json:"tag"`
 }
 
 func NewConfigRemoveRequest(group, dataId, tenant string) *ConfigRemoveRequest {
@@ -122,3 +141,54 @@ func NewConfigRemoveRequest(group, dataId, tenant string) *ConfigRemoveRequest {
 func (r *ConfigRemoveRequest) GetRequestType() string {
 	return "ConfigRemoveRequest"
 }
+
+// request of watching all configs whose groupKey matches a pattern.
+type ConfigFuzzyWatchRequest struct {
+	*ConfigRequest
+	GroupKeyPattern   string   `json:"groupKeyPattern"`
+	ReceivedGroupKeys []string `json:"receivedGroupKeys"`
+	WatchType         string   `json:"watchType"`
+	IsInitializing    bool     `json:"initializing"`
+}
+
+func NewConfigFuzzyWatchRequest(groupKeyPattern, watchType string, receivedGroupKeys []string) *ConfigFuzzyWatchRequest {
+	return &ConfigFuzzyWatchRequest{ConfigRequest: NewConfigRequest("", "", ""),
+		GroupKeyPattern: groupKeyPattern, WatchType: watchType, ReceivedGroupKeys: receivedGroupKeys}
+}
+
+func (r *ConfigFuzzyWatchRequest) GetRequestType() string {
+	return "ConfigFuzzyWatchRequest"
+}
+
+// server push of a single config added to or removed from a watched pattern.
+type ConfigFuzzyWatchChangeNotifyRequest struct {
+	*ConfigRequest
+	GroupKey   string `json:"groupKey"`
+	ChangeType string `json:"changeType"`
+}
+
+func NewConfigFuzzyWatchChangeNotifyRequest() *ConfigFuzzyWatchChangeNotifyRequest {
+	return &ConfigFuzzyWatchChangeNotifyRequest{ConfigRequest: NewConfigRequest("", "", "")}
+}
+
+func (r *ConfigFuzzyWatchChangeNotifyRequest) GetRequestType() string {
+	return "ConfigFuzzyWatchChangeNotifyRequest"
+}
+
+// server push of the full set of groupKeys matching a watched pattern, possibly split into batches.
+type ConfigFuzzyWatchSyncRequest struct {
+	*ConfigRequest
+	GroupKeyPattern string                          `json:"groupKeyPattern"`
+	Contexts        []model.ConfigFuzzyWatchContext `json:"contexts"`
+	SyncType        string                          `json:"syncType"`
+	TotalBatch      int                             `json:"totalBatch"`
+	CurrentBatch    int                             `json:"currentBatch"`
+}
+
+func NewConfigFuzzyWatchSyncRequest() *ConfigFuzzyWatchSyncRequest {
+	return &ConfigFuzzyWatchSyncRequest{ConfigRequest: NewConfigRequest("", "", "")}
+}
+
+func (r *ConfigFuzzyWatchSyncRequest) GetRequestType() string {
+	return "ConfigFuzzyWatchSyncRequest"
+}
@@ -86,6 +86,7 @@ type ConnectionSetupRequest struct {
 	Tenant          string            `json:"tenant"`
 	Labels          map[string]string `json:"labels"`
 	ClientAbilities ClientAbilities   `json:"clientAbilities"`
+	AbilityTable    map[string]bool   `json:"abilityTable"`
 }
 
 func NewConnectionSetupRequest() *ConnectionSetupRequest {
@@ -97,3 +98,13 @@ func NewConnectionSetupRequest() *ConnectionSetupRequest {
 func (r *ConnectionSetupRequest) GetRequestType() string {
 	return "ConnectionSetupRequest"
 }
+
+// SetupAckRequest is sent by the servers supporting the ability negotiation after the connection setup.
+type SetupAckRequest struct {
+	*InternalRequest
+	AbilityTable map[string]bool `json:"abilityTable"`
+}
+
+func (r *SetupAckRequest) GetRequestType() string {
+	return "SetupAckRequest"
+}
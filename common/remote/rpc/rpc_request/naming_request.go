@@ -89,6 +89,25 @@ func (r *BatchInstanceRequest) GetRequestType() string {
 	return "BatchInstanceRequest"
 }
 
+// PersistentInstanceRequest register or deregister a persistent instance, it's supported since nacos server 2.3.
+type PersistentInstanceRequest struct {
+	*NamingRequest
+	Type     string         `json:"type"`
+	Instance model.Instance `json:"instance"`
+}
+
+func NewPersistentInstanceRequest(namespace, serviceName, groupName, Type string, instance model.Instance) *PersistentInstanceRequest {
+	return &PersistentInstanceRequest{
+		NamingRequest: NewNamingRequest(namespace, serviceName, groupName),
+		Type:          Type,
+		Instance:      instance,
+	}
+}
+
+func (r *PersistentInstanceRequest) GetRequestType() string {
+	return "PersistentInstanceRequest"
+}
+
 type NotifySubscriberRequest struct {
 	*NamingRequest
 	ServiceInfo model.Service `json:"serviceInfo"`
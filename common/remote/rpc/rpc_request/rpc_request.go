@@ -16,7 +16,7 @@
 
 package rpc_request
 
-import "github.com/jun3372/nacos-sdk-go/util"
+import "github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_serializer"
 
 type Request struct {
 	Headers   map[string]string `json:"-"`
@@ -53,7 +53,8 @@ func (r *Request) GetHeaders() map[string]string {
 }
 
 func (r *Request) GetBody(request IRequest) string {
-	return util.ToJsonString(request)
+	body, _ := rpc_serializer.Marshal(request)
+	return string(body)
 }
 func (r *Request) GetRequestId() string {
 	return r.RequestId
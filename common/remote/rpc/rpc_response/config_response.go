@@ -27,6 +27,18 @@ func (c *ConfigChangeBatchListenResponse) GetResponseType() string {
 	return "ConfigChangeBatchListenResponse"
 }
 
+type ConfigSearchResponse struct {
+	*Response
+	TotalCount     int                `json:"totalCount"`
+	PageNumber     int                `json:"pageNumber"`
+	PagesAvailable int                `json:"pagesAvailable"`
+	PageItems      []model.ConfigItem `json:"pageItems"`
+}
+
+func (c *ConfigSearchResponse) GetResponseType() string {
+	return "ConfigSearchResponse"
+}
+
 type ConfigQueryResponse struct {
 	*Response
 	Content          string `json:"content"`
@@ -57,3 +69,27 @@ type ConfigRemoveResponse struct {
 func (c *ConfigRemoveResponse) GetResponseType() string {
 	return "ConfigRemoveResponse"
 }
+
+type ConfigFuzzyWatchResponse struct {
+	*Response
+}
+
+func (c *ConfigFuzzyWatchResponse) GetResponseType() string {
+	return "ConfigFuzzyWatchResponse"
+}
+
+type ConfigFuzzyWatchChangeNotifyResponse struct {
+	*Response
+}
+
+func (c *ConfigFuzzyWatchChangeNotifyResponse) GetResponseType() string {
+	return "ConfigFuzzyWatchChangeNotifyResponse"
+}
+
+type ConfigFuzzyWatchSyncResponse struct {
+	*Response
+}
+
+func (c *ConfigFuzzyWatchSyncResponse) GetResponseType() string {
+	return "ConfigFuzzyWatchSyncResponse"
+}
@@ -20,7 +20,7 @@ import (
 	"strconv"
 
 	"github.com/jun3372/nacos-sdk-go/common/logger"
-	"github.com/jun3372/nacos-sdk-go/util"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_serializer"
 )
 
 var ClientResponseMapping map[string]func() IResponse
@@ -54,7 +54,8 @@ func (r *Response) SetRequestId(requestId string) {
 }
 
 func (r *Response) GetBody() string {
-	return util.ToJsonString(r)
+	body, _ := rpc_serializer.Marshal(r)
+	return string(body)
 }
 
 func (r *Response) IsSuccess() bool {
@@ -137,6 +138,11 @@ func registerClientResponses() {
 		return &ConfigQueryResponse{Response: &Response{}}
 	})
 
+	//register ConfigSearchResponse
+	registerClientResponse(func() IResponse {
+		return &ConfigSearchResponse{Response: &Response{}}
+	})
+
 	//register ConfigPublishResponse
 	registerClientResponse(func() IResponse {
 		return &ConfigPublishResponse{Response: &Response{}}
@@ -146,6 +152,11 @@ func registerClientResponses() {
 	registerClientResponse(func() IResponse {
 		return &ConfigRemoveResponse{Response: &Response{}}
 	})
+
+	//register ConfigFuzzyWatchResponse
+	registerClientResponse(func() IResponse {
+		return &ConfigFuzzyWatchResponse{Response: &Response{}}
+	})
 }
 
 // get grpc response status code with NA default.
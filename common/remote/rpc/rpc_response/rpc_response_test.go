@@ -1,8 +1,12 @@
 package rpc_response
 
 import (
-	"github.com/stretchr/testify/assert"
+	"encoding/json"
+	"strconv"
 	"testing"
+
+	"github.com/jun3372/nacos-sdk-go/model"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestRpcResponseIsSuccess(t *testing.T) {
@@ -38,3 +42,23 @@ func TestRpcResponseIsSuccess(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkInnerResponseJsonUnmarshal(b *testing.B) {
+	response := &QueryServiceResponse{Response: &Response{ResultCode: 200, Success: true}}
+	response.ServiceInfo = model.Service{Name: "DEFAULT_GROUP@@demo", GroupName: "DEFAULT_GROUP"}
+	for i := 0; i < 1000; i++ {
+		response.ServiceInfo.Hosts = append(response.ServiceInfo.Hosts, model.Instance{Ip: "10.0.0." + strconv.Itoa(i),
+			Port: 8080, Weight: 1, Healthy: true, Enable: true, Ephemeral: true, ClusterName: "DEFAULT",
+			Metadata: map[string]string{"version": "1.0.0", "zone": "a"}})
+	}
+	body, _ := json.Marshal(response)
+	responseFunc := func() IResponse { return &QueryServiceResponse{Response: &Response{}} }
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := InnerResponseJsonUnmarshal(body, responseFunc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
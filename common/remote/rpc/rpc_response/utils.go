@@ -1,17 +1,17 @@
 package rpc_response
 
-import "encoding/json"
+import "github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_serializer"
 
 func InnerResponseJsonUnmarshal(responseBody []byte, responseFunc func() IResponse) (IResponse, error) {
 	response := responseFunc()
-	err := json.Unmarshal(responseBody, response)
+	err := rpc_serializer.Unmarshal(responseBody, response)
 	if err != nil {
 		return nil, err
 	}
 
 	if !response.IsSuccess() {
 		tempFiledMap := make(map[string]interface{})
-		err = json.Unmarshal(responseBody, &tempFiledMap)
+		err = rpc_serializer.Unmarshal(responseBody, &tempFiledMap)
 		if err != nil {
 			return response, nil
 		}
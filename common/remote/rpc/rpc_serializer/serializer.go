@@ -0,0 +1,73 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc_serializer
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// Serializer encodes the bodies of the rpc requests and responses, it must be compatible with the json
+// tags of them, e.g. jsoniter.ConfigCompatibleWithStandardLibrary or sonic.ConfigStd.
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonSerializer) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type holder struct {
+	serializer Serializer
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(holder{serializer: jsonSerializer{}})
+}
+
+// SetSerializer replace the serializer of all the clients, it should be called before creating them,
+// nil restores the encoding/json one.
+func SetSerializer(serializer Serializer) {
+	if serializer == nil {
+		serializer = jsonSerializer{}
+	}
+	current.Store(holder{serializer: serializer})
+}
+
+// GetSerializer return the serializer in use.
+func GetSerializer() Serializer {
+	return current.Load().(holder).serializer
+}
+
+// Marshal encode the body with the current serializer.
+func Marshal(v interface{}) ([]byte, error) {
+	return GetSerializer().Marshal(v)
+}
+
+// Unmarshal decode the body with the current serializer.
+func Unmarshal(data []byte, v interface{}) error {
+	return GetSerializer().Unmarshal(data, v)
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc_serializer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSerializer struct {
+	jsonSerializer
+	unmarshals int
+}
+
+func (s *countingSerializer) Unmarshal(data []byte, v interface{}) error {
+	s.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestSetSerializer(t *testing.T) {
+	serializer := &countingSerializer{}
+	SetSerializer(serializer)
+	defer SetSerializer(nil)
+
+	var body map[string]interface{}
+	assert.Nil(t, Unmarshal([]byte(`{"resultCode":200}`), &body))
+	assert.Equal(t, 1, serializer.unmarshals)
+	assert.Equal(t, float64(200), body["resultCode"])
+
+	SetSerializer(nil)
+	assert.Equal(t, jsonSerializer{}, GetSerializer())
+}
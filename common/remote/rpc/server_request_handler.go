@@ -18,6 +18,8 @@ package rpc
 
 import (
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/jun3372/nacos-sdk-go/clients/naming_client/naming_cache"
 	"github.com/jun3372/nacos-sdk-go/common/constant"
@@ -33,6 +35,24 @@ type IServerRequestHandler interface {
 	RequestReply(request rpc_request.IRequest, rpcClient *RpcClient) rpc_response.IResponse
 }
 
+// builtInServerRequests are handled by the clients themselves, replacing their handlers breaks the reconnection,
+// the config listening or the service subscription.
+var builtInServerRequests = map[string]bool{
+	"ConnectResetRequest":                 true,
+	"ClientDetectionRequest":              true,
+	"SetupAckRequest":                     true,
+	"ConfigChangeNotifyRequest":           true,
+	"ConfigFuzzyWatchChangeNotifyRequest": true,
+	"ConfigFuzzyWatchSyncRequest":         true,
+	"NotifySubscriberRequest":             true,
+}
+
+// IsBuiltInServerRequest reports whether the server push request of the type is handled by the clients, the
+// custom handlers can't be registered for it.
+func IsBuiltInServerRequest(requestType string) bool {
+	return builtInServerRequests[requestType]
+}
+
 type ConnectResetRequestHandler struct {
 }
 
@@ -52,7 +72,7 @@ func (c *ConnectResetRequestHandler) RequestReply(request rpc_request.IRequest,
 					logger.Errorf("ConnectResetRequest ServerPort type conversion error:%+v", err)
 					return nil
 				}
-				rpcClient.switchServerAsync(ServerInfo{serverIp: connectResetRequest.ServerIp, serverPort: uint64(serverPortNum)}, false)
+				rpcClient.switchServerAsync(ServerInfo{serverIp: strings.Trim(connectResetRequest.ServerIp, "[]"), serverPort: uint64(serverPortNum)}, false)
 			} else {
 				rpcClient.switchServerAsync(ServerInfo{}, true)
 			}
@@ -81,6 +101,18 @@ func (c *ClientDetectionRequestHandler) RequestReply(request rpc_request.IReques
 
 type NamingPushRequestHandler struct {
 	ServiceInfoHolder *naming_cache.ServiceInfoHolder
+	// namespaces is the holders of the other namespaces sharing the connection
+	namespaces sync.Map
+}
+
+// AddNamespace route the pushes of the namespace to the holder.
+func (c *NamingPushRequestHandler) AddNamespace(namespace string, serviceInfoHolder *naming_cache.ServiceInfoHolder) {
+	c.namespaces.Store(namespace, serviceInfoHolder)
+}
+
+// RemoveNamespace route the pushes of the namespace to ServiceInfoHolder again.
+func (c *NamingPushRequestHandler) RemoveNamespace(namespace string) {
+	c.namespaces.Delete(namespace)
 }
 
 func (*NamingPushRequestHandler) Name() string {
@@ -90,7 +122,13 @@ func (*NamingPushRequestHandler) Name() string {
 func (c *NamingPushRequestHandler) RequestReply(request rpc_request.IRequest, _ *RpcClient) rpc_response.IResponse {
 	notifySubscriberRequest, ok := request.(*rpc_request.NotifySubscriberRequest)
 	if ok {
-		c.ServiceInfoHolder.ProcessService(&notifySubscriberRequest.ServiceInfo)
+		serviceInfoHolder := c.ServiceInfoHolder
+		if notifySubscriberRequest.NamingRequest != nil {
+			if holder, ok := c.namespaces.Load(notifySubscriberRequest.Namespace); ok {
+				serviceInfoHolder = holder.(*naming_cache.ServiceInfoHolder)
+			}
+		}
+		serviceInfoHolder.ProcessPushService(&notifySubscriberRequest.ServiceInfo)
 		return &rpc_response.NotifySubscriberResponse{
 			Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true},
 		}
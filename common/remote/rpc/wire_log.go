@@ -0,0 +1,114 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	"github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_response"
+)
+
+const (
+	redactedValue  = "******"
+	maxWireLogBody = 1024
+)
+
+// redactedKeys are the lower case json keys whose values are never logged.
+var redactedKeys = map[string]struct{}{
+	"content":          {},
+	"accesskey":        {},
+	"secretkey":        {},
+	"password":         {},
+	"accesstoken":      {},
+	"encrypteddatakey": {},
+	"signature":        {},
+	"spas-signature":   {},
+	"spas-accesskey":   {},
+}
+
+// wireLogRequest send the request and log it with the response at the debug level, so the logging can be
+// turned on and off at runtime by logger.SetLevel.
+func (r *RpcClient) wireLogRequest(request rpc_request.IRequest, deadline time.Time) (rpc_response.IResponse, error) {
+	if !logger.IsDebugEnabled() {
+		return r.request(request, deadline)
+	}
+	body := request.GetBody(request)
+	logger.Debugf("[wire] %s send %s size=%d body=%s", r.name, request.GetRequestType(), len(body), redactBody(body))
+	start := time.Now()
+	response, err := r.request(request, deadline)
+	latency := time.Since(start)
+	if err != nil {
+		logger.Debugf("[wire] %s %s failed latency=%s err=%v", r.name, request.GetRequestType(), latency, err)
+		return response, err
+	}
+	body = response.GetBody()
+	logger.Debugf("[wire] %s receive %s for %s resultCode=%d size=%d latency=%s body=%s", r.name,
+		response.GetResponseType(), request.GetRequestType(), response.GetResultCode(), len(body), latency, redactBody(body))
+	return response, nil
+}
+
+// redactBody replace the values of the secret keys in the json body, and truncate the body to maxWireLogBody.
+func redactBody(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return truncateBody(body)
+	}
+	redacted, err := json.Marshal(redact(value))
+	if err != nil {
+		return truncateBody(body)
+	}
+	return truncateBody(string(redacted))
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isRedactedKey(key) {
+				if item != nil && item != "" {
+					v[key] = redactedValue
+				}
+				continue
+			}
+			v[key] = redact(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+func isRedactedKey(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := redactedKeys[key]; ok {
+		return true
+	}
+	return strings.Contains(key, "password") || strings.Contains(key, "secret") || strings.Contains(key, "token")
+}
+
+func truncateBody(body string) string {
+	if len(body) <= maxWireLogBody {
+		return body
+	}
+	return body[:maxWireLogBody] + "...(truncated)"
+}
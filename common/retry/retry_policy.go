@@ -0,0 +1,104 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package retry
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+)
+
+const (
+	defaultMaxAttempts = constant.REQUEST_DOMAIN_RETRY_TIME
+	defaultBackoff     = 100 * time.Millisecond
+	defaultMaxBackoff  = time.Second
+)
+
+// Policy decides how the failed requests are retried, the zero values mean the defaults: 3 attempts,
+// all the error codes retried, a backoff from 100ms doubled up to 1s.
+type Policy struct {
+	MaxAttempts int
+	RetryCodes  []int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// NewPolicy returns the retry policy of the client config
+func NewPolicy(clientCfg constant.ClientConfig) Policy {
+	return Policy{
+		MaxAttempts: clientCfg.RequestMaxAttempts,
+		RetryCodes:  clientCfg.RequestRetryCodes,
+		Backoff:     time.Duration(clientCfg.RequestBackoffMs) * time.Millisecond,
+		MaxBackoff:  time.Duration(clientCfg.RequestMaxBackoffMs) * time.Millisecond,
+	}
+}
+
+// Attempts returns the max attempts of a request including the first one
+func (p Policy) Attempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// RetryableCode reports whether an error response of the code is retried
+func (p Policy) RetryableCode(code int) bool {
+	if len(p.RetryCodes) == 0 {
+		return true
+	}
+	for _, retryCode := range p.RetryCodes {
+		if retryCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the delay before the next attempt of a request failed the attempts times, the backoff is
+// doubled each time and a random half of it is taken off to spread the retries of the clients.
+func (p Policy) Delay(attempts int) time.Duration {
+	backoff, maxBackoff := p.Backoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	delay := backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Wait sleeps the delay before the next attempt, it returns false without sleeping if the attempt would
+// start after the deadline.
+func (p Policy) Wait(attempts int, deadline time.Time) bool {
+	delay := p.Delay(attempts)
+	if time.Now().Add(delay).After(deadline) {
+		return false
+	}
+	time.Sleep(delay)
+	return true
+}
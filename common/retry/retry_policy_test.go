@@ -0,0 +1,46 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	policy := Policy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for i := 0; i < 10; i++ {
+		first, second, third := policy.Delay(1), policy.Delay(2), policy.Delay(5)
+		assert.True(t, first >= 50*time.Millisecond && first <= 100*time.Millisecond)
+		assert.True(t, second >= 100*time.Millisecond && second <= 200*time.Millisecond)
+		assert.True(t, third >= 150*time.Millisecond && third <= 300*time.Millisecond)
+	}
+	assert.False(t, policy.Wait(5, time.Now().Add(100*time.Millisecond)))
+}
+
+func TestPolicy_Defaults(t *testing.T) {
+	policy := Policy{}
+	assert.Equal(t, 3, policy.Attempts())
+	assert.True(t, policy.RetryableCode(500))
+	assert.True(t, policy.Delay(10) <= time.Second)
+
+	policy.RetryCodes = []int{503}
+	assert.False(t, policy.RetryableCode(500))
+	assert.True(t, policy.RetryableCode(503))
+}
@@ -18,11 +18,15 @@ package security
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
+	"math/rand"
 	"net/http"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -31,68 +35,190 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/jun3372/nacos-sdk-go/common/http_agent"
 	"github.com/jun3372/nacos-sdk-go/common/logger"
+	"github.com/jun3372/nacos-sdk-go/common/retry"
 )
 
+const (
+	// tokenFailureThreshold is the consecutive refresh failures to notify the TokenRefreshListener.
+	tokenFailureThreshold = 3
+	// initialLoginDelay is the delay to login when no token has been obtained or tried yet
+	initialLoginDelay = 5 * time.Second
+)
+
+// refreshBackoff spaces the retries of the failed token refresh.
+var refreshBackoff = retry.Policy{Backoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// sharedTokens are the token holders of the clients logging in the same servers with the same username, a
+// holder is dropped when the last client referencing it is closed.
+var (
+	sharedTokens     = map[string]*tokenHolder{}
+	sharedTokensLock sync.Mutex
+)
+
+// accessToken is a login result, it's refreshed at a random 50% to 80% of the ttl to spread the logins of
+// the clients, a zero refreshAt means the token never expires.
+type accessToken struct {
+	token     string
+	expireAt  time.Time
+	refreshAt time.Time
+}
+
+// tokenHolder keeps the token of the clients sharing the credentials, the logins are serialized by the mutex.
+type tokenHolder struct {
+	mutex    sync.Mutex
+	token    atomic.Value
+	failures int32
+	key      string
+	refs     int
+}
+
+func (h *tokenHolder) load() *accessToken {
+	if v, ok := h.token.Load().(*accessToken); ok {
+		return v
+	}
+	return nil
+}
+
+// fresh reports whether the token doesn't need to be refreshed yet.
+func (h *tokenHolder) fresh() bool {
+	t := h.load()
+	return t != nil && (t.refreshAt.IsZero() || time.Now().Before(t.refreshAt))
+}
+
+func (h *tokenHolder) store(token string, ttl int64) {
+	t := &accessToken{token: token}
+	if old := h.load(); token == "" && old != nil {
+		t.token = old.token
+	}
+	if ttl > 0 {
+		now := time.Now()
+		lifetime := time.Duration(ttl) * time.Second
+		t.expireAt = now.Add(lifetime)
+		t.refreshAt = now.Add(lifetime/2 + time.Duration(rand.Int63n(int64(lifetime*3/10)+1)))
+	}
+	h.token.Store(t)
+	atomic.StoreInt32(&h.failures, 0)
+}
+
+// IsTokenRejected reports whether the error response of the code and the message means the token is expired
+// or invalid, the permission denials are also 403 but not retried.
+func IsTokenRejected(code int, message string) bool {
+	if code == http.StatusUnauthorized {
+		return true
+	}
+	if code != http.StatusForbidden {
+		return false
+	}
+	message = strings.ToLower(message)
+	return strings.Contains(message, "token expired") || strings.Contains(message, "token invalid")
+}
+
+// keyedAuthPlugin is an auth plugin whose tokens can be shared by the clients of the same key.
+type keyedAuthPlugin interface {
+	tokenKey() string
+}
+
 type AuthClient struct {
-	username           string
-	password           string
-	accessToken        *atomic.Value
-	tokenTtl           int64
-	lastRefreshTime    int64
-	tokenRefreshWindow int64
-	agent              http_agent.IHttpAgent
-	clientCfg          constant.ClientConfig
-	serverCfgs         []constant.ServerConfig
+	plugin     constant.AuthPlugin
+	holder     *tokenHolder
+	listener   constant.TokenRefreshListener
+	agent      http_agent.IHttpAgent
+	clientCfg  constant.ClientConfig
+	serverCfgs *atomic.Value
 }
 
 func NewAuthClient(clientCfg constant.ClientConfig, serverCfgs []constant.ServerConfig, agent http_agent.IHttpAgent) AuthClient {
 	client := AuthClient{
-		username:    clientCfg.Username,
-		password:    clientCfg.Password,
-		serverCfgs:  serverCfgs,
-		clientCfg:   clientCfg,
-		agent:       agent,
-		accessToken: &atomic.Value{},
+		plugin:     clientCfg.AuthPlugin,
+		listener:   clientCfg.TokenRefreshListener,
+		serverCfgs: &atomic.Value{},
+		clientCfg:  clientCfg,
+		agent:      agent,
+	}
+	client.serverCfgs.Store(serverCfgs)
+	if client.plugin == nil && clientCfg.Username != "" {
+		client.plugin = NewNacosAuthPlugin(clientCfg.Username, clientCfg.Password, agent, clientCfg.TimeoutMs)
 	}
+	client.holder = newTokenHolder(client.plugin, clientCfg, serverCfgs)
 
 	return client
 }
 
+// newTokenHolder returns the holder shared by the clients of the same key, the servers from a ServerListProvider
+// can't be identified so the holder isn't shared then.
+func newTokenHolder(plugin constant.AuthPlugin, clientCfg constant.ClientConfig, serverCfgs []constant.ServerConfig) *tokenHolder {
+	keyed, ok := plugin.(keyedAuthPlugin)
+	if !ok || (len(serverCfgs) == 0 && clientCfg.Endpoint == "") {
+		return &tokenHolder{}
+	}
+	servers := make([]string, 0, len(serverCfgs))
+	for _, serverCfg := range serverCfgs {
+		servers = append(servers, serverCfg.Scheme+"://"+serverCfg.Address()+serverCfg.ContextPath)
+	}
+	sort.Strings(servers)
+	key := keyed.tokenKey() + "@" + clientCfg.Endpoint + "," + strings.Join(servers, ",")
+	sharedTokensLock.Lock()
+	defer sharedTokensLock.Unlock()
+	holder, ok := sharedTokens[key]
+	if !ok {
+		holder = &tokenHolder{key: key}
+		sharedTokens[key] = holder
+	}
+	holder.refs++
+	return holder
+}
+
+// releaseTokenHolder drops the reference of a client to the holder, the holder isn't shared any more once
+// no client references it.
+func releaseTokenHolder(holder *tokenHolder) {
+	if holder.key == "" {
+		return
+	}
+	sharedTokensLock.Lock()
+	defer sharedTokensLock.Unlock()
+	if holder.refs--; holder.refs <= 0 && sharedTokens[holder.key] == holder {
+		delete(sharedTokens, holder.key)
+	}
+}
+
 func (ac *AuthClient) GetAccessToken() string {
-	v := ac.accessToken.Load()
-	if v == nil {
-		return ""
+	if t := ac.holder.load(); t != nil {
+		return t.token
 	}
-	return v.(string)
+	return ""
 }
 
 func (ac *AuthClient) AutoRefresh(ctx context.Context) {
 
-	// If the username is not set, the automatic refresh Token is not enabled
+	// If neither the username nor the auth plugin is set, the automatic refresh Token is not enabled
 
-	if ac.username == "" {
+	if ac.plugin == nil {
 		return
 	}
 
 	go func() {
-		var timer *time.Timer
-		if lastLoginSuccess := ac.lastRefreshTime > 0 && ac.tokenTtl > 0 && ac.tokenRefreshWindow > 0; lastLoginSuccess {
-			timer = time.NewTimer(time.Second * time.Duration(ac.tokenTtl-ac.tokenRefreshWindow))
-		} else {
-			timer = time.NewTimer(time.Second * time.Duration(5))
+		// the shared holder is released when the client is closed
+		defer releaseTokenHolder(ac.holder)
+		delay, ok := ac.nextRefresh()
+		if !ok {
+			<-ctx.Done()
+			return
 		}
+		timer := time.NewTimer(delay)
 		defer timer.Stop()
 		for {
 			select {
 			case <-timer.C:
-				_, err := ac.Login()
-				if err != nil {
-					logger.Errorf("login has error %+v", err)
-					timer.Reset(time.Second * time.Duration(5))
-				} else {
-					logger.Infof("login success, tokenTtl: %+v seconds, tokenRefreshWindow: %+v seconds", ac.tokenTtl, ac.tokenRefreshWindow)
-					timer.Reset(time.Second * time.Duration(ac.tokenTtl-ac.tokenRefreshWindow))
+				if _, err := ac.Login(); err != nil {
+					failures := int(atomic.LoadInt32(&ac.holder.failures))
+					logger.Errorf("login has error %+v, failures: %d", err, failures)
+					ac.notifyFailure(failures, err)
 				}
+				if delay, ok = ac.nextRefresh(); !ok {
+					<-ctx.Done()
+					return
+				}
+				timer.Reset(delay)
 			case <-ctx.Done():
 				return
 			}
@@ -100,75 +226,182 @@ func (ac *AuthClient) AutoRefresh(ctx context.Context) {
 	}()
 }
 
+// nextRefresh returns the delay to refresh the token, false if the token never expires.
+func (ac *AuthClient) nextRefresh() (time.Duration, bool) {
+	if failures := int(atomic.LoadInt32(&ac.holder.failures)); failures > 0 {
+		return refreshBackoff.Delay(failures), true
+	}
+	t := ac.holder.load()
+	if t == nil {
+		return initialLoginDelay, true
+	}
+	if t.refreshAt.IsZero() {
+		return 0, false
+	}
+	if delay := time.Until(t.refreshAt); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// notifyFailure notifies the listener when the refresh fails repeatedly, so the apps can alert before the
+// token expires. The listener runs in its own goroutine so it can't stall the refresh.
+func (ac *AuthClient) notifyFailure(failures int, err error) {
+	if ac.listener == nil || failures < tokenFailureThreshold {
+		return
+	}
+	failure := constant.TokenRefreshFailure{Failures: failures, Err: err}
+	if t := ac.holder.load(); t != nil {
+		failure.ExpireAt = t.expireAt
+	}
+	go ac.listener(failure)
+}
+
+// UpdateServerList replace the servers to login, it's shared by the copies of the client
+func (ac *AuthClient) UpdateServerList(serverCfgs []constant.ServerConfig) {
+	ac.serverCfgs.Store(serverCfgs)
+}
+
+// Login logs in the servers in order until one succeeds, the token refreshed by another client sharing the
+// credentials is reused.
 func (ac *AuthClient) Login() (bool, error) {
+	if ac.plugin == nil {
+		return true, nil
+	}
+	ac.holder.mutex.Lock()
+	defer ac.holder.mutex.Unlock()
+	if ac.holder.fresh() {
+		return true, nil
+	}
+	return ac.loginServers()
+}
+
+// Relogin logs in again when the token was rejected by the server, it's skipped if the token has been
+// refreshed by another request. It returns false if no auth plugin is set.
+func (ac *AuthClient) Relogin(rejected string) (bool, error) {
+	if ac.plugin == nil {
+		return false, nil
+	}
+	ac.holder.mutex.Lock()
+	defer ac.holder.mutex.Unlock()
+	if ac.GetAccessToken() != rejected {
+		return true, nil
+	}
+	return ac.loginServers()
+}
+
+func (ac *AuthClient) loginServers() (bool, error) {
 	var throwable error = nil
-	for i := 0; i < len(ac.serverCfgs); i++ {
-		result, err := ac.login(ac.serverCfgs[i])
+	serverCfgs := ac.serverCfgs.Load().([]constant.ServerConfig)
+	for i := 0; i < len(serverCfgs); i++ {
+		result, err := ac.login(serverCfgs[i])
 		throwable = err
 		if result {
 			return true, nil
 		}
 	}
+	atomic.AddInt32(&ac.holder.failures, 1)
 	return false, throwable
 }
 
 func (ac *AuthClient) login(server constant.ServerConfig) (bool, error) {
-	if ac.username != "" {
-		contextPath := server.ContextPath
+	token, ttl, err := ac.plugin.GetLoginToken(server)
+	if err != nil {
+		return false, err
+	}
+	ac.holder.store(token, ttl)
+	return true, nil
+}
 
-		if !strings.HasPrefix(contextPath, "/") {
-			contextPath = "/" + contextPath
-		}
+// DecorateRequest puts the token of the auth plugin into the headers or the params of a request.
+func (ac *AuthClient) DecorateRequest(headers map[string]string, params map[string]string) {
+	if ac.plugin == nil {
+		return
+	}
+	ac.plugin.DecorateRequest(ac.GetAccessToken(), headers, params)
+}
 
-		if strings.HasSuffix(contextPath, "/") {
-			contextPath = contextPath[0 : len(contextPath)-1]
-		}
+// NacosAuthPlugin logs in with the username and the password of the nacos server, it's the default auth plugin
+// when the username is set.
+type NacosAuthPlugin struct {
+	username  string
+	password  string
+	agent     http_agent.IHttpAgent
+	timeoutMs uint64
+}
 
-		if server.Scheme == "" {
-			server.Scheme = "http"
-		}
+func NewNacosAuthPlugin(username, password string, agent http_agent.IHttpAgent, timeoutMs uint64) *NacosAuthPlugin {
+	return &NacosAuthPlugin{
+		username:  username,
+		password:  password,
+		agent:     agent,
+		timeoutMs: timeoutMs,
+	}
+}
 
-		reqUrl := server.Scheme + "://" + server.IpAddr + ":" + strconv.FormatInt(int64(server.Port), 10) + contextPath + "/v1/auth/users/login"
+func (p *NacosAuthPlugin) tokenKey() string {
+	digest := sha256.Sum256([]byte(p.password))
+	return p.username + ":" + hex.EncodeToString(digest[:])
+}
 
-		header := http.Header{
-			"content-type": []string{"application/x-www-form-urlencoded"},
-		}
-		resp, err := ac.agent.Post(reqUrl, header, ac.clientCfg.TimeoutMs, map[string]string{
-			"username": ac.username,
-			"password": ac.password,
-		})
+// GetLoginToken logs in the /v1/auth/users/login of the server.
+func (p *NacosAuthPlugin) GetLoginToken(server constant.ServerConfig) (string, int64, error) {
+	contextPath := server.ContextPath
 
-		if err != nil {
-			return false, err
-		}
+	if !strings.HasPrefix(contextPath, "/") {
+		contextPath = "/" + contextPath
+	}
 
-		var bytes []byte
-		bytes, err = io.ReadAll(resp.Body)
-		defer resp.Body.Close()
-		if err != nil {
-			return false, err
-		}
+	if strings.HasSuffix(contextPath, "/") {
+		contextPath = contextPath[0 : len(contextPath)-1]
+	}
 
-		if resp.StatusCode != constant.RESPONSE_CODE_SUCCESS {
-			errMsg := string(bytes)
-			return false, errors.New(errMsg)
-		}
+	if server.Scheme == "" {
+		server.Scheme = "http"
+	}
 
-		var result map[string]interface{}
+	reqUrl := server.Scheme + "://" + server.Address() + contextPath + "/v1/auth/users/login"
 
-		err = json.Unmarshal(bytes, &result)
+	header := http.Header{
+		"content-type": []string{"application/x-www-form-urlencoded"},
+	}
+	resp, err := p.agent.Post(reqUrl, header, p.timeoutMs, map[string]string{
+		"username": p.username,
+		"password": p.password,
+	})
 
-		if err != nil {
-			return false, err
-		}
+	if err != nil {
+		return "", 0, err
+	}
 
-		if val, ok := result[constant.KEY_ACCESS_TOKEN]; ok {
-			ac.accessToken.Store(val)
-			ac.lastRefreshTime = time.Now().Unix()
-			ac.tokenTtl = int64(result[constant.KEY_TOKEN_TTL].(float64))
-			ac.tokenRefreshWindow = ac.tokenTtl / 10
-		}
+	var bytes []byte
+	bytes, err = io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return "", 0, err
 	}
-	return true, nil
 
+	if resp.StatusCode != constant.RESPONSE_CODE_SUCCESS {
+		errMsg := string(bytes)
+		return "", 0, errors.New(errMsg)
+	}
+
+	var result map[string]interface{}
+
+	err = json.Unmarshal(bytes, &result)
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	token, _ := result[constant.KEY_ACCESS_TOKEN].(string)
+	ttl, _ := result[constant.KEY_TOKEN_TTL].(float64)
+	return token, int64(ttl), nil
+}
+
+// DecorateRequest puts the token into the accessToken param.
+func (p *NacosAuthPlugin) DecorateRequest(token string, headers map[string]string, params map[string]string) {
+	if token != "" {
+		params[constant.KEY_ACCESS_TOKEN] = token
+	}
 }
@@ -0,0 +1,163 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/http_agent"
+)
+
+type failingAuthPlugin struct{}
+
+func (failingAuthPlugin) GetLoginToken(server constant.ServerConfig) (string, int64, error) {
+	return "", 0, errors.New("sso is down")
+}
+
+func (failingAuthPlugin) DecorateRequest(token string, headers map[string]string, params map[string]string) {
+}
+
+func TestTokenHolder_RefreshAt(t *testing.T) {
+	holder := &tokenHolder{}
+	for i := 0; i < 10; i++ {
+		holder.store("token", 100)
+		token := holder.load()
+		lifetime := time.Until(token.expireAt)
+		refresh := time.Until(token.refreshAt)
+		assert.True(t, refresh >= lifetime/2-time.Second && refresh <= lifetime*8/10, refresh)
+	}
+	holder.store("", 0)
+	assert.Equal(t, "token", holder.load().token)
+	assert.True(t, holder.fresh())
+}
+
+func TestAuthClient_SharedToken(t *testing.T) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		_, _ = w.Write([]byte(`{"accessToken":"token","tokenTtl":18000}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	serverCfgs := []constant.ServerConfig{{Scheme: "http", IpAddr: u.Hostname(), Port: uint64(port), ContextPath: "/nacos"}}
+	clientCfg := constant.ClientConfig{Username: "shared", Password: "nacos", TimeoutMs: 1000}
+
+	first := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	second := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	_, err := first.Login()
+	assert.Nil(t, err)
+	_, err = second.Login()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&logins))
+	assert.Equal(t, "token", second.GetAccessToken())
+
+	clientCfg.Password = "other"
+	third := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	_, err = third.Login()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&logins))
+
+	delay, ok := first.nextRefresh()
+	assert.True(t, ok)
+	assert.True(t, delay > 4000*time.Second && delay <= 14400*time.Second, delay)
+}
+
+func TestAuthClient_RefreshFailure(t *testing.T) {
+	failures := make(chan constant.TokenRefreshFailure, tokenFailureThreshold)
+	release := make(chan struct{})
+	client := NewAuthClient(constant.ClientConfig{
+		AuthPlugin: failingAuthPlugin{},
+		TokenRefreshListener: func(failure constant.TokenRefreshFailure) {
+			failures <- failure
+			<-release
+		},
+	}, []constant.ServerConfig{{IpAddr: "127.0.0.1", Port: 8848}}, &http_agent.HttpAgent{})
+	for i := 1; i <= tokenFailureThreshold; i++ {
+		_, err := client.Login()
+		assert.NotNil(t, err)
+		// the blocking listener doesn't stall the refresh
+		client.notifyFailure(i, err)
+	}
+	close(release)
+	select {
+	case failure := <-failures:
+		assert.Equal(t, tokenFailureThreshold, failure.Failures)
+		assert.True(t, failure.ExpireAt.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("the listener isn't notified")
+	}
+	assert.Len(t, failures, 0)
+
+	// the initial failures back off as well
+	delay, ok := client.nextRefresh()
+	assert.True(t, ok)
+	assert.True(t, delay >= 2*time.Second && delay <= 4*time.Second, delay)
+
+	client.holder.store("token", 100)
+	atomic.StoreInt32(&client.holder.failures, 2)
+	delay, _ = client.nextRefresh()
+	assert.True(t, delay >= time.Second && delay <= 2*time.Second, delay)
+}
+
+func TestAuthClient_ReleaseSharedToken(t *testing.T) {
+	serverCfgs := []constant.ServerConfig{{Scheme: "http", IpAddr: "127.0.0.1", Port: 8848}}
+	clientCfg := constant.ClientConfig{Username: "release", Password: "nacos", TimeoutMs: 1000}
+	first := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	second := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	assert.True(t, first.holder == second.holder)
+	first.holder.store("token", 0)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	first.AutoRefresh(firstCtx)
+	second.AutoRefresh(secondCtx)
+	shared := func() bool {
+		sharedTokensLock.Lock()
+		defer sharedTokensLock.Unlock()
+		_, ok := sharedTokens[first.holder.key]
+		return ok
+	}
+	cancelFirst()
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, shared())
+	cancelSecond()
+	assert.Eventually(t, func() bool { return !shared() }, time.Second, 10*time.Millisecond)
+
+	third := NewAuthClient(clientCfg, serverCfgs, &http_agent.HttpAgent{})
+	assert.True(t, third.holder != first.holder)
+	releaseTokenHolder(third.holder)
+}
+
+func TestIsTokenRejected(t *testing.T) {
+	assert.True(t, IsTokenRejected(http.StatusForbidden, "token expired!"))
+	assert.True(t, IsTokenRejected(http.StatusForbidden, "Token invalid!"))
+	assert.True(t, IsTokenRejected(http.StatusUnauthorized, "unknown user!"))
+	assert.False(t, IsTokenRejected(http.StatusForbidden, "authorization failed!"))
+	assert.False(t, IsTokenRejected(http.StatusInternalServerError, "token expired!"))
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tls
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/common/logger"
+)
+
+// the min interval to check whether the files of a Reloader are modified
+const reloadCheckInterval = time.Second
+
+// Reloader holds the tls config of a TLSConfig, it's rebuilt once the ca, cert or key file is modified,
+// so the rotated certificates are used by the new connections without restarting the client.
+type Reloader struct {
+	c         constant.TLSConfig
+	mutex     sync.Mutex
+	config    *tls.Config
+	modTimes  []time.Time
+	checkedAt time.Time
+}
+
+// NewReloader returns a Reloader of the TLSConfig, the files must be valid at the beginning
+func NewReloader(c constant.TLSConfig) (*Reloader, error) {
+	config, err := NewTLS(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Reloader{c: c, config: config, modTimes: modTimes(c), checkedAt: time.Now()}, nil
+}
+
+// Config returns the current tls config, the previous one is kept if the modified files are invalid
+func (r *Reloader) Config() *tls.Config {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if time.Since(r.checkedAt) < reloadCheckInterval {
+		return r.config
+	}
+	r.checkedAt = time.Now()
+	current := modTimes(r.c)
+	if equalTimes(current, r.modTimes) {
+		return r.config
+	}
+	config, err := NewTLS(r.c)
+	if err != nil {
+		logger.Warnf("reload tls config failed, the previous one is still used, err:%+v", err)
+		return r.config
+	}
+	r.config, r.modTimes = config, current
+	logger.Infof("tls config is reloaded, caFile:%s, certFile:%s", r.c.CaFile, r.c.CertFile)
+	return r.config
+}
+
+func modTimes(c constant.TLSConfig) []time.Time {
+	files := []string{c.CaFile, c.CertFile, c.KeyFile}
+	times := make([]time.Time, len(files))
+	for i, file := range files {
+		if len(file) == 0 {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			times[i] = info.ModTime()
+		}
+	}
+	return times
+}
+
+func equalTimes(a, b []time.Time) bool {
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
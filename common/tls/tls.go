@@ -25,7 +25,8 @@ import (
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 )
 
-// NewTLS returns a config structure is used to configure a TLS client
+// NewTLS returns a config structure is used to configure a TLS client, the server certificates are verified
+// by the ca file, or the system roots without it, unless InsecureSkipVerify is set.
 func NewTLS(c constant.TLSConfig) (tc *tls.Config, err error) {
 	tc = &tls.Config{}
 	if len(c.CertFile) > 0 && len(c.KeyFile) > 0 {
@@ -36,13 +37,13 @@ func NewTLS(c constant.TLSConfig) (tc *tls.Config, err error) {
 		tc.Certificates = []tls.Certificate{*cert}
 	}
 
-	if len(c.CaFile) <= 0 {
-		tc.InsecureSkipVerify = true
-		return tc, nil
-	}
 	if len(c.ServerNameOverride) > 0 {
 		tc.ServerName = c.ServerNameOverride
 	}
+	tc.InsecureSkipVerify = c.InsecureSkipVerify
+	if len(c.CaFile) <= 0 {
+		return tc, nil
+	}
 	tc.RootCAs, err = rootCert(c.CaFile)
 	return
 }
@@ -17,9 +17,13 @@
 package tls
 
 import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
 	"github.com/stretchr/testify/assert"
@@ -112,6 +116,29 @@ func Test_NewTLS(t *testing.T) {
 
 	})
 
+	t.Run("TestSystemRoots", func(t *testing.T) {
+		cfg, err := NewTLS(constant.TLSConfig{Enable: true})
+		assert.Nil(t, err)
+		assert.Equal(t, false, cfg.InsecureSkipVerify)
+		assert.Nil(t, cfg.RootCAs)
+
+		// the self-signed server is rejected without the ca file
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+		address := server.Listener.Addr().String()
+		conn, err := tls.Dial("tcp", address, cfg)
+		if err == nil {
+			conn.Close()
+		}
+		assert.NotNil(t, err)
+
+		cfg, err = NewTLS(constant.SkipVerifyConfig)
+		assert.Nil(t, err)
+		conn, err = tls.Dial("tcp", address, cfg)
+		assert.Nil(t, err)
+		conn.Close()
+	})
+
 	t.Run("TestClientAuth", func(t *testing.T) {
 		cfg, err := NewTLS(*constant.NewTLSConfig(
 			constant.WithCA(caPath, ""),
@@ -134,3 +161,49 @@ func Test_NewTLS(t *testing.T) {
 		assert.NotNil(t, cfg.Certificates)
 	})
 }
+
+func Test_Reloader(t *testing.T) {
+	dir, err := os.MkdirTemp("", "tls-reload-test")
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	caPath, crtPath, keyPath := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	os.WriteFile(caPath, testCaCrt, 0666)
+	os.WriteFile(crtPath, testClientCrt, 0666)
+	os.WriteFile(keyPath, testClientKey, 0666)
+
+	reloader, err := NewReloader(*constant.NewTLSConfig(
+		constant.WithCA(caPath, ""),
+		constant.WithCertificate(crtPath, keyPath),
+	))
+	assert.Nil(t, err)
+	initial := reloader.Config()
+	assert.NotNil(t, initial.Certificates)
+
+	modified := time.Now().Add(time.Minute)
+	expireCheck := func() {
+		reloader.mutex.Lock()
+		reloader.checkedAt = time.Time{}
+		reloader.mutex.Unlock()
+	}
+
+	// the invalid files keep the previous config
+	os.WriteFile(crtPath, []byte("invalid"), 0666)
+	os.Chtimes(crtPath, modified, modified)
+	expireCheck()
+	assert.Same(t, initial, reloader.Config())
+
+	// the rotated files replace it
+	os.WriteFile(crtPath, testClientCrt, 0666)
+	os.Chtimes(crtPath, modified.Add(time.Minute), modified.Add(time.Minute))
+	expireCheck()
+	reloaded := reloader.Config()
+	assert.NotSame(t, initial, reloaded)
+	assert.NotNil(t, reloaded.Certificates)
+
+	// the unmodified files are not reloaded
+	expireCheck()
+	assert.Same(t, reloaded, reloader.Config())
+}
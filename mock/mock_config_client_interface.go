@@ -21,9 +21,14 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	config_client "github.com/jun3372/nacos-sdk-go/clients/config_client"
+	rpc "github.com/jun3372/nacos-sdk-go/common/remote/rpc"
+	rpc_request "github.com/jun3372/nacos-sdk-go/common/remote/rpc/rpc_request"
+	model "github.com/jun3372/nacos-sdk-go/model"
 	vo "github.com/jun3372/nacos-sdk-go/vo"
 )
 
@@ -149,3 +154,512 @@ func (mr *MockIConfigClientMockRecorder) GetConfigContent(dataId, groupId interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigContent", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigContent), dataId, groupId)
 }
+
+// ListenConfigWithPrefix mocks base method
+func (m *MockIConfigClient) ListenConfigWithPrefix(params vo.ConfigParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenConfigWithPrefix", params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenConfigWithPrefix indicates an expected call of ListenConfigWithPrefix
+func (mr *MockIConfigClientMockRecorder) ListenConfigWithPrefix(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenConfigWithPrefix", reflect.TypeOf((*MockIConfigClient)(nil).ListenConfigWithPrefix), params)
+}
+
+// CancelListenConfigWithPrefix mocks base method
+func (m *MockIConfigClient) CancelListenConfigWithPrefix(params vo.ConfigParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelListenConfigWithPrefix", params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelListenConfigWithPrefix indicates an expected call of CancelListenConfigWithPrefix
+func (mr *MockIConfigClientMockRecorder) CancelListenConfigWithPrefix(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelListenConfigWithPrefix", reflect.TypeOf((*MockIConfigClient)(nil).CancelListenConfigWithPrefix), params)
+}
+
+// PublishConfigBeta mocks base method
+func (m *MockIConfigClient) PublishConfigBeta(param vo.ConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfigBeta", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfigBeta indicates an expected call of PublishConfigBeta
+func (mr *MockIConfigClientMockRecorder) PublishConfigBeta(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfigBeta", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfigBeta), param)
+}
+
+// GetConfigBeta mocks base method
+func (m *MockIConfigClient) GetConfigBeta(param vo.ConfigParam) (*model.ConfigBetaItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigBeta", param)
+	ret0, _ := ret[0].(*model.ConfigBetaItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigBeta indicates an expected call of GetConfigBeta
+func (mr *MockIConfigClientMockRecorder) GetConfigBeta(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigBeta", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigBeta), param)
+}
+
+// StopConfigBeta mocks base method
+func (m *MockIConfigClient) StopConfigBeta(param vo.ConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopConfigBeta", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopConfigBeta indicates an expected call of StopConfigBeta
+func (mr *MockIConfigClientMockRecorder) StopConfigBeta(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopConfigBeta", reflect.TypeOf((*MockIConfigClient)(nil).StopConfigBeta), param)
+}
+
+// GetConfigHistory mocks base method
+func (m *MockIConfigClient) GetConfigHistory(param vo.ConfigHistoryParam) (*model.ConfigHistoryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigHistory", param)
+	ret0, _ := ret[0].(*model.ConfigHistoryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigHistory indicates an expected call of GetConfigHistory
+func (mr *MockIConfigClientMockRecorder) GetConfigHistory(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigHistory", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigHistory), param)
+}
+
+// GetConfigHistoryDetail mocks base method
+func (m *MockIConfigClient) GetConfigHistoryDetail(param vo.ConfigHistoryDetailParam) (*model.ConfigHistoryItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigHistoryDetail", param)
+	ret0, _ := ret[0].(*model.ConfigHistoryItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigHistoryDetail indicates an expected call of GetConfigHistoryDetail
+func (mr *MockIConfigClientMockRecorder) GetConfigHistoryDetail(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigHistoryDetail", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigHistoryDetail), param)
+}
+
+// RollbackConfig mocks base method
+func (m *MockIConfigClient) RollbackConfig(param vo.ConfigHistoryDetailParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackConfig", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackConfig indicates an expected call of RollbackConfig
+func (mr *MockIConfigClientMockRecorder) RollbackConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackConfig", reflect.TypeOf((*MockIConfigClient)(nil).RollbackConfig), param)
+}
+
+// GetConfigAs mocks base method
+func (m *MockIConfigClient) GetConfigAs(param vo.ConfigParam, v interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigAs", param, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetConfigAs indicates an expected call of GetConfigAs
+func (mr *MockIConfigClientMockRecorder) GetConfigAs(param, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigAs", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigAs), param, v)
+}
+
+// ListenConfigAs mocks base method
+func (m *MockIConfigClient) ListenConfigAs(params vo.ConfigParam, newValue func() interface{}, onChange vo.TypedListener) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenConfigAs", params, newValue, onChange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenConfigAs indicates an expected call of ListenConfigAs
+func (mr *MockIConfigClientMockRecorder) ListenConfigAs(params, newValue, onChange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenConfigAs", reflect.TypeOf((*MockIConfigClient)(nil).ListenConfigAs), params, newValue, onChange)
+}
+
+// BatchPublishConfig mocks base method
+func (m *MockIConfigClient) BatchPublishConfig(params []vo.ConfigParam) []model.BatchConfigResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchPublishConfig", params)
+	ret0, _ := ret[0].([]model.BatchConfigResult)
+	return ret0
+}
+
+// BatchPublishConfig indicates an expected call of BatchPublishConfig
+func (mr *MockIConfigClientMockRecorder) BatchPublishConfig(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchPublishConfig", reflect.TypeOf((*MockIConfigClient)(nil).BatchPublishConfig), params)
+}
+
+// BatchGetConfig mocks base method
+func (m *MockIConfigClient) BatchGetConfig(params []vo.ConfigParam) []model.BatchConfigResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGetConfig", params)
+	ret0, _ := ret[0].([]model.BatchConfigResult)
+	return ret0
+}
+
+// BatchGetConfig indicates an expected call of BatchGetConfig
+func (mr *MockIConfigClientMockRecorder) BatchGetConfig(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGetConfig", reflect.TypeOf((*MockIConfigClient)(nil).BatchGetConfig), params)
+}
+
+// ExportConfig mocks base method
+func (m *MockIConfigClient) ExportConfig(param vo.ExportConfigParam) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportConfig", param)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportConfig indicates an expected call of ExportConfig
+func (mr *MockIConfigClientMockRecorder) ExportConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportConfig", reflect.TypeOf((*MockIConfigClient)(nil).ExportConfig), param)
+}
+
+// ImportConfig mocks base method
+func (m *MockIConfigClient) ImportConfig(param vo.ImportConfigParam) (*model.ConfigImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportConfig", param)
+	ret0, _ := ret[0].(*model.ConfigImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportConfig indicates an expected call of ImportConfig
+func (mr *MockIConfigClientMockRecorder) ImportConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportConfig", reflect.TypeOf((*MockIConfigClient)(nil).ImportConfig), param)
+}
+
+// PublishConfigCas mocks base method
+func (m *MockIConfigClient) PublishConfigCas(param vo.ConfigParam, expectedMd5 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfigCas", param, expectedMd5)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfigCas indicates an expected call of PublishConfigCas
+func (mr *MockIConfigClientMockRecorder) PublishConfigCas(param, expectedMd5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfigCas", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfigCas), param, expectedMd5)
+}
+
+// GetConfigAdvanceInfo mocks base method
+func (m *MockIConfigClient) GetConfigAdvanceInfo(param vo.ConfigParam) (*model.ConfigAdvanceInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigAdvanceInfo", param)
+	ret0, _ := ret[0].(*model.ConfigAdvanceInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigAdvanceInfo indicates an expected call of GetConfigAdvanceInfo
+func (mr *MockIConfigClientMockRecorder) GetConfigAdvanceInfo(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigAdvanceInfo", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigAdvanceInfo), param)
+}
+
+// Watch mocks base method
+func (m *MockIConfigClient) Watch(param vo.ConfigParam) (*config_client.ConfigRef, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", param)
+	ret0, _ := ret[0].(*config_client.ConfigRef)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockIConfigClientMockRecorder) Watch(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockIConfigClient)(nil).Watch), param)
+}
+
+// DeleteConfigWithScope mocks base method
+func (m *MockIConfigClient) DeleteConfigWithScope(param vo.DeleteConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteConfigWithScope", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteConfigWithScope indicates an expected call of DeleteConfigWithScope
+func (mr *MockIConfigClientMockRecorder) DeleteConfigWithScope(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConfigWithScope", reflect.TypeOf((*MockIConfigClient)(nil).DeleteConfigWithScope), param)
+}
+
+// WaitForInitialSync mocks base method
+func (m *MockIConfigClient) WaitForInitialSync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForInitialSync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForInitialSync indicates an expected call of WaitForInitialSync
+func (mr *MockIConfigClientMockRecorder) WaitForInitialSync(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForInitialSync", reflect.TypeOf((*MockIConfigClient)(nil).WaitForInitialSync), ctx)
+}
+
+// ListListeners mocks base method
+func (m *MockIConfigClient) ListListeners() []model.ConfigListenerInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListListeners")
+	ret0, _ := ret[0].([]model.ConfigListenerInfo)
+	return ret0
+}
+
+// ListListeners indicates an expected call of ListListeners
+func (mr *MockIConfigClientMockRecorder) ListListeners() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListListeners", reflect.TypeOf((*MockIConfigClient)(nil).ListListeners))
+}
+
+// CloneConfig mocks base method
+func (m *MockIConfigClient) CloneConfig(srcNamespace string, dstNamespace string, items []vo.CloneConfigItem, policy vo.ImportPolicy) (*model.ConfigImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneConfig", srcNamespace, dstNamespace, items, policy)
+	ret0, _ := ret[0].(*model.ConfigImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneConfig indicates an expected call of CloneConfig
+func (mr *MockIConfigClientMockRecorder) CloneConfig(srcNamespace, dstNamespace, items, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneConfig", reflect.TypeOf((*MockIConfigClient)(nil).CloneConfig), srcNamespace, dstNamespace, items, policy)
+}
+
+// ServerHealth mocks base method
+func (m *MockIConfigClient) ServerHealth() model.ServerHealth {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServerHealth")
+	ret0, _ := ret[0].(model.ServerHealth)
+	return ret0
+}
+
+// ServerHealth indicates an expected call of ServerHealth
+func (mr *MockIConfigClientMockRecorder) ServerHealth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerHealth", reflect.TypeOf((*MockIConfigClient)(nil).ServerHealth))
+}
+
+// GetAllNamespaces mocks base method
+func (m *MockIConfigClient) GetAllNamespaces() ([]model.Namespace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllNamespaces")
+	ret0, _ := ret[0].([]model.Namespace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllNamespaces indicates an expected call of GetAllNamespaces
+func (mr *MockIConfigClientMockRecorder) GetAllNamespaces() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNamespaces", reflect.TypeOf((*MockIConfigClient)(nil).GetAllNamespaces))
+}
+
+// CreateNamespace mocks base method
+func (m *MockIConfigClient) CreateNamespace(param vo.NamespaceParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNamespace", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNamespace indicates an expected call of CreateNamespace
+func (mr *MockIConfigClientMockRecorder) CreateNamespace(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNamespace", reflect.TypeOf((*MockIConfigClient)(nil).CreateNamespace), param)
+}
+
+// UpdateNamespace mocks base method
+func (m *MockIConfigClient) UpdateNamespace(param vo.NamespaceParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNamespace", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNamespace indicates an expected call of UpdateNamespace
+func (mr *MockIConfigClientMockRecorder) UpdateNamespace(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNamespace", reflect.TypeOf((*MockIConfigClient)(nil).UpdateNamespace), param)
+}
+
+// DeleteNamespace mocks base method
+func (m *MockIConfigClient) DeleteNamespace(namespaceId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNamespace", namespaceId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNamespace indicates an expected call of DeleteNamespace
+func (mr *MockIConfigClientMockRecorder) DeleteNamespace(namespaceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNamespace", reflect.TypeOf((*MockIConfigClient)(nil).DeleteNamespace), namespaceId)
+}
+
+// ListenKeys mocks base method
+func (m *MockIConfigClient) ListenKeys(param vo.ConfigParam, keys []string, onChange vo.KeysListener) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenKeys", param, keys, onChange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenKeys indicates an expected call of ListenKeys
+func (mr *MockIConfigClientMockRecorder) ListenKeys(param, keys, onChange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenKeys", reflect.TypeOf((*MockIConfigClient)(nil).ListenKeys), param, keys, onChange)
+}
+
+// GetConfigWithResult mocks base method
+func (m *MockIConfigClient) GetConfigWithResult(param vo.ConfigParam) (*model.GetConfigResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigWithResult", param)
+	ret0, _ := ret[0].(*model.GetConfigResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigWithResult indicates an expected call of GetConfigWithResult
+func (mr *MockIConfigClientMockRecorder) GetConfigWithResult(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigWithResult", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigWithResult), param)
+}
+
+// WatchGroup mocks base method
+func (m *MockIConfigClient) WatchGroup(params []vo.ConfigParam, combiner vo.ConfigCombiner) (*config_client.ConfigGroupRef, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchGroup", params, combiner)
+	ret0, _ := ret[0].(*config_client.ConfigGroupRef)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchGroup indicates an expected call of WatchGroup
+func (mr *MockIConfigClientMockRecorder) WatchGroup(params, combiner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchGroup", reflect.TypeOf((*MockIConfigClient)(nil).WatchGroup), params, combiner)
+}
+
+// AddRequestHook mocks base method
+func (m *MockIConfigClient) AddRequestHook(hook config_client.ConfigRequestHook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRequestHook", hook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRequestHook indicates an expected call of AddRequestHook
+func (mr *MockIConfigClientMockRecorder) AddRequestHook(hook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRequestHook", reflect.TypeOf((*MockIConfigClient)(nil).AddRequestHook), hook)
+}
+
+// WatchConnection mocks base method
+func (m *MockIConfigClient) WatchConnection(param *vo.WatchConnectionParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchConnection", param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchConnection indicates an expected call of WatchConnection
+func (mr *MockIConfigClientMockRecorder) WatchConnection(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchConnection", reflect.TypeOf((*MockIConfigClient)(nil).WatchConnection), param)
+}
+
+// UnwatchConnection mocks base method
+func (m *MockIConfigClient) UnwatchConnection(param *vo.WatchConnectionParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnwatchConnection", param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnwatchConnection indicates an expected call of UnwatchConnection
+func (mr *MockIConfigClientMockRecorder) UnwatchConnection(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnwatchConnection", reflect.TypeOf((*MockIConfigClient)(nil).UnwatchConnection), param)
+}
+
+// AddInterceptor mocks base method
+func (m *MockIConfigClient) AddInterceptor(interceptor rpc.Interceptor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddInterceptor", interceptor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddInterceptor indicates an expected call of AddInterceptor
+func (mr *MockIConfigClientMockRecorder) AddInterceptor(interceptor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddInterceptor", reflect.TypeOf((*MockIConfigClient)(nil).AddInterceptor), interceptor)
+}
+
+// RegisterServerRequestHandler mocks base method
+func (m *MockIConfigClient) RegisterServerRequestHandler(request func() rpc_request.IRequest, handler rpc.IServerRequestHandler) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterServerRequestHandler", request, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterServerRequestHandler indicates an expected call of RegisterServerRequestHandler
+func (mr *MockIConfigClientMockRecorder) RegisterServerRequestHandler(request, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterServerRequestHandler", reflect.TypeOf((*MockIConfigClient)(nil).RegisterServerRequestHandler), request, handler)
+}
+
+// ActiveProtocol mocks base method
+func (m *MockIConfigClient) ActiveProtocol() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveProtocol")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ActiveProtocol indicates an expected call of ActiveProtocol
+func (mr *MockIConfigClientMockRecorder) ActiveProtocol() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveProtocol", reflect.TypeOf((*MockIConfigClient)(nil).ActiveProtocol))
+}
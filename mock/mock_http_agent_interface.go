@@ -138,3 +138,18 @@ func (mr *MockIHttpAgentMockRecorder) Request(method, path, header, timeoutMs, p
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockIHttpAgent)(nil).Request), method, path, header, timeoutMs, params)
 }
+
+// RequestWithBody mocks base method
+func (m *MockIHttpAgent) RequestWithBody(method string, path string, header http.Header, timeoutMs uint64, body []byte) (*http.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestWithBody", method, path, header, timeoutMs, body)
+	ret0, _ := ret[0].(*http.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestWithBody indicates an expected call of RequestWithBody
+func (mr *MockIHttpAgentMockRecorder) RequestWithBody(method, path, header, timeoutMs, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestWithBody", reflect.TypeOf((*MockIHttpAgent)(nil).RequestWithBody), method, path, header, timeoutMs, body)
+}
@@ -16,7 +16,10 @@
 
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ConfigItem struct {
 	Id      json.Number `param:"id"`
@@ -46,3 +49,157 @@ type ConfigContext struct {
 	DataId string `json:"dataId"`
 	Tenant string `json:"tenant"`
 }
+
+type ConfigFuzzyWatchContext struct {
+	GroupKey    string `json:"groupKey"`
+	ChangedType string `json:"changedType"`
+}
+
+type ConfigBetaItem struct {
+	Id               json.Number `param:"id"`
+	DataId           string      `param:"dataId"`
+	Group            string      `param:"group"`
+	Content          string      `param:"content"`
+	Md5              string      `param:"md5"`
+	Tenant           string      `param:"tenant"`
+	AppName          string      `param:"appName"`
+	Type             string      `param:"type"`
+	BetaIps          string      `param:"betaIps"`
+	EncryptedDataKey string      `param:"encryptedDataKey"`
+	LastModified     int64       `param:"lastModified"`
+}
+
+type ConfigAdvanceInfo struct {
+	Id               json.Number `param:"id"`
+	DataId           string      `param:"dataId"`
+	Group            string      `param:"group"`
+	Tenant           string      `param:"tenant"`
+	Content          string      `param:"content"`
+	Md5              string      `param:"md5"`
+	AppName          string      `param:"appName"`
+	Type             string      `param:"type"`
+	ConfigTags       string      `param:"configTags"`
+	Desc             string      `param:"desc"`
+	Use              string      `param:"use"`
+	Effect           string      `param:"effect"`
+	Schema           string      `param:"schema"`
+	CreateUser       string      `param:"createUser"`
+	CreateIp         string      `param:"createIp"`
+	CreateTime       int64       `param:"createTime"`
+	ModifyTime       int64       `param:"modifyTime"`
+	EncryptedDataKey string      `param:"encryptedDataKey"`
+}
+
+type ConfigHistoryItem struct {
+	Id               json.Number `param:"id"`
+	LastId           json.Number `param:"lastId"`
+	DataId           string      `param:"dataId"`
+	Group            string      `param:"group"`
+	Tenant           string      `param:"tenant"`
+	AppName          string      `param:"appName"`
+	Md5              string      `param:"md5"`
+	Content          string      `param:"content"`
+	SrcIp            string      `param:"srcIp"`
+	SrcUser          string      `param:"srcUser"`
+	OpType           string      `param:"opType"`
+	EncryptedDataKey string      `param:"encryptedDataKey"`
+	CreatedTime      string      `param:"createdTime"`
+	LastModifiedTime string      `param:"lastModifiedTime"`
+}
+
+type ConfigHistoryPage struct {
+	TotalCount     int                 `param:"totalCount"`
+	PageNumber     int                 `param:"pageNumber"`
+	PagesAvailable int                 `param:"pagesAvailable"`
+	PageItems      []ConfigHistoryItem `param:"pageItems"`
+}
+
+type ConfigImportItem struct {
+	DataId string `param:"dataId"`
+	Group  string `param:"group"`
+}
+
+type ConfigImportUnrecognizedItem struct {
+	ItemName string `param:"itemName"`
+	ItemType string `param:"itemType"`
+}
+
+type ConfigImportResult struct {
+	SuccCount         int                            `param:"succCount"`
+	SkipCount         int                            `param:"skipCount"`
+	FailCount         int                            `param:"failCount"`
+	UnrecognizedCount int                            `param:"unrecognizedCount"`
+	SkipData          []ConfigImportItem             `param:"skipData"`
+	FailData          []ConfigImportItem             `param:"failData"`
+	UnrecognizedData  []ConfigImportUnrecognizedItem `param:"unrecognizedData"`
+}
+
+// BatchConfigResult is the result of one item of a batch config operation, Content is only set by gets.
+type BatchConfigResult struct {
+	DataId  string
+	Group   string
+	Content string
+	Success bool
+	Err     error
+}
+
+type Namespace struct {
+	Namespace         string `json:"namespace"`
+	NamespaceShowName string `json:"namespaceShowName"`
+	NamespaceDesc     string `json:"namespaceDesc"`
+	Quota             int    `json:"quota"`
+	ConfigCount       int    `json:"configCount"`
+	Type              int    `json:"type"`
+}
+
+// ConfigEvent is a config change pushed by the server, Type is one of CHANGED, ADD_CONFIG and DELETE_CONFIG.
+type ConfigEvent struct {
+	Type   string
+	DataId string
+	Group  string
+	Tenant string
+	Time   time.Time
+}
+
+// GetConfigResult is the config content and where it comes from, Source is one of local, failover, server and snapshot.
+type GetConfigResult struct {
+	Content      string
+	Source       string
+	Md5          string
+	LastModified time.Time
+}
+
+// ConfigListenerInfo describe a listened config, all the listeners of it share one listen entry on the server.
+type ConfigListenerInfo struct {
+	DataId           string
+	Group            string
+	Tenant           string
+	Md5              string
+	ListenerCount    int
+	IsSyncWithServer bool
+}
+
+// ServerStatus is the connectivity of a client to one nacos server.
+type ServerStatus struct {
+	Address       string
+	Connected     bool
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// ConnectionEvent is a change of the rpc connection of a client, Type is CONNECTED for the first connection,
+// RECONNECTED to the same server, SWITCHED to another server whose previous one is PreviousServer, or DISCONNECTED.
+type ConnectionEvent struct {
+	Type           string
+	Server         string
+	PreviousServer string
+	ConnectionId   string
+	Time           time.Time
+}
+
+// ServerHealth is the connectivity of a client to the nacos cluster, ConnectionState is the state of the rpc client.
+type ServerHealth struct {
+	Healthy         bool
+	ConnectionState string
+	Servers         []ServerStatus
+}
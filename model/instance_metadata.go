@@ -0,0 +1,55 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+)
+
+// GetHeartBeatInterval return the preserved heartbeat interval of the instance, or the server default.
+func (i Instance) GetHeartBeatInterval() time.Duration {
+	return i.preservedDuration(constant.HEART_BEAT_INTERVAL, constant.DEFAULT_HEART_BEAT_INTERVAL)
+}
+
+// GetHeartBeatTimeout return the preserved heartbeat timeout of the instance, or the server default.
+func (i Instance) GetHeartBeatTimeout() time.Duration {
+	return i.preservedDuration(constant.HEART_BEAT_TIMEOUT, constant.DEFAULT_HEART_BEAT_TIMEOUT)
+}
+
+// GetIpDeleteTimeout return the preserved ip delete timeout of the instance, or the server default.
+func (i Instance) GetIpDeleteTimeout() time.Duration {
+	return i.preservedDuration(constant.IP_DELETE_TIMEOUT, constant.DEFAULT_IP_DELETE_TIMEOUT)
+}
+
+// GetInstanceIdGenerator return the preserved instance id generator of the instance, or the simple one.
+func (i Instance) GetInstanceIdGenerator() string {
+	if generator, ok := i.Metadata[constant.INSTANCE_ID_GENERATOR]; ok && len(generator) > 0 {
+		return generator
+	}
+	return constant.INSTANCE_ID_GENERATOR_SIMPLE
+}
+
+func (i Instance) preservedDuration(key string, defaultDuration time.Duration) time.Duration {
+	value, err := strconv.ParseInt(i.Metadata[key], 10, 64)
+	if err != nil || value <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(value) * time.Millisecond
+}
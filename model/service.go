@@ -52,6 +52,33 @@ type Service struct {
 	ReachProtectionThreshold bool       `json:"reachProtectionThreshold"`
 }
 
+// InstancesDiff is the change of the instances of a service, the instances are identified by ip, port and cluster.
+type InstancesDiff struct {
+	Added    []Instance
+	Removed  []Instance
+	Modified []Instance
+}
+
+// InstanceHealthEvent is a change of an instance registered by the client as the server sees it.
+type InstanceHealthEvent struct {
+	ServiceName string
+	GroupName   string
+	Instance    Instance // the instance as it's registered
+	Healthy     bool     // the server reports the instance healthy
+	Missing     bool     // the server doesn't list the instance, e.g. its heartbeats timed out
+}
+
+// ServiceDefinition is the service level settings of a service, such as the protect threshold and the selector.
+type ServiceDefinition struct {
+	NamespaceId      string             `json:"namespaceId"`
+	GroupName        string             `json:"groupName"`
+	Name             string             `json:"name"`
+	ProtectThreshold float64            `json:"protectThreshold"`
+	Metadata         map[string]string  `json:"metadata"`
+	Selector         ExpressionSelector `json:"selector"`
+	Clusters         []Cluster          `json:"clusters"`
+}
+
 type ServiceDetail struct {
 	Service  ServiceInfo `json:"service"`
 	Clusters []Cluster   `json:"clusters"`
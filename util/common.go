@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jun3372/nacos-sdk-go/common/constant"
@@ -70,6 +71,7 @@ var localIP = ""
 
 func LocalIP() string {
 	if localIP == "" {
+		var ipv6 string
 		netInterfaces, err := net.Interfaces()
 		if err != nil {
 			logger.Errorf("get Interfaces failed,err:%+v", err)
@@ -88,9 +90,16 @@ func LocalIP() string {
 						localIP = ipnet.IP.String()
 						break
 					}
+					// fallback to the global ipv6 address on the ipv6 only host
+					if ipnet, ok := address.(*net.IPNet); ok && len(ipv6) == 0 && ipnet.IP.IsGlobalUnicast() && ipnet.IP.To4() == nil {
+						ipv6 = ipnet.IP.String()
+					}
 				}
 			}
 		}
+		if len(localIP) == 0 {
+			localIP = ipv6
+		}
 
 		if len(localIP) > 0 {
 			logger.Infof("Local IP:%s", localIP)
@@ -99,6 +108,8 @@ func LocalIP() string {
 	return localIP
 }
 
+// GetDurationWithDefault return the duration in nanoseconds of the metadata key, the preserved metadata in
+// milliseconds are read by the accessors of model.Instance.
 func GetDurationWithDefault(metadata map[string]string, key string, defaultDuration time.Duration) time.Duration {
 	data, ok := metadata[key]
 	if ok {
@@ -139,3 +150,9 @@ func DeepCopyMap(params map[string]string) map[string]string {
 	}
 	return result
 }
+
+// IsLegacyServerVersion return true if the version of the nacos server is 1.x or older, which has no grpc.
+func IsLegacyServerVersion(version string) bool {
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimSpace(version), ".", 2)[0])
+	return err == nil && major < 2
+}
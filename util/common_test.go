@@ -0,0 +1,40 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDurationWithDefault(t *testing.T) {
+	for _, test := range []struct {
+		metadata map[string]string
+		expected time.Duration
+	}{
+		{metadata: nil, expected: time.Second},
+		{metadata: map[string]string{"other": "10"}, expected: time.Second},
+		{metadata: map[string]string{"duration": "100"}, expected: 100},
+		{metadata: map[string]string{"duration": "5000000000"}, expected: 5 * time.Second},
+		{metadata: map[string]string{"duration": "5s"}, expected: time.Second},
+		{metadata: map[string]string{"duration": ""}, expected: time.Second},
+	} {
+		assert.Equal(t, test.expected, GetDurationWithDefault(test.metadata, "duration", time.Second), "%v", test.metadata)
+	}
+}
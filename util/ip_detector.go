@@ -0,0 +1,124 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DetectIP return the ip of the first source having one: the environment variable env, the network
+// interface named iface, then the addresses of the up interfaces in the first of the preferred CIDRs
+// containing one. The empty sources are skipped.
+func DetectIP(env, iface string, preferredNetworks []string) (string, error) {
+	if len(env) > 0 {
+		if value := strings.TrimSpace(os.Getenv(env)); len(value) > 0 {
+			if net.ParseIP(value) == nil {
+				return "", errors.Errorf("env %s is not an ip: %s", env, value)
+			}
+			return value, nil
+		}
+	}
+	if len(iface) > 0 {
+		ip, err := interfaceIP(iface)
+		if err == nil || len(preferredNetworks) == 0 {
+			return ip, err
+		}
+	}
+	if len(preferredNetworks) > 0 {
+		return preferredNetworkIP(preferredNetworks)
+	}
+	return "", errors.Errorf("no ip is detected from env:%s, interface:%s", env, iface)
+}
+
+// interfaceIP return the first ipv4 address of the network interface, or its first global ipv6 address.
+func interfaceIP(name string) (string, error) {
+	netInterface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "get network interface %s", name)
+	}
+	addrs, err := netInterface.Addrs()
+	if err != nil {
+		return "", errors.Wrapf(err, "get addresses of network interface %s", name)
+	}
+	var ipv6 string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP.String(), nil
+		}
+		if len(ipv6) == 0 && ipNet.IP.IsGlobalUnicast() {
+			ipv6 = ipNet.IP.String()
+		}
+	}
+	if len(ipv6) > 0 {
+		return ipv6, nil
+	}
+	return "", errors.Errorf("network interface %s has no ip", name)
+}
+
+func preferredNetworkIP(preferredNetworks []string) (string, error) {
+	networks := make([]*net.IPNet, 0, len(preferredNetworks))
+	for _, cidr := range preferredNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return "", errors.Wrapf(err, "parse preferred network %s", cidr)
+		}
+		networks = append(networks, network)
+	}
+	netInterfaces, err := net.Interfaces()
+	if err != nil {
+		return "", errors.Wrap(err, "get network interfaces")
+	}
+	var ips []net.IP
+	for _, netInterface := range netInterfaces {
+		if netInterface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := netInterface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				ips = append(ips, ipNet.IP)
+			}
+		}
+	}
+	if ip := matchPreferredNetwork(ips, networks); ip != nil {
+		return ip.String(), nil
+	}
+	return "", errors.Errorf("no ip in the preferred networks %v", preferredNetworks)
+}
+
+// matchPreferredNetwork return the first ip in the first network containing one.
+func matchPreferredNetwork(ips []net.IP, networks []*net.IPNet) net.IP {
+	for _, network := range networks {
+		for _, ip := range ips {
+			if network.Contains(ip) {
+				return ip
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectIP(t *testing.T) {
+	t.Setenv("TEST_POD_IP", "10.1.2.3")
+	ip, err := DetectIP("TEST_POD_IP", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.2.3", ip)
+
+	t.Setenv("TEST_POD_IP", "not-an-ip")
+	_, err = DetectIP("TEST_POD_IP", "", nil)
+	assert.NotNil(t, err)
+
+	_, err = DetectIP("TEST_UNSET_IP", "", nil)
+	assert.NotNil(t, err)
+	_, err = DetectIP("", "", []string{"invalid"})
+	assert.NotNil(t, err)
+
+	ip, err = DetectIP("TEST_UNSET_IP", "", []string{"127.0.0.0/8"})
+	assert.Nil(t, err)
+	assert.True(t, net.ParseIP(ip).IsLoopback())
+}
+
+func TestMatchPreferredNetwork(t *testing.T) {
+	ips := []net.IP{net.ParseIP("192.168.1.10"), net.ParseIP("10.0.0.5"), net.ParseIP("fd00::5")}
+	var networks []*net.IPNet
+	for _, cidr := range []string{"172.16.0.0/12", "10.0.0.0/8", "192.168.0.0/16"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	assert.Equal(t, "10.0.0.5", matchPreferredNetwork(ips, networks).String())
+	assert.Nil(t, matchPreferredNetwork(ips, networks[:1]))
+}
@@ -16,9 +16,16 @@
 
 package vo
 
-import "github.com/jun3372/nacos-sdk-go/common/constant"
+import (
+	"github.com/jun3372/nacos-sdk-go/common/constant"
+	"github.com/jun3372/nacos-sdk-go/model"
+)
 
 type NacosClientParam struct {
 	ClientConfig  *constant.ClientConfig  // optional
 	ServerConfigs []constant.ServerConfig // optional
 }
+
+type WatchConnectionParam struct {
+	Callback func(event model.ConnectionEvent) //required
+}
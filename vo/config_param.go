@@ -16,12 +16,26 @@
 
 package vo
 
+import "io"
+
 type Listener func(namespace, group, dataId, data string)
 
+// TypedListener receive the config content decoded into the value created for each change,
+// err is set when the content can not be decoded.
+type TypedListener func(namespace, group, dataId string, value interface{}, err error)
+
+// KeysListener receive the changes of the listened keys, it's called only when at least one of them changes.
+type KeysListener func(namespace, group, dataId string, changes map[string]ConfigChangeItem)
+
+// ConfigCombiner combine the contents of a config group into one value, the contents are keyed by
+// util.GetConfigCacheKey of each member.
+type ConfigCombiner func(contents map[string]string) (interface{}, error)
+
 type ConfigParam struct {
 	DataId           string    `param:"dataId"`  //required
 	Group            string    `param:"group"`   //required
 	Content          string    `param:"content"` //required
+	NamespaceId      string    `param:"namespaceId"`
 	Tag              string    `param:"tag"`
 	AppName          string    `param:"appName"`
 	BetaIps          string    `param:"betaIps"`
@@ -30,8 +44,14 @@ type ConfigParam struct {
 	SrcUser          string    `param:"srcUser"`
 	EncryptedDataKey string    `param:"encryptedDataKey"`
 	KmsKeyId         string    `param:"kmsKeyId"`
+	ConfigTags       string    `param:"configTags"`
+	Desc             string    `param:"desc"`
+	Use              string    `param:"use"`
+	Effect           string    `param:"effect"`
+	Schema           string    `param:"schema"`
 	UsageType        UsageType `param:"usageType"`
 	OnChange         func(namespace, group, dataId, data string)
+	OnChangeV2       func(event ConfigChangeEvent)
 }
 
 func (this *ConfigParam) DeepCopy() *ConfigParam {
@@ -50,12 +70,116 @@ const (
 	ResponseType UsageType = "ResponseType"
 )
 
+// DeleteScope decide which version of a config is deleted.
+type DeleteScope string
+
+const (
+	DeleteScopeAll  DeleteScope = "ALL"
+	DeleteScopeBeta DeleteScope = "BETA"
+	DeleteScopeTag  DeleteScope = "TAG"
+)
+
+type DeleteConfigParam struct {
+	DataId      string      `param:"dataId"` //required
+	Group       string      `param:"group"`  //required
+	Tag         string      `param:"tag"`    //required when Scope is TAG
+	Scope       DeleteScope `param:"scope"`
+	NamespaceId string      `param:"namespaceId"`
+}
+
 type SearchConfigParam struct {
-	Search   string `param:"search"`
-	DataId   string `param:"dataId"`
-	Group    string `param:"group"`
-	Tag      string `param:"tag"`
-	AppName  string `param:"appName"`
-	PageNo   int    `param:"pageNo"`
-	PageSize int    `param:"pageSize"`
+	Search      string `param:"search"`
+	DataId      string `param:"dataId"`
+	Group       string `param:"group"`
+	Tag         string `param:"tag"`
+	AppName     string `param:"appName"`
+	PageNo      int    `param:"pageNo"`
+	PageSize    int    `param:"pageSize"`
+	NamespaceId string `param:"-"`
+}
+
+type ConfigHistoryParam struct {
+	DataId      string `param:"dataId"` //required
+	Group       string `param:"group"`  //required
+	PageNo      int    `param:"pageNo"`
+	PageSize    int    `param:"pageSize"`
+	NamespaceId string `param:"namespaceId"`
+}
+
+type ConfigHistoryDetailParam struct {
+	DataId      string `param:"dataId"` //required
+	Group       string `param:"group"`  //required
+	Nid         int64  `param:"nid"`    //required
+	NamespaceId string `param:"namespaceId"`
+}
+
+type ExportConfigParam struct {
+	Group       string `param:"group"`
+	DataId      string `param:"dataId"`
+	AppName     string `param:"appName"`
+	NamespaceId string `param:"namespaceId"`
+}
+
+// ImportConfigParam is the param to import a zip archive exported by the console or ExportConfig.
+type ImportConfigParam struct {
+	ZipReader   io.Reader    //required
+	Policy      ImportPolicy // default is ImportAbort
+	NamespaceId string
+}
+
+// NamespaceParam is the param to create or update a namespace, the server generates the NamespaceId on creating
+// when it's empty.
+type NamespaceParam struct {
+	NamespaceId   string
+	NamespaceName string //required
+	NamespaceDesc string
+}
+
+// CloneConfigItem is a config to clone, the TargetDataId and TargetGroup rename it in the target namespace.
+type CloneConfigItem struct {
+	DataId       string //required
+	Group        string //required
+	TargetDataId string
+	TargetGroup  string
+}
+
+// ImportPolicy decide what to do when an imported config already exists on the server.
+type ImportPolicy string
+
+const (
+	ImportAbort     ImportPolicy = "ABORT"
+	ImportSkip      ImportPolicy = "SKIP"
+	ImportOverwrite ImportPolicy = "OVERWRITE"
+)
+
+type ConfigChangeType string
+
+const (
+	ConfigAdded    ConfigChangeType = "ADDED"
+	ConfigModified ConfigChangeType = "MODIFIED"
+	ConfigDeleted  ConfigChangeType = "DELETED"
+)
+
+// ConfigChangeItem is the change of a key in properties, yaml, json or toml content.
+type ConfigChangeItem struct {
+	Key      string
+	OldValue string
+	NewValue string
+	Type     ConfigChangeType
+}
+
+// DiffLine is a line of the line-level diff, Op is "+" for added, "-" for removed and " " for unchanged.
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+type ConfigChangeEvent struct {
+	Namespace   string
+	Group       string
+	DataId      string
+	OldContent  string
+	NewContent  string
+	Diff        []DiffLine
+	ChangedKeys map[string]ConfigChangeItem // nil if the content is not structured
 }
@@ -16,10 +16,16 @@
 
 package vo
 
-import "github.com/jun3372/nacos-sdk-go/model"
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/jun3372/nacos-sdk-go/model"
+)
 
 type RegisterInstanceParam struct {
-	Ip          string            `param:"ip"`          //required
+	Ip          string            `param:"ip"`          //required,unless it's detected by IpEnv, IpInterface or IpPreferredNetworks
 	Port        uint64            `param:"port"`        //required
 	Weight      float64           `param:"weight"`      //required,it must be lager than 0
 	Enable      bool              `param:"enabled"`     //required,the instance can be access or not
@@ -28,12 +34,31 @@ type RegisterInstanceParam struct {
 	ClusterName string            `param:"clusterName"` //optional
 	ServiceName string            `param:"serviceName"` //required
 	GroupName   string            `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string            `param:"namespaceId"` //optional,default:the namespace of the client
 	Ephemeral   bool              `param:"ephemeral"`   //optional
+	// HealthSupplier optional,checked every BeatInterval, the instance is re-registered as unhealthy and disabled while it returns false
+	HealthSupplier func() bool
+	// IpEnv optional,detect the ip from the environment variable when Ip is empty, e.g. POD_IP
+	IpEnv string
+	// IpInterface optional,detect the ip from the network interface when Ip is empty and IpEnv is not set, e.g. eth0
+	IpInterface string
+	// IpPreferredNetworks optional,detect the ip in the first CIDR containing an ip of the host when Ip is empty and
+	// the sources above don't have one, e.g. []string{"10.0.0.0/8", "172.16.0.0/12"}
+	IpPreferredNetworks []string
+	// HeartBeatInterval optional,set the preserved metadata, the server defaults are used for the zero ones
+	HeartBeatInterval time.Duration
+	// HeartBeatTimeout optional,the instance turns unhealthy without heartbeat in it
+	HeartBeatTimeout time.Duration
+	// IpDeleteTimeout optional,the instance is removed without heartbeat in it
+	IpDeleteTimeout time.Duration
+	// InstanceIdGenerator optional,simple or snowflake
+	InstanceIdGenerator string
 }
 
 type BatchRegisterInstanceParam struct {
 	ServiceName string                  `param:"serviceName"` //required
 	GroupName   string                  `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string                  `param:"namespaceId"` //optional,default:the namespace of the client
 	Instances   []RegisterInstanceParam //required
 }
 
@@ -43,9 +68,47 @@ type DeregisterInstanceParam struct {
 	Cluster     string `param:"cluster"`     //optional
 	ServiceName string `param:"serviceName"` //required
 	GroupName   string `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string `param:"namespaceId"` //optional,default:the namespace of the client
 	Ephemeral   bool   `param:"ephemeral"`   //optional
 }
 
+type BatchDeregisterInstanceParam struct {
+	ServiceName string                    `param:"serviceName"` //required
+	GroupName   string                    `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string                    `param:"namespaceId"` //optional,default:the namespace of the client
+	Instances   []DeregisterInstanceParam //required
+}
+
+type UpdateInstanceMetadataParam struct {
+	Ip          string            `param:"ip"`          //required
+	Port        uint64            `param:"port"`        //required
+	ClusterName string            `param:"clusterName"` //optional
+	ServiceName string            `param:"serviceName"` //required
+	GroupName   string            `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string            `param:"namespaceId"` //optional,default:the namespace of the client
+	Ephemeral   bool              `param:"ephemeral"`   //optional
+	Metadata    map[string]string `param:"metadata"`    //required,the keys to add or overwrite, the other keys are kept
+}
+
+type SetInstanceWeightParam struct {
+	Ip          string  `param:"ip"`          //required
+	Port        uint64  `param:"port"`        //required
+	ClusterName string  `param:"clusterName"` //optional
+	ServiceName string  `param:"serviceName"` //required
+	GroupName   string  `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string  `param:"namespaceId"` //optional,default:the namespace of the client
+	Weight      float64 `param:"weight"`      //required,0 to stop the traffic
+}
+
+type ToggleInstanceParam struct {
+	Ip          string `param:"ip"`          //required
+	Port        uint64 `param:"port"`        //required
+	ClusterName string `param:"clusterName"` //optional
+	ServiceName string `param:"serviceName"` //required
+	GroupName   string `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string `param:"namespaceId"` //optional,default:the namespace of the client
+}
+
 type UpdateInstanceParam struct {
 	Ip          string            `param:"ip"`          //required
 	Port        uint64            `param:"port"`        //required
@@ -56,13 +119,46 @@ type UpdateInstanceParam struct {
 	ClusterName string            `param:"clusterName"` //optional
 	ServiceName string            `param:"serviceName"` //required
 	GroupName   string            `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string            `param:"namespaceId"` //optional,default:the namespace of the client
 	Ephemeral   bool              `param:"ephemeral"`   //optional
 }
 
+type CreateServiceParam struct {
+	ServiceName      string                    `param:"serviceName"`      //required
+	GroupName        string                    `param:"groupName"`        //optional,default:DEFAULT_GROUP
+	NamespaceId      string                    `param:"namespaceId"`      //optional,default:the namespace of the client
+	ProtectThreshold float64                   `param:"protectThreshold"` //optional,default:0,it must be between 0 and 1
+	Metadata         map[string]string         `param:"metadata"`         //optional
+	Selector         *model.ExpressionSelector `param:"selector"`         //optional,default:none
+}
+
+type UpdateServiceParam struct {
+	ServiceName      string                    `param:"serviceName"`      //required
+	GroupName        string                    `param:"groupName"`        //optional,default:DEFAULT_GROUP
+	NamespaceId      string                    `param:"namespaceId"`      //optional,default:the namespace of the client
+	ProtectThreshold float64                   `param:"protectThreshold"` //optional,default:0,it must be between 0 and 1
+	Metadata         map[string]string         `param:"metadata"`         //optional,it replaces the metadata of the service
+	Selector         *model.ExpressionSelector `param:"selector"`         //optional,default:none
+}
+
+type DeleteServiceParam struct {
+	ServiceName string `param:"serviceName"` //required
+	GroupName   string `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string `param:"namespaceId"` //optional,default:the namespace of the client
+}
+
+type GetServiceDetailParam struct {
+	ServiceName string `param:"serviceName"` //required
+	GroupName   string `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string `param:"namespaceId"` //optional,default:the namespace of the client
+}
+
 type GetServiceParam struct {
-	Clusters    []string `param:"clusters"`    //optional
-	ServiceName string   `param:"serviceName"` //required
-	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	Clusters    []string                  `param:"clusters"`    //optional
+	ServiceName string                    `param:"serviceName"` //required
+	GroupName   string                    `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string                    `param:"namespaceId"` //optional,default:the namespace of the client
+	Selector    *model.ExpressionSelector //optional,the label selector of the instances
 }
 
 type GetAllServiceInfoParam struct {
@@ -76,24 +172,83 @@ type SubscribeParam struct {
 	ServiceName       string                                     `param:"serviceName"` //required
 	Clusters          []string                                   `param:"clusters"`    //optional
 	GroupName         string                                     `param:"groupName"`   //optional,default:DEFAULT_GROUP
-	SubscribeCallback func(services []model.Instance, err error) //required
+	NamespaceId       string                                     `param:"namespaceId"` //optional,default:the namespace of the client
+	SubscribeCallback func(services []model.Instance, err error) //required,unless SubscribeDiffCallback is set
+	// SubscribeDiffCallback receive the diff between the previous and new instances as well, optional
+	SubscribeDiffCallback func(services []model.Instance, diff model.InstancesDiff, err error)
+	// SubsetSize limit the instances passed to the callbacks to a deterministic subset of this size picked from
+	// the healthy instances, optional
+	SubsetSize int
+	// SubsetKey choose the subset by rendezvous hashing, optional,default:ClientConfig.NamingSubsetKey or the local ip
+	SubsetKey string
+	// Selector filter the instances passed to the callbacks by the label expression before the subset, optional
+	Selector *model.ExpressionSelector
+}
+
+type WatchServicesParam struct {
+	NameSpace string                                   `param:"nameSpace"` //optional, namespaceId default:public
+	GroupName string                                   `param:"groupName"` //optional,default:DEFAULT_GROUP
+	Callback  func(added, removed []string, err error) //required
+}
+
+type ServicesIteratorParam struct {
+	NameSpace   string `param:"nameSpace"` //optional, namespaceId default:public
+	GroupName   string `param:"groupName"` //optional,default:DEFAULT_GROUP
+	PageSize    uint32 `param:"pageSize"`  //optional,default:500
+	Parallelism int    //optional,the pages are fetched one by one when it's not greater than 1
+}
+
+type WatchInstanceHealthParam struct {
+	IntervalMs uint64                                //optional,default:BeatInterval of the client config
+	Callback   func(event model.InstanceHealthEvent) //required
+}
+
+type ShutdownOnSignalParam struct {
+	Signals        []os.Signal                       //optional,default:SIGTERM and SIGINT
+	DrainMs        uint64                            //optional,the drain window of Shutdown
+	TimeoutMs      uint64                            //optional,the max time of the hooks and Shutdown, default:unlimited
+	BeforeShutdown []func(ctx context.Context) error //optional,run in order before disabling the instances
+	AfterShutdown  []func(ctx context.Context) error //optional,run in order after closing the client
+	Exit           func(code int)                    //optional,default:os.Exit, the code is 1 if Shutdown failed
 }
 
 type SelectAllInstancesParam struct {
 	Clusters    []string `param:"clusters"`    //optional
 	ServiceName string   `param:"serviceName"` //required
 	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string   `param:"namespaceId"` //optional,default:the namespace of the client
 }
 
 type SelectInstancesParam struct {
 	Clusters    []string `param:"clusters"`    //optional
 	ServiceName string   `param:"serviceName"` //required
 	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string   `param:"namespaceId"` //optional,default:the namespace of the client
 	HealthyOnly bool     `param:"healthyOnly"` //optional,value = true return only healthy instance, value = false return only unHealthy instance
+	// MetadataSelector optional,only the instances having all the metadata are returned, e.g. {"version": "v2", "region": "us-east"}
+	MetadataSelector map[string]string
+	// SubsetSize optional,only a deterministic subset of this size is returned when HealthyOnly is set
+	SubsetSize int
+	// SubsetKey optional,choose the subset by rendezvous hashing, default:ClientConfig.NamingSubsetKey or the local ip
+	SubsetKey string
+	// CacheOnly optional,only select from the cached services, ErrServiceNotCached is returned instead of subscribing
+	CacheOnly bool
 }
 
 type SelectOneHealthInstanceParam struct {
 	Clusters    []string `param:"clusters"`    //optional
 	ServiceName string   `param:"serviceName"` //required
 	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	NamespaceId string   `param:"namespaceId"` //optional,default:the namespace of the client
+	Strategy    string   `param:"strategy"`    //optional,default:weightedRandom
+	HashKey     string   `param:"hashKey"`     //optional,the key of the consistentHash strategy
+	Zone        string   `param:"zone"`        //optional,the zone preferred by the zoneAffinity strategy
+	// MetadataSelector optional,only the instances having all the metadata are selected
+	MetadataSelector map[string]string
+	// Excluded optional,the ip:port of the instances not to select, e.g. the ones failed in the previous tries
+	Excluded []string
+	// SubsetSize optional,select from a deterministic subset of this size of the healthy instances
+	SubsetSize int
+	// SubsetKey optional,choose the subset by rendezvous hashing, default:ClientConfig.NamingSubsetKey or the local ip
+	SubsetKey string
 }